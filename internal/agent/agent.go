@@ -1,5 +1,7 @@
 package agent
 
+import "gopkg.in/yaml.v3"
+
 // RoleGrant specifies a role and the privileges to grant it on an agent.
 // Corresponds to a single entry under deploy.grant.account_roles or
 // deploy.grant.database_roles in the YAML spec.
@@ -10,6 +12,9 @@ type RoleGrant struct {
 	// Privileges is the list of Snowflake privileges to grant.
 	// Use "ALL" to expand to USAGE, MODIFY, and MONITOR.
 	Privileges []string `yaml:"privileges" json:"privileges"`
+	// WithGrantOption grants the privileges WITH GRANT OPTION so the role
+	// can grant them to others.
+	WithGrantOption bool `yaml:"with_grant_option,omitempty" json:"with_grant_option,omitempty"`
 }
 
 // GrantConfig specifies role grants to apply whenever the agent is deployed.
@@ -51,6 +56,31 @@ type DeployConfig struct {
 	QuoteIdentifiers bool `yaml:"quote_identifiers,omitempty" json:"quote_identifiers,omitempty"`
 	// Grant configures GRANT/REVOKE statements applied after each apply.
 	Grant *GrantConfig `yaml:"grant,omitempty" json:"grant,omitempty"`
+	// Strategy controls how apply treats an agent that already exists:
+	// "create_only", "create_or_update" (the default when empty) or "replace".
+	Strategy string `yaml:"strategy,omitempty" json:"strategy,omitempty"`
+}
+
+// Deploy strategies accepted in deploy.strategy.
+const (
+	// StrategyCreateOnly creates the agent when it is missing and never
+	// touches an existing one (e.g. an agent managed elsewhere).
+	StrategyCreateOnly = "create_only"
+	// StrategyCreateOrUpdate creates a missing agent and updates the
+	// changed fields of an existing one. It is the default.
+	StrategyCreateOrUpdate = "create_or_update"
+	// StrategyReplace deletes and re-creates an existing agent that has
+	// changes instead of updating it in place.
+	StrategyReplace = "replace"
+)
+
+// DeployStrategy returns the spec's deploy.strategy, or
+// StrategyCreateOrUpdate when none is set.
+func (s AgentSpec) DeployStrategy() string {
+	if s.Deploy == nil || s.Deploy.Strategy == "" {
+		return StrategyCreateOrUpdate
+	}
+	return s.Deploy.Strategy
 }
 
 // EvalConfig contains evaluation test cases and judge configuration.
@@ -62,28 +92,63 @@ type EvalConfig struct {
 	// JudgeModel is the Snowflake Cortex model used to score responses.
 	// Defaults to the value in .coragent.toml or the built-in default.
 	JudgeModel string `yaml:"judge_model,omitempty" json:"judge_model,omitempty"`
+	// JudgePromptTemplate replaces the built-in judge prompt. It is a Go
+	// text/template with {{.Question}}, {{.Expected}} and {{.Actual}}.
+	// Defaults to the value in .coragent.toml or the built-in prompt.
+	JudgePromptTemplate string `yaml:"judge_prompt_template,omitempty" json:"judge_prompt_template,omitempty"`
 	// ResponseScoreThreshold is the minimum score (0–100) a response must
 	// achieve for the test case to be considered passed.
 	// A nil value means response scoring is disabled for the agent.
 	ResponseScoreThreshold *int `yaml:"response_score_threshold,omitempty" json:"response_score_threshold,omitempty"`
+	// PassRateThreshold is the fraction of tests (0–1) that must pass for
+	// the suite to pass, e.g. 0.9. A nil value means every result is
+	// reported but the suite verdict does not affect the exit code.
+	PassRateThreshold *float64 `yaml:"pass_rate_threshold,omitempty" json:"pass_rate_threshold,omitempty"`
+	// MaxResponseMs is the latency limit in milliseconds for the agent's
+	// reply to each test question; a slower reply fails the test.
+	// A nil value means latency is not checked.
+	MaxResponseMs *int `yaml:"max_response_ms,omitempty" json:"max_response_ms,omitempty"`
 }
 
 // EvalTestCase defines a single evaluation test case.
 type EvalTestCase struct {
 	// Question is the user message sent to the agent. Required.
 	Question string `yaml:"question" json:"question"`
+	// Conversation lists earlier user turns sent, in order, in the same
+	// thread before Question. Only the reply to Question is checked.
+	Conversation []string `yaml:"conversation,omitempty" json:"conversation,omitempty"`
 	// ExpectedTools lists tool names that must appear in the agent's response.
 	// The test passes only if every listed tool was invoked.
 	ExpectedTools []string `yaml:"expected_tools,omitempty" json:"expected_tools,omitempty"`
 	// ExpectedResponse is the ideal answer text used for LLM-based scoring.
 	// Requires a judge model to be configured.
 	ExpectedResponse string `yaml:"expected_response,omitempty" json:"expected_response,omitempty"`
+	// ExpectedSubstrings lists strings that must all appear in the agent's
+	// response. A cheap deterministic alternative to LLM-based scoring.
+	ExpectedSubstrings []string `yaml:"expected_substrings,omitempty" json:"expected_substrings,omitempty"`
+	// ExpectedSubstringsIgnoreCase makes the ExpectedSubstrings check
+	// case-insensitive.
+	ExpectedSubstringsIgnoreCase bool `yaml:"expected_substrings_ignore_case,omitempty" json:"expected_substrings_ignore_case,omitempty"`
 	// Command is a shell command that receives eval context via stdin (JSON)
 	// and signals pass/fail via exit code (0 = pass).
 	Command string `yaml:"command,omitempty" json:"command,omitempty"`
 	// ResponseScoreThreshold overrides the agent-level threshold for this
 	// specific test case. A pointer so that 0 can be used to disable scoring.
 	ResponseScoreThreshold *int `yaml:"response_score_threshold,omitempty" json:"response_score_threshold,omitempty"`
+	// MaxResponseMs overrides the agent-level latency limit for this test
+	// case. A pointer so that 0 can be used to disable the check.
+	MaxResponseMs *int `yaml:"max_response_ms,omitempty" json:"max_response_ms,omitempty"`
+}
+
+// PolicyConfig declares governance rules for the tools an agent may use.
+// It is stripped from the JSON payload (json:"-") and enforced at load time.
+type PolicyConfig struct {
+	// RequiredTools lists tools that must be declared. An entry matches a
+	// tool when it equals either tool_spec.name or tool_spec.type.
+	RequiredTools []string `yaml:"required_tools,omitempty" json:"required_tools,omitempty"`
+	// ForbiddenTools lists tools that must not be declared, matched the same
+	// way as RequiredTools (e.g. a type such as "sql_exec").
+	ForbiddenTools []string `yaml:"forbidden_tools,omitempty" json:"forbidden_tools,omitempty"`
 }
 
 // AgentSpec represents the Cortex Agent YAML/JSON schema payload.
@@ -93,7 +158,7 @@ type EvalTestCase struct {
 //   - Name, Comment, Profile, Models, Instructions, Orchestration, Tools, ToolResources
 //
 // Local-only fields (not part of the API contract):
-//   - Deploy, Eval
+//   - Deploy, Eval, Policy
 type AgentSpec struct {
 	// Deploy contains deployment-only settings (database, schema, grants).
 	// Not sent to the Snowflake API. Snowflake API counterpart: none.
@@ -101,6 +166,9 @@ type AgentSpec struct {
 	// Eval contains evaluation test cases run by the eval command.
 	// Not sent to the Snowflake API. Snowflake API counterpart: none.
 	Eval *EvalConfig `yaml:"eval,omitempty" json:"-"`
+	// Policy restricts which tools the agent may declare.
+	// Not sent to the Snowflake API. Snowflake API counterpart: none.
+	Policy *PolicyConfig `yaml:"policy,omitempty" json:"-"`
 	// Name is the agent identifier within its schema. Must be unique.
 	// Snowflake API counterpart: name.
 	Name string `yaml:"name" json:"name" validate:"required"`
@@ -143,6 +211,10 @@ type Models struct {
 	// Orchestration is the model used for the agent's main reasoning loop
 	// (e.g. "claude-3-5-sonnet", "llama3.1-70b").
 	Orchestration string `yaml:"orchestration,omitempty" json:"orchestration,omitempty"`
+	// Response is the model used to generate the final answer.
+	Response string `yaml:"response,omitempty" json:"response,omitempty"`
+	// ToolUse is the model used to choose and call tools.
+	ToolUse string `yaml:"tool_use,omitempty" json:"tool_use,omitempty"`
 }
 
 // Instructions configure the agent's prompts and example questions.
@@ -153,6 +225,8 @@ type Instructions struct {
 	Orchestration string `yaml:"orchestration,omitempty" json:"orchestration,omitempty"`
 	// System is the primary system prompt for the agent.
 	System string `yaml:"system,omitempty" json:"system,omitempty"`
+	// Examples is example interactions appended to the instructions.
+	Examples string `yaml:"examples,omitempty" json:"examples,omitempty"`
 	// SampleQuestions is a list of suggested questions shown in the chat UI.
 	SampleQuestions []SampleQuestion `yaml:"sample_questions,omitempty" json:"sample_questions,omitempty"`
 }
@@ -188,6 +262,39 @@ type Tool struct {
 
 // ToolResources allows per-tool configuration blocks keyed by tool name.
 // Keys must match the name field inside the corresponding tool_spec.
-// Values are tool-specific resource maps (e.g. semantic_view, search_service).
-type ToolResources map[string]map[string]any
+// Values are tool-specific resource maps (e.g. semantic_view, search_service),
+// or a list of such maps when a tool is configured with several resources.
+type ToolResources map[string]any
+
+// UnmarshalYAML decodes through a plain map so that resource maps nested in
+// tool_resources are map[string]any rather than ToolResources, which is what
+// yaml.v3 produces for maps inside a named map type.
+func (r *ToolResources) UnmarshalYAML(node *yaml.Node) error {
+	var m map[string]any
+	if err := node.Decode(&m); err != nil {
+		return err
+	}
+	*r = m
+	return nil
+}
+
+// Entries returns the resource maps configured for tool: one for the map
+// form, one per element for the list form. Non-map elements are skipped.
+func (r ToolResources) Entries(tool string) []map[string]any {
+	switch v := r[tool].(type) {
+	case map[string]any:
+		return []map[string]any{v}
+	case []map[string]any:
+		return v
+	case []any:
+		entries := make([]map[string]any, 0, len(v))
+		for _, item := range v {
+			if m, ok := item.(map[string]any); ok {
+				entries = append(entries, m)
+			}
+		}
+		return entries
+	}
+	return nil
+}
 
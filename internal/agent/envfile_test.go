@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	input := `# database settings
+MY_DATABASE=PROD_DB
+export MY_SCHEMA = PUBLIC   # trailing comment
+EMPTY=
+HASH=abc#def
+DOUBLE="line one\nline \"two\"" # comment
+SINGLE='literal \n ${ env.X } # kept'
+SPACED="  padded  "
+
+MY_DATABASE=OVERRIDE_DB
+`
+	got, err := ParseEnvFile(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseEnvFile: %v", err)
+	}
+	want := map[string]string{
+		"MY_DATABASE": "OVERRIDE_DB",
+		"MY_SCHEMA":   "PUBLIC",
+		"EMPTY":       "",
+		"HASH":        "abc#def",
+		"DOUBLE":      "line one\nline \"two\"",
+		"SINGLE":      `literal \n ${ env.X } # kept`,
+		"SPACED":      "  padded  ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseEnvFile =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestParseEnvFile_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"missing equals", "A=1\nJUST_A_KEY\n", "line 2: expected KEY=VALUE"},
+		{"invalid name", "1BAD=x\n", `invalid variable name "1BAD"`},
+		{"unterminated double", `A="open`, "unterminated double quote"},
+		{"unterminated single", `A='open`, "unterminated single quote"},
+		{"text after quote", `A="x" y`, "unexpected text after quoted value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEnvFile(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadEnvFile_Missing(t *testing.T) {
+	_, err := LoadEnvFile(filepath.Join(t.TempDir(), "missing.env"))
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreFileName is the file at the root of a directory scan that lists
+// paths LoadAgents skips.
+const ignoreFileName = ".coragentignore"
+
+// ignoreRule is one pattern line of a .coragentignore file.
+type ignoreRule struct {
+	segments []string // slash-separated glob segments; "**" matches any depth
+	negate   bool     // "!pattern" re-includes a previously ignored path
+	dirOnly  bool     // "pattern/" matches directories only
+}
+
+// ignoreMatcher holds the rules of a .coragentignore file. A nil matcher
+// ignores nothing.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+// loadIgnoreFile reads .coragentignore in dir. It returns nil when the file
+// does not exist.
+//
+// The syntax follows .gitignore: blank lines and lines starting with # are
+// skipped, "!" negates a pattern, a trailing "/" matches only directories,
+// and "**" matches any number of directories. A pattern without a slash
+// (other than a trailing one) matches at any depth; otherwise it is relative
+// to dir.
+func loadIgnoreFile(dir string) (*ignoreMatcher, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ignoreFileName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", ignoreFileName, err)
+	}
+
+	m := &ignoreMatcher{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule ignoreRule
+		if after, ok := strings.CutPrefix(line, "!"); ok {
+			rule.negate = true
+			line = after
+		}
+		if after, ok := strings.CutSuffix(line, "/"); ok {
+			rule.dirOnly = true
+			line = after
+		}
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		if line == "" {
+			continue
+		}
+		if !anchored {
+			line = "**/" + line
+		}
+		rule.segments = strings.Split(line, "/")
+		for _, seg := range rule.segments {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid pattern %q: %w", ignoreFileName, lineNo, scanner.Text(), err)
+			}
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return m, nil
+}
+
+// Match reports whether rel, a slash-separated path relative to the scan
+// root, is ignored. The last matching rule wins.
+func (m *ignoreMatcher) Match(rel string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(rule.segments, parts) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches path segments against glob segments, where a "**"
+// segment matches zero or more path segments.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
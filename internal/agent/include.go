@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// includeWrapper is used for lenient extraction of the include list.
+type includeWrapper struct {
+	Include []string `yaml:"include"`
+}
+
+// resolveIncludes merges the fragments listed under the top-level `include`
+// key into doc and removes the key. Fragment paths are relative to the
+// directory of path. Fields in doc win over fragment fields; among fragments,
+// later entries win over earlier ones. Mappings are merged recursively while
+// scalars and sequences are replaced as a whole.
+func resolveIncludes(doc *yaml.Node, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path %q: %w", path, err)
+	}
+	return mergeIncludes(doc, abs, []string{abs})
+}
+
+func mergeIncludes(doc *yaml.Node, path string, stack []string) error {
+	mapping := rootMapping(doc)
+	if mapping == nil {
+		return nil
+	}
+	includes, err := takeIncludeNode(mapping)
+	if err != nil {
+		return err
+	}
+
+	fragments := make([]*yaml.Node, 0, len(includes))
+	for _, inc := range includes {
+		incPath := includePath(path, inc)
+		if slices.Contains(stack, incPath) {
+			return fmt.Errorf("include cycle: %s", strings.Join(append(stack, incPath), " -> "))
+		}
+		fragment, err := loadFragment(incPath, append(stack, incPath))
+		if err != nil {
+			return err
+		}
+		fragments = append(fragments, fragment)
+	}
+
+	for i := len(fragments) - 1; i >= 0; i-- {
+		mergeMissing(mapping, fragments[i])
+	}
+	return nil
+}
+
+// includePath resolves an include entry of the file at path: relative
+// entries are taken from the directory of path.
+func includePath(path, inc string) string {
+	if !filepath.IsAbs(inc) {
+		inc = filepath.Join(filepath.Dir(path), inc)
+	}
+	return filepath.Clean(inc)
+}
+
+// loadFragment reads an include fragment and resolves its own includes.
+func loadFragment(path string, stack []string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", path, err)
+	}
+	data, err = normalizeEncoding(data)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", path, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("include %q: parse YAML: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	mapping := rootMapping(&doc)
+	if mapping == nil {
+		return nil, fmt.Errorf("include %q: fragment must be a YAML mapping", path)
+	}
+	stripSchemaKey(&doc)
+	for i := 0; i < len(mapping.Content)-1; i += 2 {
+		if mapping.Content[i].Value == "vars" {
+			return nil, fmt.Errorf("include %q: vars is not allowed in fragments; define vars in the including file", path)
+		}
+	}
+	if err := mergeIncludes(&doc, path, stack); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// takeIncludeNode removes the `include` key from mapping and returns its
+// entries.
+func takeIncludeNode(mapping *yaml.Node) ([]string, error) {
+	for i := 0; i < len(mapping.Content)-1; i += 2 {
+		if mapping.Content[i].Value != "include" {
+			continue
+		}
+		var includes []string
+		if err := mapping.Content[i+1].Decode(&includes); err != nil {
+			return nil, fmt.Errorf("include must be a list of file paths")
+		}
+		mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+		return includes, nil
+	}
+	return nil, nil
+}
+
+// mergeMissing copies keys from src into dst that dst does not define,
+// recursing into mappings present on both sides.
+func mergeMissing(dst, src *yaml.Node) {
+	for i := 0; i < len(src.Content)-1; i += 2 {
+		key, val := src.Content[i], src.Content[i+1]
+		existing := mappingValue(dst, key.Value)
+		if existing == nil {
+			dst.Content = append(dst.Content, key, val)
+			continue
+		}
+		if existing.Kind == yaml.MappingNode && val.Kind == yaml.MappingNode {
+			mergeMissing(existing, val)
+		}
+	}
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(mapping.Content)-1; i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func rootMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// includedFiles returns the absolute paths of fragments referenced by the
+// given spec files, directly or through other fragments, so directory loads
+// do not treat them as agents.
+func includedFiles(files []string) map[string]bool {
+	out := make(map[string]bool)
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		collectIncludes(abs, []string{abs}, out)
+	}
+	return out
+}
+
+// collectIncludes adds the fragments included by path, and those they
+// include in turn, to out. Like loadFragment it tracks the include stack to
+// stop at cycles; unreadable files and cycles are reported when the agent
+// itself is loaded.
+func collectIncludes(path string, stack []string, out map[string]bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	var wrapper includeWrapper
+	if err := yaml.Unmarshal(data, &wrapper); err != nil {
+		return
+	}
+	for _, inc := range wrapper.Include {
+		incPath := includePath(path, inc)
+		if slices.Contains(stack, incPath) {
+			continue
+		}
+		out[incPath] = true
+		collectIncludes(incPath, append(stack, incPath), out)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"gopkg.in/yaml.v3"
 )
@@ -17,6 +18,19 @@ type ParsedAgent struct {
 	Spec AgentSpec
 }
 
+// LoadFailure records a file that LoadAgentsResult could not load.
+type LoadFailure struct {
+	Path string
+	Err  error
+}
+
+// LoadResult holds the outcome of LoadAgentsResult: the agents that loaded
+// and the files that failed, both in path order.
+type LoadResult struct {
+	Agents   []ParsedAgent
+	Failures []LoadFailure
+}
+
 // LoadAgents loads agent specs from a file or directory.
 // If path is empty, it defaults to the current directory.
 // If recursive is true and path is a directory, it will recursively load from subdirectories.
@@ -42,7 +56,76 @@ func LoadAgents(path string, recursive bool, envName string) ([]ParsedAgent, err
 	return []ParsedAgent{{Path: path, Spec: spec}}, nil
 }
 
+// LoadAgentsResult loads agent specs like LoadAgents but keeps going when a
+// file fails to parse or validate, so every problem can be reported at once.
+// Per-file errors are collected in LoadResult.Failures; the returned error is
+// only set when the path itself cannot be scanned (missing path, unreadable
+// directory, invalid .coragentignore, no YAML files).
+//
+// Deploying commands should keep using LoadAgents, which stops at the first
+// invalid file so that a partial set of agents is never applied.
+func LoadAgentsResult(path string, recursive bool, envName string) (LoadResult, error) {
+	if strings.TrimSpace(path) == "" {
+		path = "."
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return LoadResult{}, fmt.Errorf("stat path %q: %w", path, err)
+	}
+
+	files := []string{path}
+	if info.IsDir() {
+		files, err = agentFiles(path, recursive)
+		if err != nil {
+			return LoadResult{}, err
+		}
+	}
+
+	var result LoadResult
+	for _, file := range files {
+		spec, err := loadFromFile(file, envName)
+		if err != nil {
+			result.Failures = append(result.Failures, LoadFailure{Path: file, Err: err})
+			continue
+		}
+		result.Agents = append(result.Agents, ParsedAgent{Path: file, Spec: spec})
+	}
+	return result, nil
+}
+
 func loadFromDir(dir string, recursive bool, envName string) ([]ParsedAgent, error) {
+	files, err := agentFiles(dir, recursive)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ParsedAgent, 0, len(files))
+	for _, file := range files {
+		spec, err := loadFromFile(file, envName)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, ParsedAgent{Path: file, Spec: spec})
+	}
+	return results, nil
+}
+
+// agentFiles lists the agent YAML files in dir in sorted order, honoring
+// .coragentignore and skipping files pulled in by another file's include.
+func agentFiles(dir string, recursive bool) ([]string, error) {
+	ignore, err := loadIgnoreFile(dir)
+	if err != nil {
+		return nil, err
+	}
+	ignored := func(path string, isDir bool) bool {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return false
+		}
+		return ignore.Match(filepath.ToSlash(rel), isDir)
+	}
+
 	var files []string
 	if recursive {
 		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
@@ -50,9 +133,12 @@ func loadFromDir(dir string, recursive bool, envName string) ([]ParsedAgent, err
 				return walkErr
 			}
 			if d.IsDir() {
+				if ignored(path, true) {
+					return filepath.SkipDir
+				}
 				return nil
 			}
-			if isYAML(path) {
+			if isYAML(path) && !ignored(path, false) {
 				files = append(files, path)
 			}
 			return nil
@@ -70,7 +156,7 @@ func loadFromDir(dir string, recursive bool, envName string) ([]ParsedAgent, err
 				continue
 			}
 			path := filepath.Join(dir, entry.Name())
-			if isYAML(path) {
+			if isYAML(path) && !ignored(path, false) {
 				files = append(files, path)
 			}
 		}
@@ -81,15 +167,17 @@ func loadFromDir(dir string, recursive bool, envName string) ([]ParsedAgent, err
 		return nil, fmt.Errorf("no YAML files found in %q", dir)
 	}
 
-	results := make([]ParsedAgent, 0, len(files))
+	// Files pulled in via `include:` are fragments, not agents.
+	fragments := includedFiles(files)
+
+	agents := make([]string, 0, len(files))
 	for _, file := range files {
-		spec, err := loadFromFile(file, envName)
-		if err != nil {
-			return nil, err
+		if abs, err := filepath.Abs(file); err == nil && fragments[abs] {
+			continue
 		}
-		results = append(results, ParsedAgent{Path: file, Spec: spec})
+		agents = append(agents, file)
 	}
-	return results, nil
+	return agents, nil
 }
 
 func loadFromFile(path string, envName string) (AgentSpec, error) {
@@ -98,6 +186,11 @@ func loadFromFile(path string, envName string) (AgentSpec, error) {
 		return AgentSpec{}, fmt.Errorf("read file %q: %w", path, err)
 	}
 
+	data, err = normalizeEncoding(data)
+	if err != nil {
+		return AgentSpec{}, fmt.Errorf("%s: %w", path, err)
+	}
+
 	// 1st pass: extract vars section (lenient parse)
 	var wrapper varsWrapper
 	if err := yaml.Unmarshal(data, &wrapper); err != nil {
@@ -116,8 +209,19 @@ func loadFromFile(path string, envName string) (AgentSpec, error) {
 		return AgentSpec{}, fmt.Errorf("parse YAML %q: %w", path, err)
 	}
 
-	// Strip vars node before KnownFields check
+	// Strip vars node and the editor's $schema key before KnownFields check
 	stripVarsNode(&doc)
+	stripSchemaKey(&doc)
+
+	// Merge include fragments so vars also apply to their content
+	if err := resolveIncludes(&doc, path); err != nil {
+		return AgentSpec{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Apply the selected environment's structural overrides
+	if err := applyEnvOverrides(&doc, envName); err != nil {
+		return AgentSpec{}, fmt.Errorf("%s: %w", path, err)
+	}
 
 	// Substitute variable references
 	if err := substituteVars(&doc, resolved); err != nil {
@@ -157,6 +261,35 @@ func loadFromFile(path string, envName string) (AgentSpec, error) {
 	return spec, nil
 }
 
+// schemaKey is the top-level key editors read to find a JSON Schema for the
+// file. The loader accepts it so annotated specs pass KnownFields, and drops
+// it before the spec is decoded, so it is never deployed.
+const schemaKey = "$schema"
+
+// stripSchemaKey removes a top-level $schema key from doc.
+func stripSchemaKey(doc *yaml.Node) {
+	if mapping := rootMapping(doc); mapping != nil {
+		takeMappingKey(mapping, schemaKey)
+	}
+}
+
+// utf8BOM is the byte order mark some Windows editors prepend to UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizeEncoding strips a leading UTF-8 BOM and rejects content that is
+// not valid UTF-8, so encoding problems are not reported as YAML errors.
+func normalizeEncoding(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size <= 1 {
+			return nil, fmt.Errorf("file is not valid UTF-8 at byte %d", i)
+		}
+		i += size
+	}
+	return data, nil
+}
+
 func isYAML(path string) bool {
 	name := filepath.Base(path)
 	if strings.HasPrefix(name, ".") {
@@ -175,17 +308,55 @@ func validateAgentSpec(spec AgentSpec) error {
 			return fmt.Errorf("tools[%d].tool_spec is required", i)
 		}
 	}
+	if err := validateToolNames(spec); err != nil {
+		return err
+	}
 	if spec.Deploy != nil && spec.Deploy.Grant != nil {
 		if err := validateGrantConfig(spec.Deploy.Grant); err != nil {
 			return fmt.Errorf("grant: %w", err)
 		}
 	}
+	switch spec.DeployStrategy() {
+	case StrategyCreateOnly, StrategyCreateOrUpdate, StrategyReplace:
+	default:
+		return fmt.Errorf("deploy.strategy must be %s, %s or %s, got %q", StrategyCreateOnly, StrategyCreateOrUpdate, StrategyReplace, spec.Deploy.Strategy)
+	}
 	if spec.Eval != nil {
 		for i, tc := range spec.Eval.Tests {
-			if len(tc.ExpectedTools) == 0 && strings.TrimSpace(tc.Command) == "" && strings.TrimSpace(tc.ExpectedResponse) == "" {
-				return fmt.Errorf("eval.tests[%d]: expected_tools, expected_response, or command is required", i)
+			latencyOnly := tc.MaxResponseMs != nil && *tc.MaxResponseMs > 0
+			if len(tc.ExpectedTools) == 0 && len(tc.ExpectedSubstrings) == 0 && strings.TrimSpace(tc.Command) == "" && strings.TrimSpace(tc.ExpectedResponse) == "" && !latencyOnly {
+				return fmt.Errorf("eval.tests[%d]: expected_tools, expected_response, expected_substrings, command, or max_response_ms is required", i)
+			}
+			for j, sub := range tc.ExpectedSubstrings {
+				if sub == "" {
+					return fmt.Errorf("eval.tests[%d].expected_substrings[%d] must not be empty", i, j)
+				}
+			}
+			if err := validateConversation(i, tc); err != nil {
+				return err
 			}
 		}
+		if v := spec.Eval.PassRateThreshold; v != nil && (*v < 0 || *v > 1) {
+			return fmt.Errorf("eval.pass_rate_threshold must be between 0 and 1, got %g", *v)
+		}
+		if err := validateMaxResponseMs(spec.Eval); err != nil {
+			return err
+		}
+	}
+	if err := validateToolResourceRefs(spec); err != nil {
+		return err
+	}
+	if err := validateToolResources(spec); err != nil {
+		return err
+	}
+	if err := validateResourceFQNs(spec); err != nil {
+		return err
+	}
+	if err := validatePolicy(spec); err != nil {
+		return err
+	}
+	if err := validateTextFields(spec); err != nil {
+		return err
 	}
 	return nil
 }
@@ -1,8 +1,11 @@
 package agent
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -52,6 +55,57 @@ func TestLoadAgentsFromDir(t *testing.T) {
 	}
 }
 
+func TestLoadAgentsResultContinuesPastInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.yaml":   "name: a",
+		"bad.yaml": "name: bad\nunknown_field: true",
+		"c.yaml":   "name: c",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	result, err := LoadAgentsResult(dir, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgentsResult error: %v", err)
+	}
+	if len(result.Agents) != 2 || result.Agents[0].Spec.Name != "a" || result.Agents[1].Spec.Name != "c" {
+		t.Errorf("expected agents a and c, got %+v", result.Agents)
+	}
+	if len(result.Failures) != 1 {
+		t.Fatalf("expected 1 failure, got %+v", result.Failures)
+	}
+	if f := result.Failures[0]; f.Path != filepath.Join(dir, "bad.yaml") || !strings.Contains(f.Err.Error(), "unknown_field") {
+		t.Errorf("unexpected failure: path %q, err %v", f.Path, f.Err)
+	}
+
+	// The strict loader still stops at the invalid file.
+	if _, err := LoadAgents(dir, false, ""); err == nil {
+		t.Error("expected LoadAgents to fail on the invalid file")
+	}
+}
+
+func TestLoadAgentsResultSingleFileFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.yaml")
+	if err := os.WriteFile(path, []byte("key: [unclosed"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	result, err := LoadAgentsResult(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgentsResult error: %v", err)
+	}
+	if len(result.Agents) != 0 || len(result.Failures) != 1 || result.Failures[0].Path != path {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	if _, err := LoadAgentsResult(filepath.Join(t.TempDir(), "missing.yaml"), false, ""); err == nil {
+		t.Error("expected an error for a missing path")
+	}
+}
+
 func TestLoadAgentsFromDirNonRecursive(t *testing.T) {
 	dir := t.TempDir()
 	// Create file in top-level directory
@@ -139,6 +193,132 @@ func TestLoadAgentsSkipsDotFiles(t *testing.T) {
 	}
 }
 
+func TestLoadAgentWithPerPhaseModels(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+models:
+  orchestration: claude-4-sonnet
+  response: claude-3-5-sonnet
+  tool_use: llama3.1-70b
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	models := agents[0].Spec.Models
+	if models == nil || models.Orchestration != "claude-4-sonnet" || models.Response != "claude-3-5-sonnet" || models.ToolUse != "llama3.1-70b" {
+		t.Fatalf("unexpected models: %+v", models)
+	}
+}
+
+func TestLoadAgentWithInstructions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+instructions:
+  system: You are a sales analyst.
+  orchestration: Prefer the sales_view tool.
+  response: Answer in one paragraph.
+  examples: "Q: Top region? A: EMEA."
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	want := &Instructions{
+		System:        "You are a sales analyst.",
+		Orchestration: "Prefer the sales_view tool.",
+		Response:      "Answer in one paragraph.",
+		Examples:      "Q: Top region? A: EMEA.",
+	}
+	if got := agents[0].Spec.Instructions; !reflect.DeepEqual(got, want) {
+		t.Fatalf("instructions = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadAgentsHonorsCoragentIgnore(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"agent.yaml":                       "name: root-agent",
+		"docker-compose.yaml":              "services: {}",
+		"ci/pipeline.yml":                  "stages: []",
+		"agents/sales/agent.yaml":          "name: sales-agent",
+		"agents/sales/values.yaml":         "replicas: 1",
+		"agents/hr/agent.yaml":             "name: hr-agent",
+		"agents/hr/values.yaml":            "replicas: 2",
+		"agents/hr/keep/values.yaml":       "name: kept-agent",
+		"agents/legacy/agent.yaml":         "name: legacy-agent",
+		"deep/nested/docker-compose.yaml":  "services: {}",
+		"deep/nested/another/agent.yml":    "name: deep-agent",
+		"agents/legacy/fixtures/data.yaml": "rows: []",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	ignore := `# not agent specs
+docker-compose.yaml
+ci/
+agents/**/values.yaml
+!agents/hr/keep/values.yaml
+/agents/legacy
+`
+	if err := os.WriteFile(filepath.Join(dir, ".coragentignore"), []byte(ignore), 0o644); err != nil {
+		t.Fatalf("write ignore file: %v", err)
+	}
+
+	agents, err := LoadAgents(dir, true, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	var names []string
+	for _, a := range agents {
+		names = append(names, a.Spec.Name)
+	}
+	want := []string{"root-agent", "hr-agent", "kept-agent", "sales-agent", "deep-agent"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("loaded agents = %v, want %v", names, want)
+	}
+
+	agents, err = LoadAgents(dir, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents (non-recursive) error: %v", err)
+	}
+	if len(agents) != 1 || agents[0].Spec.Name != "root-agent" {
+		t.Fatalf("non-recursive load should skip the ignored docker-compose.yaml, got %+v", agents)
+	}
+}
+
+func TestLoadAgentsRejectsInvalidIgnorePattern(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "agent.yaml"), []byte("name: a"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".coragentignore"), []byte("[invalid\n"), 0o644); err != nil {
+		t.Fatalf("write ignore file: %v", err)
+	}
+	_, err := LoadAgents(dir, true, "")
+	if err == nil || !strings.Contains(err.Error(), ".coragentignore:1") {
+		t.Fatalf("expected invalid pattern error with line number, got %v", err)
+	}
+}
+
 func TestLoadAgentsRejectsUnknownFields(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "agent.yaml")
@@ -153,6 +333,37 @@ func TestLoadAgentsRejectsUnknownFields(t *testing.T) {
 	}
 }
 
+func TestLoadAgentIgnoresSchemaKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	if err := os.WriteFile(path, []byte(`# yaml-language-server: $schema=./coragent.schema.json
+$schema: ./coragent.schema.json
+name: test-agent
+include:
+  - common.yaml
+`), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "common.yaml"), []byte("$schema: ./coragent.schema.json\ncomment: shared\n"), 0o644); err != nil {
+		t.Fatalf("write fragment: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	if len(agents) != 1 || agents[0].Spec.Name != "test-agent" || agents[0].Spec.Comment != "shared" {
+		t.Fatalf("unexpected agents: %+v", agents)
+	}
+	payload, err := json.Marshal(agents[0].Spec)
+	if err != nil {
+		t.Fatalf("marshal spec: %v", err)
+	}
+	if strings.Contains(string(payload), "schema.json") {
+		t.Errorf("$schema leaked into the deploy payload: %s", payload)
+	}
+}
+
 func TestLoadAgentWithGrant(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "agent.yaml")
@@ -219,6 +430,43 @@ deploy:
 	}
 }
 
+func TestLoadAgentDeployStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		deploy  string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", deploy: "", want: StrategyCreateOrUpdate},
+		{name: "create_only", deploy: "deploy:\n  strategy: create_only\n", want: StrategyCreateOnly},
+		{name: "create_or_update", deploy: "deploy:\n  strategy: create_or_update\n", want: StrategyCreateOrUpdate},
+		{name: "replace", deploy: "deploy:\n  strategy: replace\n", want: StrategyReplace},
+		{name: "unknown", deploy: "deploy:\n  strategy: upsert\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "agent.yaml")
+			if err := os.WriteFile(path, []byte("name: test-agent\n"+tt.deploy), 0o644); err != nil {
+				t.Fatalf("write file: %v", err)
+			}
+
+			agents, err := LoadAgents(path, false, "")
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "deploy.strategy") {
+					t.Fatalf("expected deploy.strategy error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadAgents error: %v", err)
+			}
+			if got := agents[0].Spec.DeployStrategy(); got != tt.want {
+				t.Errorf("DeployStrategy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestLoadAgentRejectsInvalidPrivilege(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "agent.yaml")
@@ -553,6 +801,49 @@ eval:
 	}
 }
 
+func TestLoadAgentRejectsPassRateAboveOne(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+eval:
+  pass_rate_threshold: 90
+  tests:
+    - question: "test question"
+      expected_tools: [sales_view]
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil || !strings.Contains(err.Error(), "pass_rate_threshold") {
+		t.Fatalf("expected pass_rate_threshold range error, got %v", err)
+	}
+}
+
+func TestLoadAgentRejectsNegativeMaxResponseMs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+eval:
+  max_response_ms: 5000
+  tests:
+    - question: "test question"
+      expected_tools: [sales_view]
+      max_response_ms: -1
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil || !strings.Contains(err.Error(), "eval.tests[0].max_response_ms") {
+		t.Fatalf("expected max_response_ms error, got %v", err)
+	}
+}
+
 func TestLoadAgentWithEvalCommand(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "agent.yaml")
@@ -742,6 +1033,155 @@ eval:
 	}
 }
 
+func TestLoadAgentWithMaxResponseMsOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+eval:
+  tests:
+    - question: "Say hello"
+      max_response_ms: 3000
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	parsed, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents: %v", err)
+	}
+	tc := parsed[0].Spec.Eval.Tests[0]
+	if tc.MaxResponseMs == nil || *tc.MaxResponseMs != 3000 {
+		t.Fatalf("max_response_ms = %v, want 3000", tc.MaxResponseMs)
+	}
+}
+
+func TestLoadAgentRejectsZeroMaxResponseMsOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+eval:
+  tests:
+    - question: "Say hello"
+      max_response_ms: 0
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil || !strings.Contains(err.Error(), "max_response_ms") {
+		t.Fatalf("expected missing assertion error, got %v", err)
+	}
+}
+
+func TestLoadAgentWithExpectedSubstringsOnly(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+eval:
+  tests:
+    - question: "What was revenue?"
+      expected_substrings: ["revenue", "USD"]
+      expected_substrings_ignore_case: true
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	tc := agents[0].Spec.Eval.Tests[0]
+	if len(tc.ExpectedSubstrings) != 2 || !tc.ExpectedSubstringsIgnoreCase {
+		t.Errorf("unexpected test case: %+v", tc)
+	}
+}
+
+func TestLoadAgentRejectsEmptyExpectedSubstring(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+eval:
+  tests:
+    - question: "test question"
+      expected_substrings: ["revenue", ""]
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil || !strings.Contains(err.Error(), "expected_substrings[1]") {
+		t.Fatalf("expected error for empty substring, got %v", err)
+	}
+}
+
+func TestLoadAgentWithConversation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+eval:
+  tests:
+    - conversation:
+        - "Show revenue by region"
+        - "Only EMEA"
+      question: "And last quarter?"
+      expected_tools: [sales_view]
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	tc := agents[0].Spec.Eval.Tests[0]
+	if strings.Join(tc.Conversation, "|") != "Show revenue by region|Only EMEA" || tc.Question != "And last quarter?" {
+		t.Errorf("unexpected test case: %+v", tc)
+	}
+}
+
+func TestLoadAgentRejectsInvalidConversation(t *testing.T) {
+	tests := []struct {
+		name    string
+		test    string
+		wantErr string
+	}{
+		{
+			name:    "empty turn",
+			test:    "{question: q, conversation: [first, \"\"], expected_tools: [t]}",
+			wantErr: "eval.tests[0].conversation[1] must not be empty",
+		},
+		{
+			name:    "missing question",
+			test:    "{conversation: [first], command: \"true\"}",
+			wantErr: "eval.tests[0]: conversation requires question",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "agent.yaml")
+			content := "name: test-agent\neval:\n  tests:\n    - " + tt.test + "\n"
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("write file: %v", err)
+			}
+			_, err := LoadAgents(path, false, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
 func TestLoadAgentWithVars(t *testing.T) {
 	dir := t.TempDir()
 	path := filepath.Join(dir, "agent.yaml")
@@ -843,3 +1283,713 @@ unknown_field: oops
 		t.Fatal("expected error for unknown field, got nil")
 	}
 }
+
+func TestLoadAgentWithEnvRefs(t *testing.T) {
+	t.Setenv("CORAGENT_TEST_DB", "PROD_DB")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+vars:
+  default:
+    SCHEMA: PUBLIC
+name: test-agent
+deploy:
+  database: ${ env.CORAGENT_TEST_DB }
+  schema: ${ vars.SCHEMA }
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	deploy := agents[0].Spec.Deploy
+	if deploy.Database != "PROD_DB" || deploy.Schema != "PUBLIC" {
+		t.Fatalf("deploy = %s.%s, want PROD_DB.PUBLIC", deploy.Database, deploy.Schema)
+	}
+}
+
+func TestLoadAgentRejectsUnsetEnvRef(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte("name: ${ env.CORAGENT_TEST_UNSET_XYZ }\n"), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for unset env variable, got nil")
+	}
+	if !strings.Contains(err.Error(), "CORAGENT_TEST_UNSET_XYZ") || !strings.Contains(err.Error(), path) {
+		t.Errorf("error should name the variable and file, got: %v", err)
+	}
+}
+
+func TestLoadAgentWithEnvRefsRejectsUnknownFields(t *testing.T) {
+	t.Setenv("CORAGENT_TEST_DB", "PROD_DB")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+deploy:
+  database: ${ env.CORAGENT_TEST_DB }
+unknown_field: oops
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for unknown field, got nil")
+	}
+}
+
+func TestLoadAgentRejectsForbiddenTool(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+policy:
+  forbidden_tools:
+    - sql_exec
+tools:
+  - tool_spec:
+      type: sql_exec
+      name: run_sql
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for forbidden tool, got nil")
+	}
+	if !strings.Contains(err.Error(), `forbidden tool "sql_exec"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadAgentRejectsMissingRequiredTool(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+policy:
+  required_tools:
+    - docs_search
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: sales_view
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for missing required tool, got nil")
+	}
+	if !strings.Contains(err.Error(), `required tool "docs_search"`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadAgentWithSatisfiedPolicy(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: test-agent
+policy:
+  required_tools:
+    - cortex_search
+  forbidden_tools:
+    - sql_exec
+tools:
+  - tool_spec:
+      type: cortex_search
+      name: docs_search
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	if agents[0].Spec.Policy == nil || len(agents[0].Spec.Policy.RequiredTools) != 1 {
+		t.Fatalf("expected policy to be loaded, got %+v", agents[0].Spec.Policy)
+	}
+}
+
+func TestLoadAgentStripsUTF8BOM(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	data := append([]byte{0xEF, 0xBB, 0xBF}, []byte("name: bom-agent\ncomment: from windows\n")...)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	if agents[0].Spec.Name != "bom-agent" {
+		t.Fatalf("unexpected name: %s", agents[0].Spec.Name)
+	}
+}
+
+func TestLoadAgentRejectsInvalidUTF8(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	// "name: a" followed by a Latin-1 encoded "é" (0xE9).
+	data := []byte("name: a\ncomment: caf\xe9\n")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err := LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for invalid UTF-8")
+	}
+	if !strings.Contains(err.Error(), "file is not valid UTF-8 at byte 20") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadAgentWithMultipleSemanticViews(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: multi-view
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+tool_resources:
+  analyst:
+    semantic_view:
+      - DB.SCH.SALES
+      - DB.SCH.ORDERS
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	views, ok := agents[0].Spec.ToolResources.Entries("analyst")[0]["semantic_view"].([]any)
+	if !ok || len(views) != 2 {
+		t.Fatalf("expected two semantic views, got %#v", agents[0].Spec.ToolResources.Entries("analyst")[0]["semantic_view"])
+	}
+	if views[0] != "DB.SCH.SALES" || views[1] != "DB.SCH.ORDERS" {
+		t.Errorf("unexpected semantic views: %v", views)
+	}
+}
+
+func TestLoadAgentWithMultipleResourceEntries(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: multi-entry
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+tool_resources:
+  analyst:
+    - semantic_view: DB.SCH.SALES
+      execution_environment: {type: warehouse, warehouse: WH_A}
+    - semantic_view: DB.SCH.ORDERS
+      execution_environment: {type: warehouse, warehouse: WH_B}
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	entries := agents[0].Spec.ToolResources.Entries("analyst")
+	if len(entries) != 2 || entries[0]["semantic_view"] != "DB.SCH.SALES" || entries[1]["semantic_view"] != "DB.SCH.ORDERS" {
+		t.Fatalf("analyst entries = %#v, want both resources in order", entries)
+	}
+}
+
+func TestLoadAgentRejectsUnqualifiedResourceEntry(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: multi-entry
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+tool_resources:
+  analyst:
+    - semantic_view: DB.SCH.SALES
+    - semantic_view: ORDERS
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil || !strings.Contains(err.Error(), `tool_resources.analyst[1].semantic_view "ORDERS" must be a fully qualified name`) {
+		t.Fatalf("expected unqualified entry error, got %v", err)
+	}
+}
+
+func TestLoadAgentRejectsDuplicateSemanticViews(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: multi-view
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+tool_resources:
+  analyst:
+    semantic_view: [DB.SCH.SALES, DB.SCH.SALES]
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for duplicate semantic views")
+	}
+	if !strings.Contains(err.Error(), `tool_resources.analyst.semantic_view lists "DB.SCH.SALES" more than once`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadAgentAcceptsQualifiedResourceNames(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: fqn
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+  - tool_spec:
+      type: cortex_search
+      name: docs
+tool_resources:
+  analyst:
+    semantic_view: DB.SCH.SALES
+  docs:
+    search_service: '"My.DB".SCH."Docs Search"'
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if _, err := LoadAgents(path, false, ""); err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+}
+
+func TestLoadAgentRejectsUnqualifiedResourceNames(t *testing.T) {
+	tests := []struct {
+		name      string
+		toolType  string
+		resources string
+		wantErr   string
+	}{
+		{
+			name:      "search service",
+			toolType:  "cortex_search",
+			resources: "search_service: DOCS_SEARCH",
+			wantErr:   `tool_resources.tool.search_service "DOCS_SEARCH" must be a fully qualified name (DB.SCHEMA.OBJECT)`,
+		},
+		{
+			name:      "semantic view",
+			toolType:  "cortex_analyst_text_to_sql",
+			resources: "semantic_view: SCH.SALES",
+			wantErr:   `tool_resources.tool.semantic_view "SCH.SALES" must be a fully qualified name (DB.SCHEMA.OBJECT)`,
+		},
+		{
+			name:      "semantic view list",
+			toolType:  "cortex_analyst_text_to_sql",
+			resources: "semantic_view: [DB.SCH.SALES, DB..ORDERS]",
+			wantErr:   `tool_resources.tool.semantic_view[1] "DB..ORDERS" must be a fully qualified name (DB.SCHEMA.OBJECT)`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "agent.yaml")
+			content := fmt.Sprintf(`
+name: fqn
+tools:
+  - tool_spec:
+      type: %s
+      name: tool
+tool_resources:
+  tool:
+    %s
+`, tt.toolType, tt.resources)
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatalf("write file: %v", err)
+			}
+
+			_, err := LoadAgents(path, false, "")
+			if err == nil {
+				t.Fatal("expected error for unqualified resource name")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadAgentRejectsDuplicateToolName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: dup-tools
+tools:
+  - tool_spec:
+      type: cortex_search
+      name: search
+  - tool_spec:
+      type: cortex_search
+      name: search
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for duplicate tool name")
+	}
+	if !strings.Contains(err.Error(), `tools[1]: duplicate tool name "search" (already used by tools[0])`) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestLoadAgentRejectsMissingOrInvalidToolName(t *testing.T) {
+	tests := []struct {
+		name string
+		tool string
+		want string
+	}{
+		{"missing", "type: cortex_search", "tools[0]: tool_spec.name is required"},
+		{"empty", `name: ""`, "tools[0]: tool_spec.name is required"},
+		{"invalid", `name: "my search"`, `tools[0]: tool_spec.name "my search" is not a valid identifier`},
+		{"leading digit", "name: 1search", `tools[0]: tool_spec.name "1search" is not a valid identifier`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "agent.yaml")
+			spec := "name: bad-tool\ntools:\n  - tool_spec:\n      " + tt.tool + "\n"
+			if err := os.WriteFile(path, []byte(spec), 0o644); err != nil {
+				t.Fatalf("write file: %v", err)
+			}
+			_, err := LoadAgents(path, false, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAgentWithIncludeFragments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	writeFile("tools.yaml", `
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+tool_resources:
+  analyst:
+    semantic_view: ${ vars.VIEW }
+`)
+	writeFile("grants.yaml", `
+deploy:
+  schema: FRAGMENT_SCHEMA
+  grant:
+    account_roles:
+      - role: ANALYST_ROLE
+        privileges:
+          - USAGE
+`)
+	writeFile("agent.yaml", `
+include:
+  - ./tools.yaml
+  - ./grants.yaml
+vars:
+  default:
+    VIEW: DB.SCH.SALES
+name: included-agent
+deploy:
+  database: TEST_DB
+  schema: PUBLIC
+`)
+
+	agents, err := LoadAgents(dir, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected fragments to be skipped, got %d agents", len(agents))
+	}
+	spec := agents[0].Spec
+	if len(spec.Tools) != 1 {
+		t.Fatalf("expected 1 tool from fragment, got %d", len(spec.Tools))
+	}
+	if got := spec.ToolResources.Entries("analyst")[0]["semantic_view"]; got != "DB.SCH.SALES" {
+		t.Errorf("expected vars applied to fragment, got %v", got)
+	}
+	if spec.Deploy.Database != "TEST_DB" || spec.Deploy.Schema != "PUBLIC" {
+		t.Errorf("expected including file to win, got %s.%s", spec.Deploy.Database, spec.Deploy.Schema)
+	}
+	if spec.Deploy.Grant == nil || len(spec.Deploy.Grant.AccountRoles) != 1 {
+		t.Fatalf("expected grant merged from fragment, got %#v", spec.Deploy.Grant)
+	}
+}
+
+func TestLoadAgentsDirectorySkipsNestedIncludeFragments(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+	writeFile("tools.yaml", `
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+`)
+	writeFile("base.yaml", `
+include:
+  - ./tools.yaml
+comment: shared base
+`)
+	writeFile("agent.yaml", `
+include:
+  - ./base.yaml
+name: nested-agent
+`)
+
+	agents, err := LoadAgents(dir, false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("expected nested fragments to be skipped, got %d agents", len(agents))
+	}
+	spec := agents[0].Spec
+	if spec.Name != "nested-agent" || spec.Comment != "shared base" || len(spec.Tools) != 1 {
+		t.Errorf("expected both fragment levels merged, got %+v", spec)
+	}
+}
+
+func TestLoadAgentRejectsIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("include: [./b.yaml]\ncomment: a\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("include: [./a.yaml]\ncomment: b\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	path := filepath.Join(dir, "agent.yaml")
+	if err := os.WriteFile(path, []byte("name: cyclic\ninclude: [./a.yaml]\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err := LoadAgents(path, false, "")
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+}
+
+func TestLoadAgentRejectsOverlongComment(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	content := "name: test-agent\ncomment: " + strings.Repeat("x", MaxCommentLength+1) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err := LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for over-long comment")
+	}
+	want := "comment is 4097 characters long; the maximum is 4096"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+}
+
+func TestLoadAgentRejectsControlCharacter(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	content := "name: test-agent\ncomment: \"multi\\nline is fine\"\nprofile:\n  display_name: \"Bot\\a\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err := LoadAgents(path, false, "")
+	if err == nil {
+		t.Fatal("expected error for control character")
+	}
+	want := "profile.display_name contains disallowed control character U+0007 at position 4"
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+}
+
+func TestLoadAgentWithEnvOverrides(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+vars:
+  default:
+    DB: PROD_DB
+  dev:
+    DB: DEV_DB
+name: env-agent
+orchestration:
+  budget:
+    seconds: 30
+    tokens: 16000
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+tool_resources:
+  analyst:
+    semantic_view: ${ vars.DB }.SCH.SALES
+env_overrides:
+  dev:
+    tools:
+      - tool_spec:
+          type: generic
+          name: debug_tool
+    tool_resources:
+      debug_tool:
+        identifier: ${ vars.DB }.SCH.DEBUG_PROC
+  prod:
+    orchestration:
+      budget:
+        tokens: 64000
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	load := func(env string) AgentSpec {
+		t.Helper()
+		agents, err := LoadAgents(path, false, env)
+		if err != nil {
+			t.Fatalf("LoadAgents(%q) error: %v", env, err)
+		}
+		return agents[0].Spec
+	}
+
+	dev := load("dev")
+	if len(dev.Tools) != 2 || dev.Tools[0].ToolSpec["name"] != "analyst" || dev.Tools[1].ToolSpec["name"] != "debug_tool" {
+		t.Fatalf("dev: expected analyst and debug_tool, got %#v", dev.Tools)
+	}
+	if got := dev.ToolResources.Entries("debug_tool")[0]["identifier"]; got != "DEV_DB.SCH.DEBUG_PROC" {
+		t.Errorf("dev: expected vars applied to override, got %v", got)
+	}
+	if got := dev.ToolResources.Entries("analyst")[0]["semantic_view"]; got != "DEV_DB.SCH.SALES" {
+		t.Errorf("dev: expected base tool_resources kept, got %v", got)
+	}
+	if dev.Orchestration.Budget.Tokens != 16000 {
+		t.Errorf("dev: expected base budget, got %d", dev.Orchestration.Budget.Tokens)
+	}
+
+	prod := load("prod")
+	if len(prod.Tools) != 1 {
+		t.Errorf("prod: expected only the base tool, got %#v", prod.Tools)
+	}
+	if prod.Orchestration.Budget.Tokens != 64000 || prod.Orchestration.Budget.Seconds != 30 {
+		t.Errorf("prod: expected tokens overridden and seconds kept, got %+v", prod.Orchestration.Budget)
+	}
+
+	base := load("")
+	if len(base.Tools) != 1 || base.Orchestration.Budget.Tokens != 16000 {
+		t.Errorf("default: expected base spec unchanged, got tools=%d budget=%+v", len(base.Tools), base.Orchestration.Budget)
+	}
+}
+
+func TestLoadAgentEnvOverridesMergeToolByName(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: env-agent
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+      description: Production sales data
+env_overrides:
+  dev:
+    tools:
+      - tool_spec:
+          name: analyst
+          description: Dev sales data
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	agents, err := LoadAgents(path, false, "dev")
+	if err != nil {
+		t.Fatalf("LoadAgents error: %v", err)
+	}
+	tools := agents[0].Spec.Tools
+	if len(tools) != 1 {
+		t.Fatalf("expected the override to merge into the existing tool, got %d tools", len(tools))
+	}
+	if tools[0].ToolSpec["description"] != "Dev sales data" || tools[0].ToolSpec["type"] != "cortex_analyst_text_to_sql" {
+		t.Errorf("unexpected merged tool_spec: %#v", tools[0].ToolSpec)
+	}
+}
+
+func TestLoadAgentEnvOverridesRejectsVars(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	err := os.WriteFile(path, []byte(`
+name: env-agent
+env_overrides:
+  dev:
+    vars:
+      DB: DEV_DB
+`), 0o644)
+	if err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err = LoadAgents(path, false, "dev")
+	if err == nil || !strings.Contains(err.Error(), "env_overrides.dev: vars cannot be overridden per environment") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
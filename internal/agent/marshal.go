@@ -0,0 +1,218 @@
+package agent
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML renders spec as canonical coragent YAML: two-space indent,
+// multiline strings in "|" block style, and tool_spec / tool_resources keys
+// in a readable order. It is the format written by export and new.
+func MarshalYAML(spec AgentSpec) ([]byte, error) {
+	doc, err := YAMLNode(spec)
+	if err != nil {
+		return nil, err
+	}
+	return EncodeYAML(doc)
+}
+
+// YAMLNode encodes spec into a canonical YAML document node, for callers that
+// decorate the document (e.g. with a head comment) before EncodeYAML.
+func YAMLNode(spec AgentSpec) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := doc.Encode(spec); err != nil {
+		return nil, fmt.Errorf("marshal YAML: %w", err)
+	}
+	setLiteralStyleForMultiline(&doc)
+	reorderCanonicalKeys(&doc)
+	return &doc, nil
+}
+
+// YAMLFormat holds the style options of the canonical encoder.
+type YAMLFormat struct {
+	Indent       int  // spaces per nesting level; 0 means DefaultYAMLIndent
+	QuoteStrings bool // double-quote single-line string values
+}
+
+// DefaultYAMLIndent is the indent of the canonical format, matching the
+// sample specs.
+const DefaultYAMLIndent = 2
+
+// MinYAMLIndent and MaxYAMLIndent bound YAMLFormat.Indent.
+const (
+	MinYAMLIndent = 2
+	MaxYAMLIndent = 8
+)
+
+// EncodeYAML writes a YAML node with the canonical two-space indent.
+func EncodeYAML(doc *yaml.Node) ([]byte, error) {
+	return EncodeYAMLFormat(doc, YAMLFormat{})
+}
+
+// EncodeYAMLFormat writes a YAML node like EncodeYAML, with the indent and
+// string quoting taken from format.
+func EncodeYAMLFormat(doc *yaml.Node, format YAMLFormat) ([]byte, error) {
+	indent := format.Indent
+	if indent == 0 {
+		indent = DefaultYAMLIndent
+	}
+	if indent < MinYAMLIndent || indent > MaxYAMLIndent {
+		return nil, fmt.Errorf("YAML indent must be between %d and %d, got %d", MinYAMLIndent, MaxYAMLIndent, indent)
+	}
+	if format.QuoteStrings {
+		quoteStringValues(doc)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	if err := enc.Encode(doc); err != nil {
+		return nil, fmt.Errorf("marshal YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("flush YAML encoder: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// setLiteralStyleForMultiline walks a yaml.Node tree and sets LiteralStyle
+// on scalar nodes whose value contains newlines, producing "|" block syntax.
+func setLiteralStyleForMultiline(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+	for _, child := range node.Content {
+		setLiteralStyleForMultiline(child)
+	}
+}
+
+// quoteStringValues sets DoubleQuotedStyle on string scalars that are
+// mapping values or sequence items. Keys stay plain and multiline strings
+// keep their "|" block style.
+func quoteStringValues(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			quoteStringValues(node.Content[i])
+		}
+		return
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" && node.Style != yaml.LiteralStyle {
+			node.Style = yaml.DoubleQuotedStyle
+		}
+		return
+	}
+	for _, child := range node.Content {
+		quoteStringValues(child)
+	}
+}
+
+// reorderCanonicalKeys reorders map keys in the YAML node tree so that
+// tool_spec keys appear as name, type, title, description first and
+// tool_resources entries have semantic_view / search_service first.
+func reorderCanonicalKeys(node *yaml.Node) {
+	if node == nil {
+		return
+	}
+	// Unwrap document node.
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			reorderCanonicalKeys(child)
+		}
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		for _, child := range node.Content {
+			reorderCanonicalKeys(child)
+		}
+		return
+	}
+
+	// Iterate key-value pairs to find tool_spec and tool_resources mappings.
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valNode := node.Content[i+1]
+
+		if keyNode.Kind == yaml.ScalarNode && keyNode.Value == "tool_spec" && valNode.Kind == yaml.MappingNode {
+			reorderMappingKeys(valNode, []string{"name", "type", "title", "description"})
+		}
+
+		if keyNode.Kind == yaml.ScalarNode && keyNode.Value == "tool_resources" && valNode.Kind == yaml.MappingNode {
+			// Each child of tool_resources is a tool name → resource config
+			// mapping, or a sequence of them for a tool with several resources.
+			for j := 0; j+1 < len(valNode.Content); j += 2 {
+				resVal := valNode.Content[j+1]
+				switch resVal.Kind {
+				case yaml.MappingNode:
+					reorderMappingKeys(resVal, []string{"semantic_view", "search_service"})
+				case yaml.SequenceNode:
+					for _, entry := range resVal.Content {
+						reorderMappingKeys(entry, []string{"semantic_view", "search_service"})
+					}
+				}
+			}
+		}
+
+		// Recurse into value nodes.
+		reorderCanonicalKeys(valNode)
+	}
+}
+
+// reorderMappingKeys moves the specified keys to the front of a mapping node,
+// preserving their relative order. Keys not in the priority list keep their
+// original order after the prioritized keys.
+func reorderMappingKeys(node *yaml.Node, priority []string) {
+	if node.Kind != yaml.MappingNode || len(node.Content) < 4 {
+		return
+	}
+
+	type pair struct {
+		key *yaml.Node
+		val *yaml.Node
+	}
+
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+
+	// Build index of priority keys.
+	priorityIndex := make(map[string]int, len(priority))
+	for i, k := range priority {
+		priorityIndex[k] = i
+	}
+
+	// Split into priority and rest.
+	priorityPairs := make([]pair, len(priority))
+	found := make([]bool, len(priority))
+	var rest []pair
+
+	for _, p := range pairs {
+		if idx, ok := priorityIndex[p.key.Value]; ok {
+			priorityPairs[idx] = p
+			found[idx] = true
+		} else {
+			rest = append(rest, p)
+		}
+	}
+
+	// Rebuild: priority keys first (only those that exist), then rest.
+	result := make([]*yaml.Node, 0, len(node.Content))
+	for i, p := range priorityPairs {
+		if found[i] {
+			result = append(result, p.key, p.val)
+		}
+	}
+	for _, p := range rest {
+		result = append(result, p.key, p.val)
+	}
+	node.Content = result
+}
@@ -0,0 +1,43 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KnownOrchestrationModels is the curated list of model names accepted by
+// models.orchestration. "auto" lets Snowflake pick the model. Models that
+// Cortex offers only for completion (llama, mistral) are not listed, since
+// agents reject them for orchestration. The list is
+// maintained by hand; `coragent models --refresh` asks the account instead.
+var KnownOrchestrationModels = []string{
+	"auto",
+	"claude-4-opus",
+	"claude-4-sonnet",
+	"claude-3-7-sonnet",
+	"claude-3-5-sonnet",
+	"claude-sonnet-4-5",
+	"openai-gpt-4.1",
+	"openai-gpt-5",
+}
+
+// IsKnownOrchestrationModel reports whether name is in
+// KnownOrchestrationModels, ignoring case.
+func IsKnownOrchestrationModel(name string) bool {
+	for _, known := range KnownOrchestrationModels {
+		if strings.EqualFold(name, known) {
+			return true
+		}
+	}
+	return false
+}
+
+// ModelWarnings returns non-fatal findings about spec.Models, such as an
+// orchestration model that is not in KnownOrchestrationModels. Unknown
+// names are only warned about because Snowflake adds models over time.
+func ModelWarnings(s AgentSpec) []string {
+	if s.Models == nil || s.Models.Orchestration == "" || IsKnownOrchestrationModel(s.Models.Orchestration) {
+		return nil
+	}
+	return []string{fmt.Sprintf("models.orchestration %q is not a known orchestration model (see `coragent models`)", s.Models.Orchestration)}
+}
@@ -0,0 +1,136 @@
+package agent
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// applyEnvOverrides removes the top-level `env_overrides` key from doc and
+// deep-merges the block selected by envName (or `default` when envName is
+// empty) over the rest of the spec. Environments without a block leave the
+// spec unchanged.
+//
+// Mappings are merged recursively and scalars replace the base value.
+// `tools` entries are matched by tool_spec.name: a matching entry is merged
+// into the base tool and a new name is appended. Other sequences are
+// replaced as a whole.
+func applyEnvOverrides(doc *yaml.Node, envName string) error {
+	mapping := rootMapping(doc)
+	if mapping == nil {
+		return nil
+	}
+	overrides := takeMappingKey(mapping, "env_overrides")
+	if overrides == nil {
+		return nil
+	}
+	if overrides.Kind != yaml.MappingNode {
+		return fmt.Errorf("env_overrides must be a mapping of environment names to spec fields")
+	}
+	for i := 0; i < len(overrides.Content)-1; i += 2 {
+		env, block := overrides.Content[i].Value, overrides.Content[i+1]
+		if block.Kind != yaml.MappingNode {
+			return fmt.Errorf("env_overrides.%s must be a mapping of spec fields", env)
+		}
+		for _, key := range []string{"vars", "include", "env_overrides"} {
+			if mappingValue(block, key) != nil {
+				return fmt.Errorf("env_overrides.%s: %s cannot be overridden per environment", env, key)
+			}
+		}
+	}
+
+	if envName == "" {
+		envName = "default"
+	}
+	selected := mappingValue(overrides, envName)
+	if selected == nil {
+		return nil
+	}
+	return mergeOverride(mapping, selected, "env_overrides."+envName, true)
+}
+
+// takeMappingKey removes key from mapping and returns its value, or nil when
+// the key is absent.
+func takeMappingKey(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(mapping.Content)-1; i += 2 {
+		if mapping.Content[i].Value == key {
+			val := mapping.Content[i+1]
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return val
+		}
+	}
+	return nil
+}
+
+// mergeOverride deep-merges src over dst. path names src in error messages;
+// top is set for the spec root, where `tools` is merged by name.
+func mergeOverride(dst, src *yaml.Node, path string, top bool) error {
+	for i := 0; i < len(src.Content)-1; i += 2 {
+		key, val := src.Content[i], src.Content[i+1]
+		existing := mappingValue(dst, key.Value)
+		switch {
+		case existing == nil:
+			dst.Content = append(dst.Content, key, val)
+		case existing.Kind == yaml.MappingNode && val.Kind == yaml.MappingNode:
+			if err := mergeOverride(existing, val, path+"."+key.Value, false); err != nil {
+				return err
+			}
+		case top && key.Value == "tools" && existing.Kind == yaml.SequenceNode && val.Kind == yaml.SequenceNode:
+			if err := mergeTools(existing, val, path+".tools"); err != nil {
+				return err
+			}
+		default:
+			setMappingValue(dst, key.Value, val)
+		}
+	}
+	return nil
+}
+
+// mergeTools merges override tools into base by tool_spec.name.
+func mergeTools(base, override *yaml.Node, path string) error {
+	for i, tool := range override.Content {
+		name := toolNodeName(tool)
+		if name == "" {
+			return fmt.Errorf("%s[%d]: tool_spec.name is required to merge tools", path, i)
+		}
+		var target *yaml.Node
+		for _, candidate := range base.Content {
+			if toolNodeName(candidate) == name {
+				target = candidate
+				break
+			}
+		}
+		if target == nil {
+			base.Content = append(base.Content, tool)
+			continue
+		}
+		if err := mergeOverride(target, tool, fmt.Sprintf("%s[%d]", path, i), false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// toolNodeName returns tool_spec.name of a tools entry node.
+func toolNodeName(tool *yaml.Node) string {
+	if tool.Kind != yaml.MappingNode {
+		return ""
+	}
+	spec := mappingValue(tool, "tool_spec")
+	if spec == nil || spec.Kind != yaml.MappingNode {
+		return ""
+	}
+	if name := mappingValue(spec, "name"); name != nil {
+		return name.Value
+	}
+	return ""
+}
+
+func setMappingValue(mapping *yaml.Node, key string, val *yaml.Node) {
+	for i := 0; i < len(mapping.Content)-1; i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = val
+			return
+		}
+	}
+}
@@ -1,41 +1,56 @@
 package agent
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Length limits for free-text fields, counted in characters. They can be
+// overridden before loading specs (the CLI applies the [validate] section
+// of .coragent.toml); a value of 0 or less disables the check.
+var (
+	MaxCommentLength     = 4096
+	MaxDisplayNameLength = 255
+)
 
 // Validate checks the AgentSpec for required fields and obvious misconfigurations.
 // It returns a descriptive error if the spec is invalid, or nil if it is valid.
 //
 // Rules enforced:
 //   - Name must not be empty.
-//   - Each Tool must have a non-empty tool_spec with a non-empty "name" field.
-//   - ToolResources keys must match at least one tool name in Tools when both are present.
-//   - EvalConfig.Tests must each have a non-empty Question.
+//   - Each Tool must have a non-empty tool_spec with a "name" that is a valid
+//     identifier and unique within the agent.
+//   - ToolResources keys must match a tool name in Tools.
+//   - List-valued semantic_view/semantic_model_file/search_service entries must be non-empty and unique.
+//   - semantic_view/search_service names must be fully qualified (DB.SCHEMA.OBJECT).
+//   - EvalConfig.Tests must each have a non-empty Question; conversation turns must be non-empty.
+//   - EvalConfig.PassRateThreshold must be between 0 and 1.
+//   - EvalConfig.MaxResponseMs and per-test max_response_ms must not be negative.
 //   - DeployConfig.Grant privileges must be non-empty for each RoleGrant.
+//   - Policy required tools must be declared and forbidden tools must not be.
+//   - Comment and profile.display_name must fit their length limits and contain no control characters.
 func (s AgentSpec) Validate() error {
 	if s.Name == "" {
 		return fmt.Errorf("agent name is required")
 	}
 
 	// Validate tools
-	toolNames := make(map[string]bool)
 	for i, tool := range s.Tools {
 		if len(tool.ToolSpec) == 0 {
 			return fmt.Errorf("tools[%d]: tool_spec must not be empty", i)
 		}
-		name, _ := tool.ToolSpec["name"].(string)
-		if name == "" {
-			return fmt.Errorf("tools[%d]: tool_spec.name is required", i)
-		}
-		toolNames[name] = true
+	}
+	if err := validateToolNames(s); err != nil {
+		return err
 	}
 
 	// Validate tool_resources keys reference known tools
-	if len(s.ToolResources) > 0 && len(s.Tools) > 0 {
-		for key := range s.ToolResources {
-			if !toolNames[key] {
-				return fmt.Errorf("tool_resources key %q does not match any tool name", key)
-			}
-		}
+	if err := validateToolResourceRefs(s); err != nil {
+		return err
 	}
 
 	// Validate eval test cases
@@ -44,6 +59,9 @@ func (s AgentSpec) Validate() error {
 			if tc.Question == "" {
 				return fmt.Errorf("eval.tests[%d]: question is required", i)
 			}
+			if err := validateConversation(i, tc); err != nil {
+				return err
+			}
 		}
 		if s.Eval.ResponseScoreThreshold != nil {
 			v := *s.Eval.ResponseScoreThreshold
@@ -51,6 +69,15 @@ func (s AgentSpec) Validate() error {
 				return fmt.Errorf("eval.response_score_threshold must be between 0 and 100, got %d", v)
 			}
 		}
+		if s.Eval.PassRateThreshold != nil {
+			v := *s.Eval.PassRateThreshold
+			if v < 0 || v > 1 {
+				return fmt.Errorf("eval.pass_rate_threshold must be between 0 and 1, got %g", v)
+			}
+		}
+		if err := validateMaxResponseMs(s.Eval); err != nil {
+			return err
+		}
 	}
 
 	// Validate grant config
@@ -60,5 +87,328 @@ func (s AgentSpec) Validate() error {
 		}
 	}
 
+	if err := validateToolResources(s); err != nil {
+		return err
+	}
+
+	if err := validateResourceFQNs(s); err != nil {
+		return err
+	}
+
+	if err := validatePolicy(s); err != nil {
+		return err
+	}
+
+	if err := validateTextFields(s); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateMaxResponseMs checks the agent-level and per-test latency limits.
+func validateMaxResponseMs(eval *EvalConfig) error {
+	if v := eval.MaxResponseMs; v != nil && *v < 0 {
+		return fmt.Errorf("eval.max_response_ms must not be negative, got %d", *v)
+	}
+	for i, tc := range eval.Tests {
+		if v := tc.MaxResponseMs; v != nil && *v < 0 {
+			return fmt.Errorf("eval.tests[%d].max_response_ms must not be negative, got %d", i, *v)
+		}
+	}
+	return nil
+}
+
+// validateConversation checks the earlier turns of eval.tests[i]. They lead
+// up to the question, so a conversation without a question is rejected.
+func validateConversation(i int, tc EvalTestCase) error {
+	if len(tc.Conversation) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(tc.Question) == "" {
+		return fmt.Errorf("eval.tests[%d]: conversation requires question (the final turn)", i)
+	}
+	for j, turn := range tc.Conversation {
+		if strings.TrimSpace(turn) == "" {
+			return fmt.Errorf("eval.tests[%d].conversation[%d] must not be empty", i, j)
+		}
+	}
+	return nil
+}
+
+// toolNamePattern matches a valid tools[].tool_spec.name: a letter or
+// underscore followed by letters, digits, underscores or hyphens.
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// validateToolNames requires every tools[].tool_spec.name to be present, a
+// valid identifier and unique within the agent, since tool_resources and
+// policy entries refer to tools by name.
+func validateToolNames(s AgentSpec) error {
+	seen := make(map[string]int, len(s.Tools))
+	for i, tool := range s.Tools {
+		raw, ok := tool.ToolSpec["name"]
+		if !ok || raw == nil {
+			return fmt.Errorf("tools[%d]: tool_spec.name is required", i)
+		}
+		name, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("tools[%d]: tool_spec.name must be a string", i)
+		}
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("tools[%d]: tool_spec.name is required", i)
+		}
+		if !toolNamePattern.MatchString(name) {
+			return fmt.Errorf("tools[%d]: tool_spec.name %q is not a valid identifier (use letters, digits, _ and -, starting with a letter or _)", i, name)
+		}
+		if first, dup := seen[name]; dup {
+			return fmt.Errorf("tools[%d]: duplicate tool name %q (already used by tools[%d])", i, name, first)
+		}
+		seen[name] = i
+	}
+	return nil
+}
+
+// validateTextFields checks comment and profile.display_name against the
+// configured length limits and rejects control characters other than
+// newlines and tabs, which Snowflake refuses server-side.
+func validateTextFields(s AgentSpec) error {
+	if err := validateText("comment", s.Comment, MaxCommentLength); err != nil {
+		return err
+	}
+	if s.Profile != nil {
+		if err := validateText("profile.display_name", s.Profile.DisplayName, MaxDisplayNameLength); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateText(field, value string, maxLen int) error {
+	if n := utf8.RuneCountInString(value); maxLen > 0 && n > maxLen {
+		return fmt.Errorf("%s is %d characters long; the maximum is %d", field, n, maxLen)
+	}
+	pos := 0
+	for _, r := range value {
+		pos++
+		if r == '\n' || r == '\r' || r == '\t' {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%s contains disallowed control character %U at position %d", field, r, pos)
+		}
+	}
+	return nil
+}
+
+// multiResourceKeys are tool_resources fields that may reference several
+// resources at once by holding a list instead of a single name.
+var multiResourceKeys = []string{"semantic_view", "semantic_model_file", "search_service"}
+
+// validateToolResources checks that each tool's resources are a mapping or
+// a non-empty list of mappings, and the list form of multi-resource fields:
+// the list must be non-empty and hold unique, non-empty names.
+func validateToolResources(s AgentSpec) error {
+	for _, tool := range sortedToolResourceNames(s) {
+		switch v := s.ToolResources[tool].(type) {
+		case map[string]any:
+		case []any:
+			if len(v) == 0 {
+				return fmt.Errorf("tool_resources.%s must list at least one resource entry", tool)
+			}
+			for i, item := range v {
+				if _, ok := item.(map[string]any); !ok {
+					return fmt.Errorf("tool_resources.%s[%d] must be a mapping", tool, i)
+				}
+			}
+		default:
+			return fmt.Errorf("tool_resources.%s must be a mapping or a list of mappings", tool)
+		}
+		for _, e := range toolResourceEntries(s, tool) {
+			label, resources := e.label, e.resources
+			for _, key := range multiResourceKeys {
+				list, ok := resources[key].([]any)
+				if !ok {
+					continue
+				}
+				if len(list) == 0 {
+					return fmt.Errorf("tool_resources.%s.%s must list at least one resource", label, key)
+				}
+				seen := make(map[string]bool, len(list))
+				for i, item := range list {
+					name, _ := item.(string)
+					if strings.TrimSpace(name) == "" {
+						return fmt.Errorf("tool_resources.%s.%s[%d] must be a non-empty string", label, key, i)
+					}
+					if seen[name] {
+						return fmt.Errorf("tool_resources.%s.%s lists %q more than once", label, key, name)
+					}
+					seen[name] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sortedToolResourceNames returns the tool_resources keys in sorted order.
+func sortedToolResourceNames(s AgentSpec) []string {
+	tools := make([]string, 0, len(s.ToolResources))
+	for tool := range s.ToolResources {
+		tools = append(tools, tool)
+	}
+	sort.Strings(tools)
+	return tools
+}
+
+// labeledResources is one resource map of a tool together with the path
+// used in error messages.
+type labeledResources struct {
+	label     string
+	resources map[string]any
+}
+
+// toolResourceEntries returns each resource map of tool, labeled with the
+// tool name for the map form and tool[i] for the list form.
+func toolResourceEntries(s AgentSpec, tool string) []labeledResources {
+	_, list := s.ToolResources[tool].([]any)
+	entries := s.ToolResources.Entries(tool)
+	out := make([]labeledResources, len(entries))
+	for i, resources := range entries {
+		label := tool
+		if list {
+			label = fmt.Sprintf("%s[%d]", tool, i)
+		}
+		out[i] = labeledResources{label: label, resources: resources}
+	}
+	return out
+}
+
+// qualifiedResourceKeys are tool_resources fields that name a Snowflake
+// object, which the agent resolves only when fully qualified.
+var qualifiedResourceKeys = []string{"semantic_view", "search_service"}
+
+// validateResourceFQNs requires semantic_view and search_service names, in
+// single or list form, to be three-part DB.SCHEMA.OBJECT identifiers.
+func validateResourceFQNs(s AgentSpec) error {
+	for _, tool := range sortedToolResourceNames(s) {
+		for _, e := range toolResourceEntries(s, tool) {
+			label, resources := e.label, e.resources
+			for _, key := range qualifiedResourceKeys {
+				switch v := resources[key].(type) {
+				case string:
+					if v != "" && !isQualifiedName(v) {
+						return fmt.Errorf("tool_resources.%s.%s %q must be a fully qualified name (DB.SCHEMA.OBJECT)", label, key, v)
+					}
+				case []any:
+					for i, item := range v {
+						name, _ := item.(string)
+						if name != "" && !isQualifiedName(name) {
+							return fmt.Errorf("tool_resources.%s.%s[%d] %q must be a fully qualified name (DB.SCHEMA.OBJECT)", label, key, i, name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isQualifiedName reports whether name has exactly three non-empty
+// dot-separated parts. Dots inside double-quoted identifiers do not split.
+func isQualifiedName(name string) bool {
+	parts := 1
+	partLen := 0
+	quoted := false
+	for _, r := range name {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			partLen++
+		case r == '.' && !quoted:
+			if partLen == 0 {
+				return false
+			}
+			parts++
+			partLen = 0
+		default:
+			if !quoted && unicode.IsSpace(r) {
+				return false
+			}
+			partLen++
+		}
+	}
+	return !quoted && partLen > 0 && parts == 3
+}
+
+// toolTypesWithoutResources lists tool types that take no tool_resources
+// entry. A block for such a tool is ignored by Snowflake.
+var toolTypesWithoutResources = map[string]bool{
+	"data_to_chart": true,
+}
+
+// validateToolResourceRefs reports tool_resources keys that do not match
+// any tools[].tool_spec.name, listing every orphaned key at once.
+func validateToolResourceRefs(s AgentSpec) error {
+	declared := make(map[string]bool, len(s.Tools))
+	for _, tool := range s.Tools {
+		if name, _ := tool.ToolSpec["name"].(string); name != "" {
+			declared[name] = true
+		}
+	}
+	var orphaned []string
+	for key := range s.ToolResources {
+		if !declared[key] {
+			orphaned = append(orphaned, fmt.Sprintf("%q", key))
+		}
+	}
+	if len(orphaned) == 0 {
+		return nil
+	}
+	sort.Strings(orphaned)
+	if len(orphaned) == 1 {
+		return fmt.Errorf("tool_resources key %s does not match any tool name in tools[].tool_spec.name", orphaned[0])
+	}
+	return fmt.Errorf("tool_resources keys %s do not match any tool name in tools[].tool_spec.name", strings.Join(orphaned, ", "))
+}
+
+// ToolResourceWarnings returns non-fatal findings about tool_resources, such
+// as a block configured for a tool whose type does not use resources.
+func ToolResourceWarnings(s AgentSpec) []string {
+	var warnings []string
+	for _, tool := range s.Tools {
+		name, _ := tool.ToolSpec["name"].(string)
+		typ, _ := tool.ToolSpec["type"].(string)
+		if _, ok := s.ToolResources[name]; ok && toolTypesWithoutResources[typ] {
+			warnings = append(warnings, fmt.Sprintf("tool_resources.%s is ignored: tool type %q does not use tool resources", name, typ))
+		}
+	}
+	return warnings
+}
+
+// validatePolicy checks the declared tools against spec.Policy. Policy
+// entries match a tool by its tool_spec name or type.
+func validatePolicy(s AgentSpec) error {
+	if s.Policy == nil {
+		return nil
+	}
+	declared := make(map[string]bool)
+	for _, tool := range s.Tools {
+		if name, _ := tool.ToolSpec["name"].(string); name != "" {
+			declared[name] = true
+		}
+		if typ, _ := tool.ToolSpec["type"].(string); typ != "" {
+			declared[typ] = true
+		}
+	}
+	for _, forbidden := range s.Policy.ForbiddenTools {
+		if declared[forbidden] {
+			return fmt.Errorf("policy: forbidden tool %q is declared", forbidden)
+		}
+	}
+	for _, required := range s.Policy.RequiredTools {
+		if !declared[required] {
+			return fmt.Errorf("policy: required tool %q is not declared", required)
+		}
+	}
 	return nil
 }
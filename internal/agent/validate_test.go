@@ -56,7 +56,7 @@ func TestValidate_ToolResourcesUnknownKey(t *testing.T) {
 		Name:  "agent",
 		Tools: []Tool{{ToolSpec: map[string]any{"name": "tool_a"}}},
 		ToolResources: ToolResources{
-			"unknown_tool": {"semantic_view": "DB.S.V"},
+			"unknown_tool": map[string]any{"semantic_view": "DB.S.V"},
 		},
 	}
 	err := spec.Validate()
@@ -73,7 +73,7 @@ func TestValidate_ToolResourcesMatchingKey(t *testing.T) {
 		Name:  "agent",
 		Tools: []Tool{{ToolSpec: map[string]any{"name": "tool_a"}}},
 		ToolResources: ToolResources{
-			"tool_a": {"semantic_view": "DB.S.V"},
+			"tool_a": map[string]any{"semantic_view": "DB.S.V"},
 		},
 	}
 	if err := spec.Validate(); err != nil {
@@ -211,6 +211,21 @@ func TestValidate_GrantEnvInvalidDatabaseRole(t *testing.T) {
 	}
 }
 
+func TestValidate_PolicyForbiddenToolByName(t *testing.T) {
+	spec := AgentSpec{
+		Name:   "agent",
+		Tools:  []Tool{{ToolSpec: map[string]any{"name": "run_sql", "type": "generic"}}},
+		Policy: &PolicyConfig{ForbiddenTools: []string{"run_sql"}},
+	}
+	err := spec.Validate()
+	if err == nil {
+		t.Fatal("expected error for forbidden tool")
+	}
+	if !strings.Contains(err.Error(), "policy") {
+		t.Errorf("error should mention policy, got: %v", err)
+	}
+}
+
 func TestValidate_FullValidSpec(t *testing.T) {
 	threshold := 70
 	spec := AgentSpec{
@@ -225,7 +240,7 @@ func TestValidate_FullValidSpec(t *testing.T) {
 			{ToolSpec: map[string]any{"name": "sales_tool", "type": "cortex_analyst_text_to_sql"}},
 		},
 		ToolResources: ToolResources{
-			"sales_tool": {"semantic_view": "DB.S.VIEW"},
+			"sales_tool": map[string]any{"semantic_view": "DB.S.VIEW"},
 		},
 		Eval: &EvalConfig{
 			Tests:                  []EvalTestCase{{Question: "What are sales?", ExpectedTools: []string{"sales_tool"}}},
@@ -241,3 +256,74 @@ func TestValidate_FullValidSpec(t *testing.T) {
 		t.Errorf("expected no error for full valid spec, got: %v", err)
 	}
 }
+
+func TestValidate_ToolResourcesListsAllOrphanedKeys(t *testing.T) {
+	spec := AgentSpec{
+		Name:  "agent",
+		Tools: []Tool{{ToolSpec: map[string]any{"name": "tool_a"}}},
+		ToolResources: ToolResources{
+			"tool_a":  map[string]any{"semantic_view": "DB.S.V"},
+			"tool_z":  map[string]any{"semantic_view": "DB.S.V"},
+			"typo_ab": map[string]any{"search_service": "DB.S.SRCH"},
+		},
+	}
+	err := spec.Validate()
+	if err == nil {
+		t.Fatal("expected error for orphaned tool_resources keys")
+	}
+	want := `tool_resources keys "tool_z", "typo_ab" do not match any tool name`
+	if !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %q, want it to contain %q", err, want)
+	}
+}
+
+func TestValidate_ToolResourcesWithoutTools(t *testing.T) {
+	spec := AgentSpec{
+		Name:          "agent",
+		ToolResources: ToolResources{"analyst": map[string]any{"semantic_view": "DB.S.V"}},
+	}
+	if err := spec.Validate(); err == nil {
+		t.Fatal("expected error for tool_resources without any tools")
+	}
+}
+
+func TestToolResourceWarnings(t *testing.T) {
+	spec := AgentSpec{
+		Name: "agent",
+		Tools: []Tool{
+			{ToolSpec: map[string]any{"name": "analyst", "type": "cortex_analyst_text_to_sql"}},
+			{ToolSpec: map[string]any{"name": "chart", "type": "data_to_chart"}},
+		},
+		ToolResources: ToolResources{
+			"analyst": map[string]any{"semantic_view": "DB.S.V"},
+			"chart":   map[string]any{"semantic_view": "DB.S.V"},
+		},
+	}
+	if err := spec.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	warnings := ToolResourceWarnings(spec)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "tool_resources.chart is ignored") {
+		t.Errorf("unexpected warnings: %q", warnings)
+	}
+}
+
+func TestModelWarnings(t *testing.T) {
+	tests := []struct {
+		model string
+		warn  bool
+	}{
+		{"auto", false},
+		{"Claude-4-Sonnet", false},
+		{"openai-gpt-5", false},
+		{"llama3.3-70b", true},
+		{"mistral-large2", true},
+		{"llama4-maverick", true},
+	}
+	for _, tt := range tests {
+		got := ModelWarnings(AgentSpec{Models: &Models{Orchestration: tt.model}})
+		if (len(got) > 0) != tt.warn {
+			t.Errorf("ModelWarnings(%q) = %v, want warning %v", tt.model, got, tt.warn)
+		}
+	}
+}
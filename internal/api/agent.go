@@ -3,19 +3,37 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"path"
+	"slices"
 	"sort"
 	"strings"
 
 	"coragent/internal/agent"
 )
 
+// ErrAgentNotFound is returned by RenameAgent when the source agent does not exist.
+var ErrAgentNotFound = errors.New("agent not found")
+
+// ErrAgentAlreadyExists is returned by RenameAgent when the target name is taken.
+var ErrAgentAlreadyExists = errors.New("agent already exists")
+
+// ErrRenameUnsupported is returned by RenameAgent when the account rejects
+// ALTER AGENT ... RENAME TO as an unsupported statement.
+var ErrRenameUnsupported = errors.New("ALTER AGENT RENAME is not supported")
+
 // AgentListItem is a summary entry returned by the list agents endpoint.
 type AgentListItem struct {
 	Name    string `json:"name"`
 	Comment string `json:"comment"`
+	// Owner, CreatedOn, Database and Schema are filled from the SHOW AGENTS
+	// columns of the same name when present.
+	Owner     string `json:"owner,omitempty"`
+	CreatedOn string `json:"created_on,omitempty"`
+	Database  string `json:"database_name,omitempty"`
+	Schema    string `json:"schema_name,omitempty"`
 }
 
 // DescribeResult holds the full result of a DESCRIBE AGENT call, including
@@ -57,19 +75,64 @@ func (c *Client) agentURL(db, schema, name string) string {
 
 // CreateAgent creates a new agent with the given spec.
 func (c *Client) CreateAgent(ctx context.Context, db, schema string, spec agent.AgentSpec) error {
+	if err := c.checkWritable("create agent %s", spec.Name); err != nil {
+		return err
+	}
 	payload := normalizeAgentSpec(spec)
-	return c.doJSON(ctx, http.MethodPost, c.agentsURL(db, schema), payload, nil)
+	defer c.invalidateAgentList(db, schema)
+	return countMutation(&c.stats.agentsCreated, c.doJSON(ctx, http.MethodPost, c.agentsURL(db, schema), payload, nil))
 }
 
 // UpdateAgent updates an existing agent with the given payload.
 func (c *Client) UpdateAgent(ctx context.Context, db, schema, name string, payload any) error {
+	if err := c.checkWritable("update agent %s", name); err != nil {
+		return err
+	}
 	payload = normalizePayload(payload)
-	return c.doJSON(ctx, http.MethodPut, c.agentURL(db, schema, name), payload, nil)
+	defer c.invalidateAgentList(db, schema)
+	return countMutation(&c.stats.agentsUpdated, c.doJSON(ctx, http.MethodPut, c.agentURL(db, schema, name), payload, nil))
 }
 
 // DeleteAgent deletes the named agent.
 func (c *Client) DeleteAgent(ctx context.Context, db, schema, name string) error {
-	return c.doJSON(ctx, http.MethodDelete, c.agentURL(db, schema, name), nil, nil)
+	if err := c.checkWritable("delete agent %s", name); err != nil {
+		return err
+	}
+	defer c.invalidateAgentList(db, schema)
+	return countMutation(&c.stats.agentsDeleted, c.doJSON(ctx, http.MethodDelete, c.agentURL(db, schema, name), nil, nil))
+}
+
+// DeleteAgentIfExists deletes an agent like DeleteAgent but treats a
+// not-found response as success, so repeated cleanups are idempotent.
+func (c *Client) DeleteAgentIfExists(ctx context.Context, db, schema, name string) error {
+	if err := c.DeleteAgent(ctx, db, schema, name); err != nil && !isNotFoundError(err) {
+		return err
+	}
+	return nil
+}
+
+// RenameAgent renames an agent within the same schema using
+// ALTER AGENT ... RENAME TO, which keeps its grants and thread history.
+func (c *Client) RenameAgent(ctx context.Context, db, schema, oldName, newName string) error {
+	if err := c.checkWritable("rename agent %s to %s", oldName, newName); err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf("ALTER AGENT %s.%s.%s RENAME TO %s.%s.%s",
+		identifierSegment(db), identifierSegment(schema), identifierSegment(oldName),
+		identifierSegment(db), identifierSegment(schema), identifierSegment(newName))
+	defer c.invalidateAgentList(db, schema)
+	_, err := c.executeStatement(ctx, db, schema, stmt)
+	switch {
+	case err == nil:
+		return nil
+	case IsAlreadyExistsError(err):
+		return fmt.Errorf("%w: %s", ErrAgentAlreadyExists, newName)
+	case isNotFoundError(err):
+		return fmt.Errorf("%w: %s", ErrAgentNotFound, oldName)
+	case IsRenameUnsupportedError(err):
+		return fmt.Errorf("%w: %v", ErrRenameUnsupported, err)
+	}
+	return err
 }
 
 // GetAgent returns the agent spec and a boolean indicating whether the agent exists.
@@ -86,44 +149,125 @@ func (c *Client) DescribeAgent(ctx context.Context, db, schema, name string) (De
 	return c.describeAgentFull(ctx, db, schema, name)
 }
 
-// ListAgents returns a summary list of agents in the given database and schema.
-func (c *Client) ListAgents(ctx context.Context, db, schema string) ([]AgentListItem, error) {
-	stmt := fmt.Sprintf(
-		"SHOW AGENTS IN SCHEMA %s.%s",
-		identifierSegment(db),
-		identifierSegment(schema),
-	)
-	resp, err := c.executeStatement(ctx, db, schema, stmt)
+// DescribeAgentYAML describes the agent and returns its spec as canonical
+// YAML (agent.MarshalYAML), the same format export writes by default, and
+// whether the agent exists. A missing agent returns nil data, false and no
+// error. Columns and spec keys that AgentSpec cannot represent are listed in
+// a head comment (see RenderAgentYAML); use DescribeAgent to inspect them.
+func (c *Client) DescribeAgentYAML(ctx context.Context, db, schema, name string) ([]byte, bool, error) {
+	data, result, err := c.DescribeAgentYAMLFormat(ctx, db, schema, name, agent.YAMLFormat{})
+	return data, result.Exists, err
+}
+
+// DescribeAgentYAMLFormat is DescribeAgentYAML with an explicit indent and
+// quoting. It also returns the describe result, so callers such as export
+// can warn about the unmapped columns and spec keys.
+func (c *Client) DescribeAgentYAMLFormat(ctx context.Context, db, schema, name string, format agent.YAMLFormat) ([]byte, DescribeResult, error) {
+	result, err := c.describeAgentFull(ctx, db, schema, name)
+	if err != nil || !result.Exists {
+		return nil, result, err
+	}
+	data, err := RenderAgentYAML(result, format)
+	if err != nil {
+		return nil, result, fmt.Errorf("agent %s: %w", name, err)
+	}
+	return data, result, nil
+}
+
+// RenderAgentYAML encodes the described spec as canonical YAML (see
+// agent.MarshalYAML) in the given format. Unmapped columns and spec keys are
+// listed in a head comment so the dropped data stays visible in the file.
+func RenderAgentYAML(result DescribeResult, format agent.YAMLFormat) ([]byte, error) {
+	doc, err := agent.YAMLNode(result.Spec)
 	if err != nil {
 		return nil, err
 	}
+	if len(result.UnmappedColumns) > 0 || len(result.UnmappedSpecKeys) > 0 {
+		lines := []string{"# Not exported (not representable in the coragent spec):"}
+		for _, col := range result.UnmappedColumns {
+			lines = append(lines, "#   DESCRIBE AGENT column: "+col)
+		}
+		for _, key := range result.UnmappedSpecKeys {
+			lines = append(lines, "#   agent_spec key: "+key)
+		}
+		doc.HeadComment = strings.Join(lines, "\n")
+	}
+	return agent.EncodeYAMLFormat(doc, format)
+}
 
-	colIndex := make(map[string]int)
-	for i, col := range resp.ResultSetMetaData.RowType {
-		colIndex[strings.ToLower(col.Name)] = i
+// ListAgents returns a summary list of agents in the given database and schema.
+// Results are memoized per client until an agent in that schema is created,
+// updated, deleted or renamed through the same client.
+func (c *Client) ListAgents(ctx context.Context, db, schema string) ([]AgentListItem, error) {
+	key := agentListKey(db, schema)
+	c.agentListMu.Lock()
+	cached, ok := c.agentLists[key]
+	c.agentListMu.Unlock()
+	if ok {
+		return slices.Clone(cached), nil
+	}
+
+	out, err := c.listAgents(ctx, db, schema)
+	if err != nil {
+		return nil, err
 	}
-	nameIdx, hasName := colIndex["name"]
-	commentIdx, hasComment := colIndex["comment"]
-	if !hasName {
-		return nil, fmt.Errorf("show agents: missing name column")
+	c.agentListMu.Lock()
+	if c.agentLists == nil {
+		c.agentLists = make(map[string][]AgentListItem)
 	}
+	c.agentLists[key] = slices.Clone(out)
+	c.agentListMu.Unlock()
+	return out, nil
+}
 
-	out := make([]AgentListItem, 0, len(resp.Data))
-	for _, row := range resp.Data {
-		if nameIdx >= len(row) {
-			continue
+// invalidateAgentList drops the memoized ListAgents result for a schema.
+func (c *Client) invalidateAgentList(db, schema string) {
+	c.agentListMu.Lock()
+	delete(c.agentLists, agentListKey(db, schema))
+	c.agentListMu.Unlock()
+}
+
+func agentListKey(db, schema string) string {
+	return identifierSegment(db) + "." + identifierSegment(schema)
+}
+
+// ListAgentsInDatabase returns the agents in every schema of db. Each item's
+// Database and Schema name where it lives. Results are not memoized.
+func (c *Client) ListAgentsInDatabase(ctx context.Context, db string) ([]AgentListItem, error) {
+	return c.showAgents(ctx, fmt.Sprintf("SHOW AGENTS IN DATABASE %s", identifierSegment(db)))
+}
+
+func (c *Client) listAgents(ctx context.Context, db, schema string) ([]AgentListItem, error) {
+	return c.showAgents(ctx, fmt.Sprintf(
+		"SHOW AGENTS IN SCHEMA %s.%s",
+		identifierSegment(db),
+		identifierSegment(schema),
+	))
+}
+
+func (c *Client) showAgents(ctx context.Context, stmt string) ([]AgentListItem, error) {
+	out := []AgentListItem{}
+	err := c.iterateShow(ctx, stmt, func(row map[string]any) error {
+		nameVal, hasName := row["name"]
+		if !hasName {
+			c.log.Debug("show agents: skipping row without name column", "columns", len(row))
+			return nil
 		}
-		name, ok := row[nameIdx].(string)
+		name, ok := nameVal.(string)
 		if !ok || strings.TrimSpace(name) == "" {
-			continue
+			return nil
 		}
 		item := AgentListItem{Name: name}
-		if hasComment && commentIdx < len(row) {
-			if comment, ok := row[commentIdx].(string); ok {
-				item.Comment = comment
-			}
-		}
+		item.Comment, _ = row["comment"].(string)
+		item.Owner, _ = row["owner"].(string)
+		item.CreatedOn, _ = row["created_on"].(string)
+		item.Database, _ = row["database_name"].(string)
+		item.Schema, _ = row["schema_name"].(string)
 		out = append(out, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return out, nil
 }
@@ -566,6 +710,11 @@ func normalizeModelsMap(input map[string]any) map[string]any {
 		switch {
 		case strings.EqualFold(key, "orchestration"):
 			out["orchestration"] = value
+		case strings.EqualFold(key, "response"):
+			out["response"] = value
+		case strings.EqualFold(key, "tool_use"),
+			strings.EqualFold(key, "toolUse"):
+			out["tool_use"] = value
 		default:
 			out[key] = value
 		}
@@ -658,22 +807,37 @@ func normalizeToolsList(input []any) []any {
 // normalizeToolResources converts API response format to expected format.
 // API response format: {"tool_name": [{"semantic_view": "...", ...}]} (array with single element).
 // Expected format: {"tool_name": {"semantic_view": "...", ...}} (direct object).
+// Arrays with several entries are kept as they are, and decodeSpecMap
+// rejects them, since one object per tool cannot hold them without losing
+// which fields belong to which resource.
 func normalizeToolResources(input map[string]any) map[string]any {
 	out := make(map[string]any, len(input))
 
 	for toolName, value := range input {
 		switch v := value.(type) {
 		case []any:
-			// Array format - take first element
-			if len(v) > 0 {
+			// Array format - unwrap a single element
+			switch len(v) {
+			case 0:
+			case 1:
 				if resource, ok := v[0].(map[string]any); ok {
 					out[toolName] = resource
 				}
+			default:
+				out[toolName] = v
 			}
 		case []map[string]any:
-			// Array format - take first element
-			if len(v) > 0 {
+			// Array format - unwrap a single element, keep several as a list
+			switch len(v) {
+			case 0:
+			case 1:
 				out[toolName] = v[0]
+			default:
+				list := make([]any, len(v))
+				for i, resource := range v {
+					list[i] = resource
+				}
+				out[toolName] = list
 			}
 		case map[string]any:
 			// Already in expected format
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
@@ -10,9 +11,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"testing"
 
+	"coragent/internal/agent"
 	"coragent/internal/auth"
+	"coragent/internal/diff"
+
+	"gopkg.in/yaml.v3"
 )
 
 // testRSAPEM generates a PKCS8 RSA private key PEM for use in tests that need
@@ -62,10 +68,8 @@ func buildSQLResponse(t *testing.T, cols []string, row []any) []byte {
 		rowTypes[i] = sqlRowType{Name: c}
 	}
 	resp := sqlStatementResponse{
-		Data: [][]any{row},
-		ResultSetMetaData: struct {
-			RowType []sqlRowType `json:"rowType"`
-		}{RowType: rowTypes},
+		Data:              [][]any{row},
+		ResultSetMetaData: sqlResultSetMetaData{RowType: rowTypes},
 	}
 	data, err := json.Marshal(resp)
 	if err != nil {
@@ -247,6 +251,70 @@ func TestDescribeAgentFull_ToolResources(t *testing.T) {
 	}
 }
 
+// TestDescribeAgentYAML_RoundTrip verifies that the canonical YAML returned
+// by DescribeAgentYAML decodes back into the spec DESCRIBE AGENT produced.
+func TestDescribeAgentYAML_RoundTrip(t *testing.T) {
+	agentSpec := `{
+		"name": "yaml_agent",
+		"comment": "line one\nline two",
+		"profile": {"display_name": "Sales"},
+		"models": {"orchestration": "claude-4-sonnet"},
+		"instructions": {"response": "Be brief.", "system": "You answer\nsales questions.", "sample_questions": [{"question": "Top regions?"}]},
+		"orchestration": {"budget": {"seconds": 60, "tokens": 16000}},
+		"tools": [{"tool_spec": {"type": "cortex_analyst_text_to_sql", "name": "sales", "description": "Sales data"}}],
+		"tool_resources": {"sales": {"semantic_view": "DB.SCH.SALES_VIEW", "execution_environment": {"type": "warehouse", "warehouse": "WH"}}}
+	}`
+	cols := []string{"name", "comment", "agent_spec"}
+	row := []any{"yaml_agent", "", agentSpec}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buildSQLResponse(t, cols, row))
+	}))
+	defer srv.Close()
+
+	c := newDescribeTestClient(t, srv)
+	data, exists, err := c.DescribeAgentYAML(context.Background(), "MY_DB", "PUBLIC", "yaml_agent")
+	if err != nil {
+		t.Fatalf("DescribeAgentYAML: %v", err)
+	}
+	if !exists {
+		t.Fatal("expected exists=true")
+	}
+	want, _, err := c.GetAgent(context.Background(), "MY_DB", "PUBLIC", "yaml_agent")
+	if err != nil {
+		t.Fatalf("GetAgent: %v", err)
+	}
+
+	var got agent.AgentSpec
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&got); err != nil {
+		t.Fatalf("decode YAML: %v\n%s", err, data)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("round-tripped spec = %+v, want %+v\nYAML:\n%s", got, want, data)
+	}
+	if !bytes.Contains(data, []byte("comment: |")) {
+		t.Errorf("expected canonical literal style for the multiline comment:\n%s", data)
+	}
+}
+
+// TestDescribeAgentYAML_NotFound verifies that a missing agent returns no
+// data and exists=false without an error.
+func TestDescribeAgentYAML_NotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		notFoundResponse(w)
+	}))
+	defer srv.Close()
+
+	c := newDescribeTestClient(t, srv)
+	data, exists, err := c.DescribeAgentYAML(context.Background(), "MY_DB", "PUBLIC", "ghost")
+	if err != nil || exists || data != nil {
+		t.Fatalf("DescribeAgentYAML = (%q, %v, %v), want (nil, false, nil)", data, exists, err)
+	}
+}
+
 func TestListAgents_ShowAgents(t *testing.T) {
 	cols := []string{"name", "comment"}
 	row1 := []any{"agent_one", "first"}
@@ -267,9 +335,7 @@ func TestListAgents_ShowAgents(t *testing.T) {
 
 		resp := sqlStatementResponse{
 			Data: [][]any{row1, row2},
-			ResultSetMetaData: struct {
-				RowType []sqlRowType `json:"rowType"`
-			}{
+			ResultSetMetaData: sqlResultSetMetaData{
 				RowType: []sqlRowType{{Name: cols[0]}, {Name: cols[1]}},
 			},
 		}
@@ -304,6 +370,33 @@ func TestListAgents_ShowAgents(t *testing.T) {
 	}
 }
 
+func TestListAgents_SkipsShortRows(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := sqlStatementResponse{
+			Data: [][]any{
+				{"2024-01-01", "agent_one", "first"},
+				{"2024-01-02"},
+				{"2024-01-03", "agent_two"},
+			},
+			ResultSetMetaData: sqlResultSetMetaData{
+				RowType: []sqlRowType{{Name: "created_on"}, {Name: "name"}, {Name: "comment"}},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	c := newDescribeTestClient(t, srv)
+	listed, err := c.ListAgents(context.Background(), "MY_DB", "PUBLIC")
+	if err != nil {
+		t.Fatalf("ListAgents() error = %v", err)
+	}
+	if len(listed) != 2 || listed[0].Name != "agent_one" || listed[1].Name != "agent_two" {
+		t.Fatalf("listed = %+v, want agent_one and agent_two", listed)
+	}
+}
+
 // TestDescribeAgentFull_AllKnownColumns verifies that all known SQL columns
 // are handled without appearing in UnmappedColumns.
 func TestDescribeAgentFull_AllKnownColumns(t *testing.T) {
@@ -439,3 +532,118 @@ func TestDescribeAgentFull_RawColumnsPresent(t *testing.T) {
 		t.Error("expected 'comment' in RawColumns")
 	}
 }
+
+// TestDescribeAgentFull_MultiResourceToolEntriesPreserved verifies that a
+// tool whose resources come back as two separate entries keeps both, each
+// with its own fields, and matches the equivalent local list form.
+func TestDescribeAgentFull_MultiResourceToolEntriesPreserved(t *testing.T) {
+	specJSON := `{
+		"tools": [{"tool_spec": {"type": "cortex_analyst_text_to_sql", "name": "analyst"}}],
+		"tool_resources": {"analyst": [
+			{"semantic_view": "DB.SCH.SALES", "execution_environment": {"type": "warehouse", "warehouse": "WH_A"}},
+			{"semantic_view": "DB.SCH.ORDERS", "execution_environment": {"type": "warehouse", "warehouse": "WH_B"}}
+		]}
+	}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buildSQLResponse(t, []string{"name", "agent_spec"}, []any{"multi", specJSON}))
+	}))
+	defer srv.Close()
+
+	c := newDescribeTestClient(t, srv)
+	result, err := c.describeAgentFull(context.Background(), "DB", "SCH", "multi")
+	if err != nil {
+		t.Fatalf("describeAgentFull: %v", err)
+	}
+	entries := result.Spec.ToolResources.Entries("analyst")
+	if len(entries) != 2 || entries[0]["semantic_view"] != "DB.SCH.SALES" || entries[1]["semantic_view"] != "DB.SCH.ORDERS" {
+		t.Fatalf("analyst entries = %#v, want both resources in order", entries)
+	}
+
+	local := agent.AgentSpec{
+		Name:  "multi",
+		Tools: []agent.Tool{{ToolSpec: map[string]any{"type": "cortex_analyst_text_to_sql", "name": "analyst"}}},
+		ToolResources: agent.ToolResources{
+			"analyst": []any{
+				map[string]any{"semantic_view": "DB.SCH.SALES", "execution_environment": map[string]any{"type": "warehouse", "warehouse": "WH_A"}},
+				map[string]any{"semantic_view": "DB.SCH.ORDERS", "execution_environment": map[string]any{"type": "warehouse", "warehouse": "WH_B"}},
+			},
+		},
+	}
+	changes, err := diff.Diff(local, result.Spec)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after round-trip, got %+v", changes)
+	}
+}
+
+// TestDescribeAgentFull_MultiResourceListRoundTrip verifies that a single
+// resource object listing several semantic views decodes without a diff
+// against the equivalent local spec.
+func TestDescribeAgentFull_MultiResourceListRoundTrip(t *testing.T) {
+	specJSON := `{
+		"tools": [{"tool_spec": {"type": "cortex_analyst_text_to_sql", "name": "analyst"}}],
+		"tool_resources": {"analyst": [
+			{"semantic_view": ["DB.SCH.SALES", "DB.SCH.ORDERS"], "execution_environment": {"type": "warehouse", "warehouse": "WH"}}
+		]}
+	}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buildSQLResponse(t, []string{"name", "agent_spec"}, []any{"multi", specJSON}))
+	}))
+	defer srv.Close()
+
+	c := newDescribeTestClient(t, srv)
+	result, err := c.describeAgentFull(context.Background(), "DB", "SCH", "multi")
+	if err != nil {
+		t.Fatalf("describeAgentFull: %v", err)
+	}
+
+	local := agent.AgentSpec{
+		Name:  "multi",
+		Tools: []agent.Tool{{ToolSpec: map[string]any{"type": "cortex_analyst_text_to_sql", "name": "analyst"}}},
+		ToolResources: agent.ToolResources{
+			"analyst": map[string]any{
+				"semantic_view":         []any{"DB.SCH.SALES", "DB.SCH.ORDERS"},
+				"execution_environment": map[string]any{"type": "warehouse", "warehouse": "WH"},
+			},
+		},
+	}
+	changes, err := diff.Diff(local, result.Spec)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes after round-trip, got %+v", changes)
+	}
+}
+
+// TestDescribeAgentFull_ToolSpecCommentPreserved verifies that description
+// and comment keys inside a tool spec survive normalizeToolsList and
+// decodeSpecMap, including the camelCase toolSpec form, and are not reported
+// as unmapped spec keys.
+func TestDescribeAgentFull_ToolSpecCommentPreserved(t *testing.T) {
+	specJSON := `{
+		"tools": [{"toolSpec": {"type": "generic", "name": "lookup", "description": "Looks up orders", "comment": "Owned by ops"}}]
+	}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buildSQLResponse(t, []string{"name", "agent_spec"}, []any{"docs", specJSON}))
+	}))
+	defer srv.Close()
+
+	c := newDescribeTestClient(t, srv)
+	result, err := c.describeAgentFull(context.Background(), "DB", "SCH", "docs")
+	if err != nil {
+		t.Fatalf("describeAgentFull: %v", err)
+	}
+	if len(result.UnmappedSpecKeys) != 0 {
+		t.Errorf("unexpected unmapped spec keys: %v", result.UnmappedSpecKeys)
+	}
+	want := map[string]any{"type": "generic", "name": "lookup", "description": "Looks up orders", "comment": "Owned by ops"}
+	if len(result.Spec.Tools) != 1 || !reflect.DeepEqual(result.Spec.Tools[0].ToolSpec, want) {
+		t.Errorf("tools = %+v, want tool_spec %v", result.Spec.Tools, want)
+	}
+}
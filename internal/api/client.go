@@ -1,6 +1,8 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -8,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"coragent/internal/auth"
@@ -19,21 +22,67 @@ type Client struct {
 	role         string
 	userAgent    string
 	http         *http.Client
+	transport    http.RoundTripper // set by SetTransport; nil uses http.DefaultTransport
 	authCfg      auth.Config
 	queryTagBase string
 	log          *slog.Logger
+	observer     ResponseObserver
+	stats        clientStats
+
+	// MaxAttempts is the number of tries for idempotent requests (GETs and
+	// read-only SQL) that fail with 429 or 5xx. Values below 1 disable
+	// retries.
+	MaxAttempts int
+
+	// MaxResponseBytes caps the decompressed size of a JSON response body
+	// read by doJSON; a larger response fails with ResponseTooLargeError
+	// instead of being buffered. Values below 1 disable the cap.
+	MaxResponseBytes int64
+
+	// ReadOnly blocks agent create, update, delete and rename as well as
+	// GRANT and REVOKE with a ReadOnlyError before any request is sent.
+	// Describes, lists, runs, threads and feedback are unaffected.
+	ReadOnly bool
+
+	// agentLists memoizes ListAgents results per database.schema for the
+	// lifetime of the client (one command invocation). Create, update,
+	// delete and rename invalidate the affected schema.
+	agentListMu sync.Mutex
+	agentLists  map[string][]AgentListItem
 }
 
+// DefaultMaxAttempts is the default Client.MaxAttempts.
+const DefaultMaxAttempts = 3
+
+// DefaultMaxResponseBytes is the default Client.MaxResponseBytes (64 MiB).
+const DefaultMaxResponseBytes = 64 << 20
+
 // APIError represents a non-2xx HTTP response from the Snowflake API.
 type APIError struct {
 	StatusCode int
 	Body       string
+	// Hint is advice for a recognized failure, such as a key-pair JWT
+	// rejected because of clock skew; empty otherwise.
+	Hint string
 }
 
 func (e APIError) Error() string {
+	if e.Hint != "" {
+		return fmt.Sprintf("api error: status=%d body=%s; hint: %s", e.StatusCode, e.Body, e.Hint)
+	}
 	return fmt.Sprintf("api error: status=%d body=%s", e.StatusCode, e.Body)
 }
 
+// newAPIError builds the APIError for a non-2xx response, adding a clock
+// skew hint when Snowflake rejected a key-pair JWT.
+func newAPIError(resp *http.Response, body []byte) APIError {
+	apiErr := APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	if resp.StatusCode == http.StatusUnauthorized && auth.IsJWTInvalid(apiErr.Body) {
+		apiErr.Hint = auth.ClockSkewHint(resp.Header.Get("Date"), time.Now())
+	}
+	return apiErr
+}
+
 // IsNotFoundError reports whether err indicates that a resource does not exist.
 // It returns true for HTTP 404 responses and for Snowflake SQL errors that
 // carry "does not exist" or "object not found" messages (including error code 002003).
@@ -63,6 +112,38 @@ func IsNotFoundError(err error) bool {
 		strings.Contains(errMsg, "not found")
 }
 
+// IsAlreadyExistsError reports whether err is a Snowflake SQL error for an
+// object that already exists (error code 002002).
+func IsAlreadyExistsError(err error) bool {
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	bodyLower := strings.ToLower(apiErr.Body)
+	return strings.Contains(bodyLower, "already exists") ||
+		strings.Contains(bodyLower, "002002")
+}
+
+// IsRenameUnsupportedError reports whether err is Snowflake's
+// unsupported-feature error (code 000002) for ALTER AGENT ... RENAME. Other
+// unsupported features and free-text "not supported" bodies do not match,
+// since the caller falls back to a create-and-delete copy.
+func IsRenameUnsupportedError(err error) bool {
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	var body struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal([]byte(apiErr.Body), &body) != nil || body.Code != "000002" {
+		return false
+	}
+	msg := strings.ToLower(body.Message)
+	return strings.Contains(msg, "unsupported feature") && strings.Contains(msg, "rename")
+}
+
 // isNotFoundError is the internal alias used within the api package.
 func isNotFoundError(err error) bool { return IsNotFoundError(err) }
 
@@ -80,12 +161,14 @@ func NewClient(cfg auth.Config) (*Client, error) {
 // Intended for use in tests against mock HTTP servers — no real Snowflake credentials required.
 func NewClientForTest(base *url.URL, cfg auth.Config) *Client {
 	return &Client{
-		baseURL:      base,
-		userAgent:    "test",
-		http:         &http.Client{Timeout: 30 * time.Second},
-		authCfg:      cfg,
-		queryTagBase: "coragent",
-		log:          discardLogger(),
+		baseURL:          base,
+		userAgent:        "test",
+		http:             &http.Client{Timeout: 30 * time.Second},
+		authCfg:          cfg,
+		queryTagBase:     "coragent",
+		log:              discardLogger(),
+		MaxAttempts:      DefaultMaxAttempts,
+		MaxResponseBytes: DefaultMaxResponseBytes,
 	}
 }
 
@@ -114,18 +197,39 @@ func NewClientWithDebug(cfg auth.Config, debug bool) (*Client, error) {
 	}
 
 	client := &Client{
-		baseURL:      base,
-		role:         strings.ToUpper(strings.TrimSpace(cfg.Role)),
-		userAgent:    "coragent",
-		http:         &http.Client{Timeout: 60 * time.Second},
-		authCfg:      cfg,
-		queryTagBase: "coragent",
-		log:          log,
+		baseURL:          base,
+		role:             strings.ToUpper(strings.TrimSpace(cfg.Role)),
+		userAgent:        "coragent",
+		http:             &http.Client{Timeout: 60 * time.Second},
+		authCfg:          cfg,
+		queryTagBase:     "coragent",
+		log:              log,
+		MaxAttempts:      DefaultMaxAttempts,
+		MaxResponseBytes: DefaultMaxResponseBytes,
 	}
 
 	return client, nil
 }
 
+// SetLogger replaces the client's logger, e.g. with a JSON handler. A nil
+// logger discards all output.
+func (c *Client) SetLogger(l *slog.Logger) {
+	if l == nil {
+		l = discardLogger()
+	}
+	c.log = l
+}
+
+// SetTransport replaces the HTTP transport for every request the client
+// sends: REST and SQL API calls, streaming runs, and OAuth token refreshes
+// made while authenticating them. Use it for proxies, mTLS, tracing or
+// retry decorators, or to stub responses in tests. A nil rt restores
+// http.DefaultTransport.
+func (c *Client) SetTransport(rt http.RoundTripper) {
+	c.transport = rt
+	c.http.Transport = rt
+}
+
 // SetQueryTagBase overrides the default base tag used for supported Snowflake requests.
 func (c *Client) SetQueryTagBase(base string) {
 	c.queryTagBase = strings.TrimSpace(base)
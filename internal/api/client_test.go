@@ -1,17 +1,22 @@
 package api
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"coragent/internal/agent"
+	"coragent/internal/auth"
 )
 
 func TestIsNotFoundError(t *testing.T) {
@@ -41,6 +46,28 @@ func TestIsNotFoundError(t *testing.T) {
 	}
 }
 
+func TestIsRenameUnsupportedError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"rename unsupported", APIError{StatusCode: 422, Body: `{"code":"000002","message":"SQL compilation error: Unsupported feature 'ALTER AGENT RENAME'."}`}, true},
+		{"other unsupported feature", APIError{StatusCode: 422, Body: `{"code":"000002","message":"SQL compilation error: Unsupported feature 'CLONE'."}`}, false},
+		{"not supported text", APIError{StatusCode: 400, Body: `{"code":"001003","message":"rename of this object is not supported here"}`}, false},
+		{"code in plain text", APIError{StatusCode: 400, Body: "error 000002 while renaming"}, false},
+		{"non-API error", fmt.Errorf("Unsupported feature 'ALTER AGENT RENAME'"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRenameUnsupportedError(tt.err); got != tt.want {
+				t.Errorf("IsRenameUnsupportedError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestResolveQueryTag(t *testing.T) {
 	c := &Client{queryTagBase: "team-cli"}
 
@@ -83,6 +110,186 @@ func TestDoJSON_SQLAddsQueryTag(t *testing.T) {
 	}
 }
 
+func TestDoJSON_RetriesTransientStatus(t *testing.T) {
+	oldDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = oldDelay }()
+
+	tests := []struct {
+		name      string
+		method    string
+		payload   any
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{"GET retried until success", http.MethodGet, nil, []int{503, 429, 200}, 3, false},
+		{"GET gives up after max attempts", http.MethodGet, nil, []int{503, 503, 503, 200}, 3, true},
+		{"DESCRIBE retried", http.MethodPost, sqlStatementRequest{Statement: "DESCRIBE AGENT A"}, []int{503, 200}, 2, false},
+		{"CREATE not retried", http.MethodPost, sqlStatementRequest{Statement: "CREATE AGENT A"}, []int{503, 200}, 1, true},
+		{"404 not retried", http.MethodGet, nil, []int{404, 200}, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[calls]
+				calls++
+				if status == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			client := newDescribeTestClient(t, srv)
+			client.MaxAttempts = 3
+			err := client.doJSON(context.Background(), tt.method, client.sqlURL(), tt.payload, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("doJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("server calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestDoJSON_RejectsOversizedResponse(t *testing.T) {
+	body := `{"data":[["` + strings.Repeat("x", 100) + `"]]}`
+	tests := []struct {
+		name    string
+		limit   int64
+		gzip    bool
+		wantErr bool
+	}{
+		{"exceeds limit", 64, false, true},
+		{"exact fit", int64(len(body)), false, false},
+		{"gzip counted after decompression", 64, true, true},
+		{"no limit", 0, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				if tt.gzip {
+					w.Header().Set("Content-Encoding", "gzip")
+					zw := gzip.NewWriter(w)
+					_, _ = zw.Write([]byte(body))
+					_ = zw.Close()
+					return
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer srv.Close()
+
+			client := newDescribeTestClient(t, srv)
+			client.MaxResponseBytes = tt.limit
+			var resp sqlStatementResponse
+			err := client.doJSON(context.Background(), http.MethodPost, client.sqlURL(), sqlStatementRequest{Statement: "SELECT 1"}, &resp)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("doJSON() error = %v", err)
+				}
+				if len(resp.Data) != 1 {
+					t.Errorf("decoded %d rows, want 1", len(resp.Data))
+				}
+				return
+			}
+			var tooLarge ResponseTooLargeError
+			if !errors.As(err, &tooLarge) || tooLarge.Limit != tt.limit {
+				t.Fatalf("doJSON() error = %v, want ResponseTooLargeError with limit %d", err, tt.limit)
+			}
+			if !strings.Contains(err.Error(), "exceeds the 64-byte limit") {
+				t.Errorf("error message = %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestDoJSON_JWTInvalidAddsClockSkewHint(t *testing.T) {
+	serverDate := time.Now().Add(-15 * time.Minute).UTC().Format(http.TimeFormat)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverDate)
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"code":"390144","message":"JWT token is invalid. [5d1c2f]"}`))
+	}))
+	defer srv.Close()
+
+	client := newDescribeTestClient(t, srv)
+	err := client.doJSON(context.Background(), http.MethodGet, srv.URL+"/api/v2/agents", nil, nil)
+	var apiErr APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want 401", apiErr.StatusCode)
+	}
+	if !strings.Contains(apiErr.Hint, "local clock is 15m") || !strings.Contains(err.Error(), "hint: Snowflake rejected the key-pair JWT") {
+		t.Errorf("error = %q, want a clock skew hint", err)
+	}
+
+	other := newAPIError(&http.Response{StatusCode: http.StatusUnauthorized, Header: http.Header{}}, []byte(`{"message":"Incorrect username or password"}`))
+	if other.Hint != "" {
+		t.Errorf("unrelated 401 got hint %q", other.Hint)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	oldDelay := retryBaseDelay
+	retryBaseDelay = 100 * time.Millisecond
+	defer func() { retryBaseDelay = oldDelay }()
+
+	if got := retryDelay("", 1); got != 100*time.Millisecond {
+		t.Errorf("attempt 1: got %s, want 100ms", got)
+	}
+	if got := retryDelay("", 3); got != 400*time.Millisecond {
+		t.Errorf("attempt 3: got %s, want 400ms", got)
+	}
+	if got := retryDelay("", 100); got != maxRetryDelay {
+		t.Errorf("attempt 100: got %s, want the %s cap", got, maxRetryDelay)
+	}
+	if got := retryDelay("2", 1); got != 2*time.Second {
+		t.Errorf("Retry-After seconds: got %s, want 2s", got)
+	}
+	past := time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)
+	if got := retryDelay(past, 1); got != 0 {
+		t.Errorf("Retry-After past date: got %s, want 0", got)
+	}
+	if got := retryDelay("3600", 1); got != maxRetryDelay {
+		t.Errorf("Retry-After seconds: got %s, want the %s cap", got, maxRetryDelay)
+	}
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if got := retryDelay(future, 1); got != maxRetryDelay {
+		t.Errorf("Retry-After future date: got %s, want the %s cap", got, maxRetryDelay)
+	}
+}
+
+func TestFetchResult_EscapesHandle(t *testing.T) {
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.EscapedPath()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":[]}`))
+	}))
+	defer srv.Close()
+
+	base, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+	client := newDescribeTestClient(t, srv)
+	client.baseURL = base
+
+	if _, err := client.FetchResult(context.Background(), "01b2/../x?y"); err != nil {
+		t.Fatalf("FetchResult: %v", err)
+	}
+	if want := "/api/v2/statements/01b2%2F..%2Fx%3Fy"; gotPath != want {
+		t.Errorf("path = %q, want %q", gotPath, want)
+	}
+}
+
 func TestIdentifierSegment(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -135,6 +342,41 @@ func TestUnquoteIdentifier(t *testing.T) {
 	}
 }
 
+func TestResolveIdentifier(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"my_agent", "MY_AGENT"},
+		{"MY_AGENT", "MY_AGENT"},
+		{`"my_agent"`, "my_agent"},
+		{"my-agent", "my-agent"},
+		{"  Sales$1 ", "SALES$1"},
+	}
+	for _, tt := range tests {
+		if got := ResolveIdentifier(tt.value); got != tt.want {
+			t.Errorf("ResolveIdentifier(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestIdentifierSQL(t *testing.T) {
+	tests := []struct {
+		stored string
+		want   string
+	}{
+		{"MY_AGENT", "MY_AGENT"},
+		{"my_agent", `"my_agent"`},
+		{"my-agent", `"my-agent"`},
+		{`a"b`, `"a""b"`},
+	}
+	for _, tt := range tests {
+		if got := IdentifierSQL(tt.stored); got != tt.want {
+			t.Errorf("IdentifierSQL(%q) = %q, want %q", tt.stored, got, tt.want)
+		}
+	}
+}
+
 func TestIsSimpleIdentifier(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -260,6 +502,24 @@ func TestNormalizeAgentSpecMap(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "models passes per-phase models through",
+			input: map[string]any{"models": map[string]any{
+				"orchestration": "auto",
+				"Response":      "claude-4-sonnet",
+				"toolUse":       "llama3.1-70b",
+			}},
+			check: func(m map[string]any) error {
+				models, ok := m["models"].(map[string]any)
+				if !ok {
+					return fmt.Errorf("models = %T", m["models"])
+				}
+				if models["orchestration"] != "auto" || models["response"] != "claude-4-sonnet" || models["tool_use"] != "llama3.1-70b" {
+					return fmt.Errorf("models = %v", models)
+				}
+				return nil
+			},
+		},
 		{
 			name:  "other keys preserved",
 			input: map[string]any{"name": "test", "comment": "hello"},
@@ -318,6 +578,22 @@ func TestNormalizeToolResources(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "multiple entries stay separate",
+			input: map[string]any{
+				"tool1": []any{
+					map[string]any{"semantic_view": "sv1", "execution_environment": map[string]any{"type": "warehouse"}},
+					map[string]any{"semantic_view": "sv2", "execution_environment": map[string]any{"type": "warehouse"}},
+				},
+			},
+			check: func(m map[string]any) error {
+				list, ok := m["tool1"].([]any)
+				if !ok || len(list) != 2 {
+					return fmt.Errorf("tool1 = %#v, want the two entries unchanged", m["tool1"])
+				}
+				return nil
+			},
+		},
 		{
 			name: "empty array",
 			input: map[string]any{
@@ -715,3 +991,187 @@ func TestDetectUnmappedSpecKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_UsesPreIssuedSessionToken(t *testing.T) {
+	var gotAuth, gotType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotType = r.Header.Get("X-Snowflake-Authorization-Token-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	t.Setenv("CORAGENT_API_BASE_URL", srv.URL)
+	client, err := NewClientWithDebug(auth.Config{Account: "TEST", SessionToken: "ci-token"}, false)
+	if err != nil {
+		t.Fatalf("NewClientWithDebug: %v", err)
+	}
+	if err := client.DeleteAgent(context.Background(), "DB", "SCH", "agent"); err != nil {
+		t.Fatalf("DeleteAgent: %v", err)
+	}
+	if gotAuth != "Bearer ci-token" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer ci-token")
+	}
+	if gotType != "OAUTH" {
+		t.Errorf("token type = %q, want %q", gotType, "OAUTH")
+	}
+}
+
+func TestClient_AuthHeadersPerAuthMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       func(t *testing.T) auth.Config
+		wantType  string
+		wantToken string // empty: only the Bearer scheme is checked
+	}{
+		{
+			name: "key pair",
+			cfg: func(t *testing.T) auth.Config {
+				return auth.Config{Account: "TEST", User: "TESTUSER", PrivateKey: testRSAPEM(t)}
+			},
+			wantType: auth.TokenTypeKeyPairJWT,
+		},
+		{
+			name: "oauth",
+			cfg: func(t *testing.T) auth.Config {
+				t.Setenv("HOME", t.TempDir())
+				store, err := auth.LoadTokenStore()
+				if err != nil {
+					t.Fatalf("LoadTokenStore: %v", err)
+				}
+				store.SetTokens(auth.OAuthTokens{Account: "TEST", AccessToken: "oauth-access", ExpiresAt: time.Now().Add(time.Hour)})
+				if err := store.Save(); err != nil {
+					t.Fatalf("save token store: %v", err)
+				}
+				return auth.Config{Account: "TEST", Authenticator: auth.AuthenticatorOAuth}
+			},
+			wantType:  auth.TokenTypeOAuth,
+			wantToken: "oauth-access",
+		},
+		{
+			name: "session token",
+			cfg: func(t *testing.T) auth.Config {
+				return auth.Config{Account: "TEST", SessionToken: "ci-token"}
+			},
+			wantType:  auth.TokenTypeOAuth,
+			wantToken: "ci-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			type recorded struct{ path, auth, tokenType string }
+			var got []recorded
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = append(got, recorded{r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("X-Snowflake-Authorization-Token-Type")})
+				if strings.HasSuffix(r.URL.Path, ":run") {
+					w.Header().Set("Content-Type", "text/event-stream")
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"data":[]}`))
+			}))
+			defer srv.Close()
+
+			base, err := url.Parse(srv.URL)
+			if err != nil {
+				t.Fatalf("parse server url: %v", err)
+			}
+			client := NewClientForTest(base, tt.cfg(t))
+			ctx := context.Background()
+			if err := client.DeleteAgent(ctx, "DB", "SCH", "agent"); err != nil {
+				t.Fatalf("DeleteAgent (REST): %v", err)
+			}
+			if _, err := client.ListAgents(ctx, "DB", "SCH"); err != nil {
+				t.Fatalf("ListAgents (SQL): %v", err)
+			}
+			// The empty stream fails the run; only the request headers matter.
+			_, _ = client.RunAgent(ctx, "DB", "SCH", "agent", RunAgentRequest{}, RunAgentOptions{})
+
+			if len(got) != 3 {
+				t.Fatalf("recorded %d requests, want 3: %+v", len(got), got)
+			}
+			for _, r := range got {
+				if !strings.HasPrefix(r.auth, "Bearer ") {
+					t.Errorf("%s: Authorization = %q, want Bearer scheme", r.path, r.auth)
+				}
+				if tt.wantToken != "" && r.auth != "Bearer "+tt.wantToken {
+					t.Errorf("%s: Authorization = %q, want %q", r.path, r.auth, "Bearer "+tt.wantToken)
+				}
+				if r.tokenType != tt.wantType {
+					t.Errorf("%s: token type = %q, want %q", r.path, r.tokenType, tt.wantType)
+				}
+			}
+		})
+	}
+}
+
+// stubTransport is an http.RoundTripper that records every request and
+// answers with the next canned response, so client behaviour can be tested
+// without a listening server.
+type stubTransport struct {
+	requests  []*http.Request
+	responses []stubResponse
+}
+
+type stubResponse struct {
+	status int
+	body   string
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(s.requests) >= len(s.responses) {
+		return nil, fmt.Errorf("unexpected request %d: %s %s", len(s.requests)+1, req.Method, req.URL)
+	}
+	resp := s.responses[len(s.requests)]
+	s.requests = append(s.requests, req)
+	return &http.Response{
+		StatusCode: resp.status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(resp.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestClient_SetTransport(t *testing.T) {
+	oldDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	defer func() { retryBaseDelay = oldDelay }()
+
+	stub := &stubTransport{responses: []stubResponse{
+		{http.StatusServiceUnavailable, `{"message":"busy"}`},
+		{http.StatusOK, `{"name":"SALES"}`},
+		{http.StatusNotFound, `{"message":"Agent does not exist"}`},
+	}}
+	base, _ := url.Parse("https://stub.invalid")
+	client := NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "stub-token"})
+	client.SetTransport(stub)
+
+	var got struct {
+		Name string `json:"name"`
+	}
+	if err := client.doJSON(context.Background(), http.MethodGet, "https://stub.invalid/api/v2/agent", nil, &got); err != nil {
+		t.Fatalf("doJSON: %v", err)
+	}
+	if got.Name != "SALES" {
+		t.Errorf("decoded name = %q, want %q", got.Name, "SALES")
+	}
+	if len(stub.requests) != 2 {
+		t.Fatalf("requests = %d, want 2 (one retry)", len(stub.requests))
+	}
+	for i, req := range stub.requests {
+		if got := req.Header.Get("Authorization"); got != "Bearer stub-token" {
+			t.Errorf("request %d Authorization = %q", i, got)
+		}
+		if got := req.Header.Get("User-Agent"); got != "test" {
+			t.Errorf("request %d User-Agent = %q", i, got)
+		}
+	}
+
+	_, err := client.RunAgent(context.Background(), "DB", "SCH", "SALES", RunAgentRequest{}, RunAgentOptions{})
+	if !IsNotFoundError(err) {
+		t.Fatalf("RunAgent error = %v, want not found", err)
+	}
+	if len(stub.requests) != 3 || !strings.HasSuffix(stub.requests[2].URL.Path, "/agents/SALES:run") {
+		t.Errorf("RunAgent did not use the stub transport: %d requests", len(stub.requests))
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MaxConflictRetries bounds RunAgentOptions.ConflictRetries.
+const MaxConflictRetries = 10
+
+// ConflictError is returned by RunAgent when Snowflake rejects a run because
+// another run is still in progress, typically on the same thread. The run was
+// not started, so it is safe to send again.
+type ConflictError struct {
+	APIError
+	// RetryAfter is the response's Retry-After header, if any.
+	RetryAfter string
+}
+
+func (e ConflictError) Error() string {
+	return fmt.Sprintf("agent run conflict: another run is already in progress (status=%d body=%s)", e.StatusCode, e.Body)
+}
+
+func (e ConflictError) Unwrap() error { return e.APIError }
+
+// IsConflictError reports whether err is, or wraps, a ConflictError.
+func IsConflictError(err error) bool {
+	var conflict ConflictError
+	return errors.As(err, &conflict)
+}
+
+// isRunConflict reports whether a failed :run response means another run is
+// in progress: HTTP 409, or a 4xx body saying so. 429 is rate limiting, even
+// when its body mentions concurrent requests, and is never a conflict.
+func isRunConflict(status int, body string) bool {
+	if status == http.StatusConflict {
+		return true
+	}
+	if status < 400 || status >= 500 || status == http.StatusTooManyRequests {
+		return false
+	}
+	bodyLower := strings.ToLower(body)
+	return strings.Contains(bodyLower, "already running") ||
+		strings.Contains(bodyLower, "already in progress") ||
+		strings.Contains(bodyLower, "concurrent request")
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRunConflict(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{409, `{"message":"conflict"}`, true},
+		{400, `{"message":"A request is already running on this thread"}`, true},
+		{400, `{"message":"Another run is already in progress"}`, true},
+		{429, `{"message":"Too many concurrent requests"}`, false},
+		{429, `{"message":"Another run is already in progress"}`, false},
+		{400, `{"message":"invalid request"}`, false},
+		{503, `{"message":"already running"}`, false},
+	}
+	for _, tt := range tests {
+		if got := isRunConflict(tt.status, tt.body); got != tt.want {
+			t.Errorf("isRunConflict(%d, %q) = %v, want %v", tt.status, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestConflictError_UnwrapsToAPIError(t *testing.T) {
+	err := fmt.Errorf("run agent: %w", ConflictError{APIError: APIError{StatusCode: 409, Body: "busy"}})
+	if !IsConflictError(err) {
+		t.Fatalf("IsConflictError(%v) = false", err)
+	}
+	var apiErr APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 409 {
+		t.Errorf("errors.As APIError = %+v", apiErr)
+	}
+	if IsConflictError(APIError{StatusCode: 409}) {
+		t.Error("a plain APIError is not a ConflictError")
+	}
+}
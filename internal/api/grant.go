@@ -12,6 +12,7 @@ type ShowGrantsRow struct {
 	Privilege   string
 	GrantedTo   string // "ROLE" or "DATABASE_ROLE"
 	GranteeName string
+	GrantOption bool
 }
 
 // ShowGrants executes SHOW GRANTS ON AGENT and returns current grants.
@@ -23,47 +24,20 @@ func (c *Client) ShowGrants(ctx context.Context, db, schema, agentName string) (
 
 	stmt := fmt.Sprintf("SHOW GRANTS ON AGENT %s", fqAgent)
 
-	payload := sqlStatementRequest{
-		Statement: stmt,
-		Database:  unquoteIdentifier(db),
-		Schema:    unquoteIdentifier(schema),
-	}
-	if strings.TrimSpace(c.authCfg.Warehouse) != "" {
-		payload.Warehouse = c.authCfg.Warehouse
-	}
-	if strings.TrimSpace(c.role) != "" {
-		payload.Role = c.role
-	}
-
-	var resp sqlStatementResponse
-	if err := c.doJSON(ctx, http.MethodPost, c.sqlURL(), payload, &resp); err != nil {
-		return nil, err
-	}
-
-	// Parse response - expected columns:
+	// Expected columns:
 	// created_on, privilege, granted_on, name, granted_to, grantee_name, grant_option, granted_by
 	var rows []ShowGrantsRow
-	colIndex := make(map[string]int)
-	for i, col := range resp.ResultSetMetaData.RowType {
-		colIndex[strings.ToLower(col.Name)] = i
-	}
-
-	for _, row := range resp.Data {
-		privIdx, ok1 := colIndex["privilege"]
-		grantedToIdx, ok2 := colIndex["granted_to"]
-		granteeIdx, ok3 := colIndex["grantee_name"]
-
+	err := c.iterateShow(ctx, stmt, func(row map[string]any) error {
+		privVal, ok1 := row["privilege"]
+		grantedToVal, ok2 := row["granted_to"]
+		granteeVal, ok3 := row["grantee_name"]
 		if !ok1 || !ok2 || !ok3 {
-			continue
-		}
-
-		if privIdx >= len(row) || grantedToIdx >= len(row) || granteeIdx >= len(row) {
-			continue
+			return nil
 		}
 
-		priv, _ := row[privIdx].(string)
-		grantedTo, _ := row[grantedToIdx].(string)
-		grantee, _ := row[granteeIdx].(string)
+		priv, _ := privVal.(string)
+		grantedTo, _ := grantedToVal.(string)
+		grantee, _ := granteeVal.(string)
 
 		// For DATABASE_ROLE, prefix with database name if not already qualified
 		if grantedTo == "DATABASE_ROLE" && !strings.Contains(grantee, ".") {
@@ -74,7 +48,12 @@ func (c *Client) ShowGrants(ctx context.Context, db, schema, agentName string) (
 			Privilege:   priv,
 			GrantedTo:   grantedTo, // "ROLE" or "DATABASE_ROLE"
 			GranteeName: grantee,
+			GrantOption: strings.EqualFold(fmt.Sprint(row["grant_option"]), "true"),
 		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return rows, nil
@@ -82,51 +61,44 @@ func (c *Client) ShowGrants(ctx context.Context, db, schema, agentName string) (
 
 // ExecuteGrant executes a GRANT statement for the given privilege.
 func (c *Client) ExecuteGrant(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error {
-	fqAgent := fmt.Sprintf("%s.%s.%s",
-		identifierSegment(db),
-		identifierSegment(schema),
-		identifierSegment(agentName))
-
-	var stmt string
-	if roleType == "ROLE" {
-		stmt = fmt.Sprintf("GRANT %s ON AGENT %s TO ROLE %s",
-			privilege, fqAgent, identifierSegment(roleName))
-	} else {
-		// DATABASE ROLE - roleName is already fully qualified (DB.ROLE_NAME)
-		stmt = fmt.Sprintf("GRANT %s ON AGENT %s TO DATABASE ROLE %s",
-			privilege, fqAgent, roleName)
-	}
-
-	payload := sqlStatementRequest{
-		Statement: stmt,
-		Database:  unquoteIdentifier(db),
-		Schema:    unquoteIdentifier(schema),
-	}
-	if strings.TrimSpace(c.authCfg.Warehouse) != "" {
-		payload.Warehouse = c.authCfg.Warehouse
-	}
-	if strings.TrimSpace(c.role) != "" {
-		payload.Role = c.role
-	}
+	return c.executeGrantStatement(ctx, db, schema, "GRANT %s ON AGENT %s TO %s", agentName, roleType, roleName, privilege)
+}
 
-	return c.doJSON(ctx, http.MethodPost, c.sqlURL(), payload, nil)
+// ExecuteGrantWithGrantOption executes a GRANT ... WITH GRANT OPTION
+// statement. It also upgrades an existing grant of the same privilege.
+func (c *Client) ExecuteGrantWithGrantOption(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error {
+	return c.executeGrantStatement(ctx, db, schema, "GRANT %s ON AGENT %s TO %s WITH GRANT OPTION", agentName, roleType, roleName, privilege)
 }
 
 // ExecuteRevoke executes a REVOKE statement for the given privilege.
 func (c *Client) ExecuteRevoke(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error {
+	return c.executeGrantStatement(ctx, db, schema, "REVOKE %s ON AGENT %s FROM %s", agentName, roleType, roleName, privilege)
+}
+
+// RevokeGrantOption executes REVOKE GRANT OPTION FOR, which removes the
+// grant option but keeps the privilege.
+func (c *Client) RevokeGrantOption(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error {
+	return c.executeGrantStatement(ctx, db, schema, "REVOKE GRANT OPTION FOR %s ON AGENT %s FROM %s", agentName, roleType, roleName, privilege)
+}
+
+// executeGrantStatement fills format with the privilege, the qualified agent
+// name and the grantee ("ROLE x" or "DATABASE ROLE db.x") and runs it.
+func (c *Client) executeGrantStatement(ctx context.Context, db, schema, format, agentName, roleType, roleName, privilege string) error {
 	fqAgent := fmt.Sprintf("%s.%s.%s",
 		identifierSegment(db),
 		identifierSegment(schema),
 		identifierSegment(agentName))
 
-	var stmt string
+	var grantee string
 	if roleType == "ROLE" {
-		stmt = fmt.Sprintf("REVOKE %s ON AGENT %s FROM ROLE %s",
-			privilege, fqAgent, identifierSegment(roleName))
+		grantee = "ROLE " + identifierSegment(roleName)
 	} else {
 		// DATABASE ROLE - roleName is already fully qualified (DB.ROLE_NAME)
-		stmt = fmt.Sprintf("REVOKE %s ON AGENT %s FROM DATABASE ROLE %s",
-			privilege, fqAgent, roleName)
+		grantee = "DATABASE ROLE " + roleName
+	}
+	stmt := fmt.Sprintf(format, privilege, fqAgent, grantee)
+	if err := c.checkWritable("%s", stmt); err != nil {
+		return err
 	}
 
 	payload := sqlStatementRequest{
@@ -4,15 +4,25 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"coragent/internal/auth"
 )
 
+// retryBaseDelay is the backoff before the first retry; it doubles on each
+// further attempt. Overridden in tests to avoid real delays.
+var retryBaseDelay = 500 * time.Millisecond
+
+// maxRetryDelay caps the exponential backoff between retries.
+const maxRetryDelay = 30 * time.Second
+
 func (c *Client) doJSON(ctx context.Context, method, urlStr string, payload any, out any) error {
 	if method == http.MethodPost {
 		if sqlPayload, ok := payload.(sqlStatementRequest); ok {
@@ -20,7 +30,6 @@ func (c *Client) doJSON(ctx context.Context, method, urlStr string, payload any,
 		}
 	}
 
-	var body io.Reader
 	var reqBody []byte
 	if payload != nil {
 		data, err := json.Marshal(payload)
@@ -28,39 +37,44 @@ func (c *Client) doJSON(ctx context.Context, method, urlStr string, payload any,
 			return fmt.Errorf("marshal payload: %w", err)
 		}
 		reqBody = data
-		body = bytes.NewReader(data)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
 	}
 
-	// Set authorization header
-	token, tokenType, err := auth.BearerToken(ctx, c.authCfg)
-	if err != nil {
-		return err
+	attempts := 1
+	if isIdempotentRequest(method, payload) {
+		attempts = max(c.MaxAttempts, 1)
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("X-Snowflake-Authorization-Token-Type", tokenType)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", c.userAgent)
-	if payload != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	if c.role != "" {
-		req.Header.Set("X-Snowflake-Role", c.role)
-	}
-
-	resp, err := c.http.Do(req)
-	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+	var resp *http.Response
+	for attempt := 1; ; attempt++ {
+		var err error
+		resp, err = c.sendJSON(ctx, method, urlStr, reqBody)
+		if err != nil {
+			return err
+		}
+		if attempt >= attempts || !isRetryableStatus(resp.StatusCode) {
+			break
+		}
+		delay := retryDelay(resp.Header.Get("Retry-After"), attempt)
+		c.stats.retries.Add(1)
+		c.log.Debug("retrying request", "method", method, "url", urlStr, "status", resp.StatusCode, "attempt", attempt, "delay", delay)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("request failed: %w", ctx.Err())
+		case <-time.After(delay):
+		}
 	}
 	defer resp.Body.Close()
+	// The transport already decompresses gzip bodies, so the cap applies to
+	// the decoded size.
+	body := limitResponseBody(resp.Body, c.MaxResponseBytes)
 
 	// When debug logging is enabled, buffer the response body so we can log it.
 	if c.log.Enabled(ctx, slog.LevelDebug) {
-		bodyBytes, _ := io.ReadAll(resp.Body)
+		bodyBytes, err := io.ReadAll(body)
+		if isResponseTooLarge(err) {
+			return err
+		}
 		c.log.Debug("http", "method", method, "url", urlStr, "status", resp.StatusCode)
 		if len(reqBody) > 0 {
 			c.log.Debug("request body", "body", truncateDebug(reqBody))
@@ -69,7 +83,7 @@ func (c *Client) doJSON(ctx context.Context, method, urlStr string, payload any,
 			c.log.Debug("response body", "body", truncateDebug(bodyBytes))
 		}
 		if resp.StatusCode >= 300 {
-			return APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+			return newAPIError(resp, bodyBytes)
 		}
 		if out != nil {
 			if err := json.NewDecoder(bytes.NewReader(bodyBytes)).Decode(out); err != nil && err != io.EOF {
@@ -80,12 +94,18 @@ func (c *Client) doJSON(ctx context.Context, method, urlStr string, payload any,
 	}
 
 	if resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+		bodyBytes, err := io.ReadAll(body)
+		if isResponseTooLarge(err) {
+			return err
+		}
+		return newAPIError(resp, bodyBytes)
 	}
 
 	if out != nil {
-		if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		if err := json.NewDecoder(body).Decode(out); err != nil && err != io.EOF {
+			if isResponseTooLarge(err) {
+				return err
+			}
 			return fmt.Errorf("decode response: %w", err)
 		}
 	}
@@ -93,6 +113,157 @@ func (c *Client) doJSON(ctx context.Context, method, urlStr string, payload any,
 	return nil
 }
 
+// ResponseTooLargeError is returned when a response body exceeds
+// Client.MaxResponseBytes.
+type ResponseTooLargeError struct {
+	Limit int64
+}
+
+func (e ResponseTooLargeError) Error() string {
+	return fmt.Sprintf("response body exceeds the %d-byte limit; narrow the query (e.g. a shorter time range) to reduce the result size", e.Limit)
+}
+
+func isResponseTooLarge(err error) bool {
+	var tooLarge ResponseTooLargeError
+	return errors.As(err, &tooLarge)
+}
+
+// limitResponseBody returns r wrapped so that reading more than limit bytes
+// fails with ResponseTooLargeError. A limit below 1 returns r unchanged.
+func limitResponseBody(r io.Reader, limit int64) io.Reader {
+	if limit < 1 {
+		return r
+	}
+	return &limitedBody{r: r, limit: limit, remaining: limit}
+}
+
+type limitedBody struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ResponseTooLargeError{Limit: l.limit}
+	}
+	// Read one byte past the limit to tell an exact fit from an overflow.
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), ResponseTooLargeError{Limit: l.limit}
+	}
+	return n, err
+}
+
+// sendJSON builds and sends a single request with the auth and content
+// headers. A nil reqBody sends no body.
+func (c *Client) sendJSON(ctx context.Context, method, urlStr string, reqBody []byte) (*http.Response, error) {
+	var body io.Reader
+	if reqBody != nil {
+		body = bytes.NewReader(reqBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	if err := c.setAuthHeaders(ctx, req); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.role != "" {
+		req.Header.Set("X-Snowflake-Role", c.role)
+	}
+
+	resp, err := c.do(c.http, req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// setAuthHeaders sets the Authorization header and the token type header
+// matching the configured authenticator: a key pair JWT is sent as
+// KEYPAIR_JWT, while OAuth access tokens and pre-issued session tokens are
+// sent as OAUTH. Both REST and SQL API requests use the Bearer scheme.
+func (c *Client) setAuthHeaders(ctx context.Context, req *http.Request) error {
+	if c.transport != nil {
+		ctx = auth.WithTransport(ctx, c.transport)
+	}
+	token, tokenType, err := auth.BearerToken(ctx, c.authCfg)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-Snowflake-Authorization-Token-Type", tokenType)
+	return nil
+}
+
+// readOnlyStatementPrefixes are SQL statement keywords that do not modify
+// state, so their requests may be repeated safely.
+var readOnlyStatementPrefixes = []string{"DESCRIBE", "DESC", "SHOW", "SELECT"}
+
+// isIdempotentRequest reports whether a request may be retried: GETs and
+// SQL API POSTs that only read (DESCRIBE, SHOW, SELECT).
+func isIdempotentRequest(method string, payload any) bool {
+	if method == http.MethodGet {
+		return true
+	}
+	if method != http.MethodPost {
+		return false
+	}
+	sqlPayload, ok := payload.(sqlStatementRequest)
+	if !ok {
+		return false
+	}
+	fields := strings.Fields(sqlPayload.Statement)
+	if len(fields) == 0 {
+		return false
+	}
+	first := strings.ToUpper(fields[0])
+	for _, prefix := range readOnlyStatementPrefixes {
+		if first == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+// isRetryableStatus reports whether status is transient: 429 or any 5xx.
+// Other 4xx responses are never retried.
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryDelay returns the wait before the next attempt. A Retry-After header
+// (seconds or HTTP date) takes precedence over exponential backoff, which
+// doubles from retryBaseDelay. Both are capped at maxRetryDelay so a server
+// cannot stall the CLI indefinitely.
+func retryDelay(retryAfter string, attempt int) time.Duration {
+	if retryAfter = strings.TrimSpace(retryAfter); retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, maxRetryDelay)
+		}
+		if t, err := http.ParseTime(retryAfter); err == nil {
+			return min(max(time.Until(t), 0), maxRetryDelay)
+		}
+	}
+	d := retryBaseDelay
+	for i := 1; i < attempt && d < maxRetryDelay; i++ {
+		d *= 2
+	}
+	return min(d, maxRetryDelay)
+}
+
 func truncateDebug(data []byte) string {
 	const limit = 4000
 	if len(data) <= limit {
@@ -127,6 +298,30 @@ func unquoteIdentifier(value string) string {
 	return trimmed
 }
 
+// ResolveIdentifier returns the name Snowflake stores for an identifier
+// written as value. Quoted identifiers, and the non-simple ones that
+// identifierSegment quotes, keep their case; simple unquoted identifiers are
+// folded to upper case.
+func ResolveIdentifier(value string) string {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) >= 2 && strings.HasPrefix(trimmed, `"`) && strings.HasSuffix(trimmed, `"`) {
+		return trimmed[1 : len(trimmed)-1]
+	}
+	if isSimpleIdentifier(trimmed) {
+		return strings.ToUpper(trimmed)
+	}
+	return trimmed
+}
+
+// IdentifierSQL returns how a stored name must be written in SQL: bare when
+// an unquoted identifier resolves to it, double-quoted otherwise.
+func IdentifierSQL(stored string) string {
+	if isSimpleIdentifier(stored) && strings.ToUpper(stored) == stored {
+		return stored
+	}
+	return `"` + strings.ReplaceAll(stored, `"`, `""`) + `"`
+}
+
 func isSimpleIdentifier(value string) bool {
 	if value == "" {
 		return false
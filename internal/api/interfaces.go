@@ -11,6 +11,8 @@ type AgentService interface {
 	CreateAgent(ctx context.Context, db, schema string, spec agent.AgentSpec) error
 	UpdateAgent(ctx context.Context, db, schema, name string, payload any) error
 	DeleteAgent(ctx context.Context, db, schema, name string) error
+	DeleteAgentIfExists(ctx context.Context, db, schema, name string) error
+	RenameAgent(ctx context.Context, db, schema, oldName, newName string) error
 	GetAgent(ctx context.Context, db, schema, name string) (agent.AgentSpec, bool, error)
 	DescribeAgent(ctx context.Context, db, schema, name string) (DescribeResult, error)
 	ListAgents(ctx context.Context, db, schema string) ([]AgentListItem, error)
@@ -19,11 +21,13 @@ type AgentService interface {
 // RunService defines the contract for agent execution.
 type RunService interface {
 	RunAgent(ctx context.Context, db, schema, name string, req RunAgentRequest, opts RunAgentOptions) (*ResponseEvent, error)
+	TestTool(ctx context.Context, db, schema, agentName, toolName, input string) (*ToolTestResult, error)
 }
 
 // ThreadService defines the contract for thread management.
 type ThreadService interface {
 	CreateThread(ctx context.Context) (string, error)
+	CreateNamedThread(ctx context.Context, name string) (string, error)
 	ListThreads(ctx context.Context) ([]Thread, error)
 	GetThread(ctx context.Context, threadID string) (*Thread, error)
 	DeleteThread(ctx context.Context, threadID string) error
@@ -34,6 +38,8 @@ type GrantService interface {
 	ShowGrants(ctx context.Context, db, schema, agentName string) ([]ShowGrantsRow, error)
 	ExecuteGrant(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error
 	ExecuteRevoke(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error
+	ExecuteGrantWithGrantOption(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error
+	RevokeGrantOption(ctx context.Context, db, schema, agentName, roleType, roleName, privilege string) error
 }
 
 // QueryService defines the contract for SQL-based query operations.
@@ -41,6 +47,10 @@ type QueryService interface {
 	GetFeedback(ctx context.Context, db, schema, agentName string, opts FeedbackQueryOptions) ([]FeedbackRecord, error)
 	CortexComplete(ctx context.Context, sqlStmt string) (string, error)
 	FeedbackInferenceColumnsExist(ctx context.Context, db, schema, table string) (bool, error)
+	SubmitSQL(ctx context.Context, db, schema, stmt string) (string, error)
+	FetchResult(ctx context.Context, handle string) (*SQLResult, error)
+	ListModels(ctx context.Context) ([]string, error)
+	Ping(ctx context.Context) (Session, error)
 }
 
 // Compile-time assertions: *Client must implement all service interfaces.
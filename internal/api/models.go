@@ -0,0 +1,28 @@
+package api
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// ListModels returns the names of the Cortex models exposed to the current
+// role as objects in SNOWFLAKE.MODELS, lowercased and sorted. The schema
+// covers every model the account can call, not only those that support
+// agent orchestration.
+func (c *Client) ListModels(ctx context.Context) ([]string, error) {
+	out := []string{}
+	err := c.iterateShow(ctx, "SHOW MODELS IN SCHEMA SNOWFLAKE.MODELS", func(row map[string]any) error {
+		name, _ := row["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return nil
+		}
+		out = append(out, strings.ToLower(name))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(out)
+	return out, nil
+}
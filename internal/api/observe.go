@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestRecord describes one HTTP exchange made by the client. Retried
+// attempts are recorded separately.
+type RequestRecord struct {
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	Status          int               `json:"status,omitempty"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	StartedAt       time.Time         `json:"started_at"`
+	DurationMs      int64             `json:"duration_ms"`
+	Error           string            `json:"error,omitempty"`
+}
+
+// ResponseObserver is called after every HTTP request the client sends,
+// whether it succeeded or failed. For streaming requests the duration covers
+// the time until the response headers arrive.
+type ResponseObserver func(RequestRecord)
+
+// SetResponseObserver registers fn to be called for each HTTP request. A nil
+// fn disables observation.
+func (c *Client) SetResponseObserver(fn ResponseObserver) {
+	c.observer = fn
+}
+
+// redactedHeaders lists headers whose values are never recorded.
+var redactedHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// do sends req with hc, updates the usage counters and reports the exchange
+// to the observer.
+func (c *Client) do(hc *http.Client, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	c.stats.requests.Add(1)
+	if req.ContentLength > 0 {
+		c.stats.bytesSent.Add(req.ContentLength)
+	}
+	resp, err := hc.Do(req)
+	if err == nil {
+		resp.Body = countingBody{ReadCloser: resp.Body, n: &c.stats.bytesReceived}
+	}
+	if c.observer != nil {
+		rec := RequestRecord{
+			Method:         req.Method,
+			URL:            req.URL.String(),
+			RequestHeaders: redactHeaders(req.Header),
+			StartedAt:      start,
+			DurationMs:     time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			rec.Error = err.Error()
+		} else {
+			rec.Status = resp.StatusCode
+			rec.ResponseHeaders = redactHeaders(resp.Header)
+		}
+		c.observer(rec)
+	}
+	return resp, err
+}
+
+// redactHeaders flattens h into a map, replacing sensitive values.
+func redactHeaders(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = "REDACTED"
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
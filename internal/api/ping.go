@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+// pingStatement reads the session context with Snowflake's context
+// functions; it touches no objects, so any role can run it.
+const pingStatement = "SELECT CURRENT_ACCOUNT() AS ACCOUNT, CURRENT_USER() AS USER_NAME, CURRENT_ROLE() AS ROLE, CURRENT_WAREHOUSE() AS WAREHOUSE"
+
+// Session is the identity a statement ran as, as reported by Snowflake.
+// Warehouse is empty when no warehouse is in use.
+type Session struct {
+	Account   string
+	User      string
+	Role      string
+	Warehouse string
+}
+
+// Ping runs a trivial SELECT through the SQL API and returns the resolved
+// session. A nil error means the credentials were accepted and the
+// configured role (and warehouse, if any) could be used.
+func (c *Client) Ping(ctx context.Context) (Session, error) {
+	var session Session
+	rows := 0
+	err := c.iterateShow(ctx, pingStatement, func(row map[string]any) error {
+		rows++
+		session.Account, _ = sqlCellString(row["account"])
+		session.User, _ = sqlCellString(row["user_name"])
+		session.Role, _ = sqlCellString(row["role"])
+		session.Warehouse, _ = sqlCellString(row["warehouse"])
+		return nil
+	})
+	if err != nil {
+		return Session{}, fmt.Errorf("ping: %w", err)
+	}
+	if rows == 0 {
+		return Session{}, fmt.Errorf("ping: statement returned no rows")
+	}
+	return session, nil
+}
@@ -28,14 +28,23 @@ type sqlRowType struct {
 }
 
 type sqlStatementResponse struct {
-	Data               [][]any `json:"data"`
-	Code               string  `json:"code"`
-	Message            string  `json:"message"`
-	StatementHandle    string  `json:"statementHandle"`
-	StatementStatusURL string  `json:"statementStatusUrl"`
-	ResultSetMetaData  struct {
-		RowType []sqlRowType `json:"rowType"`
-	} `json:"resultSetMetaData"`
+	Data               [][]any              `json:"data"`
+	Code               string               `json:"code"`
+	Message            string               `json:"message"`
+	StatementHandle    string               `json:"statementHandle"`
+	StatementStatusURL string               `json:"statementStatusUrl"`
+	ResultSetMetaData  sqlResultSetMetaData `json:"resultSetMetaData"`
+}
+
+type sqlResultSetMetaData struct {
+	RowType []sqlRowType `json:"rowType"`
+	// PartitionInfo has one entry per result partition. Data holds only the
+	// first; the rest are fetched by statement handle.
+	PartitionInfo []sqlPartitionInfo `json:"partitionInfo"`
+}
+
+type sqlPartitionInfo struct {
+	RowCount int `json:"rowCount"`
 }
 
 func (c *Client) sqlURL() string {
@@ -129,6 +138,32 @@ type FeedbackQueryOptions struct {
 	RequestSince  string
 	InferNegative bool
 	JudgeModel    string
+	// MaxRows caps the rows fetched by each observability query (newest
+	// first); 0 uses DefaultFeedbackMaxRows.
+	MaxRows int
+	// OnSQLProgress, when set, is called with a short status message before
+	// each long-running SQL statement (e.g. "Querying observability events...").
+	OnSQLProgress func(message string)
+}
+
+// DefaultFeedbackMaxRows is the default FeedbackQueryOptions.MaxRows. It
+// keeps a busy agent's observability history from producing a response
+// larger than Client.MaxResponseBytes.
+const DefaultFeedbackMaxRows = 10000
+
+// maxRows returns MaxRows or DefaultFeedbackMaxRows when it is not set.
+func (o FeedbackQueryOptions) maxRows() int {
+	if o.MaxRows > 0 {
+		return o.MaxRows
+	}
+	return DefaultFeedbackMaxRows
+}
+
+// sqlProgress reports msg to OnSQLProgress when it is set.
+func (o FeedbackQueryOptions) sqlProgress(msg string) {
+	if o.OnSQLProgress != nil {
+		o.OnSQLProgress(msg)
+	}
 }
 
 type negativeInferenceResult struct {
@@ -154,7 +189,8 @@ type ToolUseInfo struct {
 // CORTEX_AGENT_FEEDBACK events and optionally infers negative sentiment for
 // request-only interactions when opts.InferNegative is enabled.
 func (c *Client) GetFeedback(ctx context.Context, db, schema, agentName string, opts FeedbackQueryOptions) ([]FeedbackRecord, error) {
-	explicit, err := c.getExplicitFeedback(ctx, db, schema, agentName, opts.ExplicitSince)
+	opts.sqlProgress("Querying observability feedback events...")
+	explicit, err := c.getExplicitFeedback(ctx, db, schema, agentName, opts.ExplicitSince, opts.maxRows())
 	if err != nil {
 		return nil, err
 	}
@@ -166,13 +202,14 @@ func (c *Client) GetFeedback(ctx context.Context, db, schema, agentName string,
 	if requestSince == "" {
 		requestSince = opts.Since
 	}
-	candidates, err := c.getRequestOnlyFeedbackCandidates(ctx, db, schema, agentName, requestSince)
+	opts.sqlProgress("Querying observability request events...")
+	candidates, err := c.getRequestOnlyFeedbackCandidates(ctx, db, schema, agentName, requestSince, opts.maxRows())
 	if err != nil {
 		return nil, err
 	}
 
 	var inferred []FeedbackRecord
-	for _, candidate := range candidates {
+	for i, candidate := range candidates {
 		if strings.TrimSpace(candidate.Question) == "" {
 			continue
 		}
@@ -187,6 +224,7 @@ func (c *Client) GetFeedback(ctx context.Context, db, schema, agentName string,
 			inferred = append(inferred, candidate)
 			continue
 		}
+		opts.sqlProgress(fmt.Sprintf("Inferring sentiment (%d/%d)...", i+1, len(candidates)))
 		result, err := c.inferNegativeFeedback(ctx, opts.JudgeModel, candidate)
 		if err != nil {
 			return nil, err
@@ -204,7 +242,7 @@ func (c *Client) GetFeedback(ctx context.Context, db, schema, agentName string,
 	return mergeFeedbackRecords(explicit, inferred, true), nil
 }
 
-func (c *Client) getExplicitFeedback(ctx context.Context, db, schema, agentName, since string) ([]FeedbackRecord, error) {
+func (c *Client) getExplicitFeedback(ctx context.Context, db, schema, agentName, since string, limit int) ([]FeedbackRecord, error) {
 	dbEsc := escapeSQLString(unquoteIdentifier(db))
 	schemaEsc := escapeSQLString(unquoteIdentifier(schema))
 	agentEsc := escapeSQLString(agentName)
@@ -230,10 +268,11 @@ func (c *Client) getExplicitFeedback(ctx context.Context, db, schema, agentName,
 			"   AND r.RECORD:name = 'CORTEX_AGENT_REQUEST'"+
 			" WHERE f.RECORD:name = 'CORTEX_AGENT_FEEDBACK'"+
 			"%s"+
-			" ORDER BY f.TIMESTAMP DESC",
+			" ORDER BY f.TIMESTAMP DESC"+
+			" LIMIT %d",
 		dbEsc, schemaEsc, agentEsc,
 		dbEsc, schemaEsc, agentEsc,
-		whereExtra,
+		whereExtra, limit,
 	)
 
 	payload := sqlStatementRequest{
@@ -350,7 +389,7 @@ func (c *Client) getExplicitFeedback(ctx context.Context, db, schema, agentName,
 	return records, nil
 }
 
-func (c *Client) getRequestOnlyFeedbackCandidates(ctx context.Context, db, schema, agentName, since string) ([]FeedbackRecord, error) {
+func (c *Client) getRequestOnlyFeedbackCandidates(ctx context.Context, db, schema, agentName, since string, limit int) ([]FeedbackRecord, error) {
 	dbEsc := escapeSQLString(unquoteIdentifier(db))
 	schemaEsc := escapeSQLString(unquoteIdentifier(schema))
 	agentEsc := escapeSQLString(agentName)
@@ -375,10 +414,11 @@ func (c *Client) getRequestOnlyFeedbackCandidates(ctx context.Context, db, schem
 			" WHERE r.RECORD:name = 'CORTEX_AGENT_REQUEST'"+
 			"   AND f.RECORD:name IS NULL"+
 			"%s"+
-			" ORDER BY r.TIMESTAMP DESC",
+			" ORDER BY r.TIMESTAMP DESC"+
+			" LIMIT %d",
 		dbEsc, schemaEsc, agentEsc,
 		dbEsc, schemaEsc, agentEsc,
-		whereExtra,
+		whereExtra, limit,
 	)
 
 	payload := sqlStatementRequest{
@@ -942,29 +982,19 @@ func (c *Client) feedbackTableColumnSet(ctx context.Context, db, schema, table s
 	fq := fmt.Sprintf("%s.%s.%s",
 		identifierSegment(db), identifierSegment(schema), identifierSegment(table))
 	stmt := fmt.Sprintf("SHOW COLUMNS IN TABLE %s", fq)
-	resp, err := c.executeStatement(ctx, db, schema, stmt)
-	if err != nil {
-		return nil, err
-	}
-	colIndex := make(map[string]int)
-	for i, col := range resp.ResultSetMetaData.RowType {
-		colIndex[strings.ToLower(col.Name)] = i
-	}
-	nameIdx, ok := colIndex["column_name"]
-	if !ok {
-		nameIdx, ok = colIndex["name"]
-	}
-	if !ok {
-		return map[string]struct{}{}, nil
-	}
-	colSet := make(map[string]struct{}, len(resp.Data))
-	for _, row := range resp.Data {
-		if nameIdx >= len(row) {
-			continue
+	colSet := make(map[string]struct{})
+	err := c.iterateShow(ctx, stmt, func(row map[string]any) error {
+		nameVal, ok := row["column_name"]
+		if !ok {
+			nameVal = row["name"]
 		}
-		if name, ok := row[nameIdx].(string); ok {
+		if name, ok := nameVal.(string); ok {
 			colSet[strings.ToLower(strings.Trim(name, `"`))] = struct{}{}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return colSet, nil
 }
@@ -975,6 +1005,9 @@ func (c *Client) feedbackTableColumnSet(ctx context.Context, db, schema, table s
 // response, response_time_ms, tool_uses, request_value, checked, checked_at,
 // created_at, updated_at.
 func (c *Client) CreateFeedbackTable(ctx context.Context, db, schema, table string) error {
+	if err := c.checkWritable("create feedback table %s", table); err != nil {
+		return err
+	}
 	fq := fmt.Sprintf("%s.%s.%s",
 		identifierSegment(db), identifierSegment(schema), identifierSegment(table))
 	stmt := fmt.Sprintf(`CREATE OR REPLACE TABLE %s (
@@ -1013,6 +1046,9 @@ func (c *Client) CreateFeedbackTable(ctx context.Context, db, schema, table stri
 
 // RenameFeedbackTable renames an existing feedback table within the same schema.
 func (c *Client) RenameFeedbackTable(ctx context.Context, db, schema, fromTable, toTable string) error {
+	if err := c.checkWritable("rename feedback table %s to %s", fromTable, toTable); err != nil {
+		return err
+	}
 	fq := fmt.Sprintf("%s.%s.%s",
 		identifierSegment(db), identifierSegment(schema), identifierSegment(fromTable))
 	stmt := fmt.Sprintf("ALTER TABLE %s RENAME TO %s", fq, identifierSegment(toTable))
@@ -1040,8 +1076,9 @@ func (c *Client) FeedbackInferenceColumnsExist(ctx context.Context, db, schema,
 	return hasSource && hasReason, nil
 }
 
-// executeStatement runs a single statement in the given database and schema.
-func (c *Client) executeStatement(ctx context.Context, db, schema, stmt string) (*sqlStatementResponse, error) {
+// statementRequest builds a SQL API request for stmt using the client's
+// warehouse and role.
+func (c *Client) statementRequest(db, schema, stmt string) sqlStatementRequest {
 	payload := sqlStatementRequest{
 		Statement: stmt,
 		Database:  unquoteIdentifier(db),
@@ -1053,21 +1090,41 @@ func (c *Client) executeStatement(ctx context.Context, db, schema, stmt string)
 	if strings.TrimSpace(c.role) != "" {
 		payload.Role = c.role
 	}
+	return payload
+}
+
+// statementURL returns the URL of GET /api/v2/statements/{handle}, with the
+// handle escaped as a single path segment.
+func (c *Client) statementURL(handle string) *url.URL {
+	u := *c.baseURL
+	u.Path = path.Join(u.Path, "api/v2/statements")
+	u.RawPath = ""
+	u.RawPath = u.EscapedPath() + "/" + url.PathEscape(handle)
+	u.Path += "/" + handle
+	return &u
+}
+
+// statementInProgress reports whether Snowflake is still executing the
+// statement described by r.
+func statementInProgress(r sqlStatementResponse) bool {
+	switch r.Code {
+	case "333333", "333334":
+		return true
+	default:
+		return false
+	}
+}
+
+// executeStatement runs a single statement in the given database and schema.
+func (c *Client) executeStatement(ctx context.Context, db, schema, stmt string) (*sqlStatementResponse, error) {
+	payload := c.statementRequest(db, schema, stmt)
 	var resp sqlStatementResponse
 	if err := c.doJSON(ctx, http.MethodPost, c.sqlURL(), payload, &resp); err != nil {
 		return nil, err
 	}
 	// Long-running SQL statements can return 202 with statementStatusUrl.
 	// Poll only while Snowflake reports the statement is still in progress.
-	isInProgress := func(r sqlStatementResponse) bool {
-		switch r.Code {
-		case "333333", "333334":
-			return true
-		default:
-			return false
-		}
-	}
-	for resp.StatementStatusURL != "" && isInProgress(resp) {
+	for resp.StatementStatusURL != "" && statementInProgress(resp) {
 		select {
 		case <-ctx.Done():
 			return nil, fmt.Errorf("wait statement completion: %w", ctx.Err())
@@ -1086,9 +1143,63 @@ func (c *Client) executeStatement(ctx context.Context, db, schema, stmt string)
 			return nil, err
 		}
 	}
+	if err := c.fetchPartitions(ctx, &resp); err != nil {
+		return nil, err
+	}
 	return &resp, nil
 }
 
+// fetchPartitions appends the rows of every result partition after the first
+// to resp.Data. Snowflake splits large results (for example SHOW AGENTS in a
+// schema with many agents) into partitions that are requested with
+// GET /api/v2/statements/{handle}?partition=N.
+func (c *Client) fetchPartitions(ctx context.Context, resp *sqlStatementResponse) error {
+	partitions := len(resp.ResultSetMetaData.PartitionInfo)
+	if partitions <= 1 {
+		return nil
+	}
+	if resp.StatementHandle == "" {
+		return fmt.Errorf("result has %d partitions but no statement handle", partitions)
+	}
+	u := c.statementURL(resp.StatementHandle)
+	for i := 1; i < partitions; i++ {
+		u.RawQuery = url.Values{"partition": {strconv.Itoa(i)}}.Encode()
+		var page sqlStatementResponse
+		if err := c.doJSON(ctx, http.MethodGet, u.String(), nil, &page); err != nil {
+			return fmt.Errorf("fetch result partition %d: %w", i, err)
+		}
+		resp.Data = append(resp.Data, page.Data...)
+	}
+	return nil
+}
+
+// iterateShow runs a SHOW (or other row-returning) statement and calls fn
+// for each result row, keyed by lowercased column name. Iteration stops at
+// the first error returned by fn. The statement must be fully qualified, as
+// no database or schema context is sent.
+func (c *Client) iterateShow(ctx context.Context, stmt string, fn func(row map[string]any) error) error {
+	resp, err := c.executeStatement(ctx, "", "", stmt)
+	if err != nil {
+		return err
+	}
+	columns := make([]string, len(resp.ResultSetMetaData.RowType))
+	for i, col := range resp.ResultSetMetaData.RowType {
+		columns[i] = strings.ToLower(col.Name)
+	}
+	for _, data := range resp.Data {
+		row := make(map[string]any, len(columns))
+		for i, col := range columns {
+			if i < len(data) {
+				row[col] = data[i]
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // UpsertFeedbackRecords inserts or updates feedback records in the remote table.
 // Existing rows keep their checked state while mutable feedback fields are refreshed.
 // It stages rows into a transient table and executes a single MERGE for better throughput.
@@ -1096,6 +1207,9 @@ func (c *Client) UpsertFeedbackRecords(ctx context.Context, db, schema, table st
 	if len(records) == 0 {
 		return nil
 	}
+	if err := c.checkWritable("upsert feedback records into %s", table); err != nil {
+		return err
+	}
 
 	fq := fmt.Sprintf("%s.%s.%s",
 		identifierSegment(db), identifierSegment(schema), identifierSegment(table))
@@ -1628,6 +1742,9 @@ VALUES (s.record_id, s.event_ts, s.agent_name, s.user_name, s.sentiment, s.senti
 // default. When opts.InferNegative is enabled, it falls back to Go-side
 // materialization so request-only interactions can be classified and upserted.
 func (c *Client) SyncFeedbackFromEventsToTable(ctx context.Context, srcDB, srcSchema, agentName, dstDB, dstSchema, dstTable string, opts FeedbackQueryOptions) error {
+	if err := c.checkWritable("sync feedback into %s", dstTable); err != nil {
+		return err
+	}
 	if opts.InferNegative {
 		ok, err := c.FeedbackInferenceColumnsExist(ctx, dstDB, dstSchema, dstTable)
 		if err != nil {
@@ -1642,10 +1759,12 @@ func (c *Client) SyncFeedbackFromEventsToTable(ctx context.Context, srcDB, srcSc
 			RequestSince:  opts.RequestSince,
 			InferNegative: true,
 			JudgeModel:    opts.JudgeModel,
+			OnSQLProgress: opts.OnSQLProgress,
 		})
 		if err != nil {
 			return err
 		}
+		opts.sqlProgress("Writing feedback records to " + dstDB + "." + dstSchema + "." + dstTable + "...")
 		return c.syncInferNegativeFeedbackFromSource(ctx, srcDB, srcSchema, agentName, dstDB, dstSchema, dstTable, records)
 	}
 
@@ -1831,6 +1950,7 @@ VALUES (
 		srcDBEsc, srcSchemaEsc, agentEsc,
 		srcWhereExtra,
 	)
+	opts.sqlProgress("Merging observability events into " + dstFQ + "...")
 	_, err := c.executeStatement(ctx, dstDB, dstSchema, stmt)
 	return err
 }
@@ -2019,6 +2139,9 @@ FROM %s WHERE agent_name = '%s' ORDER BY event_ts DESC NULLS LAST`,
 
 // UpdateFeedbackChecked sets the checked flag and checked_at for a record in the remote table.
 func (c *Client) UpdateFeedbackChecked(ctx context.Context, db, schema, table, recordID string, checked bool) error {
+	if err := c.checkWritable("update checked state of feedback %s", recordID); err != nil {
+		return err
+	}
 	fq := fmt.Sprintf("%s.%s.%s",
 		identifierSegment(db), identifierSegment(schema), identifierSegment(table))
 	ridEsc := escapeSQLString(recordID)
@@ -2035,6 +2158,9 @@ func (c *Client) UpdateFeedbackChecked(ctx context.Context, db, schema, table, r
 
 // ClearFeedbackForAgent deletes all feedback rows for the given agent from the remote table.
 func (c *Client) ClearFeedbackForAgent(ctx context.Context, db, schema, table, agentName string) error {
+	if err := c.checkWritable("clear feedback for agent %s", agentName); err != nil {
+		return err
+	}
 	fq := fmt.Sprintf("%s.%s.%s",
 		identifierSegment(db), identifierSegment(schema), identifierSegment(table))
 	agentEsc := escapeSQLString(agentName)
@@ -3,8 +3,11 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -548,18 +551,14 @@ func TestGetFeedbackInferNegativePreservesExplicitSince(t *testing.T) {
 		w.Header().Set("Content-Type", "application/json")
 		if len(statements) == 1 {
 			_ = json.NewEncoder(w).Encode(sqlStatementResponse{
-				ResultSetMetaData: struct {
-					RowType []sqlRowType `json:"rowType"`
-				}{RowType: []sqlRowType{{Name: "timestamp"}, {Name: "record_id"}}},
-				Data: [][]any{},
+				ResultSetMetaData: sqlResultSetMetaData{RowType: []sqlRowType{{Name: "timestamp"}, {Name: "record_id"}}},
+				Data:              [][]any{},
 			})
 			return
 		}
 		_ = json.NewEncoder(w).Encode(sqlStatementResponse{
-			ResultSetMetaData: struct {
-				RowType []sqlRowType `json:"rowType"`
-			}{RowType: []sqlRowType{{Name: "timestamp"}, {Name: "record_id"}}},
-			Data: [][]any{},
+			ResultSetMetaData: sqlResultSetMetaData{RowType: []sqlRowType{{Name: "timestamp"}, {Name: "record_id"}}},
+			Data:              [][]any{},
 		})
 	}))
 	defer srv.Close()
@@ -584,6 +583,45 @@ func TestGetFeedbackInferNegativePreservesExplicitSince(t *testing.T) {
 	if !strings.Contains(statements[1], "r.TIMESTAMP >= TO_TIMESTAMP_TZ('2026-03-08 12:34:56.000 +0000'") {
 		t.Fatalf("request-only query missing request cursor:\n%s", statements[1])
 	}
+	for i, stmt := range statements {
+		if !strings.HasSuffix(stmt, fmt.Sprintf("DESC LIMIT %d", DefaultFeedbackMaxRows)) {
+			t.Errorf("statement %d is not limited to the newest rows:\n%s", i, stmt)
+		}
+	}
+}
+
+func TestGetFeedbackReportsSQLProgress(t *testing.T) {
+	t.Parallel()
+
+	var messages []string
+	var seenAtStatement []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenAtStatement = append(seenAtStatement, len(messages))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(sqlStatementResponse{
+			ResultSetMetaData: sqlResultSetMetaData{RowType: []sqlRowType{{Name: "timestamp"}, {Name: "record_id"}}},
+			Data:              [][]any{},
+		})
+	}))
+	defer srv.Close()
+
+	client := newDescribeTestClient(t, srv)
+
+	_, err := client.GetFeedback(context.Background(), "DB", "SC", "agent", FeedbackQueryOptions{
+		InferNegative: true,
+		OnSQLProgress: func(msg string) { messages = append(messages, msg) },
+	})
+	if err != nil {
+		t.Fatalf("GetFeedback() error = %v", err)
+	}
+	want := []string{"Querying observability feedback events...", "Querying observability request events..."}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("messages = %q, want %q", messages, want)
+	}
+	// Each message must arrive before its statement is sent.
+	if !reflect.DeepEqual(seenAtStatement, []int{1, 2}) {
+		t.Fatalf("messages seen at each statement = %v, want [1 2]", seenAtStatement)
+	}
 }
 
 func TestSyncFeedbackFromEventsToTableInferNegativePreservesExplicitSince(t *testing.T) {
@@ -604,9 +642,7 @@ func TestSyncFeedbackFromEventsToTableInferNegativePreservesExplicitSince(t *tes
 		switch len(statements) {
 		case 1:
 			_ = json.NewEncoder(w).Encode(sqlStatementResponse{
-				ResultSetMetaData: struct {
-					RowType []sqlRowType `json:"rowType"`
-				}{RowType: []sqlRowType{{Name: "column_name"}}},
+				ResultSetMetaData: sqlResultSetMetaData{RowType: []sqlRowType{{Name: "column_name"}}},
 				Data: [][]any{
 					{"record_id"},
 					{"sentiment_source"},
@@ -615,9 +651,7 @@ func TestSyncFeedbackFromEventsToTableInferNegativePreservesExplicitSince(t *tes
 			})
 		case 2:
 			_ = json.NewEncoder(w).Encode(sqlStatementResponse{
-				ResultSetMetaData: struct {
-					RowType []sqlRowType `json:"rowType"`
-				}{RowType: []sqlRowType{
+				ResultSetMetaData: sqlResultSetMetaData{RowType: []sqlRowType{
 					{Name: "timestamp"},
 					{Name: "resource_attributes"},
 					{Name: "feedback_attrs"},
@@ -636,10 +670,8 @@ func TestSyncFeedbackFromEventsToTableInferNegativePreservesExplicitSince(t *tes
 			})
 		default:
 			_ = json.NewEncoder(w).Encode(sqlStatementResponse{
-				ResultSetMetaData: struct {
-					RowType []sqlRowType `json:"rowType"`
-				}{RowType: []sqlRowType{{Name: "timestamp"}, {Name: "record_id"}}},
-				Data: [][]any{},
+				ResultSetMetaData: sqlResultSetMetaData{RowType: []sqlRowType{{Name: "timestamp"}, {Name: "record_id"}}},
+				Data:              [][]any{},
 			})
 		}
 	}))
@@ -711,3 +743,91 @@ func TestMergeFeedbackRecords(t *testing.T) {
 		}
 	})
 }
+
+func TestIterateShow_MultiRow(t *testing.T) {
+	var gotStmt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req sqlStatementRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request body: %v", err)
+		}
+		gotStmt = req.Statement
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"resultSetMetaData": {"rowType": [{"name": "NAME"}, {"name": "Comment"}]},
+			"data": [["AGENT_A", "first"], ["AGENT_B", null], ["AGENT_C"]]
+		}`))
+	}))
+	defer srv.Close()
+
+	client := newDescribeTestClient(t, srv)
+	var rows []map[string]any
+	err := client.iterateShow(context.Background(), "SHOW AGENTS IN SCHEMA DB.SCH", func(row map[string]any) error {
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("iterateShow() error = %v", err)
+	}
+	if gotStmt != "SHOW AGENTS IN SCHEMA DB.SCH" {
+		t.Errorf("statement = %q", gotStmt)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+	if rows[0]["name"] != "AGENT_A" || rows[0]["comment"] != "first" {
+		t.Errorf("row 0 = %v", rows[0])
+	}
+	if v, ok := rows[1]["comment"]; !ok || v != nil {
+		t.Errorf("row 1 comment = %v (present %v), want nil", v, ok)
+	}
+	if _, ok := rows[2]["comment"]; ok {
+		t.Errorf("row 2 should not have a comment column: %v", rows[2])
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = client.iterateShow(context.Background(), "SHOW AGENTS IN SCHEMA DB.SCH", func(row map[string]any) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("callback error: err = %v, calls = %d; want stop after 1 call", err, calls)
+	}
+}
+
+func TestPing(t *testing.T) {
+	var gotStatement string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req sqlStatementRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gotStatement = req.Statement
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(buildSQLResponse(t, []string{"ACCOUNT", "USER_NAME", "ROLE", "WAREHOUSE"}, []any{"MYACCT", "ALICE", "ANALYST", nil}))
+	}))
+	defer srv.Close()
+
+	session, err := newDescribeTestClient(t, srv).Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	want := Session{Account: "MYACCT", User: "ALICE", Role: "ANALYST"}
+	if session != want {
+		t.Errorf("Ping() = %+v, want %+v", session, want)
+	}
+	if !strings.HasPrefix(gotStatement, "SELECT CURRENT_ACCOUNT()") {
+		t.Errorf("statement = %q", gotStatement)
+	}
+}
+
+func TestPing_NoRows(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	if _, err := newDescribeTestClient(t, srv).Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "no rows") {
+		t.Fatalf("expected no-rows error, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ReadOnlyError is returned by the mutating Client methods (agent create,
+// update, delete and rename, GRANT and REVOKE, feedback table writes and
+// thread deletion) when Client.ReadOnly is set. The request is not sent.
+type ReadOnlyError struct {
+	// Operation describes the blocked call, e.g. "create agent MY_AGENT".
+	Operation string
+}
+
+func (e ReadOnlyError) Error() string {
+	return fmt.Sprintf("read-only mode: %s was blocked (drop --read-only to allow changes)", e.Operation)
+}
+
+// IsReadOnlyError reports whether err is, or wraps, a ReadOnlyError.
+func IsReadOnlyError(err error) bool {
+	var readOnly ReadOnlyError
+	return errors.As(err, &readOnly)
+}
+
+// checkWritable returns a ReadOnlyError for operation when the client is in
+// read-only mode.
+func (c *Client) checkWritable(format string, args ...any) error {
+	if !c.ReadOnly {
+		return nil
+	}
+	return ReadOnlyError{Operation: fmt.Sprintf(format, args...)}
+}
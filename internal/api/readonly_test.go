@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"coragent/internal/agent"
+)
+
+func TestReadOnly_BlocksMutations(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := newDescribeTestClient(t, srv)
+	client.ReadOnly = true
+	ctx := context.Background()
+
+	mutations := map[string]func() error{
+		"CreateAgent": func() error { return client.CreateAgent(ctx, "DB", "SCH", agent.AgentSpec{Name: "A"}) },
+		"UpdateAgent": func() error { return client.UpdateAgent(ctx, "DB", "SCH", "A", map[string]any{"comment": "x"}) },
+		"DeleteAgent": func() error { return client.DeleteAgent(ctx, "DB", "SCH", "A") },
+		"DeleteAgentIfExists": func() error {
+			return client.DeleteAgentIfExists(ctx, "DB", "SCH", "A")
+		},
+		"RenameAgent":  func() error { return client.RenameAgent(ctx, "DB", "SCH", "A", "B") },
+		"ExecuteGrant": func() error { return client.ExecuteGrant(ctx, "DB", "SCH", "A", "ROLE", "ANALYST", "USAGE") },
+		"ExecuteGrantWithGrantOption": func() error {
+			return client.ExecuteGrantWithGrantOption(ctx, "DB", "SCH", "A", "ROLE", "ANALYST", "USAGE")
+		},
+		"ExecuteRevoke": func() error { return client.ExecuteRevoke(ctx, "DB", "SCH", "A", "ROLE", "ANALYST", "USAGE") },
+		"RevokeGrantOption": func() error {
+			return client.RevokeGrantOption(ctx, "DB", "SCH", "A", "ROLE", "ANALYST", "USAGE")
+		},
+		"CreateFeedbackTable": func() error { return client.CreateFeedbackTable(ctx, "DB", "SCH", "FEEDBACK") },
+		"RenameFeedbackTable": func() error {
+			return client.RenameFeedbackTable(ctx, "DB", "SCH", "FEEDBACK", "FEEDBACK_OLD")
+		},
+		"UpsertFeedbackRecords": func() error {
+			return client.UpsertFeedbackRecords(ctx, "DB", "SCH", "FEEDBACK", []FeedbackRecord{{RecordID: "r1", AgentName: "A"}})
+		},
+		"SyncFeedbackFromEventsToTable": func() error {
+			return client.SyncFeedbackFromEventsToTable(ctx, "DB", "SCH", "A", "DB", "SCH", "FEEDBACK", FeedbackQueryOptions{})
+		},
+		"UpdateFeedbackChecked": func() error {
+			return client.UpdateFeedbackChecked(ctx, "DB", "SCH", "FEEDBACK", "r1", true)
+		},
+		"ClearFeedbackForAgent": func() error { return client.ClearFeedbackForAgent(ctx, "DB", "SCH", "FEEDBACK", "A") },
+		"DeleteThread":          func() error { return client.DeleteThread(ctx, "42") },
+	}
+	for name, call := range mutations {
+		t.Run(name, func(t *testing.T) {
+			err := call()
+			if !IsReadOnlyError(err) {
+				t.Fatalf("expected ReadOnlyError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "read-only mode") {
+				t.Errorf("error = %q", err)
+			}
+		})
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("blocked mutations sent %d request(s), want 0", n)
+	}
+}
+
+func TestReadOnly_AllowsReads(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	client := newDescribeTestClient(t, srv)
+	client.ReadOnly = true
+	ctx := context.Background()
+
+	if _, _, err := client.GetAgent(ctx, "DB", "SCH", "A"); IsReadOnlyError(err) {
+		t.Errorf("GetAgent blocked: %v", err)
+	}
+	if _, err := client.ListAgents(ctx, "DB", "SCH"); IsReadOnlyError(err) {
+		t.Errorf("ListAgents blocked: %v", err)
+	}
+	if _, err := client.ShowGrants(ctx, "DB", "SCH", "A"); IsReadOnlyError(err) {
+		t.Errorf("ShowGrants blocked: %v", err)
+	}
+	if _, err := client.ListThreads(ctx); IsReadOnlyError(err) {
+		t.Errorf("ListThreads blocked: %v", err)
+	}
+	if n := calls.Load(); n < 4 {
+		t.Errorf("reads sent %d request(s), want at least 4", n)
+	}
+}
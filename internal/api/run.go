@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -11,9 +12,8 @@ import (
 	"path"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
-
-	"coragent/internal/auth"
 )
 
 // RunAgentRequest represents the request payload for running an agent.
@@ -21,6 +21,19 @@ type RunAgentRequest struct {
 	Messages        []Message `json:"messages"`
 	ThreadID        string    `json:"thread_id,omitempty"`
 	ParentMessageID *int64    `json:"parent_message_id,omitempty"`
+	// ResponseFormat constrains the agent's final answer, e.g.
+	// {"type": "json", "schema": {...}}. It is omitted when nil.
+	ResponseFormat any `json:"response_format,omitempty"`
+	// ToolChoice controls which tools the orchestrator may call. It is
+	// omitted when nil, leaving the choice to the agent.
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+}
+
+// ToolChoice restricts tool selection for a run. Type is "auto", "required"
+// or "tool"; with "tool", Name lists the tools that must be used.
+type ToolChoice struct {
+	Type string   `json:"type"`
+	Name []string `json:"name,omitempty"`
 }
 
 // Message represents a chat message.
@@ -84,6 +97,18 @@ type ToolResultEvent struct {
 type ResponseEvent struct {
 	Content  []ResponseContentBlock `json:"content"`
 	Metadata *ResponseMetadata      `json:"metadata,omitempty"`
+	// StopReason tells why the agent stopped; empty for a natural completion.
+	StopReason string `json:"stop_reason,omitempty"`
+}
+
+// StopReasonBudgetExhausted is the stop reason of a response cut short
+// because the agent reached its orchestration budget (time or tokens).
+const StopReasonBudgetExhausted = "budget_exhausted"
+
+// BudgetExhausted reports whether the response was truncated by the
+// orchestration budget. It is false for a nil response.
+func (r *ResponseEvent) BudgetExhausted() bool {
+	return r != nil && r.StopReason == StopReasonBudgetExhausted
 }
 
 // ResponseMetadata contains metadata about the response including thread info.
@@ -134,10 +159,33 @@ type ResponseContentBlock struct {
 	ToolResult json.RawMessage `json:"tool_result,omitempty"`
 }
 
-// ErrorEvent represents an error from the agent.
+// ErrorEvent represents an error from the agent, delivered as an "error" or
+// "response.error" SSE event.
 type ErrorEvent struct {
-	Message string `json:"message"`
-	Code    string `json:"code"`
+	Message   string `json:"message"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// AgentRunError is returned by RunAgent when the stream reports an error
+// event. PartialText holds the text streamed before the error arrived.
+type AgentRunError struct {
+	Code        string
+	Message     string
+	RequestID   string
+	PartialText string
+}
+
+func (e *AgentRunError) Error() string {
+	msg := "agent error"
+	if e.Code != "" {
+		msg += " [" + e.Code + "]"
+	}
+	msg += ": " + e.Message
+	if e.RequestID != "" {
+		msg += " (request_id " + e.RequestID + ")"
+	}
+	return msg
 }
 
 // StatusEvent represents a status update from the agent.
@@ -194,8 +242,26 @@ func (m *MetadataEvent) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("metadata.thread_id must be string or integer")
 }
 
+// DefaultStreamIdleTimeout is the maximum time RunAgent waits between bytes on
+// the SSE stream before giving up on a stalled connection.
+const DefaultStreamIdleTimeout = 120 * time.Second
+
+// IncompleteStreamError is returned by RunAgent when the SSE stream stops
+// delivering data for longer than the idle timeout before completing.
+type IncompleteStreamError struct {
+	IdleTimeout time.Duration
+}
+
+func (e *IncompleteStreamError) Error() string {
+	return fmt.Sprintf("incomplete stream: no event received for %s", e.IdleTimeout)
+}
+
 // RunAgentOptions configures callbacks for streaming events.
 type RunAgentOptions struct {
+	// StreamIdleTimeout bounds the gap between received bytes on the stream.
+	// Zero uses DefaultStreamIdleTimeout; a negative value disables the check.
+	StreamIdleTimeout time.Duration
+
 	OnStatus        func(status, message string)
 	OnTextDelta     func(delta string)
 	OnThinkingDelta func(delta string)
@@ -203,12 +269,50 @@ type RunAgentOptions struct {
 	OnToolResult    func(name string, result json.RawMessage)
 	OnMetadata      func(threadID string, messageID int64)
 	OnProgress      func(phase string) // Called during pre-SSE phases (auth, sending, etc.)
+
+	// ConflictRetries is how many times a run rejected with a ConflictError
+	// is sent again, with exponential backoff (or the Retry-After header).
+	// Zero returns the ConflictError right away; values above
+	// MaxConflictRetries are capped.
+	ConflictRetries int
 }
 
-// RunAgent executes an agent with SSE streaming.
+// RunAgent executes an agent with SSE streaming. A run rejected because
+// another run is in progress returns a ConflictError, after retrying up to
+// opts.ConflictRetries times.
 func (c *Client) RunAgent(ctx context.Context, db, schema, name string, req RunAgentRequest, opts RunAgentOptions) (*ResponseEvent, error) {
+	retries := min(opts.ConflictRetries, MaxConflictRetries)
+	for attempt := 1; ; attempt++ {
+		resp, err := c.runAgentOnce(ctx, db, schema, name, req, opts)
+		var conflict ConflictError
+		if attempt > retries || !errors.As(err, &conflict) {
+			return resp, err
+		}
+		delay := retryDelay(conflict.RetryAfter, attempt)
+		c.stats.retries.Add(1)
+		c.log.Info("retrying run after conflict", "agent", name, "thread_id", req.ThreadID, "attempt", attempt, "delay", delay)
+		if opts.OnProgress != nil {
+			opts.OnProgress(fmt.Sprintf("Another run is in progress; retrying in %s...", delay))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (gave up waiting: %w)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// runAgentOnce sends one :run request and parses its SSE stream.
+func (c *Client) runAgentOnce(ctx context.Context, db, schema, name string, req RunAgentRequest, opts RunAgentOptions) (*ResponseEvent, error) {
 	urlStr := c.agentRunURL(db, schema, name)
 
+	idleTimeout := opts.StreamIdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = DefaultStreamIdleTimeout
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	data, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
@@ -223,12 +327,9 @@ func (c *Client) RunAgent(ctx context.Context, db, schema, name string, req RunA
 	if opts.OnProgress != nil {
 		opts.OnProgress("Authenticating...")
 	}
-	token, tokenType, err := auth.BearerToken(ctx, c.authCfg)
-	if err != nil {
+	if err := c.setAuthHeaders(ctx, httpReq); err != nil {
 		return nil, err
 	}
-	httpReq.Header.Set("Authorization", "Bearer "+token)
-	httpReq.Header.Set("X-Snowflake-Authorization-Token-Type", tokenType)
 	httpReq.Header.Set("Accept", "text/event-stream")
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("User-Agent", c.userAgent)
@@ -237,7 +338,7 @@ func (c *Client) RunAgent(ctx context.Context, db, schema, name string, req RunA
 	}
 
 	// Use a client with longer timeout for streaming
-	httpClient := &http.Client{Timeout: 15 * time.Minute}
+	httpClient := &http.Client{Timeout: 15 * time.Minute, Transport: c.transport}
 
 	c.log.Debug("http", "method", "POST", "url", urlStr)
 	c.log.Debug("request body", "body", truncateDebug(data))
@@ -245,7 +346,7 @@ func (c *Client) RunAgent(ctx context.Context, db, schema, name string, req RunA
 	if opts.OnProgress != nil {
 		opts.OnProgress("Sending request...")
 	}
-	resp, err := httpClient.Do(httpReq)
+	resp, err := c.do(httpClient, httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -255,13 +356,48 @@ func (c *Client) RunAgent(ctx context.Context, db, schema, name string, req RunA
 
 	if resp.StatusCode >= 300 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+		apiErr := newAPIError(resp, bodyBytes)
+		if isRunConflict(resp.StatusCode, apiErr.Body) {
+			return nil, ConflictError{APIError: apiErr, RetryAfter: resp.Header.Get("Retry-After")}
+		}
+		return nil, apiErr
 	}
 
 	if opts.OnProgress != nil {
 		opts.OnProgress("Waiting for response...")
 	}
-	return parseSSEStream(resp.Body, opts, c.log)
+	if idleTimeout < 0 {
+		return parseSSEStream(resp.Body, opts, c.log)
+	}
+
+	var idled atomic.Bool
+	timer := time.AfterFunc(idleTimeout, func() {
+		idled.Store(true)
+		cancel()
+	})
+	defer timer.Stop()
+
+	final, err := parseSSEStream(&idleReader{r: resp.Body, timer: timer, timeout: idleTimeout}, opts, c.log)
+	if err != nil && idled.Load() {
+		return final, &IncompleteStreamError{IdleTimeout: idleTimeout}
+	}
+	return final, err
+}
+
+// idleReader resets timer every time data arrives so that the timer only
+// fires when the underlying stream has been silent for timeout.
+type idleReader struct {
+	r       io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.timer.Reset(r.timeout)
+	}
+	return n, err
 }
 
 func (c *Client) agentRunURL(db, schema, name string) string {
@@ -279,12 +415,30 @@ func (c *Client) agentRunURL(db, schema, name string) string {
 }
 
 // parseSSEStream parses Server-Sent Events from the response body.
+// When an error event arrives, the returned *AgentRunError carries the text
+// streamed so far.
 func parseSSEStream(body io.Reader, opts RunAgentOptions, log *slog.Logger) (*ResponseEvent, error) {
 	reader := bufio.NewReader(body)
 	var currentEvent string
 	var dataBuffer strings.Builder
 	var finalResponse *ResponseEvent
 
+	var partial strings.Builder
+	onTextDelta := opts.OnTextDelta
+	opts.OnTextDelta = func(delta string) {
+		partial.WriteString(delta)
+		if onTextDelta != nil {
+			onTextDelta(delta)
+		}
+	}
+	withPartial := func(err error) error {
+		var runErr *AgentRunError
+		if errors.As(err, &runErr) {
+			runErr.PartialText = partial.String()
+		}
+		return err
+	}
+
 	for {
 		line, err := reader.ReadString('\n')
 		if err != nil {
@@ -300,7 +454,7 @@ func parseSSEStream(body io.Reader, opts RunAgentOptions, log *slog.Logger) (*Re
 		if line == "" {
 			if currentEvent != "" && dataBuffer.Len() > 0 {
 				if err := processSSEEvent(currentEvent, dataBuffer.String(), opts, &finalResponse, log); err != nil {
-					return finalResponse, err
+					return finalResponse, withPartial(err)
 				}
 			}
 			currentEvent = ""
@@ -330,7 +484,7 @@ func parseSSEStream(body io.Reader, opts RunAgentOptions, log *slog.Logger) (*Re
 	// Process any remaining buffered event
 	if currentEvent != "" && dataBuffer.Len() > 0 {
 		if err := processSSEEvent(currentEvent, dataBuffer.String(), opts, &finalResponse, log); err != nil {
-			return finalResponse, err
+			return finalResponse, withPartial(err)
 		}
 	}
 
@@ -397,12 +551,12 @@ func processSSEEvent(eventType, data string, opts RunAgentOptions, finalResponse
 			opts.OnMetadata(evt.Metadata.ThreadID, evt.Metadata.MessageID)
 		}
 
-	case "error":
+	case "error", "response.error":
 		var evt ErrorEvent
 		if err := json.Unmarshal([]byte(data), &evt); err != nil {
 			return fmt.Errorf("parse error event: %w", err)
 		}
-		return fmt.Errorf("agent error [%s]: %s", evt.Code, evt.Message)
+		return &AgentRunError{Code: evt.Code, Message: evt.Message, RequestID: evt.RequestID}
 
 	case "metadata":
 		var evt MetadataEvent
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"log/slog"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
@@ -302,3 +305,49 @@ func TestNewTextMessage_AssistantRole(t *testing.T) {
 		t.Errorf("Role = %q, want %q", msg.Role, "assistant")
 	}
 }
+
+func TestRunAgent_SendsResponseFormat(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: response.text.delta\ndata: {\"text\":\"{}\"}\n\n"))
+	}))
+	defer srv.Close()
+	client := newDescribeTestClient(t, srv)
+
+	req := RunAgentRequest{
+		Messages: []Message{NewTextMessage("user", "hi")},
+		ResponseFormat: map[string]any{
+			"type":   "json",
+			"schema": map[string]any{"type": "object"},
+		},
+	}
+	if _, err := client.RunAgent(context.Background(), "DB", "SCH", "agent", req, RunAgentOptions{}); err != nil {
+		t.Fatalf("RunAgent: %v", err)
+	}
+
+	rf, ok := got["response_format"].(map[string]any)
+	if !ok {
+		t.Fatalf("response_format missing from request: %v", got)
+	}
+	if rf["type"] != "json" {
+		t.Errorf("response_format.type = %v, want json", rf["type"])
+	}
+	schema, _ := rf["schema"].(map[string]any)
+	if schema["type"] != "object" {
+		t.Errorf("response_format.schema = %v", rf["schema"])
+	}
+}
+
+func TestRunAgentRequest_OmitsEmptyResponseFormat(t *testing.T) {
+	data, err := json.Marshal(RunAgentRequest{Messages: []Message{NewTextMessage("user", "hi")}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), "response_format") {
+		t.Errorf("expected response_format to be omitted, got %s", data)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ErrStatementInProgress is returned by FetchResult when the statement has
+// not finished executing yet. Callers can poll again later with the same handle.
+var ErrStatementInProgress = errors.New("statement still in progress")
+
+// SQLResult holds the column names and rows of a completed SQL statement.
+type SQLResult struct {
+	StatementHandle string
+	Columns         []string
+	Rows            [][]any
+}
+
+// SubmitSQL submits stmt for asynchronous execution and returns its statement
+// handle without waiting for completion. Use FetchResult to retrieve the rows.
+func (c *Client) SubmitSQL(ctx context.Context, db, schema, stmt string) (string, error) {
+	u := *c.baseURL
+	u.Path = path.Join(u.Path, "api/v2/statements")
+	u.RawQuery = "async=true"
+
+	var resp sqlStatementResponse
+	if err := c.doJSON(ctx, http.MethodPost, u.String(), c.statementRequest(db, schema, stmt), &resp); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(resp.StatementHandle) == "" {
+		return "", fmt.Errorf("submit statement: response did not include a statement handle")
+	}
+	return resp.StatementHandle, nil
+}
+
+// FetchResult retrieves the result of a statement previously submitted with
+// SubmitSQL. It returns ErrStatementInProgress while the statement is running.
+func (c *Client) FetchResult(ctx context.Context, handle string) (*SQLResult, error) {
+	handle = strings.TrimSpace(handle)
+	if handle == "" {
+		return nil, fmt.Errorf("statement handle is required")
+	}
+
+	var resp sqlStatementResponse
+	if err := c.doJSON(ctx, http.MethodGet, c.statementURL(handle).String(), nil, &resp); err != nil {
+		return nil, err
+	}
+	if statementInProgress(resp) {
+		return nil, ErrStatementInProgress
+	}
+	if resp.StatementHandle == "" {
+		resp.StatementHandle = handle
+	}
+	if err := c.fetchPartitions(ctx, &resp); err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, len(resp.ResultSetMetaData.RowType))
+	for i, col := range resp.ResultSetMetaData.RowType {
+		columns[i] = col.Name
+	}
+	return &SQLResult{
+		StatementHandle: handle,
+		Columns:         columns,
+		Rows:            resp.Data,
+	}, nil
+}
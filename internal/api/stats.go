@@ -0,0 +1,69 @@
+package api
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// Stats is a snapshot of a client's API usage counters.
+type Stats struct {
+	// Requests counts HTTP requests sent, including retried attempts and
+	// streaming runs.
+	Requests int64 `json:"requests"`
+	// Retries counts attempts repeated after a 429 or 5xx response.
+	Retries int64 `json:"retries"`
+	// BytesSent and BytesReceived count request and response body bytes.
+	BytesSent     int64 `json:"bytes_sent"`
+	BytesReceived int64 `json:"bytes_received"`
+	// AgentsCreated, AgentsUpdated and AgentsDeleted count successful agent
+	// mutations.
+	AgentsCreated int64 `json:"agents_created"`
+	AgentsUpdated int64 `json:"agents_updated"`
+	AgentsDeleted int64 `json:"agents_deleted"`
+}
+
+// clientStats holds the live counters behind Client.Stats. Updates are
+// single atomic adds, so they are safe for concurrent use.
+type clientStats struct {
+	requests      atomic.Int64
+	retries       atomic.Int64
+	bytesSent     atomic.Int64
+	bytesReceived atomic.Int64
+	agentsCreated atomic.Int64
+	agentsUpdated atomic.Int64
+	agentsDeleted atomic.Int64
+}
+
+// Stats returns the API usage counters accumulated since the client was
+// created. Embedders can poll it to monitor coragent's API traffic.
+func (c *Client) Stats() Stats {
+	return Stats{
+		Requests:      c.stats.requests.Load(),
+		Retries:       c.stats.retries.Load(),
+		BytesSent:     c.stats.bytesSent.Load(),
+		BytesReceived: c.stats.bytesReceived.Load(),
+		AgentsCreated: c.stats.agentsCreated.Load(),
+		AgentsUpdated: c.stats.agentsUpdated.Load(),
+		AgentsDeleted: c.stats.agentsDeleted.Load(),
+	}
+}
+
+// countMutation increments counter when the mutation err is nil.
+func countMutation(counter *atomic.Int64, err error) error {
+	if err == nil {
+		counter.Add(1)
+	}
+	return err
+}
+
+// countingBody adds the bytes read from a response body to a counter.
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
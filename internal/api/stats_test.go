@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"coragent/internal/agent"
+)
+
+func TestClientStats_CountsRequestsAndMutations(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Fail the first GET once so the retry counter moves.
+		if r.Method == http.MethodGet && calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	defer srv.Close()
+
+	prev := retryBaseDelay
+	retryBaseDelay = 0
+	defer func() { retryBaseDelay = prev }()
+
+	client := newDescribeTestClient(t, srv)
+	client.MaxAttempts = 2
+	ctx := context.Background()
+
+	if err := client.CreateAgent(ctx, "DB", "SCH", agent.AgentSpec{Name: "a"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	if err := client.UpdateAgent(ctx, "DB", "SCH", "a", map[string]any{"comment": "x"}); err != nil {
+		t.Fatalf("UpdateAgent: %v", err)
+	}
+	var out map[string]any
+	if err := client.doJSON(ctx, http.MethodGet, client.agentURL("DB", "SCH", "a"), nil, &out); err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	if err := client.DeleteAgent(ctx, "DB", "SCH", "a"); err != nil {
+		t.Fatalf("DeleteAgent: %v", err)
+	}
+
+	got := client.Stats()
+	// create, update, failed GET, retried GET, delete
+	if got.Requests != 5 {
+		t.Errorf("Requests = %d, want 5", got.Requests)
+	}
+	if got.Retries != 1 {
+		t.Errorf("Retries = %d, want 1", got.Retries)
+	}
+	if got.AgentsCreated != 1 || got.AgentsUpdated != 1 || got.AgentsDeleted != 1 {
+		t.Errorf("mutations = %d/%d/%d, want 1/1/1", got.AgentsCreated, got.AgentsUpdated, got.AgentsDeleted)
+	}
+	if got.BytesSent == 0 {
+		t.Error("BytesSent = 0, want request bodies counted")
+	}
+	if got.BytesReceived == 0 {
+		t.Error("BytesReceived = 0, want response bodies counted")
+	}
+}
+
+func TestClientStats_FailedMutationNotCounted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	client := newDescribeTestClient(t, srv)
+	if err := client.DeleteAgent(context.Background(), "DB", "SCH", "a"); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := client.Stats(); got.Requests != 1 || got.AgentsDeleted != 0 {
+		t.Errorf("Stats = %+v, want 1 request and no deletions", got)
+	}
+}
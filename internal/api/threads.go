@@ -34,13 +34,21 @@ type ThreadMessage struct {
 // CreateThreadRequest represents the request to create a new thread.
 type CreateThreadRequest struct {
 	OriginApplication string `json:"origin_application,omitempty"`
+	ThreadName        string `json:"thread_name,omitempty"`
 }
 
 // CreateThread creates a new conversation thread.
 // Returns the thread_id as a string.
 func (c *Client) CreateThread(ctx context.Context) (string, error) {
+	return c.CreateNamedThread(ctx, "")
+}
+
+// CreateNamedThread creates a new conversation thread labeled with name.
+// An empty name creates an unnamed thread like CreateThread.
+func (c *Client) CreateNamedThread(ctx context.Context, name string) (string, error) {
 	req := CreateThreadRequest{
 		OriginApplication: "coragent",
+		ThreadName:        name,
 	}
 
 	var thread Thread
@@ -90,6 +98,9 @@ func (c *Client) GetThread(ctx context.Context, threadID string) (*Thread, error
 
 // DeleteThread deletes a thread by ID.
 func (c *Client) DeleteThread(ctx context.Context, threadID string) error {
+	if err := c.checkWritable("delete thread %s", threadID); err != nil {
+		return err
+	}
 	if err := c.doJSON(ctx, http.MethodDelete, c.threadURL(threadID), nil, nil); err != nil {
 		return fmt.Errorf("delete thread: %w", err)
 	}
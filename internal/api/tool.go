@@ -0,0 +1,55 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ToolTestResult is the outcome of invoking a single agent tool via TestTool.
+type ToolTestResult struct {
+	ToolName string          `json:"tool_name"`
+	Input    json.RawMessage `json:"input,omitempty"`
+	Result   json.RawMessage `json:"result,omitempty"`
+	// Response is the agent's final text, if any was produced after the tool ran.
+	Response string `json:"response,omitempty"`
+}
+
+// TestTool invokes one tool of an agent in isolation. The Cortex Agents API
+// has no endpoint to call a tool directly, so the agent is run with
+// input as the user message and a tool_choice that forces toolName. The
+// first invocation of that tool and its result are returned; an error is
+// returned when the agent finishes without calling it.
+func (c *Client) TestTool(ctx context.Context, db, schema, agentName, toolName, input string) (*ToolTestResult, error) {
+	result := &ToolTestResult{ToolName: toolName}
+	var called, answered bool
+
+	var text strings.Builder
+	_, err := c.RunAgent(ctx, db, schema, agentName, RunAgentRequest{
+		Messages:   []Message{NewTextMessage("user", input)},
+		ToolChoice: &ToolChoice{Type: "tool", Name: []string{toolName}},
+	}, RunAgentOptions{
+		OnTextDelta: func(delta string) { text.WriteString(delta) },
+		OnToolUse: func(name string, in json.RawMessage) {
+			if name == toolName && !called {
+				called = true
+				result.Input = in
+			}
+		},
+		OnToolResult: func(name string, out json.RawMessage) {
+			if name == toolName && !answered {
+				answered = true
+				result.Result = out
+			}
+		},
+	})
+	if err != nil {
+		return result, err
+	}
+	if !called {
+		return result, fmt.Errorf("agent %q did not invoke tool %q", agentName, toolName)
+	}
+	result.Response = text.String()
+	return result, nil
+}
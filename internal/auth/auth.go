@@ -22,6 +22,13 @@ const (
 	AuthenticatorOAuth   = "OAUTH"
 )
 
+// Values of the X-Snowflake-Authorization-Token-Type header returned by
+// BearerToken. Snowflake uses them to interpret the Bearer token.
+const (
+	TokenTypeKeyPairJWT = "KEYPAIR_JWT"
+	TokenTypeOAuth      = "OAUTH"
+)
+
 type Config struct {
 	Account              string
 	User                 string
@@ -34,6 +41,9 @@ type Config struct {
 	Authenticator        string
 	// OAuth redirect URI (optional, default: http://127.0.0.1:8080)
 	OAuthRedirectURI string
+	// SessionToken is a pre-issued token (SNOWFLAKE_TOKEN). When set it is
+	// used as the bearer token directly and key pair / OAuth are skipped.
+	SessionToken string
 }
 
 func FromEnv() Config {
@@ -48,6 +58,7 @@ func FromEnv() Config {
 		PrivateKeyPassphrase: envOrDefault("SNOWFLAKE_PRIVATE_KEY_PASSPHRASE", os.Getenv("PRIVATE_KEY_PASSPHRASE")),
 		Authenticator:        envOrDefault("SNOWFLAKE_AUTHENTICATOR", AuthenticatorKeyPair),
 		OAuthRedirectURI:     envOrDefault("SNOWFLAKE_OAUTH_REDIRECT_URI", DefaultOAuthRedirectURI),
+		SessionToken:         strings.TrimSpace(os.Getenv("SNOWFLAKE_TOKEN")),
 	}
 }
 
@@ -61,7 +72,12 @@ func AuthHeader(ctx context.Context, cfg Config) (string, error) {
 
 // BearerToken returns the bearer token and token type for the configured authenticator.
 // Token types: "KEYPAIR_JWT" for key pair auth, "OAUTH" for OAuth.
+// A pre-issued SessionToken takes precedence and is sent with type "OAUTH".
 func BearerToken(ctx context.Context, cfg Config) (token string, tokenType string, err error) {
+	if token := strings.TrimSpace(cfg.SessionToken); token != "" {
+		return token, TokenTypeOAuth, nil
+	}
+
 	auth := strings.ToUpper(strings.TrimSpace(cfg.Authenticator))
 	if auth == "" {
 		auth = AuthenticatorKeyPair
@@ -70,10 +86,10 @@ func BearerToken(ctx context.Context, cfg Config) (token string, tokenType strin
 	switch auth {
 	case AuthenticatorKeyPair:
 		token, err := keyPairJWT(cfg)
-		return token, "KEYPAIR_JWT", err
+		return token, TokenTypeKeyPairJWT, err
 	case AuthenticatorOAuth:
 		token, err := GetValidAccessToken(ctx, cfg)
-		return token, "OAUTH", err
+		return token, TokenTypeOAuth, err
 	default:
 		return "", "", fmt.Errorf("unsupported authenticator: %s", cfg.Authenticator)
 	}
@@ -139,7 +155,7 @@ func loadKeyPair(inline, passphrase string) (*rsa.PrivateKey, *rsa.PublicKey, er
 			return privKey, pubKey, nil
 		}
 		// Also accept base64-encoded DER (PKCS#8 or PKCS#1).
-		if derKey, derErr := parsePrivateKeyDER(decoded); derErr == nil {
+		if derKey, derErr := parsePrivateKeyDER("base64 DER key", decoded); derErr == nil {
 			return derKey, derKey.Public().(*rsa.PublicKey), nil
 		}
 	}
@@ -179,6 +195,16 @@ func normalizePEM(s string) string {
 	return strings.Join(lines, "\n")
 }
 
+// isEncryptedPrivateKey reports whether data is a PEM private key protected by
+// a passphrase (PKCS8 "ENCRYPTED PRIVATE KEY" or legacy Proc-Type encryption).
+func isEncryptedPrivateKey(data []byte) bool {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false
+	}
+	return block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block) //nolint:staticcheck // Legacy PEM encryption needed for Snowflake key compatibility.
+}
+
 func parsePrivateKey(data []byte, passphrase string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
 	block, _ := pem.Decode(data)
 	if block == nil {
@@ -188,32 +214,30 @@ func parsePrivateKey(data []byte, passphrase string) (*rsa.PrivateKey, *rsa.Publ
 	var privKey *rsa.PrivateKey
 	if x509.IsEncryptedPEMBlock(block) { //nolint:staticcheck // Legacy PEM encryption needed for Snowflake key compatibility.
 		if strings.TrimSpace(passphrase) == "" {
-			return nil, nil, fmt.Errorf("private key is encrypted but no passphrase was provided (set SNOWFLAKE_PRIVATE_KEY_PASSPHRASE)")
+			return nil, nil, fmt.Errorf("private key is encrypted but no passphrase was provided (set SNOWFLAKE_PRIVATE_KEY_PASSPHRASE or private_key_file_pwd in config.toml)")
 		}
 		der, err := x509.DecryptPEMBlock(block, []byte(passphrase)) //nolint:staticcheck
 		if err != nil {
 			return nil, nil, fmt.Errorf("decrypt private key: %w", err)
 		}
-		key, err := parsePrivateKeyDER(der)
+		key, err := parsePrivateKeyDER(block.Type, der)
 		if err != nil {
 			return nil, nil, err
 		}
 		privKey = key
 	} else {
 		switch block.Type {
-		case "PRIVATE KEY":
-			key, parseErr := x509.ParsePKCS8PrivateKey(block.Bytes)
-			if parseErr != nil {
-				return nil, nil, fmt.Errorf("parse PKCS8 key: %w", parseErr)
-			}
-			rsaKey, ok := key.(*rsa.PrivateKey)
-			if !ok {
-				return nil, nil, fmt.Errorf("private key is not RSA")
+		case "PRIVATE KEY", "RSA PRIVATE KEY":
+			// The PEM label is only a hint: some tools write PKCS1 data under
+			// "PRIVATE KEY" and vice versa, so both encodings are tried.
+			key, err := parsePrivateKeyDER(block.Type, block.Bytes)
+			if err != nil {
+				return nil, nil, err
 			}
-			privKey = rsaKey
+			privKey = key
 		case "ENCRYPTED PRIVATE KEY":
 			if strings.TrimSpace(passphrase) == "" {
-				return nil, nil, fmt.Errorf("private key is encrypted but no passphrase was provided (set SNOWFLAKE_PRIVATE_KEY_PASSPHRASE)")
+				return nil, nil, fmt.Errorf("private key is encrypted but no passphrase was provided (set SNOWFLAKE_PRIVATE_KEY_PASSPHRASE or private_key_file_pwd in config.toml)")
 			}
 			key, err := pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(passphrase))
 			if err != nil {
@@ -224,14 +248,8 @@ func parsePrivateKey(data []byte, passphrase string) (*rsa.PrivateKey, *rsa.Publ
 				return nil, nil, fmt.Errorf("private key is not RSA")
 			}
 			privKey = rsaKey
-		case "RSA PRIVATE KEY":
-			key, parseErr := x509.ParsePKCS1PrivateKey(block.Bytes)
-			if parseErr != nil {
-				return nil, nil, fmt.Errorf("parse PKCS1 key: %w", parseErr)
-			}
-			privKey = key
 		default:
-			return nil, nil, fmt.Errorf("unsupported key type %q", block.Type)
+			return nil, nil, fmt.Errorf("unsupported key type %q (expected PRIVATE KEY, RSA PRIVATE KEY or ENCRYPTED PRIVATE KEY)", block.Type)
 		}
 	}
 
@@ -239,17 +257,20 @@ func parsePrivateKey(data []byte, passphrase string) (*rsa.PrivateKey, *rsa.Publ
 	return privKey, pubKey, nil
 }
 
-func parsePrivateKeyDER(der []byte) (*rsa.PrivateKey, error) {
-	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
-		return key, nil
-	}
-	key, err := x509.ParsePKCS8PrivateKey(der)
-	if err != nil {
-		return nil, fmt.Errorf("parse decrypted key: %w", err)
+// parsePrivateKeyDER parses an RSA key as PKCS8 and falls back to PKCS1.
+// pemType is the label of the PEM block the DER came from, for errors.
+func parsePrivateKeyDER(pemType string, der []byte) (*rsa.PrivateKey, error) {
+	key, pkcs8Err := x509.ParsePKCS8PrivateKey(der)
+	if pkcs8Err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s is not an RSA key (got %T)", pemType, key)
+		}
+		return rsaKey, nil
 	}
-	rsaKey, ok := key.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("private key is not RSA")
+	rsaKey, pkcs1Err := x509.ParsePKCS1PrivateKey(der)
+	if pkcs1Err != nil {
+		return nil, fmt.Errorf("parse %s: not a PKCS8 or PKCS1 RSA key (PKCS8: %v; PKCS1: %v)", pemType, pkcs8Err, pkcs1Err)
 	}
 	return rsaKey, nil
 }
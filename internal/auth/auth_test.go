@@ -2,13 +2,17 @@ package auth
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
+	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 // generateTestPEM generates a PEM-encoded PKCS8 private key for testing.
@@ -108,6 +112,65 @@ func TestLoadKeyPair_PKCS1(t *testing.T) {
 	}
 }
 
+func TestParsePrivateKey_FallsBackAcrossPEMLabels(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	pkcs1 := x509.MarshalPKCS1PrivateKey(key)
+
+	tests := []struct {
+		name  string
+		block *pem.Block
+	}{
+		{"PKCS8 as PRIVATE KEY", &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}},
+		{"PKCS1 as RSA PRIVATE KEY", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: pkcs1}},
+		{"PKCS1 as PRIVATE KEY", &pem.Block{Type: "PRIVATE KEY", Bytes: pkcs1}},
+		{"PKCS8 as RSA PRIVATE KEY", &pem.Block{Type: "RSA PRIVATE KEY", Bytes: pkcs8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			priv, _, err := parsePrivateKey(pem.EncodeToMemory(tt.block), "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !priv.Equal(key) {
+				t.Error("parsed key does not match the generated key")
+			}
+		})
+	}
+}
+
+func TestParsePrivateKey_ErrorNamesPEMType(t *testing.T) {
+	garbage := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: []byte("not a key")})
+	_, _, err := parsePrivateKey(garbage, "")
+	if err == nil || !strings.Contains(err.Error(), "parse RSA PRIVATE KEY: not a PKCS8 or PKCS1 RSA key") {
+		t.Fatalf("expected error naming the PEM type, got %v", err)
+	}
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate EC key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("marshal EC key: %v", err)
+	}
+	_, _, err = parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), "")
+	if err == nil || !strings.Contains(err.Error(), "PRIVATE KEY is not an RSA key") {
+		t.Fatalf("expected not-RSA error, got %v", err)
+	}
+
+	_, _, err = parsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), "")
+	if err == nil || !strings.Contains(err.Error(), `unsupported key type "EC PRIVATE KEY"`) {
+		t.Fatalf("expected unsupported type error, got %v", err)
+	}
+}
+
 func TestLoadKeyPair_Base64Encoded(t *testing.T) {
 	pemStr := generateTestPEM(t)
 	encoded := base64.StdEncoding.EncodeToString([]byte(pemStr))
@@ -287,3 +350,82 @@ func TestEnvOrDefault(t *testing.T) {
 		}
 	})
 }
+
+func TestBearerToken_SessionTokenSkipsAuthenticator(t *testing.T) {
+	// No private key and no stored OAuth tokens: either authenticator would
+	// fail, so success proves the pre-issued token was used directly.
+	t.Setenv("HOME", t.TempDir())
+	for _, authenticator := range []string{AuthenticatorKeyPair, AuthenticatorOAuth} {
+		cfg := Config{
+			Account:       "ACCT",
+			Authenticator: authenticator,
+			SessionToken:  "ci-token",
+		}
+		token, tokenType, err := BearerToken(context.Background(), cfg)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", authenticator, err)
+		}
+		if token != "ci-token" || tokenType != "OAUTH" {
+			t.Errorf("%s: got (%q, %q), want (%q, %q)", authenticator, token, tokenType, "ci-token", "OAUTH")
+		}
+	}
+}
+
+func TestLogin_SessionTokenSkipsLoginRequest(t *testing.T) {
+	// The account does not resolve, so any login request would fail.
+	cfg := Config{Account: "invalid.host.example", SessionToken: "ci-token"}
+	session, err := Login(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if session.Token != "ci-token" {
+		t.Errorf("Token = %q, want %q", session.Token, "ci-token")
+	}
+}
+
+func TestClockSkewHint(t *testing.T) {
+	server := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	date := server.Format(http.TimeFormat)
+	tests := []struct {
+		name       string
+		serverDate string
+		now        time.Time
+		want       string
+	}{
+		{"local ahead", date, server.Add(5*time.Minute + 12*time.Second), "local clock is 5m12s ahead"},
+		{"local behind", date, server.Add(-2 * time.Hour), "local clock is 2h0m0s behind"},
+		{"in sync", date, server.Add(3 * time.Second), "clock matches Snowflake's, so check that the user's RSA_PUBLIC_KEY"},
+		{"no date", "", server, "check that the local clock is in sync"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClockSkewHint(tt.serverDate, tt.now); !strings.Contains(got, tt.want) {
+				t.Errorf("ClockSkewHint() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogin_JWTInvalidReportsClockSkew(t *testing.T) {
+	serverDate := time.Now().Add(-10 * time.Minute).UTC().Format(http.TimeFormat)
+	rt := &recordingTransport{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", serverDate)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":null,"code":"390144","message":"JWT token is invalid. [a1b2c3]","success":false}`))
+	})}
+	ctx := WithTransport(context.Background(), rt)
+
+	cfg := Config{Account: "MYACCT", User: "ME", PrivateKey: generateTestPEM(t)}
+	_, err := Login(ctx, cfg)
+	if err == nil {
+		t.Fatal("expected login error")
+	}
+	for _, want := range []string{"JWT token is invalid", "390144", "local clock is 10m", "ahead of Snowflake's"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q missing %q", err, want)
+		}
+	}
+	if len(rt.urls) != 1 || !strings.HasPrefix(rt.urls[0], "https://MYACCT.snowflakecomputing.com/session/v1/login-request") {
+		t.Errorf("requests = %v", rt.urls)
+	}
+}
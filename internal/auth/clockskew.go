@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// jwtInvalidCode is the Snowflake error code for a rejected key-pair JWT.
+const jwtInvalidCode = "390144"
+
+// clockSkewTolerance is the clock difference below which ClockSkewHint does
+// not blame the clock. HTTP Date headers only have second precision.
+const clockSkewTolerance = 30 * time.Second
+
+// IsJWTInvalid reports whether an error message or response body is
+// Snowflake's rejection of a key-pair JWT ("JWT token is invalid", code
+// 390144). The most common cause is a local clock that is off, since the
+// token is only valid for an hour from its issue time.
+func IsJWTInvalid(body string) bool {
+	return strings.Contains(strings.ToLower(body), "jwt token is invalid") ||
+		strings.Contains(body, jwtInvalidCode)
+}
+
+// ClockSkewHint explains a rejected key-pair JWT. serverDate is the HTTP Date
+// header of the rejecting response; when it parses, the hint reports how far
+// the local clock is from Snowflake's, or points at the key registration when
+// the clocks agree.
+func ClockSkewHint(serverDate string, now time.Time) string {
+	const base = "Snowflake rejected the key-pair JWT"
+	serverTime, err := http.ParseTime(serverDate)
+	if err != nil {
+		return base + "; check that the local clock is in sync (e.g. enable NTP) and that the user's RSA_PUBLIC_KEY matches the private key"
+	}
+	skew := now.Sub(serverTime).Round(time.Second)
+	switch {
+	case skew >= clockSkewTolerance:
+		return fmt.Sprintf("%s; the local clock is %s ahead of Snowflake's, sync it (e.g. enable NTP) and retry", base, skew)
+	case skew <= -clockSkewTolerance:
+		return fmt.Sprintf("%s; the local clock is %s behind Snowflake's, sync it (e.g. enable NTP) and retry", base, -skew)
+	default:
+		return base + "; the local clock matches Snowflake's, so check that the user's RSA_PUBLIC_KEY matches the private key"
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+var (
+	// oauthRefreshLockTimeout bounds how long a process waits for another
+	// process to finish refreshing the OAuth tokens before refreshing on its own.
+	oauthRefreshLockTimeout = 10 * time.Second
+	// lockStaleAfter is the age after which a lockfile is treated as left
+	// behind by a crashed process and removed.
+	lockStaleAfter = time.Minute
+)
+
+// acquireFileLock creates path exclusively, retrying with exponential backoff
+// (25ms doubling up to 500ms) while another process holds it. It returns a
+// release func and true once the lock is held, or false when timeout elapses
+// or ctx is done, in which case the caller proceeds without the lock.
+func acquireFileLock(ctx context.Context, path string, timeout time.Duration) (func(), bool) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, false
+	}
+	deadline := time.Now().Add(timeout)
+	wait := 25 * time.Millisecond
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, true
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, false
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			breakStaleLock(path, info)
+			continue
+		}
+		if time.Now().Add(wait).After(deadline) {
+			return nil, false
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, 500*time.Millisecond)
+	}
+}
+
+// breakStaleLock removes the lockfile at path that was found stale as stale.
+// The file is first renamed to a name unique to this process, so only one
+// process can claim it; if the claimed file turns out to be a newer lock taken
+// after stale was observed, it is linked back into place instead of deleted.
+func breakStaleLock(path string, stale os.FileInfo) {
+	claimed := fmt.Sprintf("%s.%d.%d.stale", path, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(path, claimed); err != nil {
+		return
+	}
+	if info, err := os.Stat(claimed); err == nil && (!os.SameFile(stale, info) || time.Since(info.ModTime()) <= lockStaleAfter) {
+		_ = os.Link(claimed, path)
+	}
+	os.Remove(claimed)
+}
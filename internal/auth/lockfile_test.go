@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireFileLockBreaksStaleLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.lock")
+	if err := os.WriteFile(path, []byte("1\n"), 0o600); err != nil {
+		t.Fatalf("write lock: %v", err)
+	}
+	old := time.Now().Add(-2 * lockStaleAfter)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	release, ok := acquireFileLock(context.Background(), path, time.Second)
+	if !ok {
+		t.Fatal("expected to acquire lock after breaking the stale one")
+	}
+	release()
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatalf("read dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("leftover files after release: %v", entries)
+	}
+}
+
+func TestBreakStaleLockKeepsReplacedLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "token.lock")
+
+	// Observe a stale lock, then let another process replace it with a live one.
+	if err := os.WriteFile(path, []byte("1\n"), 0o600); err != nil {
+		t.Fatalf("write stale lock: %v", err)
+	}
+	stale, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	// Keep the stale file alive elsewhere so its inode is not reused.
+	if err := os.Rename(path, filepath.Join(t.TempDir(), "old.lock")); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("2\n"), 0o600); err != nil {
+		t.Fatalf("write live lock: %v", err)
+	}
+
+	breakStaleLock(path, stale)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("live lock was removed: %v", err)
+	}
+	if string(data) != "2\n" {
+		t.Errorf("lock content = %q, want the live lock", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only the live lock, got %v", entries)
+	}
+}
@@ -60,7 +60,12 @@ type SessionToken struct {
 }
 
 // Login authenticates with Snowflake and returns a session token.
+// If cfg.SessionToken is set, it is returned as-is without a login request.
 func Login(ctx context.Context, cfg Config) (*SessionToken, error) {
+	if token := strings.TrimSpace(cfg.SessionToken); token != "" {
+		return &SessionToken{Token: token}, nil
+	}
+
 	auth := strings.ToUpper(strings.TrimSpace(cfg.Authenticator))
 	if auth == "" {
 		auth = AuthenticatorKeyPair
@@ -139,8 +144,7 @@ func doLogin(ctx context.Context, cfg Config, authenticator, token, provider str
 	req.Header.Set("User-Agent", clientAppID)
 
 	// Send request
-	client := &http.Client{Timeout: 60 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := httpClient(ctx, 60*time.Second).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("login request failed: %w", err)
 	}
@@ -159,6 +163,9 @@ func doLogin(ctx context.Context, cfg Config, authenticator, token, provider str
 	}
 
 	if !loginResp.Success {
+		if IsJWTInvalid(loginResp.Code + " " + loginResp.Message) {
+			return nil, fmt.Errorf("login failed: %s (code: %s); %s", loginResp.Message, loginResp.Code, ClockSkewHint(resp.Header.Get("Date"), time.Now()))
+		}
 		return nil, fmt.Errorf("login failed: %s (code: %s)", loginResp.Message, loginResp.Code)
 	}
 
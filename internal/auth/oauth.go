@@ -130,7 +130,7 @@ func ExchangeCodeForTokens(ctx context.Context, cfg OAuthConfig, code string, co
 		return nil, fmt.Errorf("authorization code is required")
 	}
 	tokenURL := fmt.Sprintf("https://%s.snowflakecomputing.com/oauth/token-request", cfg.Account)
-	return exchangeCodeForTokensInternal(ctx, cfg, code, codeVerifier, tokenURL, &http.Client{Timeout: 30 * time.Second})
+	return exchangeCodeForTokensInternal(ctx, cfg, code, codeVerifier, tokenURL, httpClient(ctx, 30*time.Second))
 }
 
 // exchangeCodeForTokensInternal is the testable core of ExchangeCodeForTokens.
@@ -210,7 +210,7 @@ func RefreshAccessToken(ctx context.Context, cfg OAuthConfig, refreshToken strin
 		return nil, fmt.Errorf("refresh token is required")
 	}
 	tokenURL := fmt.Sprintf("https://%s.snowflakecomputing.com/oauth/token-request", cfg.Account)
-	return refreshAccessTokenInternal(ctx, cfg, refreshToken, tokenURL, &http.Client{Timeout: 30 * time.Second})
+	return refreshAccessTokenInternal(ctx, cfg, refreshToken, tokenURL, httpClient(ctx, 30*time.Second))
 }
 
 // refreshAccessTokenInternal is the testable core of RefreshAccessToken.
@@ -283,8 +283,17 @@ func refreshAccessTokenInternal(ctx context.Context, cfg OAuthConfig, refreshTok
 	return tokens, nil
 }
 
+// refreshTokens is the refresh call used by GetValidAccessToken; tests
+// replace it to avoid network access.
+var refreshTokens = RefreshAccessToken
+
 // GetValidAccessToken returns a valid access token, refreshing if necessary.
 // It loads tokens from store, checks expiry, refreshes if needed, and saves updated tokens.
+//
+// Concurrent coragent processes coordinate the refresh through a lockfile
+// next to the token store: one refreshes and saves, the others wait and then
+// reuse the saved token. If the lock cannot be taken within
+// oauthRefreshLockTimeout, the process refreshes independently.
 func GetValidAccessToken(ctx context.Context, cfg Config) (string, error) {
 	store, err := LoadTokenStore()
 	if err != nil {
@@ -301,6 +310,21 @@ func GetValidAccessToken(ctx context.Context, cfg Config) (string, error) {
 		return tokens.AccessToken, nil
 	}
 
+	if release, ok := acquireFileLock(ctx, oauthFilePath()+".lock", oauthRefreshLockTimeout); ok {
+		defer release()
+		// Another process may have refreshed while we waited for the lock.
+		store, err = LoadTokenStore()
+		if err != nil {
+			return "", fmt.Errorf("load token store: %w", err)
+		}
+		if tokens = store.GetTokens(cfg.Account); tokens == nil {
+			return "", fmt.Errorf("no OAuth tokens found for account %s; run 'coragent login' first", cfg.Account)
+		}
+		if !tokens.IsExpired() {
+			return tokens.AccessToken, nil
+		}
+	}
+
 	// Token expired, try to refresh
 	if tokens.RefreshToken == "" {
 		return "", fmt.Errorf("access token expired and no refresh token available; run 'coragent login' again")
@@ -312,7 +336,7 @@ func GetValidAccessToken(ctx context.Context, cfg Config) (string, error) {
 		RedirectURI: cfg.OAuthRedirectURI,
 	}
 
-	newTokens, err := RefreshAccessToken(ctx, oauthCfg, tokens.RefreshToken)
+	newTokens, err := refreshTokens(ctx, oauthCfg, tokens.RefreshToken)
 	if err != nil {
 		return "", fmt.Errorf("refresh access token failed (stored refresh token may be invalid or revoked): %w; run 'coragent login' again", err)
 	}
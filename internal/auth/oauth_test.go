@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -433,3 +437,126 @@ func TestGetValidAccessToken_RefreshFailurePromptsLogin(t *testing.T) {
 		t.Errorf("error = %q, want to prompt login", err.Error())
 	}
 }
+
+// saveExpiredTokens primes the token store in a temp HOME with an expired
+// access token for account ACCT.
+func saveExpiredTokens(t *testing.T) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	store := &TokenStore{Tokens: make(map[string]OAuthTokens)}
+	store.SetTokens(OAuthTokens{
+		Account:      "ACCT",
+		AccessToken:  "expired-access-token",
+		RefreshToken: "refresh-token",
+		ExpiresAt:    time.Now().Add(-1 * time.Minute),
+	})
+	if err := store.Save(); err != nil {
+		t.Fatalf("save token store: %v", err)
+	}
+}
+
+// stubRefresh replaces refreshTokens with a fake that counts calls, sleeps
+// for delay and returns a fresh token.
+func stubRefresh(t *testing.T, delay time.Duration) *atomic.Int32 {
+	t.Helper()
+	var calls atomic.Int32
+	orig := refreshTokens
+	refreshTokens = func(ctx context.Context, cfg OAuthConfig, refreshToken string) (*OAuthTokens, error) {
+		n := calls.Add(1)
+		time.Sleep(delay)
+		return &OAuthTokens{
+			Account:      cfg.Account,
+			AccessToken:  fmt.Sprintf("fresh-access-token-%d", n),
+			RefreshToken: refreshToken,
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}, nil
+	}
+	t.Cleanup(func() { refreshTokens = orig })
+	return &calls
+}
+
+func TestGetValidAccessToken_ConcurrentCallersShareOneRefresh(t *testing.T) {
+	saveExpiredTokens(t)
+	calls := stubRefresh(t, 200*time.Millisecond)
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 2)
+	errs := make([]error, 2)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], errs[i] = GetValidAccessToken(context.Background(), Config{Account: "ACCT"})
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("caller %d: %v", i, err)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("refresh called %d times, want 1", got)
+	}
+	if tokens[0] != tokens[1] || tokens[0] != "fresh-access-token-1" {
+		t.Errorf("tokens = %q, want both fresh-access-token-1", tokens)
+	}
+	if _, err := os.Stat(oauthFilePath() + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("lockfile not removed: %v", err)
+	}
+}
+
+func TestGetValidAccessToken_LockTimeoutFallsBackToOwnRefresh(t *testing.T) {
+	saveExpiredTokens(t)
+	calls := stubRefresh(t, 0)
+	orig := oauthRefreshLockTimeout
+	oauthRefreshLockTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { oauthRefreshLockTimeout = orig })
+
+	// A fresh lock held by another process.
+	if err := os.WriteFile(oauthFilePath()+".lock", []byte("1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := GetValidAccessToken(context.Background(), Config{Account: "ACCT"})
+	if err != nil {
+		t.Fatalf("GetValidAccessToken: %v", err)
+	}
+	if token != "fresh-access-token-1" || calls.Load() != 1 {
+		t.Errorf("token = %q, calls = %d; want an independent refresh", token, calls.Load())
+	}
+}
+
+// recordingTransport forwards requests to handler in-process and records
+// their URLs.
+type recordingTransport struct {
+	handler http.Handler
+	urls    []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.urls = append(rt.urls, req.URL.String())
+	rec := httptest.NewRecorder()
+	rt.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+// TestRefreshAccessToken_UsesContextTransport verifies that a transport set
+// with WithTransport carries the token request instead of the network.
+func TestRefreshAccessToken_UsesContextTransport(t *testing.T) {
+	rt := &recordingTransport{handler: successTokenHandler(tokenServerResponse{AccessToken: "via-stub", ExpiresIn: 600})}
+	ctx := WithTransport(context.Background(), rt)
+
+	tokens, err := RefreshAccessToken(ctx, OAuthConfig{Account: "MYACCT", ClientID: "LOCAL_APPLICATION"}, "refresh")
+	if err != nil {
+		t.Fatalf("RefreshAccessToken: %v", err)
+	}
+	if tokens.AccessToken != "via-stub" {
+		t.Errorf("AccessToken = %q, want %q", tokens.AccessToken, "via-stub")
+	}
+	want := "https://MYACCT.snowflakecomputing.com/oauth/token-request"
+	if len(rt.urls) != 1 || rt.urls[0] != want {
+		t.Errorf("requests = %v, want [%s]", rt.urls, want)
+	}
+}
@@ -32,6 +32,9 @@ type ConfigDiagnostics struct {
 	ConnectionName  string
 	ConnectionNames []string
 	Messages        []DiagMessage
+	// TokenAuth is true when SNOWFLAKE_TOKEN is set, which overrides the
+	// authenticator configured in config.toml.
+	TokenAuth bool
 }
 
 // SnowflakeConnection represents a [connections.<name>] section in config.toml.
@@ -46,6 +49,7 @@ type SnowflakeConnection struct {
 	PrivateKeyFile    string `toml:"private_key_file"`
 	PrivateKeyPath    string `toml:"private_key_path"`
 	PrivateKeyRaw     string `toml:"private_key_raw"`
+	PrivateKeyFilePwd string `toml:"private_key_file_pwd"`
 	OAuthClientID     string `toml:"oauth_client_id"`
 	OAuthClientSecret string `toml:"oauth_client_secret"`
 	OAuthRedirectURI  string `toml:"oauth_redirect_uri"`
@@ -139,6 +143,8 @@ func (c *SnowflakeConnection) ToAuthConfig() (Config, error) {
 	} else if c.PrivateKeyRaw != "" {
 		cfg.PrivateKey = c.PrivateKeyRaw
 	}
+	// Passphrase for an encrypted key; SNOWFLAKE_PRIVATE_KEY_PASSPHRASE overrides it via overlayEnv.
+	cfg.PrivateKeyPassphrase = c.PrivateKeyFilePwd
 
 	// OAuth credentials are stored in the connection config and used at login/token time.
 
@@ -211,6 +217,14 @@ func LoadConfig(connectionName string) Config {
 func DiagnoseConfig(connectionName string) ConfigDiagnostics {
 	diag := ConfigDiagnostics{}
 
+	if strings.TrimSpace(os.Getenv("SNOWFLAKE_TOKEN")) != "" {
+		diag.TokenAuth = true
+		diag.Messages = append(diag.Messages, DiagMessage{
+			Level:   DiagInfo,
+			Message: "SNOWFLAKE_TOKEN is set. Using the pre-issued token; key pair and OAuth settings are ignored.",
+		})
+	}
+
 	// Find config file
 	diag.ConfigPath = findConfigPath()
 	if diag.ConfigPath == "" {
@@ -298,14 +312,26 @@ func DiagnoseConfig(connectionName string) ConfigDiagnostics {
 	}
 
 	// Check private key file existence
+	keyData := []byte(conn.PrivateKeyRaw)
 	if keyFile := firstNonEmptyStr(conn.PrivateKeyFile, conn.PrivateKeyPath); keyFile != "" {
 		expanded := expandHome(keyFile)
-		if _, err := os.Stat(expanded); err != nil {
+		data, err := os.ReadFile(expanded)
+		if err != nil {
 			diag.Messages = append(diag.Messages, DiagMessage{
 				Level:   DiagError,
 				Message: fmt.Sprintf("Private key file not found: %s", expanded),
 			})
 		}
+		keyData = data
+	}
+
+	// Check that an encrypted key has a passphrase source
+	passphraseEnv := envOrDefault("SNOWFLAKE_PRIVATE_KEY_PASSPHRASE", os.Getenv("PRIVATE_KEY_PASSPHRASE"))
+	if isEncryptedPrivateKey(keyData) && conn.PrivateKeyFilePwd == "" && passphraseEnv == "" {
+		diag.Messages = append(diag.Messages, DiagMessage{
+			Level:   DiagWarning,
+			Message: fmt.Sprintf("Connection %q uses an encrypted private key but no passphrase is configured. Set 'private_key_file_pwd' in config.toml or SNOWFLAKE_PRIVATE_KEY_PASSPHRASE.", resolvedName),
+		})
 	}
 
 	return diag
@@ -377,6 +403,7 @@ func WriteConnection(connName string, conn SnowflakeConnection, setAsDefault boo
 		writeTomlField(&sb, "private_key_file", c.PrivateKeyFile)
 		writeTomlField(&sb, "private_key_path", c.PrivateKeyPath)
 		writeTomlField(&sb, "private_key_raw", c.PrivateKeyRaw)
+		writeTomlField(&sb, "private_key_file_pwd", c.PrivateKeyFilePwd)
 		writeTomlField(&sb, "oauth_client_id", c.OAuthClientID)
 		writeTomlField(&sb, "oauth_client_secret", c.OAuthClientSecret)
 		writeTomlField(&sb, "oauth_redirect_uri", c.OAuthRedirectURI)
@@ -427,4 +454,7 @@ func overlayEnv(cfg *Config) {
 	if v := strings.TrimSpace(os.Getenv("SNOWFLAKE_OAUTH_REDIRECT_URI")); v != "" {
 		cfg.OAuthRedirectURI = v
 	}
+	if v := strings.TrimSpace(os.Getenv("SNOWFLAKE_TOKEN")); v != "" {
+		cfg.SessionToken = v
+	}
 }
@@ -0,0 +1,24 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type transportKey struct{}
+
+// WithTransport returns a copy of ctx that makes the login and OAuth token
+// requests of this package use rt instead of http.DefaultTransport. The API
+// client sets it from api.(*Client).SetTransport so that a token refresh
+// goes through the same proxy or test stub as the API calls.
+func WithTransport(ctx context.Context, rt http.RoundTripper) context.Context {
+	return context.WithValue(ctx, transportKey{}, rt)
+}
+
+// httpClient returns an http.Client with the given timeout that uses the
+// transport stored in ctx by WithTransport, or the default transport.
+func httpClient(ctx context.Context, timeout time.Duration) *http.Client {
+	rt, _ := ctx.Value(transportKey{}).(http.RoundTripper)
+	return &http.Client{Timeout: timeout, Transport: rt}
+}
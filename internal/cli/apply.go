@@ -22,12 +22,24 @@ type applyItem struct {
 	Exists    bool
 	Changes   []diff.Change
 	GrantDiff grant.GrantDiff
+	// Replace deletes and re-creates the existing agent instead of updating
+	// it (deploy.strategy: replace with changes). GrantDiff then holds every
+	// desired grant, since the new agent starts without any.
+	Replace bool
+	// Skipped marks an existing agent left untouched because of
+	// deploy.strategy: create_only. It has no changes and no grant diff.
+	Skipped bool
 }
 
 func newApplyCmd(opts *RootOptions) *cobra.Command {
 	var autoApprove bool
 	var recursive bool
 	var runEval bool
+	var output string
+	var onlyChanged bool
+	var dryRun bool
+	var prune bool
+	var verify bool
 	cmd := &cobra.Command{
 		Use:   "apply [path]",
 		Short: "Apply agent changes",
@@ -38,18 +50,62 @@ func newApplyCmd(opts *RootOptions) *cobra.Command {
   coragent apply agent.yaml -y
 
   # Apply all agents recursively and run eval tests after
-  coragent apply -R ./agents/ --eval`,
+  coragent apply -R ./agents/ --eval
+
+  # Apply without prompting and print the change set as JSON
+  coragent apply -y --output json
+
+  # Keep CI logs focused on agents that change
+  coragent apply -R ./agents/ -y --only-changed
+
+  # Show what apply would create, update, grant and revoke without doing it
+  coragent apply --dry-run
+
+  # Re-fetch every applied agent and warn about fields the server changed
+  coragent apply -R ./agents/ --verify
+
+  # Treat ./agents/ as the source of truth: also delete deployed agents in
+  # the targeted schemas that have no spec file
+  coragent apply -R ./agents/ --prune`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+			jsonOutput := output == "json"
+			if jsonOutput && !autoApprove && !dryRun {
+				return UserErr(fmt.Errorf("--output json requires --yes"))
+			}
+			if jsonOutput && runEval {
+				return UserErr(fmt.Errorf("--output json cannot be combined with --eval"))
+			}
+			if dryRun && runEval {
+				return UserErr(fmt.Errorf("--dry-run cannot be combined with --eval"))
+			}
+			// With JSON output, stdout carries only the change set.
+			progress := os.Stdout
+			if jsonOutput {
+				progress = os.Stderr
+			}
+
 			path := "."
 			if len(args) == 1 {
 				path = args[0]
 			}
+			if prune {
+				if err := checkPruneScope(path, recursive); err != nil {
+					return err
+				}
+			}
 
 			specs, err := agent.LoadAgents(path, recursive, opts.Env)
 			if err != nil {
 				return UserErr(err)
 			}
+			writeSpecWarnings(os.Stderr, specs)
+			if !cmd.Flags().Changed("verify") {
+				verify = len(specs) == 1
+			}
 
 			client, cfg, err := buildClientAndCfg(opts)
 			if err != nil {
@@ -61,11 +117,30 @@ func newApplyCmd(opts *RootOptions) *cobra.Command {
 				return err
 			}
 
-			summary, err := writePlanPreview(os.Stdout, planItems)
+			summary, err := writePlan(os.Stdout, planItems, output, onlyChanged)
 			if err != nil {
 				return err
 			}
 			if summary.createCount+summary.updateCount == 0 {
+				// The text plan already ends with the unchanged count.
+				if onlyChanged && output == "json" {
+					writeUnchangedCount(progress, summary.noChangeCount)
+				}
+				if prune {
+					return pruneAgents(commandContext("apply"), progress, cmd.InOrStdin(), planItems, client, autoApprove, dryRun)
+				}
+				return nil
+			}
+
+			if dryRun {
+				unchanged := writeDryRunActions(progress, planItems, onlyChanged)
+				if onlyChanged {
+					writeUnchangedCount(progress, unchanged)
+				}
+				fmt.Fprintln(progress, "\nDry run: no changes were applied.")
+				if prune {
+					return pruneAgents(commandContext("apply"), progress, cmd.InOrStdin(), planItems, client, autoApprove, true)
+				}
 				return nil
 			}
 
@@ -76,22 +151,27 @@ func newApplyCmd(opts *RootOptions) *cobra.Command {
 				}
 			}
 
-			for _, item := range planItems {
-				if !item.Exists {
-					color.New(color.FgGreen).Fprintf(os.Stdout, "Creating %s...\n", item.Parsed.Spec.Name)
-				} else if diff.HasChanges(item.Changes) || item.GrantDiff.HasChanges() {
-					color.New(color.FgYellow).Fprintf(os.Stdout, "Updating %s...\n", item.Parsed.Spec.Name)
-				} else {
-					color.New(color.FgCyan).Fprintf(os.Stdout, "No changes for %s\n", item.Parsed.Spec.Name)
-				}
-			}
+			unchanged := writeApplyProgress(progress, planItems, onlyChanged)
 
 			appliedItems, err := executeApply(commandContext("apply"), planItems, client, client)
 			if err != nil {
 				return err
 			}
 
-			color.New(color.FgGreen).Fprintln(os.Stdout, "\nApply complete successfully!")
+			color.New(color.FgGreen).Fprintln(progress, "\nApply complete successfully!")
+			if onlyChanged {
+				writeUnchangedCount(progress, unchanged)
+			}
+
+			if verify {
+				verifyApplied(commandContext("apply"), progress, appliedItems, client)
+			}
+
+			if prune {
+				if err := pruneAgents(commandContext("apply"), progress, cmd.InOrStdin(), planItems, client, autoApprove, false); err != nil {
+					return err
+				}
+			}
 
 			if !runEval {
 				return nil
@@ -126,9 +206,14 @@ func newApplyCmd(opts *RootOptions) *cobra.Command {
 				eo := evalOptions{
 					judgeModel:             resolveJudgeModel(item.Parsed.Spec, appCfg),
 					responseScoreThreshold: resolveResponseScoreThreshold(item.Parsed.Spec, appCfg),
+					maxResponseMs:          resolveMaxResponseMs(item.Parsed.Spec, appCfg),
+					passRateThreshold:      resolvePassRateThreshold(item.Parsed.Spec, appCfg),
 				}
-				if err := runEvalForAgent(client, item.Target, item.Parsed.Spec, outputDir, specDir, appCfg.Eval.TimestampSuffix, eo); err != nil {
+				summary, err := runEvalForAgent(client, item.Target, item.Parsed.Spec, outputDir, specDir, appCfg.Eval.TimestampSuffix, eo)
+				if err != nil {
 					evalErrors = append(evalErrors, fmt.Sprintf("%s: %v", item.Parsed.Spec.Name, err))
+				} else if eo.passRateThreshold > 0 && !suitePasses(summary, eo.passRateThreshold) {
+					evalErrors = append(evalErrors, fmt.Sprintf("%s: %s", item.Parsed.Spec.Name, suiteVerdict(summary, eo.passRateThreshold)))
 				}
 			}
 			if len(evalErrors) > 0 {
@@ -141,6 +226,11 @@ func newApplyCmd(opts *RootOptions) *cobra.Command {
 	cmd.Flags().BoolVarP(&autoApprove, "yes", "y", false, "Skip confirmation prompt")
 	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Recursively load agents from subdirectories")
 	cmd.Flags().BoolVar(&runEval, "eval", false, "Run eval tests for changed agents after apply")
+	cmd.Flags().StringVar(&output, "output", "text", "Plan output format: text or json (json requires --yes)")
+	cmd.Flags().BoolVar(&onlyChanged, "only-changed", false, "Skip per-agent lines for unchanged agents and print an 'N agents unchanged' summary")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan and the statements apply would run, without prompting or changing anything")
+	cmd.Flags().BoolVar(&verify, "verify", false, "After applying, re-fetch created/updated agents and warn about fields the server stored differently (default: on when applying a single agent)")
+	cmd.Flags().BoolVar(&prune, "prune", false, "After applying, delete deployed agents in the targeted schemas that have no spec under the loaded directory; requires a directory and -R (asks for confirmation unless --yes)")
 	return cmd
 }
 
@@ -12,6 +12,7 @@ import (
 
 // executeApply applies each plan item to Snowflake.
 // It creates or updates agents as needed, then applies the precomputed grant diff.
+// Items marked Replace are deleted and created again; Skipped items are left alone.
 // Items with no spec changes still have their grants applied to converge on desired state.
 // Returns the subset of items that were created or updated (not the no-change ones).
 func executeApply(
@@ -24,7 +25,16 @@ func executeApply(
 	for _, item := range items {
 		db, schema, name := item.Target.Database, item.Target.Schema, item.Parsed.Spec.Name
 
-		if !item.Exists {
+		if item.Skipped {
+			continue
+		}
+		if item.Replace {
+			if err := agentSvc.DeleteAgent(ctx, db, schema, name); err != nil {
+				return applied, fmt.Errorf("replace %s: delete: %w", name, err)
+			}
+		}
+
+		if !item.Exists || item.Replace {
 			if err := agentSvc.CreateAgent(ctx, db, schema, item.Parsed.Spec); err != nil {
 				return applied, fmt.Errorf("create %s: %w", name, err)
 			}
@@ -76,11 +86,23 @@ func applyGrantDiff(
 	}
 	var errs []string
 	for _, e := range gd.ToRevoke {
+		if e.GrantOptionOnly {
+			if err := grantSvc.RevokeGrantOption(ctx, db, schema, agentName, e.RoleType, e.RoleName, e.Privilege); err != nil {
+				errs = append(errs, fmt.Sprintf("REVOKE GRANT OPTION FOR %s FROM %s %s: %v", e.Privilege, e.RoleType, e.RoleName, err))
+			}
+			continue
+		}
 		if err := grantSvc.ExecuteRevoke(ctx, db, schema, agentName, e.RoleType, e.RoleName, e.Privilege); err != nil {
 			errs = append(errs, fmt.Sprintf("REVOKE %s FROM %s %s: %v", e.Privilege, e.RoleType, e.RoleName, err))
 		}
 	}
 	for _, e := range gd.ToGrant {
+		if e.GrantOption {
+			if err := grantSvc.ExecuteGrantWithGrantOption(ctx, db, schema, agentName, e.RoleType, e.RoleName, e.Privilege); err != nil {
+				errs = append(errs, fmt.Sprintf("GRANT %s TO %s %s WITH GRANT OPTION: %v", e.Privilege, e.RoleType, e.RoleName, err))
+			}
+			continue
+		}
 		if err := grantSvc.ExecuteGrant(ctx, db, schema, agentName, e.RoleType, e.RoleName, e.Privilege); err != nil {
 			errs = append(errs, fmt.Sprintf("GRANT %s TO %s %s: %v", e.Privilege, e.RoleType, e.RoleName, err))
 		}
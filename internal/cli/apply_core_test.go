@@ -3,13 +3,16 @@ package cli
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"strings"
 	"testing"
 
 	"coragent/internal/agent"
 	"coragent/internal/api"
+	"coragent/internal/auth"
 	"coragent/internal/diff"
 	"coragent/internal/grant"
+	"coragent/internal/regression"
 )
 
 // applyFakeService tracks API calls and supports per-method error injection.
@@ -17,18 +20,25 @@ import (
 type applyFakeService struct {
 	// State
 	Agents map[string]agent.AgentSpec
+	Remote []api.AgentListItem // returned by ListAgents
 
 	// Call tracking
 	CreateCalls []string // agent names passed to CreateAgent
 	UpdateCalls []string // agent names passed to UpdateAgent
 	GrantCalls  []string // "privilege:roleType:roleName" per ExecuteGrant call
 	RevokeCalls []string // "privilege:roleType:roleName" per ExecuteRevoke call
+	DeleteCalls []string // agent names passed to DeleteAgentIfExists
+	// ReplaceDeletes records agent names passed to DeleteAgent.
+	ReplaceDeletes []string
+	RenameCalls    []string // "old->new" per RenameAgent call
 
 	// Error injection
 	CreateErr error
 	UpdateErr error
 	GrantErr  error
 	RevokeErr error
+	DeleteErr map[string]error // per agent name
+	RenameErr error
 }
 
 func (f *applyFakeService) key(db, schema, name string) string {
@@ -51,7 +61,26 @@ func (f *applyFakeService) UpdateAgent(_ context.Context, _, _, name string, _ a
 	return nil
 }
 
-func (f *applyFakeService) DeleteAgent(_ context.Context, _, _, _ string) error { return nil }
+func (f *applyFakeService) DeleteAgent(_ context.Context, _, _, name string) error {
+	f.ReplaceDeletes = append(f.ReplaceDeletes, name)
+	return nil
+}
+
+func (f *applyFakeService) DeleteAgentIfExists(_ context.Context, _, _, name string) error {
+	if err := f.DeleteErr[name]; err != nil {
+		return err
+	}
+	f.DeleteCalls = append(f.DeleteCalls, name)
+	return nil
+}
+
+func (f *applyFakeService) RenameAgent(_ context.Context, _, _, oldName, newName string) error {
+	if f.RenameErr != nil {
+		return f.RenameErr
+	}
+	f.RenameCalls = append(f.RenameCalls, oldName+"->"+newName)
+	return nil
+}
 
 func (f *applyFakeService) GetAgent(_ context.Context, db, schema, name string) (agent.AgentSpec, bool, error) {
 	spec, ok := f.Agents[f.key(db, schema, name)]
@@ -63,7 +92,7 @@ func (f *applyFakeService) DescribeAgent(_ context.Context, _, _, _ string) (api
 }
 
 func (f *applyFakeService) ListAgents(_ context.Context, _, _ string) ([]api.AgentListItem, error) {
-	return nil, nil
+	return f.Remote, nil
 }
 
 func (f *applyFakeService) ShowGrants(_ context.Context, _, _, _ string) ([]api.ShowGrantsRow, error) {
@@ -78,6 +107,22 @@ func (f *applyFakeService) ExecuteGrant(_ context.Context, _, _, _, roleType, ro
 	return nil
 }
 
+func (f *applyFakeService) ExecuteGrantWithGrantOption(_ context.Context, _, _, _, roleType, roleName, privilege string) error {
+	if f.GrantErr != nil {
+		return f.GrantErr
+	}
+	f.GrantCalls = append(f.GrantCalls, privilege+":"+roleType+":"+roleName+":WITH GRANT OPTION")
+	return nil
+}
+
+func (f *applyFakeService) RevokeGrantOption(_ context.Context, _, _, _, roleType, roleName, privilege string) error {
+	if f.RevokeErr != nil {
+		return f.RevokeErr
+	}
+	f.RevokeCalls = append(f.RevokeCalls, "GRANT OPTION FOR "+privilege+":"+roleType+":"+roleName)
+	return nil
+}
+
 func (f *applyFakeService) ExecuteRevoke(_ context.Context, _, _, _, roleType, roleName, privilege string) error {
 	if f.RevokeErr != nil {
 		return f.RevokeErr
@@ -125,7 +170,7 @@ func TestExecuteApply_CreateWithToolResources(t *testing.T) {
 	svc := &applyFakeService{}
 	item := newApplyItem("new-agent", false, nil, grant.GrantDiff{})
 	item.Parsed.Spec.ToolResources = agent.ToolResources{
-		"analyst_tool": {"execution_environment": map[string]any{"type": "warehouse", "warehouse": ""}},
+		"analyst_tool": map[string]any{"execution_environment": map[string]any{"type": "warehouse", "warehouse": ""}},
 	}
 
 	applied, err := executeApply(context.Background(), []applyItem{item}, svc, svc)
@@ -165,6 +210,70 @@ func TestExecuteApply_Update(t *testing.T) {
 	}
 }
 
+// TestExecuteApply_ToolCommentRoundTrip verifies that description and
+// comment keys inside tool_spec reach both the create and the update payload
+// and come back from DESCRIBE AGENT without diffs or unmapped-key warnings.
+func TestExecuteApply_ToolCommentRoundTrip(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	client := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	ctx := context.Background()
+
+	item := newApplyItem("tool-docs", false, nil, grant.GrantDiff{})
+	item.Parsed.Spec.Tools = []agent.Tool{{ToolSpec: map[string]any{
+		"type":        "cortex_analyst_text_to_sql",
+		"name":        "analyst",
+		"description": "Answers sales questions",
+		"comment":     "Owned by the sales team",
+	}}}
+	if _, err := executeApply(ctx, []applyItem{item}, client, client); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	remote, err := client.DescribeAgent(ctx, "DB", "PUBLIC", "tool-docs")
+	if err != nil {
+		t.Fatalf("DescribeAgent: %v", err)
+	}
+	if len(remote.UnmappedSpecKeys) != 0 {
+		t.Errorf("unexpected unmapped spec keys: %v", remote.UnmappedSpecKeys)
+	}
+	if got := remote.Spec.Tools[0].ToolSpec["comment"]; got != "Owned by the sales team" {
+		t.Errorf("created tool comment = %v", got)
+	}
+	changes, err := diff.Diff(item.Parsed.Spec, remote.Spec)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes after create, got %+v", changes)
+	}
+
+	item.Parsed.Spec.Tools[0].ToolSpec["comment"] = "Owned by finance"
+	item.Exists = true
+	item.Changes, err = diff.Diff(item.Parsed.Spec, remote.Spec)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(item.Changes) != 1 || !strings.HasSuffix(item.Changes[0].Path, "tool_spec.comment") {
+		t.Fatalf("expected one tool_spec.comment change, got %+v", item.Changes)
+	}
+	if _, err := executeApply(ctx, []applyItem{item}, client, client); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	remote, err = client.DescribeAgent(ctx, "DB", "PUBLIC", "tool-docs")
+	if err != nil {
+		t.Fatalf("DescribeAgent: %v", err)
+	}
+	tool := remote.Spec.Tools[0].ToolSpec
+	if tool["comment"] != "Owned by finance" || tool["description"] != "Answers sales questions" {
+		t.Errorf("updated tool_spec = %v", tool)
+	}
+}
+
 // TestExecuteApply_NoChange verifies that an unchanged existing item is not
 // returned in applied items and does not call Create or Update.
 func TestExecuteApply_NoChange(t *testing.T) {
@@ -226,6 +335,57 @@ func TestExecuteApply_GrantsOnNoChange(t *testing.T) {
 	}
 }
 
+// TestExecuteApply_Replace verifies that a Replace item deletes the agent,
+// creates it again from the spec and applies every desired grant.
+func TestExecuteApply_Replace(t *testing.T) {
+	svc := &applyFakeService{}
+	changes := []diff.Change{{Path: "comment", Type: diff.Modified, Before: "old", After: "new"}}
+	gd := grant.GrantDiff{
+		ToGrant: []grant.GrantEntry{
+			{Privilege: "USAGE", RoleType: "ROLE", RoleName: "ANALYST"},
+		},
+	}
+	item := newApplyItem("replaced", true, changes, gd)
+	item.Replace = true
+
+	applied, err := executeApply(context.Background(), []applyItem{item}, svc, svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("expected 1 applied item, got %d", len(applied))
+	}
+	if len(svc.ReplaceDeletes) != 1 || svc.ReplaceDeletes[0] != "replaced" {
+		t.Errorf("DeleteAgent calls = %v, want [replaced]", svc.ReplaceDeletes)
+	}
+	if len(svc.CreateCalls) != 1 || svc.CreateCalls[0] != "replaced" {
+		t.Errorf("CreateCalls = %v, want [replaced]", svc.CreateCalls)
+	}
+	if len(svc.UpdateCalls) != 0 {
+		t.Errorf("unexpected UpdateCalls: %v", svc.UpdateCalls)
+	}
+	if len(svc.GrantCalls) != 1 || svc.GrantCalls[0] != "USAGE:ROLE:ANALYST" {
+		t.Errorf("GrantCalls = %v, want [USAGE:ROLE:ANALYST]", svc.GrantCalls)
+	}
+}
+
+// TestExecuteApply_Skipped verifies that a create_only agent that already
+// exists is not touched at all.
+func TestExecuteApply_Skipped(t *testing.T) {
+	svc := &applyFakeService{}
+	item := newApplyItem("managed-elsewhere", true, nil, grant.GrantDiff{})
+	item.Skipped = true
+
+	applied, err := executeApply(context.Background(), []applyItem{item}, svc, svc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 0 || len(svc.CreateCalls)+len(svc.UpdateCalls)+len(svc.ReplaceDeletes)+len(svc.GrantCalls) != 0 {
+		t.Errorf("expected no calls, got applied=%d create=%v update=%v delete=%v grant=%v",
+			len(applied), svc.CreateCalls, svc.UpdateCalls, svc.ReplaceDeletes, svc.GrantCalls)
+	}
+}
+
 // TestExecuteApply_CreateError verifies that CreateAgent errors are propagated.
 func TestExecuteApply_CreateError(t *testing.T) {
 	svc := &applyFakeService{CreateErr: fmt.Errorf("API unavailable")}
@@ -330,6 +490,29 @@ func TestApplyGrantDiff_Revoke(t *testing.T) {
 	}
 }
 
+// TestApplyGrantDiff_GrantOption verifies that grant option changes use the
+// WITH GRANT OPTION and REVOKE GRANT OPTION FOR statements.
+func TestApplyGrantDiff_GrantOption(t *testing.T) {
+	svc := &applyFakeService{}
+	gd := grant.GrantDiff{
+		ToGrant: []grant.GrantEntry{
+			{Privilege: "USAGE", RoleType: "ROLE", RoleName: "ADMIN", GrantOption: true},
+		},
+		ToRevoke: []grant.GrantEntry{
+			{Privilege: "USAGE", RoleType: "ROLE", RoleName: "ANALYST", GrantOption: true, GrantOptionOnly: true},
+		},
+	}
+	if err := applyGrantDiff(context.Background(), svc, "DB", "S", "agent", gd); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(svc.GrantCalls) != 1 || svc.GrantCalls[0] != "USAGE:ROLE:ADMIN:WITH GRANT OPTION" {
+		t.Errorf("GrantCalls = %v", svc.GrantCalls)
+	}
+	if len(svc.RevokeCalls) != 1 || svc.RevokeCalls[0] != "GRANT OPTION FOR USAGE:ROLE:ANALYST" {
+		t.Errorf("RevokeCalls = %v", svc.RevokeCalls)
+	}
+}
+
 // TestApplyGrantDiff_GrantError verifies that ExecuteGrant errors are collected
 // and returned as a combined error.
 func TestApplyGrantDiff_GrantError(t *testing.T) {
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"coragent/internal/api"
+
+	"github.com/fatih/color"
+)
+
+// pruneItem is a deployed agent that has no local spec.
+type pruneItem struct {
+	Target Target
+	Name   string
+}
+
+// checkPruneScope rejects --prune unless path is a directory loaded with
+// --recursive. Prune treats the loaded specs as the complete set for every
+// targeted schema, so a single file or a non-recursive load would delete
+// the agents whose specs live elsewhere.
+func checkPruneScope(path string, recursive bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return UserErr(err)
+	}
+	if !info.IsDir() {
+		return UserErr(fmt.Errorf("--prune requires a directory: a single file cannot tell which deployed agents to keep"))
+	}
+	if !recursive {
+		return UserErr(fmt.Errorf("--prune requires -R/--recursive so that agents with specs in subdirectories are not deleted"))
+	}
+	return nil
+}
+
+// findPruneCandidates lists the agents of every database/schema targeted by
+// items and returns those without a local spec, sorted by schema and name.
+// Only schemas that at least one local spec deploys to are considered.
+// Names are compared case-insensitively, so an agent is never pruned just
+// because Snowflake reports an unquoted name in upper case.
+func findPruneCandidates(ctx context.Context, items []applyItem, agentSvc api.AgentService) ([]pruneItem, error) {
+	local := map[Target]map[string]bool{}
+	var targets []Target
+	for _, item := range items {
+		names, ok := local[item.Target]
+		if !ok {
+			names = map[string]bool{}
+			local[item.Target] = names
+			targets = append(targets, item.Target)
+		}
+		names[strings.ToUpper(unquoteIdentifier(item.Parsed.Spec.Name))] = true
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Database != targets[j].Database {
+			return targets[i].Database < targets[j].Database
+		}
+		return targets[i].Schema < targets[j].Schema
+	})
+
+	var candidates []pruneItem
+	for _, target := range targets {
+		remote, err := agentSvc.ListAgents(ctx, target.Database, target.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("list agents in %s.%s: %w", target.Database, target.Schema, err)
+		}
+		var names []string
+		for _, a := range remote {
+			if !local[target][strings.ToUpper(unquoteIdentifier(a.Name))] {
+				names = append(names, a.Name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			candidates = append(candidates, pruneItem{Target: target, Name: name})
+		}
+	}
+	return candidates, nil
+}
+
+// unquoteIdentifier strips surrounding double quotes from an identifier.
+func unquoteIdentifier(name string) string {
+	name = strings.TrimSpace(name)
+	if len(name) >= 2 && strings.HasPrefix(name, `"`) && strings.HasSuffix(name, `"`) {
+		return name[1 : len(name)-1]
+	}
+	return name
+}
+
+// pruneAgents deletes deployed agents that have no local spec after asking
+// for confirmation (skipped with autoApprove). With dryRun it only lists
+// them. Deletion continues past failures and reports them at the end.
+func pruneAgents(ctx context.Context, w io.Writer, in io.Reader, items []applyItem, agentSvc api.AgentService, autoApprove, dryRun bool) error {
+	candidates, err := findPruneCandidates(ctx, items, agentSvc)
+	if err != nil {
+		return fmt.Errorf("prune: %w", err)
+	}
+	if len(candidates) == 0 {
+		fmt.Fprintln(w, "\nPrune: no deployed agents without a local spec.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nPrune: %d deployed agent(s) have no local spec:\n", len(candidates))
+	for _, c := range candidates {
+		fmt.Fprintf(w, "  %s %s.%s.%s\n", color.New(color.FgRed).Sprint("-"), c.Target.Database, c.Target.Schema, c.Name)
+	}
+	if dryRun {
+		fmt.Fprintln(w, "Dry run: no agents were deleted.")
+		return nil
+	}
+	if !autoApprove {
+		if !confirm("Delete these agents?", in) {
+			fmt.Fprintln(w, "Prune aborted.")
+			return nil
+		}
+	}
+
+	var failed []string
+	for _, c := range candidates {
+		fmt.Fprintf(w, "Deleting %s... ", c.Name)
+		if err := agentSvc.DeleteAgentIfExists(ctx, c.Target.Database, c.Target.Schema, c.Name); err != nil {
+			fmt.Fprintln(w, "failed")
+			failed = append(failed, fmt.Sprintf("%s: %v", c.Name, err))
+			continue
+		}
+		color.New(color.FgGreen).Fprintln(w, "done")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("prune: %d of %d deletions failed:\n  %s", len(failed), len(candidates), strings.Join(failed, "\n  "))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"coragent/internal/api"
+	"coragent/internal/grant"
+)
+
+func TestFindPruneCandidates(t *testing.T) {
+	svc := &applyFakeService{Remote: []api.AgentListItem{
+		{Name: "SALES_AGENT"},
+		{Name: "old-agent"},
+		{Name: "LEGACY"},
+		{Name: "support-agent"},
+	}}
+	items := []applyItem{
+		newApplyItem("sales_agent", true, nil, grant.GrantDiff{}),
+		newApplyItem(`"support-agent"`, true, nil, grant.GrantDiff{}),
+	}
+
+	got, err := findPruneCandidates(context.Background(), items, svc)
+	if err != nil {
+		t.Fatalf("findPruneCandidates: %v", err)
+	}
+	var names []string
+	for _, c := range got {
+		names = append(names, c.Name)
+		if c.Target.Database != "DB" || c.Target.Schema != "PUBLIC" {
+			t.Errorf("candidate %s target = %+v", c.Name, c.Target)
+		}
+	}
+	if strings.Join(names, ",") != "LEGACY,old-agent" {
+		t.Errorf("candidates = %v, want [LEGACY old-agent]", names)
+	}
+}
+
+func TestPruneAgents(t *testing.T) {
+	items := []applyItem{newApplyItem("KEEP", true, nil, grant.GrantDiff{})}
+	remote := []api.AgentListItem{{Name: "KEEP"}, {Name: "STALE_A"}, {Name: "STALE_B"}}
+
+	t.Run("confirmed", func(t *testing.T) {
+		svc := &applyFakeService{Remote: remote}
+		var out bytes.Buffer
+		if err := pruneAgents(context.Background(), &out, strings.NewReader("y\n"), items, svc, false, false); err != nil {
+			t.Fatalf("pruneAgents: %v", err)
+		}
+		if strings.Join(svc.DeleteCalls, ",") != "STALE_A,STALE_B" {
+			t.Errorf("deleted = %v", svc.DeleteCalls)
+		}
+		if !strings.Contains(out.String(), "2 deployed agent(s) have no local spec") {
+			t.Errorf("output:\n%s", out.String())
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		svc := &applyFakeService{Remote: remote}
+		var out bytes.Buffer
+		if err := pruneAgents(context.Background(), &out, strings.NewReader("n\n"), items, svc, false, false); err != nil {
+			t.Fatalf("pruneAgents: %v", err)
+		}
+		if len(svc.DeleteCalls) != 0 || !strings.Contains(out.String(), "Prune aborted.") {
+			t.Errorf("deleted = %v, output:\n%s", svc.DeleteCalls, out.String())
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		svc := &applyFakeService{Remote: remote}
+		var out bytes.Buffer
+		if err := pruneAgents(context.Background(), &out, strings.NewReader(""), items, svc, true, true); err != nil {
+			t.Fatalf("pruneAgents: %v", err)
+		}
+		if len(svc.DeleteCalls) != 0 || !strings.Contains(out.String(), "DB.PUBLIC.STALE_A") {
+			t.Errorf("deleted = %v, output:\n%s", svc.DeleteCalls, out.String())
+		}
+	})
+
+	t.Run("failure continues", func(t *testing.T) {
+		svc := &applyFakeService{Remote: remote, DeleteErr: map[string]error{"STALE_A": errors.New("insufficient privileges")}}
+		var out bytes.Buffer
+		err := pruneAgents(context.Background(), &out, strings.NewReader(""), items, svc, true, false)
+		if err == nil || !strings.Contains(err.Error(), "1 of 2 deletions failed") {
+			t.Fatalf("error = %v, want 1 of 2 failed", err)
+		}
+		if strings.Join(svc.DeleteCalls, ",") != "STALE_B" {
+			t.Errorf("deleted = %v, want [STALE_B]", svc.DeleteCalls)
+		}
+	})
+
+	t.Run("nothing to prune", func(t *testing.T) {
+		svc := &applyFakeService{Remote: []api.AgentListItem{{Name: "KEEP"}}}
+		var out bytes.Buffer
+		if err := pruneAgents(context.Background(), &out, strings.NewReader(""), items, svc, false, false); err != nil {
+			t.Fatalf("pruneAgents: %v", err)
+		}
+		if !strings.Contains(out.String(), "no deployed agents without a local spec") {
+			t.Errorf("output:\n%s", out.String())
+		}
+	})
+}
+
+func TestApplyCmd_PruneRequiresRecursiveDirectory(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	if err := os.WriteFile(path, []byte("name: one-agent\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"file", []string{path, "-R"}, "--prune requires a directory"},
+		{"directory without -R", []string{dir}, "--prune requires -R/--recursive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := ms.Requests()
+			root, _ := newRootCmd()
+			root.SetOut(&bytes.Buffer{})
+			root.SetArgs(append([]string{"apply", "--prune", "-y", "-d", "DB", "-s", "SCH"}, tt.args...))
+			err := root.Execute()
+			if !IsUserError(err) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected %q user error, got %v", tt.want, err)
+			}
+			if n := ms.Requests() - before; n != 0 {
+				t.Errorf("rejected prune sent %d request(s), want 0", n)
+			}
+		})
+	}
+}
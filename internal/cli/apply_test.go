@@ -2,13 +2,19 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"coragent/internal/agent"
 	"coragent/internal/api"
+	"coragent/internal/auth"
 	"coragent/internal/diff"
 	"coragent/internal/grant"
+	"coragent/internal/regression"
 )
 
 func TestTopLevel(t *testing.T) {
@@ -214,7 +220,7 @@ func TestWritePlanPreview_ApplyShowsGrantOnlyUpdatesAndHidesUnchanged(t *testing
 	}
 
 	var buf bytes.Buffer
-	summary, err := writePlanPreview(&buf, items)
+	summary, err := writePlanPreview(&buf, items, false)
 	if err != nil {
 		t.Fatalf("writePlanPreview: %v", err)
 	}
@@ -236,3 +242,98 @@ func TestWritePlanPreview_ApplyShowsGrantOnlyUpdatesAndHidesUnchanged(t *testing
 		t.Fatalf("unexpected summary: %+v", summary)
 	}
 }
+
+func TestWriteDryRunActions(t *testing.T) {
+	items := []applyItem{
+		{
+			Parsed: agent.ParsedAgent{Spec: agent.AgentSpec{Name: "NEW"}},
+			GrantDiff: grant.GrantDiff{
+				ToGrant: []grant.GrantEntry{{Privilege: "USAGE", RoleType: "ROLE", RoleName: "ANALYST"}},
+			},
+		},
+		{
+			Parsed: agent.ParsedAgent{Spec: agent.AgentSpec{Name: "GRANT_ONLY"}},
+			Exists: true,
+			GrantDiff: grant.GrantDiff{
+				ToRevoke: []grant.GrantEntry{
+					{Privilege: "USAGE", RoleType: "ROLE", RoleName: "OLD"},
+					{Privilege: "MONITOR", RoleType: "DATABASE ROLE", RoleName: "DB.MON", GrantOptionOnly: true},
+				},
+			},
+		},
+		{
+			Parsed: agent.ParsedAgent{Spec: agent.AgentSpec{Name: "QUIET"}},
+			Exists: true,
+		},
+	}
+
+	var buf bytes.Buffer
+	unchanged := writeDryRunActions(&buf, items, true)
+	out := buf.String()
+	if unchanged != 1 {
+		t.Errorf("unchanged = %d, want 1", unchanged)
+	}
+	for _, want := range []string{
+		"Would create NEW",
+		"would grant USAGE to ROLE ANALYST",
+		"Would update grants of GRANT_ONLY",
+		"would revoke USAGE from ROLE OLD",
+		"would revoke GRANT OPTION FOR MONITOR from DATABASE ROLE DB.MON",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "QUIET") {
+		t.Errorf("unchanged agent should be omitted with onlyChanged:\n%s", out)
+	}
+}
+
+func TestApplyDryRun_DoesNotMutateMock(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SNOWFLAKE_HOME", home)
+	t.Setenv("CORAGENT_API_BASE_URL", ms.URL())
+	t.Setenv("SNOWFLAKE_ACCOUNT", "TEST")
+	t.Setenv("SNOWFLAKE_TOKEN", "tok")
+
+	specPath := filepath.Join(t.TempDir(), "agent.yaml")
+	spec := "name: dry-agent\ndeploy:\n  grant:\n    account_roles:\n      - role: ANALYST\n        privileges: [USAGE]\n"
+	if err := os.WriteFile(specPath, []byte(spec), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newApplyCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetArgs([]string{specPath, "--dry-run", "-y"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply --dry-run: %v", err)
+	}
+
+	check := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	if _, exists, err := check.GetAgent(context.Background(), "DB", "SCH", "dry-agent"); err != nil || exists {
+		t.Fatalf("GetAgent after dry run: exists=%v err=%v", exists, err)
+	}
+	rows, err := check.ShowGrants(context.Background(), "DB", "SCH", "dry-agent")
+	if err != nil {
+		t.Fatalf("ShowGrants: %v", err)
+	}
+	if len(rows) != 0 {
+		t.Errorf("dry run issued grants: %+v", rows)
+	}
+}
+
+func TestApplyDryRun_RejectsEval(t *testing.T) {
+	cmd := newApplyCmd(&RootOptions{})
+	cmd.SetArgs([]string{"--dry-run", "--eval"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--dry-run cannot be combined with --eval") {
+		t.Fatalf("err = %v", err)
+	}
+}
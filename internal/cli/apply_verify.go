@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"coragent/internal/api"
+	"coragent/internal/diff"
+
+	"github.com/fatih/color"
+)
+
+// verifyApplied re-fetches each created or updated agent and diffs it against
+// its local spec, so that fields the server silently normalized or ignored
+// show up right after apply instead of as a plan that never converges.
+// Residual differences and failed fetches are reported as warnings on w; it
+// returns the number of agents that did not match.
+func verifyApplied(ctx context.Context, w io.Writer, items []applyItem, agentSvc api.AgentService) int {
+	if len(items) == 0 {
+		return 0
+	}
+	fmt.Fprintf(w, "\nVerifying %d agent(s)...\n", len(items))
+	warn := color.New(color.FgYellow)
+	mismatched := 0
+	for _, item := range items {
+		name := item.Parsed.Spec.Name
+		remote, exists, err := agentSvc.GetAgent(ctx, item.Target.Database, item.Target.Schema, name)
+		if err == nil && !exists {
+			err = fmt.Errorf("agent not found after apply")
+		}
+		var changes []diff.Change
+		if err == nil {
+			changes, err = diff.DiffWithOptions(item.Parsed.Spec, remote, diff.Options{MatchArraysByKey: true})
+		}
+		if err != nil {
+			mismatched++
+			warn.Fprintf(w, "Warning: could not verify %s: %v\n", name, err)
+			continue
+		}
+		if !diff.HasChanges(changes) {
+			continue
+		}
+		mismatched++
+		warn.Fprintf(w, "Warning: %s differs from its spec after apply (the server may normalize or ignore these fields):\n", name)
+		for _, c := range changes {
+			writePlanChange(w, c)
+		}
+	}
+	if mismatched == 0 {
+		color.New(color.FgGreen).Fprintf(w, "Verified: %d agent(s) match their spec.\n", len(items))
+	}
+	return mismatched
+}
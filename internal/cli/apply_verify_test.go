@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
+	"coragent/internal/grant"
+	"coragent/internal/regression"
+)
+
+// TestVerifyApplied_ReportsServerNormalization applies an agent to a mock
+// server that drops the comment and lowercases the orchestration model, and
+// checks that verification reports both residual differences.
+func TestVerifyApplied_ReportsServerNormalization(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	ms.SetStoreHook(func(payload map[string]any) {
+		delete(payload, "comment")
+		if models, ok := payload["models"].(map[string]any); ok {
+			if m, ok := models["orchestration"].(string); ok {
+				models["orchestration"] = strings.ToLower(m)
+			}
+		}
+	})
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	client := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+
+	item := newApplyItem("normalized-agent", false, nil, grant.GrantDiff{})
+	item.Parsed.Spec.Comment = "kept locally"
+	item.Parsed.Spec.Models = &agent.Models{Orchestration: "CLAUDE-4-SONNET"}
+	applied, err := executeApply(context.Background(), []applyItem{item}, client, client)
+	if err != nil {
+		t.Fatalf("executeApply: %v", err)
+	}
+
+	var out bytes.Buffer
+	if got := verifyApplied(context.Background(), &out, applied, client); got != 1 {
+		t.Errorf("verifyApplied = %d, want 1\n%s", got, out.String())
+	}
+	for _, want := range []string{
+		"Warning: normalized-agent differs from its spec after apply",
+		"comment",
+		"models.orchestration",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestVerifyApplied_Converged(t *testing.T) {
+	item := newApplyItem("steady", true, nil, grant.GrantDiff{})
+	item.Parsed.Spec.Comment = "same"
+	svc := &applyFakeService{Agents: map[string]agent.AgentSpec{
+		"DB.PUBLIC.steady": {Name: "steady", Comment: "same"},
+	}}
+
+	var out bytes.Buffer
+	if got := verifyApplied(context.Background(), &out, []applyItem{item}, svc); got != 0 {
+		t.Errorf("verifyApplied = %d, want 0\n%s", got, out.String())
+	}
+	if !strings.Contains(out.String(), "Verified: 1 agent(s) match their spec.") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
+
+func TestVerifyApplied_MissingAgent(t *testing.T) {
+	item := newApplyItem("vanished", true, nil, grant.GrantDiff{})
+	svc := &applyFakeService{}
+
+	var out bytes.Buffer
+	if got := verifyApplied(context.Background(), &out, []applyItem{item}, svc); got != 1 {
+		t.Errorf("verifyApplied = %d, want 1", got)
+	}
+	if !strings.Contains(out.String(), "could not verify vanished: agent not found after apply") {
+		t.Errorf("output:\n%s", out.String())
+	}
+}
@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"strings"
 	"time"
 
+	"coragent/internal/api"
 	"coragent/internal/auth"
 
 	"github.com/spf13/cobra"
@@ -18,13 +24,16 @@ func newAuthCmd(opts *RootOptions) *cobra.Command {
 	}
 
 	cmd.AddCommand(newAuthStatusCmd(opts))
+	cmd.AddCommand(newAuthTestCmd(opts))
 	cmd.AddCommand(newAuthInitCmd(opts))
+	cmd.AddCommand(newLogoutCmd(opts))
 
 	return cmd
 }
 
 type authStatusOptions struct {
 	account string
+	format  string
 }
 
 func newAuthStatusCmd(opts *RootOptions) *cobra.Command {
@@ -40,24 +49,136 @@ Example:
   coragent auth status
 
   # Show status for specific account
-  coragent auth status --account myaccount`,
+  coragent auth status --account myaccount
+
+  # Export the resolved connection (without secrets) into the current shell
+  eval "$(coragent auth status --format env)"`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runAuthStatus(opts, statusOpts)
+			switch statusOpts.format {
+			case "text":
+				return runAuthStatus(cmd.OutOrStdout(), opts, statusOpts)
+			case "env":
+				cfg := auth.LoadConfig(opts.Connection)
+				applyAuthOverrides(&cfg, opts)
+				if statusOpts.account != "" {
+					cfg.Account = statusOpts.account
+				}
+				return writeAuthEnv(cmd.OutOrStdout(), cfg)
+			}
+			return UserErr(fmt.Errorf("invalid --format %q (valid: text, env)", statusOpts.format))
 		},
 	}
 
 	cmd.Flags().StringVarP(&statusOpts.account, "account", "a", "", "Snowflake account to check (overrides global flag)")
+	cmd.Flags().StringVar(&statusOpts.format, "format", "text", "Output format: text, or env for shell export lines of the non-secret settings")
 
 	return cmd
 }
 
-func runAuthStatus(rootOpts *RootOptions, opts *authStatusOptions) error {
+func newAuthTestCmd(opts *RootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "test",
+		Short: "Verify credentials with a live SQL round-trip",
+		Long: `Authenticate with the resolved connection and run a harmless SELECT
+through the SQL API to confirm that the credentials are accepted and the
+role and warehouse can be used.
+
+Unlike 'auth status', which only inspects local configuration, this makes
+a real request. On failure the error is labeled as a configuration,
+credentials, permission or network problem.`,
+		Example: `  # Test the default connection
+  coragent auth test
+
+  # Test a named connection with a specific role
+  coragent auth test -c prod -r CORTEX_USER`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, cfg, err := buildClientAndCfg(opts)
+			if err != nil {
+				return UserErr(fmt.Errorf("auth test failed (configuration): %w", err))
+			}
+			ctx, cancel := context.WithTimeout(commandContext("auth-test"), 30*time.Second)
+			defer cancel()
+
+			session, err := client.Ping(ctx)
+			if err != nil {
+				writeAuthTestAttempt(cmd.ErrOrStderr(), cfg)
+				return fmt.Errorf("auth test failed (%s): %w", classifyAuthTestError(err), err)
+			}
+			writeAuthTestResult(cmd.OutOrStdout(), session)
+			return nil
+		},
+	}
+}
+
+// classifyAuthTestError names the likely cause of a failed auth test so
+// config, credential, permission and network problems can be told apart.
+func classifyAuthTestError(err error) string {
+	var apiErr api.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized:
+			return "credentials"
+		case apiErr.StatusCode == http.StatusForbidden, apiErr.StatusCode == http.StatusUnprocessableEntity:
+			return "permission"
+		case apiErr.StatusCode >= 500:
+			return "server"
+		}
+		return "request"
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return "network"
+	}
+	// Remaining errors come from obtaining a token (key pair, OAuth) before
+	// any request is sent.
+	return "credentials"
+}
+
+// writeAuthTestAttempt prints the configured settings a failed auth test
+// used, so a wrong account, role or warehouse is easy to spot.
+func writeAuthTestAttempt(w io.Writer, cfg auth.Config) {
+	fmt.Fprintf(w, "Account:   %s\n", orNotSet(cfg.Account))
+	fmt.Fprintf(w, "Role:      %s\n", orNotSet(cfg.Role))
+	fmt.Fprintf(w, "Warehouse: %s\n", orNotSet(cfg.Warehouse))
+}
+
+// orNotSet returns v, or "(not set)" when it is empty.
+func orNotSet(v string) string {
+	if v == "" {
+		return "(not set)"
+	}
+	return v
+}
+
+// writeAuthTestResult prints the session resolved by a successful auth test.
+func writeAuthTestResult(w io.Writer, s api.Session) {
+	warehouse := s.Warehouse
+	if warehouse == "" {
+		warehouse = "(none)"
+	}
+	fmt.Fprintln(w, "Authentication OK")
+	fmt.Fprintf(w, "Account:   %s\n", s.Account)
+	fmt.Fprintf(w, "User:      %s\n", s.User)
+	fmt.Fprintf(w, "Role:      %s\n", s.Role)
+	fmt.Fprintf(w, "Warehouse: %s\n", warehouse)
+}
+
+func runAuthStatus(w io.Writer, rootOpts *RootOptions, opts *authStatusOptions) error {
 	cfg := auth.LoadConfig(rootOpts.Connection)
 	applyAuthOverrides(&cfg, rootOpts)
 
 	// Run diagnostics
 	diag := auth.DiagnoseConfig(rootOpts.Connection)
 
+	// The OAuth client secret is not part of auth.Config; read it from the
+	// connection so it can be reported masked.
+	var clientSecret string
+	if conn, err := auth.LoadSnowflakeConnection(rootOpts.Connection); err == nil && conn != nil {
+		clientSecret = conn.OAuthClientSecret
+	}
+	secrets := authStatusSecrets(cfg, clientSecret)
+
 	// Determine account
 	account := opts.account
 	if account == "" {
@@ -70,18 +191,18 @@ func runAuthStatus(rootOpts *RootOptions, opts *authStatusOptions) error {
 		authenticator = auth.AuthenticatorKeyPair
 	}
 
-	fmt.Println("Authentication Status")
-	fmt.Println("=====================")
-	fmt.Println()
+	fmt.Fprintln(w, "Authentication Status")
+	fmt.Fprintln(w, "=====================")
+	fmt.Fprintln(w)
 
 	// Show config file info
 	if diag.ConfigPath != "" {
-		fmt.Printf("Config:  %s\n", diag.ConfigPath)
+		fmt.Fprintf(w, "Config:  %s\n", diag.ConfigPath)
 	} else {
-		fmt.Println("Config:  (none found)")
+		fmt.Fprintln(w, "Config:  (none found)")
 	}
 	if diag.ConnectionName != "" {
-		fmt.Printf("Conn:    %s\n", diag.ConnectionName)
+		fmt.Fprintf(w, "Conn:    %s\n", diag.ConnectionName)
 	}
 
 	// Show diagnostic issues
@@ -89,64 +210,172 @@ func runAuthStatus(rootOpts *RootOptions, opts *authStatusOptions) error {
 	for _, msg := range diag.Messages {
 		if msg.Level >= auth.DiagWarning {
 			if !hasIssues {
-				fmt.Println()
-				fmt.Println("Issues:")
+				fmt.Fprintln(w)
+				fmt.Fprintln(w, "Issues:")
 				hasIssues = true
 			}
 			prefix := "[WARNING]"
 			if msg.Level == auth.DiagError {
 				prefix = "[ERROR]"
 			}
-			fmt.Printf("  %s %s\n", prefix, msg.Message)
+			fmt.Fprintf(w, "  %s %s\n", prefix, redactSecrets(msg.Message, secrets))
 		}
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
 
 	if account == "" {
-		fmt.Println("Account: (not configured)")
-		fmt.Println()
-		fmt.Println("Set SNOWFLAKE_ACCOUNT or use --account flag")
+		fmt.Fprintln(w, "Account: (not configured)")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Set SNOWFLAKE_ACCOUNT or use --account flag")
 		return nil
 	}
 
-	fmt.Printf("Account: %s\n", strings.ToUpper(account))
-	fmt.Printf("Method:  %s\n", authenticator)
-	fmt.Println()
+	fmt.Fprintf(w, "Account: %s\n", strings.ToUpper(account))
+	if cfg.SessionToken != "" {
+		fmt.Fprintln(w, "Method:  TOKEN (SNOWFLAKE_TOKEN)")
+		writeMaskedSecrets(w, secrets)
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Status:  Configured")
+		return nil
+	}
+	fmt.Fprintf(w, "Method:  %s\n", authenticator)
+	writeMaskedSecrets(w, secrets)
+	fmt.Fprintln(w)
 
 	switch authenticator {
 	case auth.AuthenticatorKeyPair:
-		return showKeyPairStatus(cfg)
+		return showKeyPairStatus(w, cfg)
 	case auth.AuthenticatorOAuth:
-		return showOAuthStatus(account)
+		return showOAuthStatus(w, account)
 	default:
-		fmt.Printf("Unknown authenticator: %s\n", authenticator)
+		fmt.Fprintf(w, "Unknown authenticator: %s\n", authenticator)
 	}
 
 	return nil
 }
 
-func showKeyPairStatus(cfg auth.Config) error {
+// statusSecret is a credential that auth status reports only in masked form.
+type statusSecret struct {
+	label string
+	value string
+	// whole hides the entire value instead of keeping its last 4
+	// characters; used for private key material and its passphrase, which
+	// are not identifiers a user would recognize by a suffix.
+	whole bool
+}
+
+// authStatusSecrets returns the credentials auth status may mention.
+func authStatusSecrets(cfg auth.Config, clientSecret string) []statusSecret {
+	return []statusSecret{
+		{label: "Token", value: cfg.SessionToken},
+		{label: "Private key", value: cfg.PrivateKey, whole: true},
+		{label: "Key passphrase", value: cfg.PrivateKeyPassphrase, whole: true},
+		{label: "Client secret", value: clientSecret},
+	}
+}
+
+// maskSecret returns "***" followed by the last 4 characters of s, or just
+// "***" when whole is set or s is too short for the suffix to be safe.
+func maskSecret(s string, whole bool) string {
+	if whole || len(s) < 12 {
+		return "***"
+	}
+	return "***" + s[len(s)-4:]
+}
+
+// writeMaskedSecrets lists the configured secrets in masked form so users
+// can confirm which credentials were picked up without revealing them.
+func writeMaskedSecrets(w io.Writer, secrets []statusSecret) {
+	header := false
+	for _, s := range secrets {
+		if s.value == "" {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "Secrets:")
+			header = true
+		}
+		fmt.Fprintf(w, "  %-15s %s\n", s.label+":", maskSecret(s.value, s.whole))
+	}
+}
+
+// redactSecrets replaces any secret value found in s with its mask. Private
+// key material is also redacted line by line, so a fragment quoted in a
+// diagnostic message cannot leak either.
+func redactSecrets(s string, secrets []statusSecret) string {
+	for _, secret := range secrets {
+		value := strings.TrimSpace(secret.value)
+		if len(value) < 4 {
+			continue
+		}
+		s = strings.ReplaceAll(s, value, maskSecret(value, secret.whole))
+		if !secret.whole {
+			continue
+		}
+		for _, line := range strings.Split(value, "\n") {
+			line = strings.TrimSpace(line)
+			if len(line) >= 16 && !strings.HasPrefix(line, "-----") {
+				s = strings.ReplaceAll(s, line, "***")
+			}
+		}
+	}
+	return s
+}
+
+// writeAuthEnv writes the non-secret settings of cfg as POSIX shell export
+// lines. The private key, its passphrase and SNOWFLAKE_TOKEN are never
+// written; empty settings are skipped.
+func writeAuthEnv(w io.Writer, cfg auth.Config) error {
+	vars := []struct{ name, value string }{
+		{"SNOWFLAKE_ACCOUNT", cfg.Account},
+		{"SNOWFLAKE_USER", cfg.User},
+		{"SNOWFLAKE_ROLE", cfg.Role},
+		{"SNOWFLAKE_WAREHOUSE", cfg.Warehouse},
+		{"SNOWFLAKE_DATABASE", cfg.Database},
+		{"SNOWFLAKE_SCHEMA", cfg.Schema},
+		{"SNOWFLAKE_AUTHENTICATOR", cfg.Authenticator},
+	}
+	if strings.EqualFold(cfg.Authenticator, auth.AuthenticatorOAuth) {
+		vars = append(vars, struct{ name, value string }{"SNOWFLAKE_OAUTH_REDIRECT_URI", cfg.OAuthRedirectURI})
+	}
+	for _, v := range vars {
+		if v.value == "" {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "export %s=%s\n", v.name, shellQuote(v.value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shellQuote single-quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func showKeyPairStatus(w io.Writer, cfg auth.Config) error {
 	if cfg.PrivateKey == "" {
-		fmt.Println("Status:  Not configured")
-		fmt.Println()
-		fmt.Println("Missing: Private key")
-		fmt.Println("  Set SNOWFLAKE_PRIVATE_KEY environment variable, or configure")
-		fmt.Println("  private_key_file in ~/.snowflake/config.toml")
+		fmt.Fprintln(w, "Status:  Not configured")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Missing: Private key")
+		fmt.Fprintln(w, "  Set SNOWFLAKE_PRIVATE_KEY environment variable, or configure")
+		fmt.Fprintln(w, "  private_key_file in ~/.snowflake/config.toml")
 	} else if cfg.User == "" {
-		fmt.Println("Status:  Not configured")
-		fmt.Println()
-		fmt.Println("Missing: User")
-		fmt.Println("  Set SNOWFLAKE_USER environment variable, or configure")
-		fmt.Println("  user in ~/.snowflake/config.toml")
+		fmt.Fprintln(w, "Status:  Not configured")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Missing: User")
+		fmt.Fprintln(w, "  Set SNOWFLAKE_USER environment variable, or configure")
+		fmt.Fprintln(w, "  user in ~/.snowflake/config.toml")
 	} else {
-		fmt.Println("Status:  Configured")
-		fmt.Printf("User:    %s\n", strings.ToUpper(cfg.User))
+		fmt.Fprintln(w, "Status:  Configured")
+		fmt.Fprintf(w, "User:    %s\n", strings.ToUpper(cfg.User))
 	}
 
 	return nil
 }
 
-func showOAuthStatus(account string) error {
+func showOAuthStatus(w io.Writer, account string) error {
 	store, err := auth.LoadTokenStore()
 	if err != nil {
 		return fmt.Errorf("load token store: %w", err)
@@ -154,40 +383,40 @@ func showOAuthStatus(account string) error {
 
 	tokens := store.GetTokens(account)
 	if tokens == nil {
-		fmt.Println("Status:  Not authenticated")
-		fmt.Println()
-		fmt.Println("Run 'coragent login' to authenticate with OAuth")
+		fmt.Fprintln(w, "Status:  Not authenticated")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Run 'coragent login' to authenticate with OAuth")
 		return nil
 	}
 
 	now := time.Now()
 	if tokens.IsExpired() {
-		fmt.Println("Status:  Expired")
-		fmt.Printf("Expired: %s (%s ago)\n",
+		fmt.Fprintln(w, "Status:  Expired")
+		fmt.Fprintf(w, "Expired: %s (%s ago)\n",
 			tokens.ExpiresAt.Format(time.RFC3339),
 			formatDuration(now.Sub(tokens.ExpiresAt)))
 
 		if tokens.RefreshToken != "" {
-			fmt.Println()
-			fmt.Println("A refresh token is available. The token will be")
-			fmt.Println("automatically refreshed on next API call.")
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "A refresh token is available. The token will be")
+			fmt.Fprintln(w, "automatically refreshed on next API call.")
 		} else {
-			fmt.Println()
-			fmt.Println("No refresh token available. Run 'coragent login' again.")
+			fmt.Fprintln(w)
+			fmt.Fprintln(w, "No refresh token available. Run 'coragent login' again.")
 		}
 	} else {
-		fmt.Println("Status:  Authenticated")
-		fmt.Printf("Expires: %s (%s remaining)\n",
+		fmt.Fprintln(w, "Status:  Authenticated")
+		fmt.Fprintf(w, "Expires: %s (%s remaining)\n",
 			tokens.ExpiresAt.Format(time.RFC3339),
 			formatDuration(tokens.ExpiresAt.Sub(now)))
 
 		if tokens.RefreshToken != "" {
-			fmt.Println("Refresh: Available (automatic renewal)")
+			fmt.Fprintln(w, "Refresh: Available (automatic renewal)")
 		}
 	}
 
 	if tokens.Scope != "" {
-		fmt.Printf("Scope:   %s\n", tokens.Scope)
+		fmt.Fprintf(w, "Scope:   %s\n", tokens.Scope)
 	}
 
 	return nil
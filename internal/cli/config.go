@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+
+	"coragent/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd(opts *RootOptions) *cobra.Command {
+	var file string
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Read and write coragent settings",
+		Long: `Commands for managing coragent's own settings file.
+
+Keys are dotted paths into the settings schema (e.g. eval.judge_model,
+feedback.remote.enabled). By default ~/.coragent/config.toml is used; pass
+--file .coragent.toml to edit the project-level file instead.`,
+	}
+	cmd.PersistentFlags().StringVar(&file, "file", "", "Settings file to read or write (default: ~/.coragent/config.toml)")
+
+	cmd.AddCommand(newConfigGetCmd(&file))
+	cmd.AddCommand(newConfigSetCmd(&file))
+
+	return cmd
+}
+
+func newConfigGetCmd(file *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a setting value",
+		Example: `  coragent config get eval.output_dir
+  coragent config get query_tag.base --file .coragent.toml`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := configFilePath(*file)
+			if err != nil {
+				return err
+			}
+			value, found, err := config.GetValue(path, args[0])
+			if err != nil {
+				return UserErr(err)
+			}
+			if !found {
+				return UserErr(fmt.Errorf("%s is not set in %s", args[0], path))
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+}
+
+func newConfigSetCmd(file *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Write a setting value",
+		Example: `  coragent config set eval.judge_model claude-3-5-sonnet
+  coragent config set eval.timestamp_suffix true
+  coragent config set eval.ignore_tools tool_a,tool_b`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := configFilePath(*file)
+			if err != nil {
+				return err
+			}
+			if err := config.SetValue(path, args[0], args[1]); err != nil {
+				return UserErr(err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Set %s in %s\n", args[0], path)
+			return nil
+		},
+	}
+}
+
+// configFilePath returns file, or the global settings path when file is empty.
+func configFilePath(file string) (string, error) {
+	if file != "" {
+		return file, nil
+	}
+	return config.GlobalConfigPath()
+}
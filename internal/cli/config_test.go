@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigCmd_SetThenGet(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	set := newConfigCmd(&RootOptions{})
+	set.SetArgs([]string{"set", "eval.output_dir", "./eval-results"})
+	set.SetOut(&bytes.Buffer{})
+	if err := set.Execute(); err != nil {
+		t.Fatalf("config set: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".coragent", "config.toml")); err != nil {
+		t.Fatalf("expected global config file to be created: %v", err)
+	}
+
+	var out bytes.Buffer
+	get := newConfigCmd(&RootOptions{})
+	get.SetArgs([]string{"get", "eval.output_dir"})
+	get.SetOut(&out)
+	if err := get.Execute(); err != nil {
+		t.Fatalf("config get: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "./eval-results" {
+		t.Errorf("config get = %q, want ./eval-results", out.String())
+	}
+}
+
+func TestConfigCmd_GetUnsetIsUserError(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cmd := newConfigCmd(&RootOptions{})
+	cmd.SetArgs([]string{"get", "eval.judge_model"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	if err == nil || !IsUserError(err) {
+		t.Fatalf("expected user error for unset key, got %v", err)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"coragent/internal/auth"
 	"coragent/internal/config"
 	"coragent/internal/grant"
+
+	"golang.org/x/term"
 )
 
 // buildClient constructs an API client from the root options.
@@ -23,7 +25,7 @@ func buildClient(opts *RootOptions) (*api.Client, error) {
 	if err != nil {
 		return nil, UserErr(err)
 	}
-	client.SetQueryTagBase(strings.TrimSpace(config.LoadCoragentConfig().QueryTag.Base))
+	configureClient(client, opts)
 	return client, nil
 }
 
@@ -36,10 +38,31 @@ func buildClientAndCfg(opts *RootOptions) (*api.Client, auth.Config, error) {
 	if err != nil {
 		return nil, auth.Config{}, UserErr(err)
 	}
-	client.SetQueryTagBase(strings.TrimSpace(config.LoadCoragentConfig().QueryTag.Base))
+	configureClient(client, opts)
 	return client, cfg, nil
 }
 
+// configureClient applies the .coragent.toml client settings and the root
+// flags shared by every command to a newly built client.
+func configureClient(client *api.Client, opts *RootOptions) {
+	cc := config.LoadCoragentConfig()
+	client.SetQueryTagBase(strings.TrimSpace(cc.QueryTag.Base))
+	if cc.API.MaxResponseBytes > 0 {
+		client.MaxResponseBytes = int64(cc.API.MaxResponseBytes)
+	}
+	client.ReadOnly = opts.ReadOnly
+	if opts.logger != nil {
+		client.SetLogger(opts.logger)
+	}
+	attachTrace(client, opts)
+}
+
+// canPrompt reports whether interactive prompts may be shown: stdin must be
+// a terminal and --no-input must not be set.
+func canPrompt(opts *RootOptions) bool {
+	return !opts.NoInput && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
 func commandContext(command string) context.Context {
 	return api.WithQueryTagCommand(context.Background(), command)
 }
@@ -63,6 +86,7 @@ func convertGrantRows(rows []api.ShowGrantsRow) []grant.ShowGrantsRow {
 			Privilege:   r.Privilege,
 			GrantedTo:   r.GrantedTo,
 			GranteeName: r.GranteeName,
+			GrantOption: r.GrantOption,
 		}
 	}
 	return result
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"coragent/internal/api"
+)
+
+func TestBuildClient_MaxResponseBytesFromConfig(t *testing.T) {
+	_, home := setupRunMock(t)
+
+	client, err := buildClient(&RootOptions{})
+	if err != nil {
+		t.Fatalf("buildClient: %v", err)
+	}
+	if client.MaxResponseBytes != api.DefaultMaxResponseBytes {
+		t.Errorf("MaxResponseBytes = %d, want default %d", client.MaxResponseBytes, api.DefaultMaxResponseBytes)
+	}
+
+	dir := filepath.Join(home, ".coragent")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.toml"), []byte("[api]\nmax_response_bytes = 1048576\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	client, err = buildClient(&RootOptions{})
+	if err != nil {
+		t.Fatalf("buildClient: %v", err)
+	}
+	if client.MaxResponseBytes != 1<<20 {
+		t.Errorf("MaxResponseBytes = %d, want 1048576 from config", client.MaxResponseBytes)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"coragent/internal/agent"
+	"coragent/internal/api"
 	"coragent/internal/diff"
 
 	"github.com/fatih/color"
@@ -21,6 +22,8 @@ type deleteItem struct {
 func newDeleteCmd(opts *RootOptions) *cobra.Command {
 	var autoApprove bool
 	var recursive bool
+	var selectRemote bool
+	var force bool
 	cmd := &cobra.Command{
 		Use:   "delete [path]",
 		Short: "Delete agents defined in YAML files",
@@ -31,9 +34,25 @@ func newDeleteCmd(opts *RootOptions) *cobra.Command {
   coragent delete agent.yaml -y
 
   # Delete all agents in a directory tree
-  coragent delete -R ./agents/`,
+  coragent delete -R ./agents/
+
+  # Pick deployed agents to delete from an interactive list
+  coragent delete --select
+
+  # Idempotent cleanup for scripts: no prompt, missing agents are not errors
+  coragent delete ./agents/ --force`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if force {
+				autoApprove = true
+			}
+			if selectRemote {
+				if len(args) > 0 || recursive {
+					return UserErr(fmt.Errorf("--select cannot be combined with a path or --recursive"))
+				}
+				return runDeleteSelected(cmd, opts, autoApprove, force)
+			}
+
 			path := "."
 			if len(args) == 1 {
 				path = args[0]
@@ -113,7 +132,7 @@ func newDeleteCmd(opts *RootOptions) *cobra.Command {
 				}
 
 				fmt.Fprintf(os.Stdout, "Deleting %s... ", item.Parsed.Spec.Name)
-				if err := client.DeleteAgent(commandContext("delete"), item.Target.Database, item.Target.Schema, item.Parsed.Spec.Name); err != nil {
+				if err := deleteAgent(client, item.Target, item.Parsed.Spec.Name, force); err != nil {
 					fmt.Fprintln(os.Stdout, "failed")
 					return fmt.Errorf("snowflake API error: %w", err)
 				}
@@ -125,5 +144,65 @@ func newDeleteCmd(opts *RootOptions) *cobra.Command {
 	}
 	cmd.Flags().BoolVarP(&autoApprove, "yes", "y", false, "Skip confirmation prompt")
 	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Recursively load agents from subdirectories")
+	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation and treat agents that no longer exist as deleted")
+	cmd.Flags().BoolVar(&selectRemote, "select", false, "Interactively pick deployed agents to delete instead of loading YAML files")
 	return cmd
 }
+
+// runDeleteSelected deletes agents picked from the target schema's agent list.
+func runDeleteSelected(cmd *cobra.Command, opts *RootOptions, autoApprove, force bool) error {
+	if !canPrompt(opts) {
+		return UserErr(fmt.Errorf("--select requires an interactive terminal; pass a YAML path to delete specific agents"))
+	}
+	client, cfg, err := buildClientAndCfg(opts)
+	if err != nil {
+		return err
+	}
+	target, err := ResolveTargetForExport(opts, cfg)
+	if err != nil {
+		return err
+	}
+	names, err := selectRemoteAgents(client, target, "delete")
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stdout, "No agents selected.")
+		return nil
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stdout, "%s:\n", name)
+		fmt.Fprintf(os.Stdout, "  database: %s\n", target.Database)
+		fmt.Fprintf(os.Stdout, "  schema:   %s\n", target.Schema)
+		color.New(color.FgRed).Fprintln(os.Stdout, "  - delete")
+	}
+	fmt.Fprintf(os.Stdout, "\nPlan: %d to delete\n", len(names))
+
+	if !autoApprove {
+		if !confirm("Delete these agents?", cmd.InOrStdin()) {
+			fmt.Fprintln(os.Stdout, "Aborted.")
+			return nil
+		}
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(os.Stdout, "Deleting %s... ", name)
+		if err := deleteAgent(client, target, name, force); err != nil {
+			fmt.Fprintln(os.Stdout, "failed")
+			return fmt.Errorf("snowflake API error: %w", err)
+		}
+		color.New(color.FgGreen).Fprintln(os.Stdout, "done")
+	}
+	return nil
+}
+
+// deleteAgent deletes one agent. With force, an agent that is already gone
+// (e.g. removed between planning and deletion) counts as deleted.
+func deleteAgent(client *api.Client, target Target, name string, force bool) error {
+	ctx := commandContext("delete")
+	if force {
+		return client.DeleteAgentIfExists(ctx, target.Database, target.Schema, name)
+	}
+	return client.DeleteAgent(ctx, target.Database, target.Schema, name)
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"coragent/internal/api"
+	"coragent/internal/diff"
+
+	"github.com/spf13/cobra"
+)
+
+func newDescribeCmd(opts *RootOptions) *cobra.Command {
+	var raw bool
+	var field string
+	cmd := &cobra.Command{
+		Use:     "describe <agent-name>",
+		Aliases: []string{"show"},
+		Short:   "Show a deployed agent as JSON",
+		Long: `Show a deployed agent's decoded spec as JSON.
+
+With --raw, print every column returned by DESCRIBE AGENT exactly as Snowflake
+returned it (agent_spec stays a literal JSON string). Use this to debug cases
+where the decoded spec or an export looks wrong.
+
+With --field, print only the value at a dotted path into the decoded spec,
+using the same paths as plan and diff output: "models.orchestration",
+"tools[0].tool_spec.name" or "tools[sales_view].tool_spec.description".
+Strings and numbers are printed as-is; objects and arrays as JSON.`,
+		Example: `  # Show the decoded spec
+  coragent describe MY_AGENT
+
+  # Dump the unprocessed DESCRIBE AGENT columns
+  coragent describe MY_AGENT --raw
+
+  # Print the orchestration model, e.g. in a script
+  coragent show MY_AGENT --field models.orchestration
+
+  # Print one tool's resources as JSON
+  coragent show MY_AGENT --field tool_resources.sales_view`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if raw && field != "" {
+				return UserErr(fmt.Errorf("--field cannot be combined with --raw"))
+			}
+
+			client, cfg, err := buildClientAndCfg(opts)
+			if err != nil {
+				return err
+			}
+			target, err := ResolveTargetForExport(opts, cfg)
+			if err != nil {
+				return err
+			}
+
+			var result api.DescribeResult
+			err = runWithRetry(opts, func() error {
+				result, err = client.DescribeAgent(commandContext("describe"), target.Database, target.Schema, name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("snowflake API error: %w", err)
+			}
+			if !result.Exists {
+				return UserErr(fmt.Errorf("agent %q not found", name))
+			}
+
+			if raw {
+				return writeJSONIndent(cmd.OutOrStdout(), result.RawColumns)
+			}
+			for _, col := range result.UnmappedColumns {
+				fmt.Fprintf(os.Stderr, "\033[33mWarning: DESCRIBE AGENT returned unmapped column %q (use --raw to inspect)\033[0m\n", col)
+			}
+			if field != "" {
+				specMap, err := diff.ToMap(result.Spec)
+				if err != nil {
+					return err
+				}
+				value, err := diff.Lookup(specMap, field)
+				if err != nil {
+					return UserErr(err)
+				}
+				return writeFieldValue(cmd.OutOrStdout(), value)
+			}
+			return writeJSONIndent(cmd.OutOrStdout(), result.Spec)
+		},
+	}
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print the unprocessed DESCRIBE AGENT columns and values")
+	cmd.Flags().StringVar(&field, "field", "", "Print only the value at this dotted path (e.g. models.orchestration, tools[0].tool_spec.name)")
+	return cmd
+}
+
+// writeFieldValue prints a value looked up by describe --field: strings
+// without quotes, other scalars in their JSON form, and objects and arrays
+// as indented JSON.
+func writeFieldValue(w io.Writer, v any) error {
+	switch v.(type) {
+	case string:
+		_, err := fmt.Fprintln(w, v)
+		return err
+	case map[string]any, []any:
+		return writeJSONIndent(w, v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("marshal JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+}
+
+// writeJSONIndent writes v as indented JSON followed by a newline.
+func writeJSONIndent(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
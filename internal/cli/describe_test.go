@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"coragent/internal/regression"
+)
+
+func TestDescribeRaw_IncludesAllColumns(t *testing.T) {
+	client := newMockAgentClient(t, "raw-agent", regression.BuildSSEReply("ok"))
+
+	result, err := client.DescribeAgent(context.Background(), "DB", "SCH", "raw-agent")
+	if err != nil {
+		t.Fatalf("DescribeAgent: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSONIndent(&buf, result.RawColumns); err != nil {
+		t.Fatalf("writeJSONIndent: %v", err)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal raw output %q: %v", buf.String(), err)
+	}
+	for _, col := range []string{"agent_spec", "name", "comment", "profile"} {
+		if _, ok := got[col]; !ok {
+			t.Errorf("raw output missing column %q: %s", col, buf.String())
+		}
+	}
+	specJSON, ok := got["agent_spec"].(string)
+	if !ok {
+		t.Fatalf("agent_spec should be the literal JSON string, got %T", got["agent_spec"])
+	}
+	var spec map[string]any
+	if err := json.Unmarshal([]byte(specJSON), &spec); err != nil {
+		t.Fatalf("agent_spec is not valid JSON: %v", err)
+	}
+	if spec["name"] != "raw-agent" {
+		t.Errorf("agent_spec name = %v, want raw-agent", spec["name"])
+	}
+}
+
+func TestWriteFieldValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"string unquoted", "claude-4-sonnet", "claude-4-sonnet\n"},
+		{"number", float64(4096), "4096\n"},
+		{"bool", true, "true\n"},
+		{"null", nil, "null\n"},
+		{"object as JSON", map[string]any{"semantic_view": "DB.SCH.SV"}, "{\n  \"semantic_view\": \"DB.SCH.SV\"\n}\n"},
+		{"array as JSON", []any{"a"}, "[\n  \"a\"\n]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeFieldValue(&buf, tt.value); err != nil {
+				t.Fatalf("writeFieldValue: %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("output = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeCmd_FieldRejectsRaw(t *testing.T) {
+	root, _ := newRootCmd()
+	root.SetArgs([]string{"show", "MY_AGENT", "--raw", "--field", "name"})
+	if err := root.Execute(); err == nil || !IsUserError(err) {
+		t.Errorf("expected user error, got %v", err)
+	}
+}
+
+func TestDescribeCmd_Field(t *testing.T) {
+	setupRunMock(t)
+
+	root, _ := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"show", "thread-agent", "-d", "DB", "-s", "SCH", "--field", "name"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("show --field: %v", err)
+	}
+	if out.String() != "thread-agent\n" {
+		t.Errorf("output = %q, want %q", out.String(), "thread-agent\n")
+	}
+
+	root, _ = newRootCmd()
+	root.SetArgs([]string{"show", "thread-agent", "-d", "DB", "-s", "SCH", "--field", "models.nope"})
+	if err := root.Execute(); err == nil || !IsUserError(err) {
+		t.Errorf("expected user error for unknown path, got %v", err)
+	}
+}
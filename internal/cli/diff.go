@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
+	"coragent/internal/diff"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd(opts *RootOptions) *cobra.Command {
+	var recursive bool
+	var basePath string
+	var swap bool
+	cmd := &cobra.Command{
+		Use:   "diff [path]",
+		Short: "Show local vs. remote spec changes without deploying",
+		Long: `Compare local agent specs with the deployed agents and print the changes
+grouped by agent. Grants are not compared; use plan for the full picture.
+
+With --base, the deployed side is replaced by specs loaded from a YAML file
+or directory (for example an earlier export), matched to the local specs by
+agent name. No connection to Snowflake is made.
+
+Each agent is printed under a "--- remote (current)" (or "--- base") header
+and a "+++ local" header: "-" lines are the current value, "+" lines the
+local one. --swap flips the display so that local is "---" and remote "+++",
+for readers who expect the other direction.
+
+The command exits 0 when there are no changes, 2 when any agent differs and
+1 on errors, like plan --exit-code, so a CI check can tell drift from a
+failure.`,
+		Example: `  # Diff the current directory
+  coragent diff
+
+  # Diff all agents in a directory tree
+  coragent diff -R ./agents/
+
+  # Compare two spec files without contacting Snowflake
+  coragent diff --base old-agent.yaml agent.yaml
+
+  # Show local as the old side and remote as the new side
+  coragent diff --swap`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			specs, err := agent.LoadAgents(path, recursive, opts.Env)
+			if err != nil {
+				return UserErr(err)
+			}
+
+			var changed bool
+			if basePath != "" {
+				base, err := agent.LoadAgents(basePath, recursive, opts.Env)
+				if err != nil {
+					return UserErr(fmt.Errorf("load --base: %w", err))
+				}
+				changed, err = runBaseDiff(os.Stdout, specs, base, swap)
+				if err != nil {
+					return err
+				}
+			} else {
+				client, cfg, err := buildClientAndCfg(opts)
+				if err != nil {
+					return err
+				}
+				changed, err = runDiff(commandContext("diff"), os.Stdout, os.Stderr, specs, opts, cfg, client, swap)
+				if err != nil {
+					return err
+				}
+			}
+			if changed {
+				return ExitCodeError{Code: ExitChanges}
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Recursively load agents from subdirectories")
+	cmd.Flags().StringVar(&basePath, "base", "", "Compare against specs loaded from this YAML file or directory instead of the deployed agents")
+	cmd.Flags().BoolVar(&swap, "swap", false, "Display local as the old (---) side and remote or base as the new (+++) side")
+	return cmd
+}
+
+// runDiff writes the spec changes of each agent to w and reports whether any
+// agent differs from its deployed state. Agents that do not exist remotely
+// are shown with every field added. With swap, each section is displayed
+// from local to remote instead. Names that differ from a deployed agent's
+// name only by case are reported as warnings on errW.
+func runDiff(ctx context.Context, w, errW io.Writer, specs []agent.ParsedAgent, opts *RootOptions, cfg auth.Config, agentSvc api.AgentService, swap bool) (bool, error) {
+	for _, msg := range localNameCaseWarnings(specs) {
+		fmt.Fprintf(errW, "\033[33mWarning: %s\033[0m\n", msg)
+	}
+	changed := 0
+	for _, item := range specs {
+		target, err := ResolveTarget(item.Spec, opts, cfg)
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", item.Path, err)
+		}
+		var remote agent.AgentSpec
+		var exists bool
+		err = runWithRetry(opts, func() error {
+			remote, exists, err = agentSvc.GetAgent(ctx, target.Database, target.Schema, item.Spec.Name)
+			return err
+		})
+		if err != nil {
+			return false, fmt.Errorf("get agent %s: %w", item.Spec.Name, err)
+		}
+		// The case check is advisory, so a failed listing does not fail the diff.
+		if listed, err := agentSvc.ListAgents(ctx, target.Database, target.Schema); err == nil {
+			if msg := remoteNameCaseWarning(item.Spec.Name, target, listed); msg != "" {
+				fmt.Fprintf(errW, "\033[33mWarning: %s: %s\033[0m\n", item.Path, msg)
+			}
+		}
+
+		var changes []diff.Change
+		if exists {
+			changes, err = diff.DiffWithOptions(item.Spec, remote, diff.Options{MatchArraysByKey: true})
+		} else {
+			changes, err = diff.DiffForCreate(item.Spec)
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", item.Path, err)
+		}
+		if !diff.HasChanges(changes) {
+			continue
+		}
+		changed++
+
+		fqn := fmt.Sprintf("%s.%s.%s", target.Database, target.Schema, item.Spec.Name)
+		if !exists {
+			fqn += " (does not exist)"
+		}
+		writeDiffSection(w, diffSide{"remote (current)", fqn}, diffSide{"local", item.Path}, changes, swap)
+	}
+
+	fmt.Fprintf(w, "Diff: %d of %d agents differ\n", changed, len(specs))
+	return changed > 0, nil
+}
+
+// runBaseDiff is runDiff with the deployed side taken from base, a set of
+// specs loaded from disk. Specs are matched by agent name.
+func runBaseDiff(w io.Writer, specs, base []agent.ParsedAgent, swap bool) (bool, error) {
+	byName := make(map[string]agent.ParsedAgent, len(base))
+	for _, item := range base {
+		if prev, ok := byName[item.Spec.Name]; ok {
+			return false, UserErr(fmt.Errorf("--base defines agent %q twice (%s and %s)", item.Spec.Name, prev.Path, item.Path))
+		}
+		byName[item.Spec.Name] = item
+	}
+
+	changed := 0
+	for _, item := range specs {
+		baseItem, exists := byName[item.Spec.Name]
+		var changes []diff.Change
+		var err error
+		if exists {
+			changes, err = diff.DiffWithOptions(item.Spec, baseItem.Spec, diff.Options{MatchArraysByKey: true})
+		} else {
+			changes, err = diff.DiffForCreate(item.Spec)
+		}
+		if err != nil {
+			return false, fmt.Errorf("%s: %w", item.Path, err)
+		}
+		if !diff.HasChanges(changes) {
+			continue
+		}
+		changed++
+
+		from := diffSide{"base", baseItem.Path}
+		if !exists {
+			from.name = item.Spec.Name + " (does not exist)"
+		}
+		writeDiffSection(w, from, diffSide{"local", item.Path}, changes, swap)
+	}
+
+	fmt.Fprintf(w, "Diff: %d of %d agents differ\n", changed, len(specs))
+	return changed > 0, nil
+}
+
+// diffSide labels one side of a diff section, e.g. "remote (current)" and
+// the agent's qualified name.
+type diffSide struct {
+	label, name string
+}
+
+// writeDiffSection prints one agent's changes under a ---/+++ header. The
+// changes run from old to local; with swap, local is shown as the --- side
+// and every change is displayed in the opposite direction.
+func writeDiffSection(w io.Writer, old, local diffSide, changes []diff.Change, swap bool) {
+	if swap {
+		old, local = local, old
+		changes = reverseChanges(changes)
+	}
+	width := max(6, len(old.label), len(local.label))
+	color.New(color.FgRed).Fprintf(w, "--- %-*s %s\n", width, old.label, old.name)
+	color.New(color.FgGreen).Fprintf(w, "+++ %-*s %s\n", width, local.label, local.name)
+	for _, c := range changes {
+		writePlanChange(w, c)
+	}
+	fmt.Fprintln(w)
+}
+
+// reverseChanges returns changes as seen from the other side: added and
+// removed swap and Before/After trade places. Only the display is affected.
+func reverseChanges(changes []diff.Change) []diff.Change {
+	out := make([]diff.Change, len(changes))
+	for i, c := range changes {
+		c.Before, c.After = c.After, c.Before
+		switch c.Type {
+		case diff.Added:
+			c.Type = diff.Removed
+		case diff.Removed:
+			c.Type = diff.Added
+		}
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/diff"
+)
+
+func TestRunDiff_ReportsChangedAgentsOnly(t *testing.T) {
+	svc := &fakeAgentService{Agents: map[string]agent.AgentSpec{
+		"TEST_DB.PUBLIC.same":    {Name: "same", Comment: "hello"},
+		"TEST_DB.PUBLIC.changed": {Name: "changed", Comment: "old"},
+	}}
+	specs := []agent.ParsedAgent{
+		{Path: "same.yaml", Spec: agent.AgentSpec{Name: "same", Comment: "hello"}},
+		{Path: "changed.yaml", Spec: agent.AgentSpec{Name: "changed", Comment: "new"}},
+		{Path: "missing.yaml", Spec: agent.AgentSpec{Name: "missing"}},
+	}
+
+	var buf bytes.Buffer
+	changed, err := runDiff(context.Background(), &buf, io.Discard, specs, testOpts(), testCfg(), svc, false)
+	if err != nil {
+		t.Fatalf("runDiff: %v", err)
+	}
+	if !changed {
+		t.Error("expected changed = true")
+	}
+	out := buf.String()
+	if strings.Contains(out, "TEST_DB.PUBLIC.same") {
+		t.Errorf("unchanged agent should not be printed:\n%s", out)
+	}
+	for _, want := range []string{
+		"--- remote (current) TEST_DB.PUBLIC.changed\n",
+		"+++ local            changed.yaml\n",
+		"comment =",
+		"--- remote (current) TEST_DB.PUBLIC.missing (does not exist)\n",
+		"Diff: 2 of 3 agents differ\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunDiff_NoChanges(t *testing.T) {
+	svc := &fakeAgentService{Agents: map[string]agent.AgentSpec{
+		"TEST_DB.PUBLIC.same": {Name: "same"},
+	}}
+	var buf bytes.Buffer
+	changed, err := runDiff(context.Background(), &buf, io.Discard, []agent.ParsedAgent{makeSpec("same")}, testOpts(), testCfg(), svc, false)
+	if err != nil {
+		t.Fatalf("runDiff: %v", err)
+	}
+	if changed {
+		t.Errorf("expected no changes, got output:\n%s", buf.String())
+	}
+}
+
+func TestRunDiff_RetriesTransientGetAgent(t *testing.T) {
+	slept := stubRetrySleep(t)
+	svc := &fakeAgentService{
+		Agents:           map[string]agent.AgentSpec{"TEST_DB.PUBLIC.same": {Name: "same"}},
+		GetAgentFailures: 1,
+	}
+	opts := testOpts()
+	opts.Retry = 2
+	var buf bytes.Buffer
+	changed, err := runDiff(context.Background(), &buf, io.Discard, []agent.ParsedAgent{makeSpec("same")}, opts, testCfg(), svc, false)
+	if err != nil {
+		t.Fatalf("runDiff: %v", err)
+	}
+	if changed {
+		t.Errorf("expected no changes, got output:\n%s", buf.String())
+	}
+	if len(*slept) != 1 {
+		t.Errorf("slept %d times, want 1 retry", len(*slept))
+	}
+}
+
+func TestRunBaseDiff_ComparesAgainstBaseSpecs(t *testing.T) {
+	base := []agent.ParsedAgent{
+		{Path: "old/same.yaml", Spec: agent.AgentSpec{Name: "same", Comment: "hello"}},
+		{Path: "old/changed.yaml", Spec: agent.AgentSpec{Name: "changed", Comment: "old"}},
+	}
+	specs := []agent.ParsedAgent{
+		{Path: "same.yaml", Spec: agent.AgentSpec{Name: "same", Comment: "hello"}},
+		{Path: "changed.yaml", Spec: agent.AgentSpec{Name: "changed", Comment: "new"}},
+		{Path: "added.yaml", Spec: agent.AgentSpec{Name: "added"}},
+	}
+
+	var buf bytes.Buffer
+	changed, err := runBaseDiff(&buf, specs, base, false)
+	if err != nil {
+		t.Fatalf("runBaseDiff: %v", err)
+	}
+	if !changed {
+		t.Error("expected changed = true")
+	}
+	out := buf.String()
+	if strings.Contains(out, "same.yaml") {
+		t.Errorf("unchanged agent should not be printed:\n%s", out)
+	}
+	for _, want := range []string{
+		"--- base   old/changed.yaml\n",
+		"+++ local  changed.yaml\n",
+		"comment =",
+		"--- base   added (does not exist)\n",
+		"Diff: 2 of 3 agents differ\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDiffCmd_BaseFileNeedsNoConnection(t *testing.T) {
+	// No Snowflake credentials or API base URL: --base must not build a client.
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("SNOWFLAKE_HOME", t.TempDir())
+	t.Setenv("SNOWFLAKE_ACCOUNT", "")
+
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "old.yaml")
+	newPath := filepath.Join(dir, "new.yaml")
+	if err := os.WriteFile(oldPath, []byte("name: my-agent\ncomment: before\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(newPath, []byte("name: my-agent\ncomment: before\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newDiffCmd(&RootOptions{})
+	cmd.SetArgs([]string{"--base", oldPath, newPath})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("identical files: expected no error, got %v", err)
+	}
+
+	if err := os.WriteFile(newPath, []byte("name: my-agent\ncomment: after\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = newDiffCmd(&RootOptions{})
+	cmd.SetArgs([]string{"--base", oldPath, newPath})
+	err := cmd.Execute()
+	var exitErr ExitCodeError
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitChanges {
+		t.Fatalf("changed files: expected exit code %d, got %v", ExitChanges, err)
+	}
+}
+
+func TestWriteDiffSection_LabelsAndSwap(t *testing.T) {
+	changes := []diff.Change{
+		{Path: "comment", Type: diff.Modified, Before: "deployed", After: "edited"},
+		{Path: "instructions.response", Type: diff.Added, After: "be brief"},
+	}
+	remote := diffSide{"remote (current)", "DB.SCH.agent"}
+	local := diffSide{"local", "agent.yaml"}
+
+	var buf bytes.Buffer
+	writeDiffSection(&buf, remote, local, changes, false)
+	want := `--- remote (current) DB.SCH.agent
++++ local            agent.yaml
+  ~ comment =
+      - "deployed"
+      + "edited"
+  + instructions.response = "be brief"
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("unswapped output:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	writeDiffSection(&buf, remote, local, changes, true)
+	want = `--- local            agent.yaml
++++ remote (current) DB.SCH.agent
+  ~ comment =
+      - "edited"
+      + "deployed"
+  - instructions.response = "be brief"
+
+`
+	if got := buf.String(); got != want {
+		t.Errorf("swapped output:\n%s\nwant:\n%s", got, want)
+	}
+	if changes[0].Before != "deployed" || changes[1].Type != diff.Added {
+		t.Errorf("swap mutated the input changes: %+v", changes)
+	}
+}
@@ -1,6 +1,11 @@
 package cli
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"coragent/internal/api"
+)
 
 // UserError marks an error as a user/configuration mistake rather than an
 // unexpected system failure. Execute uses this to suppress the --debug hint
@@ -19,8 +24,34 @@ func UserErr(err error) error {
 	return UserError{cause: err}
 }
 
-// IsUserError reports whether err is (or wraps) a UserError.
+// Exit codes used by diff-consuming commands (e.g. plan --exit-code) so that
+// scripts can branch on the result.
+const (
+	ExitClean   = 0 // no changes
+	ExitFailure = 1 // any error
+	ExitChanges = 2 // changes present
+)
+
+// ExitCodeError requests a specific process exit code from Execute. When Err
+// is nil the exit is silent (e.g. "changes present" is not a failure).
+type ExitCodeError struct {
+	Code int
+	Err  error
+}
+
+func (e ExitCodeError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e ExitCodeError) Unwrap() error { return e.Err }
+
+// IsUserError reports whether err is (or wraps) a UserError. A mutation
+// blocked by --read-only (api.ReadOnlyError) also counts, since the flag
+// was the user's choice.
 func IsUserError(err error) bool {
 	var u UserError
-	return errors.As(err, &u)
+	return errors.As(err, &u) || api.IsReadOnlyError(err)
 }
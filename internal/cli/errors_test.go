@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+
+	"coragent/internal/api"
 )
 
 func TestUserErr_NilIsNil(t *testing.T) {
@@ -46,3 +48,10 @@ func TestIsUserError_ThroughWrapper(t *testing.T) {
 		t.Error("IsUserError should find UserError through fmt.Errorf wrapping")
 	}
 }
+
+func TestIsUserError_ReadOnly(t *testing.T) {
+	err := fmt.Errorf("create agent: %w", api.ReadOnlyError{Operation: "create agent A"})
+	if !IsUserError(err) {
+		t.Error("a mutation blocked by --read-only should be a user error")
+	}
+}
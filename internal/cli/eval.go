@@ -5,10 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"coragent/internal/agent"
@@ -16,11 +20,13 @@ import (
 	"coragent/internal/config"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // EvalResult holds the result of a single evaluation test case.
 type EvalResult struct {
 	Question            string   `json:"question"`
+	Conversation        []string `json:"conversation,omitempty"`
 	ExpectedTools       []string `json:"expected_tools,omitempty"`
 	ActualTools         []string `json:"actual_tools"`
 	ToolMatch           bool     `json:"tool_match"`
@@ -32,12 +38,22 @@ type EvalResult struct {
 	CommandOutput       string   `json:"command_output,omitempty"`
 	CommandError        string   `json:"command_error,omitempty"`
 	ExpectedResponse    string   `json:"expected_response,omitempty"`
+	ExpectedSubstrings  []string `json:"expected_substrings,omitempty"`
+	SubstringMatch      *bool    `json:"substring_match,omitempty"`
+	MissingSubstrings   []string `json:"missing_substrings,omitempty"`
 	ResponseScore       *int     `json:"response_score,omitempty"`
 	ResponseScoreReason string   `json:"response_score_reason,omitempty"`
 	JudgeModel          string   `json:"judge_model,omitempty"`
 	ResponseScoreErr    string   `json:"response_score_error,omitempty"`
+	ResponseSchemaValid *bool    `json:"response_schema_valid,omitempty"`
+	ResponseSchemaError string   `json:"response_schema_error,omitempty"`
 	Passed              bool     `json:"passed"`
 	Error               string   `json:"error,omitempty"`
+	DurationMs          int64    `json:"duration_ms"`
+	// ResponseMs is how long the agent took to answer the question, not
+	// counting earlier conversation turns, the command or the judge.
+	ResponseMs    int64 `json:"response_ms,omitempty"`
+	MaxResponseMs int   `json:"max_response_ms,omitempty"`
 }
 
 // CommandInput is the JSON payload written to stdin of eval commands.
@@ -50,25 +66,92 @@ type CommandInput struct {
 	ThreadID         string   `json:"thread_id"`
 }
 
+// EvalSummary is the per-agent result line printed by eval --summary-only.
+type EvalSummary struct {
+	AgentName string   `json:"agent_name"`
+	Passed    int      `json:"passed"`
+	Total     int      `json:"total"`
+	Failed    []string `json:"failed,omitempty"`
+}
+
+// evalJSONLResult is a per-test line of the --jsonl report.
+type evalJSONLResult struct {
+	Type      string `json:"type"`
+	AgentName string `json:"agent_name"`
+	EvalResult
+}
+
+// evalJSONLSummary is the closing line of an agent's tests in the --jsonl report.
+type evalJSONLSummary struct {
+	Type string `json:"type"`
+	EvalSummary
+}
+
 // EvalReport holds the full evaluation report.
 type EvalReport struct {
-	AgentName   string       `json:"agent_name"`
-	Database    string       `json:"database"`
-	Schema      string       `json:"schema"`
-	EvaluatedAt string       `json:"evaluated_at"`
-	Results     []EvalResult `json:"results"`
+	AgentName     string       `json:"agent_name"`
+	Database      string       `json:"database"`
+	Schema        string       `json:"schema"`
+	EvaluatedAt   string       `json:"evaluated_at"`
+	Results       []EvalResult `json:"results"`
+	OmittedPassed int          `json:"omitted_passed,omitempty"` // passed results dropped by --failures-only
 }
 
 func newEvalCmd(opts *RootOptions) *cobra.Command {
 	var outputDir string
 	var recursive bool
+	var streamIdleTimeout time.Duration
+	var timeout time.Duration
+	var jsonSchemaPath string
+	var summaryOnly bool
+	var passRate float64
+	var quiet bool
+	var jsonlPath string
+	var htmlPath string
+	var concurrency int
+	var delay time.Duration
+	var failFast bool
+	var failuresOnly bool
+	var exitZero bool
+	var conflictRetries int
 
 	cmd := &cobra.Command{
 		Use:   "eval [path]",
 		Short: "Evaluate agent accuracy using test cases",
 		Long: `Evaluate a Cortex Agent by running test cases defined in the spec file's eval section.
 Each test case sends a question to the agent and checks if the expected tools were used.
-Results are output as JSON and Markdown reports.
+Results are output as JSON and Markdown reports. With --summary-only, no report
+files are written; a JSON summary line per agent is printed to stdout instead.
+
+With a pass-rate threshold (eval.pass_rate_threshold, .coragent.toml, or
+--pass-rate), each agent's suite gets a PASS/FAIL verdict and the command exits
+non-zero when any suite falls below its threshold. Without a threshold, the
+command exits non-zero when any test case fails. --exit-zero always exits 0
+once the reports are written.
+
+With --failures-only, the JSON and Markdown reports contain only the failed
+and errored test cases, with a note on how many passed tests were omitted.
+The console results, --jsonl and --html still cover every test.
+
+With --fail-fast, no further test cases (or agents) are started once a test
+case fails; the reports hold the results gathered so far.
+
+With --concurrency N, up to N test cases of an agent run at the same time;
+reports keep the order of the spec.
+
+With --delay D (or eval.request_delay in .coragent.toml), the command waits D
+between test cases, and between agents, to avoid being rate-limited. The delay
+applies per worker: with --concurrency N, each of the N workers waits D after
+finishing a test case before starting its next one, so up to N requests can
+still start together.
+
+Each test case fails when it has not finished within --timeout (default 15m).
+
+With --html, a self-contained HTML report (inline CSS, collapsible details per
+test) is written as well, also with --summary-only.
+
+With --jsonl, each result is appended to the given file as one JSON line as
+soon as its test completes, followed by a summary line per agent.
 
 Agents without an eval section are skipped.`,
 		Example: `  # Run evaluation (current directory)
@@ -84,7 +167,37 @@ Agents without an eval section are skipped.`,
   coragent eval ./agents/ -R
 
   # Specify output directory
-  coragent eval agent.yaml -o ./eval-results`,
+  coragent eval agent.yaml -o ./eval-results
+
+  # Request JSON output and record schema validity per test
+  coragent eval agent.yaml --json-schema answer.schema.json
+
+  # Print only pass/fail summaries (no report files), e.g. in CI
+  coragent eval ./agents/ -R --summary-only
+
+  # Accept the suite when at least 90% of tests pass
+  coragent eval agent.yaml --pass-rate 0.9
+
+  # Write reports without failing the build
+  coragent eval ./agents/ -R --exit-zero
+
+  # Keep only failing test cases in the reports for review
+  coragent eval ./agents/ -R --failures-only
+
+  # Stop at the first failing test case, e.g. in a pre-commit hook
+  coragent eval agent.yaml --fail-fast
+
+  # Run up to 4 test cases at a time
+  coragent eval agent.yaml --concurrency 4
+
+  # Pause 2 seconds between test cases to avoid throttling
+  coragent eval ./agents/ -R --delay 2s
+
+  # Stream results as JSON Lines for ingestion
+  coragent eval ./agents/ -R --jsonl results.jsonl
+
+  # Write an HTML report to share
+  coragent eval agent.yaml --html eval-report.html`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path := "."
@@ -109,6 +222,29 @@ Agents without an eval section are skipped.`,
 				return fmt.Errorf("no eval tests defined in any agent in %s", path)
 			}
 
+			if concurrency < 1 {
+				return UserErr(fmt.Errorf("--concurrency must be at least 1, got %d", concurrency))
+			}
+			if err := validateConflictRetries(conflictRetries); err != nil {
+				return err
+			}
+
+			if passRate < 0 || passRate > 1 {
+				return UserErr(fmt.Errorf("--pass-rate must be between 0 and 1, got %g", passRate))
+			}
+
+			if timeout <= 0 {
+				return UserErr(fmt.Errorf("--timeout must be positive, got %s", timeout))
+			}
+
+			var schema map[string]any
+			if jsonSchemaPath != "" {
+				schema, err = loadJSONSchema(jsonSchemaPath)
+				if err != nil {
+					return UserErr(err)
+				}
+			}
+
 			// 2. Setup auth and client
 			client, cfg, err := buildClientAndCfg(opts)
 			if err != nil {
@@ -122,36 +258,120 @@ Agents without an eval section are skipped.`,
 					outputDir = appCfg.Eval.OutputDir
 				}
 			}
+			if !cmd.Flags().Changed("delay") {
+				delay = appCfg.Eval.RequestDelay
+			}
+			if delay < 0 {
+				return UserErr(fmt.Errorf("--delay (or eval.request_delay) must not be negative, got %s", delay))
+			}
 
 			// Ensure output directory exists
-			if err := os.MkdirAll(outputDir, 0o755); err != nil {
-				return fmt.Errorf("create output dir: %w", err)
+			if !summaryOnly {
+				if err := os.MkdirAll(outputDir, 0o755); err != nil {
+					return fmt.Errorf("create output dir: %w", err)
+				}
+			}
+
+			var jsonlOut io.Writer
+			if jsonlPath != "" {
+				f, err := os.Create(jsonlPath)
+				if err != nil {
+					return fmt.Errorf("create JSONL report: %w", err)
+				}
+				defer f.Close()
+				jsonlOut = f
 			}
 
 			// 3. Evaluate each agent
-			for _, item := range evalSpecs {
+			var belowThreshold, withFailures []string
+			var failedTests, totalTests int
+			for i, item := range evalSpecs {
+				if i > 0 && delay > 0 {
+					time.Sleep(delay)
+				}
 				target, err := ResolveTarget(item.Spec, opts, cfg)
 				if err != nil {
 					return fmt.Errorf("%s: %w", item.Path, err)
 				}
 
+				judgePrompt, err := parseJudgePromptTemplate(resolveJudgePromptTemplate(item.Spec, appCfg))
+				if err != nil {
+					return UserErr(fmt.Errorf("%s: %w", item.Path, err))
+				}
+
 				specDir := filepath.Dir(item.Path)
 				eo := evalOptions{
 					judgeModel:             resolveJudgeModel(item.Spec, appCfg),
+					judgePrompt:            judgePrompt,
 					responseScoreThreshold: resolveResponseScoreThreshold(item.Spec, appCfg),
+					maxResponseMs:          resolveMaxResponseMs(item.Spec, appCfg),
 					ignoreTools:            mergeIgnoreTools(defaultIgnoreTools, appCfg.Eval.IgnoreTools),
+					streamIdleTimeout:      streamIdleTimeout,
+					conflictRetries:        conflictRetries,
+					timeout:                timeout,
+					responseSchema:         schema,
+					summaryOnly:            summaryOnly,
+					summaryOut:             cmd.OutOrStdout(),
+					passRateThreshold:      resolvePassRateThreshold(item.Spec, appCfg),
+					quiet:                  quiet,
+					jsonlOut:               jsonlOut,
+					concurrency:            concurrency,
+					delay:                  delay,
+					failFast:               failFast,
+					failuresOnly:           failuresOnly,
+				}
+				if htmlPath != "" {
+					eo.htmlPath = evalHTMLPath(htmlPath, item.Spec.Name, len(evalSpecs) > 1)
 				}
-				if err := runEvalForAgent(client, target, item.Spec, outputDir, specDir, appCfg.Eval.TimestampSuffix, eo); err != nil {
+				if cmd.Flags().Changed("pass-rate") {
+					eo.passRateThreshold = passRate
+				}
+				summary, err := runEvalForAgent(client, target, item.Spec, outputDir, specDir, appCfg.Eval.TimestampSuffix, eo)
+				if err != nil {
 					return fmt.Errorf("%s: %w", item.Path, err)
 				}
+				if eo.passRateThreshold > 0 {
+					if !suitePasses(summary, eo.passRateThreshold) {
+						belowThreshold = append(belowThreshold, item.Spec.Name)
+					}
+				} else {
+					totalTests += summary.Total
+					if len(summary.Failed) > 0 {
+						failedTests += len(summary.Failed)
+						withFailures = append(withFailures, item.Spec.Name)
+					}
+				}
+				if failFast && len(summary.Failed) > 0 {
+					break
+				}
 			}
 
-			return nil
+			if exitZero {
+				return nil
+			}
+			if err := passRateError(belowThreshold); err != nil {
+				return err
+			}
+			return failedTestsError(failedTests, totalTests, withFailures)
 		},
 	}
 
 	cmd.Flags().StringVarP(&outputDir, "output-dir", "o", ".", "Output directory for reports")
 	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Recursively load agents from subdirectories")
+	cmd.Flags().StringVar(&jsonSchemaPath, "json-schema", "", "Request JSON output matching this JSON Schema file and record validity per test")
+	cmd.Flags().DurationVar(&streamIdleTimeout, "stream-idle-timeout", api.DefaultStreamIdleTimeout, "Abort a test's response stream when no event arrives within this duration")
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultRunTimeout, "Fail a test case that has not finished within this duration")
+	cmd.Flags().BoolVar(&summaryOnly, "summary-only", false, "Skip JSON/Markdown report files and print a JSON summary line per agent to stdout")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Hide per-test result lines and progress; print only the final results")
+	cmd.Flags().StringVar(&jsonlPath, "jsonl", "", "Also write each result as a JSON line to this file as tests complete, plus a summary line per agent")
+	cmd.Flags().StringVar(&htmlPath, "html", "", "Also write a self-contained HTML report to this file (one file per agent, suffixed with its name, when several agents are evaluated)")
+	cmd.Flags().BoolVar(&exitZero, "exit-zero", false, "Exit 0 even when tests fail or a suite is below its pass rate; only reports are produced")
+	cmd.Flags().BoolVar(&failFast, "fail-fast", false, "Stop starting test cases and agents after the first failing test case")
+	cmd.Flags().BoolVar(&failuresOnly, "failures-only", false, "Write only failed and errored test cases to the JSON and Markdown reports")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 1, "Number of test cases to run in parallel per agent")
+	cmd.Flags().IntVar(&conflictRetries, "conflict-retries", 0, "Retry a test's run rejected because another run is in progress this many times (0-10), with backoff capped at 30s")
+	cmd.Flags().DurationVar(&delay, "delay", 0, "Wait this long between test cases (per worker) and between agents to avoid rate limiting")
+	cmd.Flags().Float64Var(&passRate, "pass-rate", 0, "Fraction of tests (0-1) that must pass for each suite; exit non-zero when a suite falls below it")
 
 	return cmd
 }
@@ -168,7 +388,7 @@ func evalOutputPaths(outputDir, agentName string, timestampSuffix bool) (jsonPat
 	return
 }
 
-func runEvalForAgent(client *api.Client, target Target, spec agent.AgentSpec, outputDir, specDir string, timestampSuffix bool, eo evalOptions) error {
+func runEvalForAgent(client *api.Client, target Target, spec agent.AgentSpec, outputDir, specDir string, timestampSuffix bool, eo evalOptions) (EvalSummary, error) {
 	report := EvalReport{
 		AgentName:   spec.Name,
 		Database:    target.Database,
@@ -180,52 +400,267 @@ func runEvalForAgent(client *api.Client, target Target, spec agent.AgentSpec, ou
 
 	tests := spec.Eval.Tests
 	fmt.Fprintf(os.Stderr, "Evaluating %s (%d tests)...\n", spec.Name, len(tests))
+	eo.progress = newEvalProgress(os.Stderr, len(tests), term.IsTerminal(int(os.Stderr.Fd())), eo.quiet)
+
+	concurrency := max(eo.concurrency, 1)
+	eo.progress.workers = concurrency
+
+	// Run test cases through a worker pool. Results are stored by index so
+	// the report keeps the spec order regardless of completion order.
+	results := make([]EvalResult, len(tests))
+	completed := make([]bool, len(tests))
+	var mu sync.Mutex
+	failed := false
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ran := false
+			for i := range indices {
+				mu.Lock()
+				skip := eo.failFast && failed
+				mu.Unlock()
+				if skip {
+					continue
+				}
+				if ran && eo.delay > 0 {
+					time.Sleep(eo.delay)
+				}
+				ran = true
+				start := time.Now()
+				result := runEvalTest(client, target, spec.Name, tests[i], i+1, len(tests), specDir, eo)
+				duration := time.Since(start)
+				result.DurationMs = duration.Milliseconds()
+
+				mu.Lock()
+				results[i] = result
+				completed[i] = true
+				if !result.Passed {
+					failed = true
+				}
+				eo.progress.record(duration)
+				report.Results = completedResults(results, completed)
+				if err := writeEvalJSONLine(eo.jsonlOut, evalJSONLResult{Type: "result", AgentName: spec.Name, EvalResult: result}); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to write JSONL result: %v\n", err)
+				}
+				// Write intermediate JSON after each test
+				if !eo.summaryOnly {
+					if err := writeEvalJSON(jsonPath, eo.fileReport(report)); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to write intermediate JSON: %v\n", err)
+					}
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range tests {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	eo.progress.clear()
+	summary := summarizeEval(report)
+	if notRun := len(tests) - summary.Total; notRun > 0 {
+		fmt.Fprintf(os.Stderr, "Stopped after a failing test (--fail-fast); %d of %d tests not run\n", notRun, len(tests))
+	}
+	if err := writeEvalJSONLine(eo.jsonlOut, evalJSONLSummary{Type: "summary", EvalSummary: summary}); err != nil {
+		return summary, fmt.Errorf("write JSONL summary: %w", err)
+	}
 
-	// Run each test case
-	for i, tc := range tests {
-		result := runEvalTest(client, target, spec.Name, tc, i+1, len(tests), specDir, eo)
-		report.Results = append(report.Results, result)
+	if eo.htmlPath != "" {
+		if err := writeEvalHTML(eo.htmlPath, report); err != nil {
+			return summary, fmt.Errorf("write HTML report: %w", err)
+		}
+	}
 
-		// Write intermediate JSON after each test
-		if err := writeEvalJSON(jsonPath, report); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to write intermediate JSON: %v\n", err)
+	if eo.summaryOnly {
+		fmt.Fprintf(os.Stderr, "\nResults: %d/%d passed\n", summary.Passed, summary.Total)
+		fmt.Fprintf(os.Stderr, "Elapsed: %s\n", formatEvalDuration(eo.progress.elapsed()))
+		printSuiteVerdict(summary, eo.passRateThreshold)
+		if eo.htmlPath != "" {
+			fmt.Fprintf(os.Stderr, "HTML report: %s\n", eo.htmlPath)
 		}
+		return summary, writeEvalSummary(eo.summaryOut, summary)
 	}
 
 	// Write final JSON
-	if err := writeEvalJSON(jsonPath, report); err != nil {
-		return fmt.Errorf("write JSON report: %w", err)
+	fileReport := eo.fileReport(report)
+	if err := writeEvalJSON(jsonPath, fileReport); err != nil {
+		return summary, fmt.Errorf("write JSON report: %w", err)
 	}
 
 	// Write Markdown report
-	if err := writeEvalMarkdown(mdPath, report); err != nil {
-		return fmt.Errorf("write Markdown report: %w", err)
+	if err := writeEvalMarkdown(mdPath, fileReport); err != nil {
+		return summary, fmt.Errorf("write Markdown report: %w", err)
 	}
 
 	// Print summary
-	passed := 0
+	fmt.Fprintf(os.Stderr, "\nResults: %d/%d passed\n", summary.Passed, summary.Total)
+	fmt.Fprintf(os.Stderr, "Elapsed: %s\n", formatEvalDuration(eo.progress.elapsed()))
+	printSuiteVerdict(summary, eo.passRateThreshold)
+	fmt.Fprintf(os.Stderr, "Output: %s\n", jsonPath)
+	fmt.Fprintf(os.Stderr, "Report: %s\n", mdPath)
+	if eo.htmlPath != "" {
+		fmt.Fprintf(os.Stderr, "HTML report: %s\n", eo.htmlPath)
+	}
+
+	return summary, nil
+}
+
+// failuresOnlyReport returns a copy of report without its passed results,
+// recording how many were dropped in OmittedPassed.
+func failuresOnlyReport(report EvalReport) EvalReport {
+	filtered := report
+	filtered.Results = []EvalResult{}
 	for _, r := range report.Results {
 		if r.Passed {
-			passed++
+			filtered.OmittedPassed++
+			continue
 		}
+		filtered.Results = append(filtered.Results, r)
 	}
-	fmt.Fprintf(os.Stderr, "\nResults: %d/%d passed\n", passed, len(report.Results))
-	fmt.Fprintf(os.Stderr, "Output: %s\n", jsonPath)
-	fmt.Fprintf(os.Stderr, "Report: %s\n", mdPath)
+	return filtered
+}
+
+// completedResults returns the finished results in spec order.
+func completedResults(results []EvalResult, completed []bool) []EvalResult {
+	out := make([]EvalResult, 0, len(results))
+	for i, r := range results {
+		if completed[i] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// suitePasses reports whether the share of passed tests meets threshold (0–1).
+func suitePasses(summary EvalSummary, threshold float64) bool {
+	if summary.Total == 0 {
+		return true
+	}
+	return float64(summary.Passed)/float64(summary.Total) >= threshold
+}
+
+// suiteVerdict formats the suite result against threshold, e.g.
+// "Suite: PASS (92% >= 90%)".
+func suiteVerdict(summary EvalSummary, threshold float64) string {
+	rate := 1.0
+	if summary.Total > 0 {
+		rate = float64(summary.Passed) / float64(summary.Total)
+	}
+	r, t := formatPercents(rate, threshold)
+	if suitePasses(summary, threshold) {
+		return fmt.Sprintf("Suite: PASS (%s >= %s)", r, t)
+	}
+	return fmt.Sprintf("Suite: FAIL (%s < %s)", r, t)
+}
+
+// printSuiteVerdict prints the suite verdict to stderr when a threshold is set.
+func printSuiteVerdict(summary EvalSummary, threshold float64) {
+	if threshold > 0 {
+		fmt.Fprintln(os.Stderr, suiteVerdict(summary, threshold))
+	}
+}
+
+// formatPercent renders a 0–1 fraction as a percentage with at most the given
+// number of decimals.
+func formatPercent(v float64, decimals int) string {
+	scale := math.Pow(10, float64(decimals))
+	return strconv.FormatFloat(math.Round(v*100*scale)/scale, 'f', -1, 64) + "%"
+}
+
+// formatPercents renders a pass rate and its threshold as percentages. One
+// decimal is used by default; more are added until unequal values print
+// differently, so a rate of 0.8995 never shows as "90% < 90%".
+func formatPercents(rate, threshold float64) (string, string) {
+	decimals := 1
+	r, t := formatPercent(rate, decimals), formatPercent(threshold, decimals)
+	for rate != threshold && r == t && decimals < 6 {
+		decimals++
+		r, t = formatPercent(rate, decimals), formatPercent(threshold, decimals)
+	}
+	return r, t
+}
+
+// passRateError returns an exit-code error naming the agents whose suites
+// fell below their pass-rate threshold, or nil when there are none.
+func passRateError(agents []string) error {
+	if len(agents) == 0 {
+		return nil
+	}
+	return ExitCodeError{
+		Code: ExitFailure,
+		Err:  fmt.Errorf("eval suite below pass rate threshold: %s", strings.Join(agents, ", ")),
+	}
+}
+
+// failedTestsError returns an exit-code error such as "3 of 12 eval tests
+// failed: sales, hr" naming the agents with failed test cases, or nil when
+// no test failed.
+func failedTestsError(failed, total int, agents []string) error {
+	if failed == 0 {
+		return nil
+	}
+	return ExitCodeError{
+		Code: ExitFailure,
+		Err:  fmt.Errorf("%d of %d eval tests failed: %s", failed, total, strings.Join(agents, ", ")),
+	}
+}
 
-	return nil
+// summarizeEval counts passed tests and collects the labels of failed ones.
+func summarizeEval(report EvalReport) EvalSummary {
+	summary := EvalSummary{AgentName: report.AgentName, Total: len(report.Results)}
+	for _, r := range report.Results {
+		if r.Passed {
+			summary.Passed++
+			continue
+		}
+		label := r.Question
+		if label == "" {
+			label = r.Command
+		}
+		summary.Failed = append(summary.Failed, label)
+	}
+	return summary
+}
+
+// writeEvalSummary writes summary as a single JSON line.
+func writeEvalSummary(w io.Writer, summary EvalSummary) error {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("marshal eval summary: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// writeEvalJSONLine appends v to w as a single JSON line. A nil w is a no-op.
+func writeEvalJSONLine(w io.Writer, v any) error {
+	if w == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
 }
 
 func runEvalTest(client *api.Client, target Target, agentName string, tc agent.EvalTestCase, num, total int, specDir string, eo evalOptions) EvalResult {
 	result := EvalResult{
 		Question:         tc.Question,
+		Conversation:     tc.Conversation,
 		ExpectedTools:    tc.ExpectedTools,
 		ActualTools:      []string{},
 		Command:          tc.Command,
 		ExpectedResponse: tc.ExpectedResponse,
 	}
 
-	ctx, cancel := context.WithTimeout(commandContext("eval"), 15*time.Minute)
+	ctx, cancel := context.WithTimeout(commandContext("eval"), eo.testTimeout())
 	defer cancel()
 
 	// Run agent only when question is specified
@@ -234,35 +669,74 @@ func runEvalTest(client *api.Client, target Target, agentName string, tc agent.E
 		if err != nil {
 			result.Error = fmt.Sprintf("create thread: %v", err)
 			result.Passed = false
-			fmt.Fprintf(os.Stderr, "[%d/%d] %s ... ❌ (%s)\n", num, total, tc.Question, result.Error)
+			if !eo.quiet {
+				eo.progress.print(func() {
+					fmt.Fprintf(os.Stderr, "[%d/%d] %s ... ❌ (%s)\n", num, total, tc.Question, result.Error)
+				})
+			}
 			return result
 		}
 		result.ThreadID = threadID
 
-		zero := int64(0)
-		req := api.RunAgentRequest{
-			Messages: []api.Message{
-				api.NewTextMessage("user", tc.Question),
-			},
-			ThreadID:        threadID,
-			ParentMessageID: &zero,
-		}
-
 		var toolsUsed []string
 		var responseText strings.Builder
+		var messageID int64
 
 		runOpts := api.RunAgentOptions{
+			StreamIdleTimeout: eo.streamIdleTimeout,
+			ConflictRetries:   eo.conflictRetries,
 			OnToolUse: func(name string, input json.RawMessage) {
 				toolsUsed = append(toolsUsed, name)
 			},
 			OnTextDelta: func(delta string) {
 				responseText.WriteString(delta)
 			},
+			OnMetadata: func(_ string, mid int64) {
+				messageID = mid
+			},
 		}
 
-		_, err = client.RunAgent(ctx, target.Database, target.Schema, agentName, req, runOpts)
-		if err != nil {
-			result.Error = fmt.Sprintf("run agent: %v", err)
+		// Earlier conversation turns build up the thread; each one is the
+		// parent of the next, and only the reply to the question is checked.
+		parentID := int64(0)
+		for i, turn := range tc.Conversation {
+			messageID = 0
+			parent := parentID
+			req := api.RunAgentRequest{
+				Messages:        []api.Message{api.NewTextMessage("user", turn)},
+				ThreadID:        threadID,
+				ParentMessageID: &parent,
+			}
+			if _, err := client.RunAgent(ctx, target.Database, target.Schema, agentName, req, runOpts); err != nil {
+				result.Error = fmt.Sprintf("conversation turn %d: %v", i+1, err)
+				break
+			}
+			if messageID == 0 {
+				result.Error = fmt.Sprintf("conversation turn %d: response has no message ID to continue from", i+1)
+				break
+			}
+			parentID = messageID
+		}
+		toolsUsed = nil
+		responseText.Reset()
+
+		if result.Error == "" {
+			req := api.RunAgentRequest{
+				Messages: []api.Message{
+					api.NewTextMessage("user", tc.Question),
+				},
+				ThreadID:        threadID,
+				ParentMessageID: &parentID,
+			}
+			if eo.responseSchema != nil {
+				req.ResponseFormat = jsonResponseFormat(eo.responseSchema)
+			}
+			start := time.Now()
+			if _, err := client.RunAgent(ctx, target.Database, target.Schema, agentName, req, runOpts); err != nil {
+				result.Error = fmt.Sprintf("run agent: %v", err)
+			}
+			result.ResponseMs = time.Since(start).Milliseconds()
+			result.MaxResponseMs = effectiveMaxResponseMs(tc, eo.maxResponseMs)
 		}
 
 		toolsUsed = filterIgnoredTools(toolsUsed, eo.ignoreTools)
@@ -270,6 +744,22 @@ func runEvalTest(client *api.Client, target Target, agentName string, tc agent.E
 		result.Response = responseText.String()
 		result.ToolMatch = checkToolMatch(tc.ExpectedTools, toolsUsed)
 		result.ExtraToolCalls = hasExtraToolCalls(tc.ExpectedTools, toolsUsed)
+
+		if eo.responseSchema != nil && result.Error == "" {
+			valid := true
+			if err := validateResponseJSON(result.Response, eo.responseSchema); err != nil {
+				valid = false
+				result.ResponseSchemaError = err.Error()
+			}
+			result.ResponseSchemaValid = &valid
+		}
+	}
+
+	if len(tc.ExpectedSubstrings) > 0 {
+		result.ExpectedSubstrings = tc.ExpectedSubstrings
+		result.MissingSubstrings = missingSubstrings(result.Response, tc.ExpectedSubstrings, tc.ExpectedSubstringsIgnoreCase)
+		match := len(result.MissingSubstrings) == 0
+		result.SubstringMatch = &match
 	}
 
 	// Run command if specified
@@ -294,10 +784,10 @@ func runEvalTest(client *api.Client, target Target, agentName string, tc agent.E
 		}
 	}
 
-	// Run LLM judge if expected_response is set
-	if strings.TrimSpace(tc.ExpectedResponse) != "" && result.Response != "" {
+	// Run LLM judge if expected_response is set and the run did not error
+	if strings.TrimSpace(tc.ExpectedResponse) != "" && result.Response != "" && result.Error == "" {
 		result.JudgeModel = eo.judgeModel
-		jr, err := judgeResponse(ctx, client, eo.judgeModel, tc.Question, tc.ExpectedResponse, result.Response)
+		jr, err := judgeResponse(ctx, client, eo.judgeModel, eo.judgePrompt, tc.Question, tc.ExpectedResponse, result.Response)
 		if err != nil {
 			result.ResponseScoreErr = err.Error()
 		} else {
@@ -309,7 +799,16 @@ func runEvalTest(client *api.Client, target Target, agentName string, tc agent.E
 	threshold := effectiveThreshold(tc, eo.responseScoreThreshold)
 	result.Passed = computeOverallPass(result, tc, threshold)
 
-	// Console output
+	if !eo.quiet {
+		eo.progress.print(func() {
+			printEvalTestResult(result, tc, num, total, threshold)
+		})
+	}
+	return result
+}
+
+// printEvalTestResult prints the console line(s) for a finished test.
+func printEvalTestResult(result EvalResult, tc agent.EvalTestCase, num, total, threshold int) {
 	label := tc.Question
 	if label == "" {
 		label = tc.Command
@@ -325,9 +824,18 @@ func runEvalTest(client *api.Client, target Target, agentName string, tc agent.E
 		if result.CommandPassed != nil && !*result.CommandPassed {
 			reasons = append(reasons, fmt.Sprintf("command failed: %s", result.CommandError))
 		}
+		if result.SubstringMatch != nil && !*result.SubstringMatch {
+			reasons = append(reasons, fmt.Sprintf("missing substrings: %s", strings.Join(result.MissingSubstrings, ", ")))
+		}
 		if result.ResponseScore != nil && threshold > 0 && *result.ResponseScore < threshold {
 			reasons = append(reasons, fmt.Sprintf("score %d < threshold %d", *result.ResponseScore, threshold))
 		}
+		if result.ResponseSchemaValid != nil && !*result.ResponseSchemaValid {
+			reasons = append(reasons, fmt.Sprintf("schema: %s", result.ResponseSchemaError))
+		}
+		if responseTooSlow(result) {
+			reasons = append(reasons, fmt.Sprintf("response %dms > max %dms", result.ResponseMs, result.MaxResponseMs))
+		}
 		fmt.Fprintf(os.Stderr, "[%d/%d] %s ... ❌ (%s)\n", num, total, label, strings.Join(reasons, "; "))
 	} else if result.ExtraToolCalls {
 		fmt.Fprintf(os.Stderr, "[%d/%d] %s ... ⚠️ (tools: %s) extra tool calls detected\n", num, total, label, strings.Join(result.ActualTools, ", "))
@@ -347,7 +855,6 @@ func runEvalTest(client *api.Client, target Target, agentName string, tc agent.E
 		}
 	}
 
-	return result
 }
 
 // runEvalCommand executes a command via sh -c, passing input as JSON on stdin.
@@ -381,8 +888,10 @@ func runEvalCommand(ctx context.Context, command string, input CommandInput, wor
 }
 
 // computeOverallPass determines the overall pass/fail for a test case.
-// Tool match (if expected_tools specified), command (if specified), and
-// response score threshold (if > 0) must all pass.
+// Tool match (if expected_tools specified), substring match (if
+// expected_substrings specified), command (if specified), response score
+// threshold (if > 0), JSON schema validation (if a schema was given) and
+// response latency (if max_response_ms > 0) must all pass.
 func computeOverallPass(result EvalResult, tc agent.EvalTestCase, responseScoreThreshold int) bool {
 	if result.Error != "" {
 		return false
@@ -390,15 +899,49 @@ func computeOverallPass(result EvalResult, tc agent.EvalTestCase, responseScoreT
 	if len(tc.ExpectedTools) > 0 && !result.ToolMatch {
 		return false
 	}
+	if result.SubstringMatch != nil && !*result.SubstringMatch {
+		return false
+	}
 	if result.CommandPassed != nil && !*result.CommandPassed {
 		return false
 	}
 	if responseScoreThreshold > 0 && result.ResponseScore != nil && *result.ResponseScore < responseScoreThreshold {
 		return false
 	}
+	if result.ResponseSchemaValid != nil && !*result.ResponseSchemaValid {
+		return false
+	}
+	if responseTooSlow(result) {
+		return false
+	}
 	return true
 }
 
+// responseTooSlow reports whether the agent's reply exceeded the test's
+// max_response_ms.
+func responseTooSlow(result EvalResult) bool {
+	return result.MaxResponseMs > 0 && result.ResponseMs > int64(result.MaxResponseMs)
+}
+
+// missingSubstrings returns the entries of want that do not occur in
+// response, in their original order.
+func missingSubstrings(response string, want []string, ignoreCase bool) []string {
+	if ignoreCase {
+		response = strings.ToLower(response)
+	}
+	var missing []string
+	for _, sub := range want {
+		needle := sub
+		if ignoreCase {
+			needle = strings.ToLower(sub)
+		}
+		if !strings.Contains(response, needle) {
+			missing = append(missing, sub)
+		}
+	}
+	return missing
+}
+
 // hasExtraToolCalls returns true if actual tools contain duplicate calls
 // or tools not listed in expected, suggesting the agent struggled to find results.
 func hasExtraToolCalls(expected, actual []string) bool {
@@ -450,6 +993,9 @@ func generateEvalMarkdown(report EvalReport) string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "## Agent Evaluation: %s\n\n", report.AgentName)
+	if report.OmittedPassed > 0 {
+		fmt.Fprintf(&b, "> Failures only: %d passed test(s) omitted.\n\n", report.OmittedPassed)
+	}
 
 	// Check optional columns
 	hasCommand := false
@@ -482,15 +1028,12 @@ func generateEvalMarkdown(report EvalReport) string {
 	passed := 0
 	warned := 0
 	for i, r := range report.Results {
-		icon := "✅"
-		if !r.Passed {
-			icon = "❌"
-		} else if r.ExtraToolCalls {
-			icon = "⚠️"
-			passed++
-			warned++
-		} else {
+		icon := evalResultIcon(r)
+		if r.Passed {
 			passed++
+			if r.ExtraToolCalls {
+				warned++
+			}
 		}
 
 		cmdStatus := ""
@@ -523,7 +1066,7 @@ func generateEvalMarkdown(report EvalReport) string {
 		b.WriteString(row)
 	}
 
-	fmt.Fprintf(&b, "\n**Result: %d/%d passed", passed, len(report.Results))
+	fmt.Fprintf(&b, "\n**Result: %d/%d passed", passed+report.OmittedPassed, len(report.Results)+report.OmittedPassed)
 	if warned > 0 {
 		fmt.Fprintf(&b, " (%d warned)", warned)
 	}
@@ -531,14 +1074,17 @@ func generateEvalMarkdown(report EvalReport) string {
 
 	// Detail sections
 	for i, r := range report.Results {
-		icon := "✅"
-		if !r.Passed {
-			icon = "❌"
-		} else if r.ExtraToolCalls {
-			icon = "⚠️"
-		}
+		icon := evalResultIcon(r)
 		fmt.Fprintf(&b, "\n<details>\n<summary>Q%d: %s %s</summary>\n\n", i+1, r.Question, icon)
 
+		if len(r.Conversation) > 0 {
+			b.WriteString("**Earlier Turns:**\n")
+			for j, turn := range r.Conversation {
+				fmt.Fprintf(&b, "%d. %s\n", j+1, turn)
+			}
+			b.WriteString("\n")
+		}
+
 		if len(r.ExpectedTools) > 0 {
 			fmt.Fprintf(&b, "**Expected Tools:** %s\n", formatToolList(r.ExpectedTools))
 		}
@@ -569,6 +1115,15 @@ func generateEvalMarkdown(report EvalReport) string {
 			}
 		}
 
+		if len(r.ExpectedSubstrings) > 0 {
+			fmt.Fprintf(&b, "\n**Expected Substrings:** %s\n", formatToolList(r.ExpectedSubstrings))
+			if r.SubstringMatch != nil && *r.SubstringMatch {
+				b.WriteString("**Substring Result:** ✅ all found\n")
+			} else if r.SubstringMatch != nil {
+				fmt.Fprintf(&b, "**Substring Result:** ❌ missing %s\n", formatToolList(r.MissingSubstrings))
+			}
+		}
+
 		if r.ExpectedResponse != "" {
 			fmt.Fprintf(&b, "\n**Expected Response:** %s\n", r.ExpectedResponse)
 		}
@@ -584,6 +1139,16 @@ func generateEvalMarkdown(report EvalReport) string {
 		if r.ResponseScoreErr != "" {
 			fmt.Fprintf(&b, "**Score Error:** %s\n", r.ResponseScoreErr)
 		}
+		if r.ResponseSchemaValid != nil {
+			if *r.ResponseSchemaValid {
+				b.WriteString("\n**JSON Schema:** ✅ valid\n")
+			} else {
+				fmt.Fprintf(&b, "\n**JSON Schema:** ❌ %s\n", r.ResponseSchemaError)
+			}
+		}
+		if r.ResponseMs > 0 {
+			fmt.Fprintf(&b, "\n**Response Time:** %s\n", formatResponseTime(r))
+		}
 
 		fmt.Fprintf(&b, "\n**Response:**\n\n%s\n", r.Response)
 		b.WriteString("\n</details>\n")
@@ -592,6 +1157,33 @@ func generateEvalMarkdown(report EvalReport) string {
 	return b.String()
 }
 
+// formatResponseTime renders the reply latency of r with its limit, e.g.
+// "1250 ms (max 1000 ms) ❌".
+func formatResponseTime(r EvalResult) string {
+	s := fmt.Sprintf("%d ms", r.ResponseMs)
+	if r.MaxResponseMs > 0 {
+		icon := "✅"
+		if responseTooSlow(r) {
+			icon = "❌"
+		}
+		s += fmt.Sprintf(" (max %d ms) %s", r.MaxResponseMs, icon)
+	}
+	return s
+}
+
+// evalResultIcon returns the report icon of r: passed, passed with extra
+// tool calls, or failed.
+func evalResultIcon(r EvalResult) string {
+	switch {
+	case !r.Passed:
+		return "❌"
+	case r.ExtraToolCalls:
+		return "⚠️"
+	default:
+		return "✅"
+	}
+}
+
 func formatToolList(tools []string) string {
 	if len(tools) == 0 {
 		return "(none)"
@@ -0,0 +1,282 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// evalHTMLRow is one test result prepared for the HTML report.
+type evalHTMLRow struct {
+	Num    int
+	Icon   string
+	Status string // CSS class: pass, warn or fail
+	EvalResult
+	CommandStatus       string
+	CommandOK           bool
+	Score               string
+	HasSubstringVerdict bool
+	SubstringsFound     bool
+	HasSchemaVerdict    bool
+	SchemaValid         bool
+	ResponseTime        string
+}
+
+// evalHTMLSegment is one part of the summary bar: the results with one
+// status and their share of the bar width in percent.
+type evalHTMLSegment struct {
+	Status string // CSS class: pass, warn or fail
+	Label  string
+	Count  int
+	Width  string
+}
+
+// evalHTMLView is the data rendered by evalHTMLTemplate. It carries the same
+// information as the Markdown report.
+type evalHTMLView struct {
+	Report     EvalReport
+	Rows       []evalHTMLRow
+	HasCommand bool
+	HasScore   bool
+	Passed     int
+	Warned     int
+	Total      int
+	Bar        []evalHTMLSegment
+}
+
+var evalHTMLTemplate = template.Must(template.New("eval").Funcs(template.FuncMap{
+	"badges": evalHTMLBadges,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Agent Evaluation: {{.Report.AgentName}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 1100px; padding: 0 1rem; color: #1f2328; line-height: 1.5; }
+h1 { font-size: 1.6rem; margin-bottom: 0.25rem; }
+.meta { color: #59636e; margin-top: 0; }
+.summary { font-size: 1.1rem; font-weight: 600; margin: 1rem 0; }
+.bar { display: flex; height: 1.25rem; border-radius: 6px; overflow: hidden; background: #eaeef2; margin: 0.5rem 0; }
+.bar span.pass { background: #1a7f37; }
+.bar span.warn { background: #bf8700; }
+.bar span.fail { background: #cf222e; }
+.legend { color: #59636e; margin: 0 0 1.5rem; }
+.legend span::before { content: ""; display: inline-block; width: 0.75rem; height: 0.75rem; border-radius: 2px; margin: 0 0.3rem 0 0.75rem; vertical-align: -0.05rem; }
+.legend span:first-child::before { margin-left: 0; }
+.legend span.pass::before { background: #1a7f37; }
+.legend span.warn::before { background: #bf8700; }
+.legend span.fail::before { background: #cf222e; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5rem; }
+th, td { border: 1px solid #d1d9e0; padding: 0.4rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+.badge { display: inline-block; background: #ddf4ff; color: #0969da; border-radius: 999px; padding: 0 0.5rem; margin: 0.1rem; font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.85em; }
+.none { color: #59636e; font-style: italic; }
+details { border: 1px solid #d1d9e0; border-radius: 6px; margin: 0.5rem 0; padding: 0.5rem 0.75rem; }
+details.fail { border-left: 4px solid #cf222e; }
+details.warn { border-left: 4px solid #bf8700; }
+details.pass { border-left: 4px solid #1a7f37; }
+summary { cursor: pointer; font-weight: 600; }
+dt { font-weight: 600; margin-top: 0.5rem; }
+dd { margin-left: 1rem; }
+pre { background: #f6f8fa; border-radius: 6px; padding: 0.75rem; overflow-x: auto; white-space: pre-wrap; }
+.warning { color: #9a6700; }
+.error { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Agent Evaluation: {{.Report.AgentName}}</h1>
+<p class="meta">{{.Report.Database}}.{{.Report.Schema}} &middot; evaluated at {{.Report.EvaluatedAt}}</p>
+<table>
+<thead>
+<tr><th>#</th><th>Question</th><th>Expected Tools</th><th>Actual Tools</th>{{if .HasCommand}}<th>Command</th>{{end}}{{if .HasScore}}<th>Score</th>{{end}}<th>Result</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr class="{{.Status}}"><td>{{.Num}}</td><td>{{.Question}}</td><td>{{badges .ExpectedTools}}</td><td>{{badges .ActualTools}}</td>{{if $.HasCommand}}<td>{{.CommandStatus}}</td>{{end}}{{if $.HasScore}}<td>{{.Score}}</td>{{end}}<td>{{.Icon}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<p class="summary">Result: {{.Passed}}/{{.Total}} passed{{if .Warned}} ({{.Warned}} warned){{end}}</p>
+{{- if .Bar}}
+<div class="bar">{{range .Bar}}<span class="{{.Status}}" style="width: {{.Width}}%" title="{{.Count}} {{.Label}}"></span>{{end}}</div>
+<p class="legend">{{range .Bar}}<span class="{{.Status}}">{{.Count}} {{.Label}}</span>{{end}}</p>
+{{- end}}
+{{range .Rows}}
+<details class="{{.Status}}">
+<summary>Q{{.Num}}: {{.Question}} {{.Icon}}</summary>
+<dl>
+{{- if .Conversation}}
+<dt>Earlier Turns</dt><dd><ol>{{range .Conversation}}<li>{{.}}</li>{{end}}</ol></dd>
+{{- end}}
+{{- if .ExpectedTools}}
+<dt>Expected Tools</dt><dd>{{badges .ExpectedTools}}</dd>
+{{- end}}
+<dt>Actual Tools</dt><dd>{{badges .ActualTools}}</dd>
+{{- if .ExtraToolCalls}}
+<dt class="warning">Warning</dt><dd class="warning">Extra tool calls detected. The agent may have failed to retrieve the expected results.</dd>
+{{- end}}
+{{- if .Error}}
+<dt class="error">Error</dt><dd class="error">{{.Error}}</dd>
+{{- end}}
+{{- if .Command}}
+<dt>Command</dt><dd><code>{{.Command}}</code></dd>
+{{- if .CommandStatus}}
+<dt>Command Result</dt><dd>{{.CommandStatus}} {{if .CommandOK}}passed{{else}}failed{{end}}</dd>
+{{- end}}
+{{- if .CommandError}}
+<dt>Command Error</dt><dd>{{.CommandError}}</dd>
+{{- end}}
+{{- if .CommandOutput}}
+<dt>Command Output</dt><dd><pre>{{.CommandOutput}}</pre></dd>
+{{- end}}
+{{- end}}
+{{- if .ExpectedSubstrings}}
+<dt>Expected Substrings</dt><dd>{{badges .ExpectedSubstrings}}</dd>
+{{- if .HasSubstringVerdict}}
+<dt>Substring Result</dt><dd>{{if .SubstringsFound}}✅ all found{{else}}❌ missing {{badges .MissingSubstrings}}{{end}}</dd>
+{{- end}}
+{{- end}}
+{{- if .ExpectedResponse}}
+<dt>Expected Response</dt><dd>{{.ExpectedResponse}}</dd>
+{{- end}}
+{{- if .Score}}
+<dt>Response Score</dt><dd>{{.Score}}/100</dd>
+{{- if .ResponseScoreReason}}
+<dt>Score Reasoning</dt><dd>{{.ResponseScoreReason}}</dd>
+{{- end}}
+{{- if .JudgeModel}}
+<dt>Judge Model</dt><dd>{{.JudgeModel}}</dd>
+{{- end}}
+{{- end}}
+{{- if .ResponseScoreErr}}
+<dt>Score Error</dt><dd>{{.ResponseScoreErr}}</dd>
+{{- end}}
+{{- if .HasSchemaVerdict}}
+<dt>JSON Schema</dt><dd>{{if .SchemaValid}}✅ valid{{else}}❌ {{.ResponseSchemaError}}{{end}}</dd>
+{{- end}}
+{{- if .ResponseTime}}
+<dt>Response Time</dt><dd>{{.ResponseTime}}</dd>
+{{- end}}
+<dt>Response</dt><dd><pre>{{.Response}}</pre></dd>
+</dl>
+</details>
+{{- end}}
+</body>
+</html>
+`))
+
+// evalHTMLBadges renders tools (or substrings) as badges, or "(none)".
+func evalHTMLBadges(items []string) template.HTML {
+	if len(items) == 0 {
+		return `<span class="none">(none)</span>`
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = `<span class="badge">` + template.HTMLEscapeString(item) + `</span>`
+	}
+	return template.HTML(strings.Join(parts, " "))
+}
+
+// generateEvalHTML renders report as a self-contained HTML page with inline
+// CSS and no external resources.
+func generateEvalHTML(report EvalReport) (string, error) {
+	view := evalHTMLView{Report: report, Total: len(report.Results)}
+	for i, r := range report.Results {
+		row := evalHTMLRow{Num: i + 1, Icon: evalResultIcon(r), Status: "pass", EvalResult: r}
+		switch {
+		case !r.Passed:
+			row.Status = "fail"
+		case r.ExtraToolCalls:
+			row.Status = "warn"
+		}
+		if r.Passed {
+			view.Passed++
+			if r.ExtraToolCalls {
+				view.Warned++
+			}
+		}
+		if r.Command != "" {
+			view.HasCommand = true
+			if r.CommandPassed != nil {
+				row.CommandOK = *r.CommandPassed
+				row.CommandStatus = "❌"
+				if row.CommandOK {
+					row.CommandStatus = "✅"
+				}
+			}
+		}
+		if r.ResponseScore != nil {
+			view.HasScore = true
+			row.Score = fmt.Sprintf("%d", *r.ResponseScore)
+		}
+		if r.SubstringMatch != nil {
+			row.HasSubstringVerdict = true
+			row.SubstringsFound = *r.SubstringMatch
+		}
+		if r.ResponseSchemaValid != nil {
+			row.HasSchemaVerdict = true
+			row.SchemaValid = *r.ResponseSchemaValid
+		}
+		if r.ResponseMs > 0 {
+			row.ResponseTime = formatResponseTime(r)
+		}
+		view.Rows = append(view.Rows, row)
+	}
+	view.Bar = evalHTMLBar(view.Passed-view.Warned, view.Warned, view.Total-view.Passed)
+
+	var b bytes.Buffer
+	if err := evalHTMLTemplate.Execute(&b, view); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// evalHTMLBar returns the summary bar segments for the given counts,
+// skipping statuses without results.
+func evalHTMLBar(passed, warned, failed int) []evalHTMLSegment {
+	total := passed + warned + failed
+	if total == 0 {
+		return nil
+	}
+	var bar []evalHTMLSegment
+	for _, s := range []evalHTMLSegment{
+		{Status: "pass", Label: "passed", Count: passed},
+		{Status: "warn", Label: "warned", Count: warned},
+		{Status: "fail", Label: "failed", Count: failed},
+	} {
+		if s.Count == 0 {
+			continue
+		}
+		s.Width = strconv.FormatFloat(float64(s.Count)*100/float64(total), 'f', 1, 64)
+		bar = append(bar, s)
+	}
+	return bar
+}
+
+// evalHTMLPath returns where the HTML report of agentName is written. When
+// several agents are evaluated, the agent name is appended to the base name of
+// path so that each agent gets its own file.
+func evalHTMLPath(path, agentName string, multiple bool) string {
+	if !multiple {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + agentName + ext
+}
+
+func writeEvalHTML(path string, report EvalReport) error {
+	html, err := generateEvalHTML(report)
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(html), 0o644)
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/regression"
+)
+
+func TestGenerateEvalHTML(t *testing.T) {
+	score := 85
+	report := EvalReport{
+		AgentName:   "TEST-AGENT",
+		Database:    "TEST_DB",
+		Schema:      "PUBLIC",
+		EvaluatedAt: "2025-01-15T10:30:00Z",
+		Results: []EvalResult{
+			{
+				Question:      "売上データを教えて",
+				ExpectedTools: []string{"sample_semantic_view"},
+				ActualTools:   []string{"sample_semantic_view"},
+				ToolMatch:     true,
+				Passed:        true,
+				Response:      "売上データによると...",
+				ResponseScore: &score,
+			},
+			{
+				Question:      "Is <b>bold</b> escaped?",
+				ExpectedTools: []string{"snowflake_docs_service"},
+				ActualTools:   []string{},
+				Passed:        false,
+				Error:         "create thread: boom",
+			},
+		},
+	}
+
+	html, err := generateEvalHTML(report)
+	if err != nil {
+		t.Fatalf("generateEvalHTML: %v", err)
+	}
+
+	for _, want := range []string{
+		"<title>Agent Evaluation: TEST-AGENT</title>",
+		"<h1>Agent Evaluation: TEST-AGENT</h1>",
+		"TEST_DB.PUBLIC",
+		`<p class="summary">Result: 1/2 passed</p>`,
+		`<span class="pass" style="width: 50.0%" title="1 passed"></span>`,
+		`<span class="fail" style="width: 50.0%" title="1 failed"></span>`,
+		`<span class="fail">1 failed</span>`,
+		"<th>Score</th>",
+		`<details class="pass">`,
+		"<summary>Q1: 売上データを教えて ✅</summary>",
+		`<details class="fail">`,
+		"<summary>Q2: Is &lt;b&gt;bold&lt;/b&gt; escaped? ❌</summary>",
+		`<span class="badge">sample_semantic_view</span>`,
+		`<span class="none">(none)</span>`,
+		"create thread: boom",
+		"<style>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML missing %q", want)
+		}
+	}
+	if strings.Contains(html, "<b>bold</b>") {
+		t.Error("question was not HTML-escaped")
+	}
+	if strings.Contains(html, "<link") || strings.Contains(html, "<script") {
+		t.Error("HTML report must not reference external resources")
+	}
+}
+
+func TestEvalHTMLBar(t *testing.T) {
+	bar := evalHTMLBar(2, 1, 0)
+	if len(bar) != 2 {
+		t.Fatalf("bar = %+v, want pass and warn segments only", bar)
+	}
+	if bar[0].Status != "pass" || bar[0].Width != "66.7" || bar[1].Status != "warn" || bar[1].Width != "33.3" {
+		t.Errorf("bar = %+v", bar)
+	}
+	if evalHTMLBar(0, 0, 0) != nil {
+		t.Error("empty report should have no bar")
+	}
+}
+
+func TestEvalHTMLPath(t *testing.T) {
+	if got := evalHTMLPath("out/report.html", "sales", false); got != "out/report.html" {
+		t.Errorf("single agent: got %q", got)
+	}
+	if got := evalHTMLPath("out/report.html", "sales", true); got != "out/report_sales.html" {
+		t.Errorf("multiple agents: got %q", got)
+	}
+}
+
+func TestRunEvalForAgent_WritesHTMLReport(t *testing.T) {
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	outDir := t.TempDir()
+	spec := agent.AgentSpec{
+		Name: "ci-agent",
+		Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+			{Question: "pass?", ExpectedTools: []string{"sales_view"}},
+		}},
+	}
+
+	htmlPath := filepath.Join(outDir, "html", "report.html")
+	eo := evalOptions{quiet: true, summaryOnly: true, summaryOut: &strings.Builder{}, htmlPath: htmlPath}
+	if _, err := runEvalForAgent(client, Target{Database: "DB", Schema: "SCH"}, spec, outDir, outDir, false, eo); err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+
+	data, err := os.ReadFile(htmlPath)
+	if err != nil {
+		t.Fatalf("expected HTML report: %v", err)
+	}
+	if !strings.Contains(string(data), "Q1: pass? ✅") {
+		t.Errorf("HTML report missing the test section:\n%s", data)
+	}
+}
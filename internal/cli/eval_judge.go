@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
+	"text/template"
+	"time"
 
 	"coragent/internal/agent"
 	"coragent/internal/api"
@@ -13,6 +16,20 @@ import (
 
 const defaultJudgeModel = "llama4-scout"
 
+// defaultJudgePromptTemplate is the judge prompt used when neither the spec
+// nor .coragent.toml sets eval.judge_prompt_template.
+const defaultJudgePromptTemplate = "You are an evaluation judge. Compare the actual response to the expected response for the given question.\n\n" +
+	"Question: {{.Question}}\n\nExpected Response: {{.Expected}}\n\nActual Response: {{.Actual}}\n\n" +
+	"Score the actual response from 0 to 100 based on how well it matches the expected response in meaning and correctness. " +
+	"Provide a brief reasoning."
+
+// judgePromptData is the data passed to the judge prompt template.
+type judgePromptData struct {
+	Question string
+	Expected string
+	Actual   string
+}
+
 // defaultIgnoreTools lists tool names that are excluded from eval tool matching
 // by default. These are utility tools (e.g. visualization) that agents may call
 // autonomously and should not affect tool-match evaluation.
@@ -24,7 +41,61 @@ var defaultIgnoreTools = []string{
 type evalOptions struct {
 	judgeModel             string
 	responseScoreThreshold int
+	maxResponseMs          int // agent-level latency limit for a test reply; 0 disables it
 	ignoreTools            []string
+	streamIdleTimeout      time.Duration
+	conflictRetries        int // retries of a run rejected with api.ConflictError
+	// timeout bounds each test case, including its command and judge
+	// call; 0 uses defaultRunTimeout.
+	timeout        time.Duration
+	responseSchema map[string]any
+	// summaryOnly skips the JSON/Markdown report files and writes a
+	// one-line JSON summary per agent to summaryOut instead.
+	summaryOnly bool
+	summaryOut  io.Writer
+	// passRateThreshold is the fraction of tests (0–1) that must pass for
+	// the suite verdict to be PASS; 0 disables the verdict.
+	passRateThreshold float64
+	// quiet hides per-test result lines and progress; only the final
+	// results are printed.
+	quiet bool
+	// concurrency is the number of test cases run at once; values below 1
+	// run them sequentially.
+	concurrency int
+	// delay is the pause a worker takes before each test case after its
+	// first, to stay under the agent endpoint's rate limit; 0 disables it.
+	delay time.Duration
+	// progress reports elapsed time and ETA; nil disables it.
+	progress *evalProgress
+	// jsonlOut receives one JSON line per completed test followed by a
+	// summary line per agent; nil disables the JSONL report.
+	jsonlOut io.Writer
+	// htmlPath is where the HTML report is written; empty disables it.
+	htmlPath string
+	// failFast stops starting new test cases once one has failed; tests
+	// already running still finish and are reported.
+	failFast bool
+	// failuresOnly drops passed results from the JSON and Markdown report
+	// files; see failuresOnlyReport.
+	failuresOnly bool
+	// judgePrompt builds the judge prompt; nil uses the built-in template.
+	judgePrompt *template.Template
+}
+
+// fileReport returns the report to write to the JSON and Markdown files.
+func (eo evalOptions) fileReport(report EvalReport) EvalReport {
+	if eo.failuresOnly {
+		return failuresOnlyReport(report)
+	}
+	return report
+}
+
+// testTimeout returns the time limit of a single test case.
+func (eo evalOptions) testTimeout() time.Duration {
+	if eo.timeout > 0 {
+		return eo.timeout
+	}
+	return defaultRunTimeout
 }
 
 // judgeResult is the structured output from the LLM judge.
@@ -54,6 +125,72 @@ func resolveResponseScoreThreshold(spec agent.AgentSpec, appCfg config.CoragentC
 	return appCfg.Eval.ResponseScoreThreshold
 }
 
+// resolveMaxResponseMs returns the agent-level latency limit using priority:
+// agent spec > config.toml > 0 (disabled).
+func resolveMaxResponseMs(spec agent.AgentSpec, appCfg config.CoragentConfig) int {
+	if spec.Eval != nil && spec.Eval.MaxResponseMs != nil {
+		return *spec.Eval.MaxResponseMs
+	}
+	return appCfg.Eval.MaxResponseMs
+}
+
+// resolvePassRateThreshold returns the suite pass-rate threshold using priority:
+// agent spec > config.toml > 0 (disabled). The --pass-rate flag overrides both.
+func resolvePassRateThreshold(spec agent.AgentSpec, appCfg config.CoragentConfig) float64 {
+	if spec.Eval != nil && spec.Eval.PassRateThreshold != nil {
+		return *spec.Eval.PassRateThreshold
+	}
+	return appCfg.Eval.PassRateThreshold
+}
+
+// resolveJudgePromptTemplate returns the judge prompt template text using
+// priority: agent spec > config.toml > built-in default.
+func resolveJudgePromptTemplate(spec agent.AgentSpec, appCfg config.CoragentConfig) string {
+	if spec.Eval != nil && strings.TrimSpace(spec.Eval.JudgePromptTemplate) != "" {
+		return spec.Eval.JudgePromptTemplate
+	}
+	if strings.TrimSpace(appCfg.Eval.JudgePromptTemplate) != "" {
+		return appCfg.Eval.JudgePromptTemplate
+	}
+	return defaultJudgePromptTemplate
+}
+
+// parseJudgePromptTemplate parses a judge prompt template and checks that it
+// renders with sample data and includes the actual response, without which
+// the judge has nothing to score. Unknown fields are rejected.
+func parseJudgePromptTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("judge_prompt_template").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("eval.judge_prompt_template: %w", err)
+	}
+	const sentinel = "\x00actual\x00"
+	var b strings.Builder
+	if err := tmpl.Execute(&b, judgePromptData{Question: "q", Expected: "e", Actual: sentinel}); err != nil {
+		return nil, fmt.Errorf("eval.judge_prompt_template: %w", err)
+	}
+	if !strings.Contains(b.String(), sentinel) {
+		return nil, fmt.Errorf("eval.judge_prompt_template must include {{.Actual}}")
+	}
+	return tmpl, nil
+}
+
+// buildJudgePrompt renders the judge prompt. A nil tmpl uses the built-in
+// template.
+func buildJudgePrompt(tmpl *template.Template, question, expectedResponse, actualResponse string) (string, error) {
+	if tmpl == nil {
+		var err error
+		if tmpl, err = parseJudgePromptTemplate(defaultJudgePromptTemplate); err != nil {
+			return "", err
+		}
+	}
+	var b strings.Builder
+	data := judgePromptData{Question: question, Expected: expectedResponse, Actual: actualResponse}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("render judge prompt: %w", err)
+	}
+	return b.String(), nil
+}
+
 // effectiveThreshold returns the threshold for a specific test case using priority:
 // test case > agent-level default.
 func effectiveThreshold(tc agent.EvalTestCase, agentDefault int) int {
@@ -63,21 +200,39 @@ func effectiveThreshold(tc agent.EvalTestCase, agentDefault int) int {
 	return agentDefault
 }
 
+// effectiveMaxResponseMs returns the latency limit for a specific test case
+// using priority: test case > agent-level default.
+func effectiveMaxResponseMs(tc agent.EvalTestCase, agentDefault int) int {
+	if tc.MaxResponseMs != nil {
+		return *tc.MaxResponseMs
+	}
+	return agentDefault
+}
+
 // judgeResponse calls SNOWFLAKE.CORTEX.AI_COMPLETE with structured output to score
 // the actual response against the expected response. Returns score (0-100) and reasoning.
-func judgeResponse(ctx context.Context, client *api.Client, model, question, expectedResponse, actualResponse string) (judgeResult, error) {
-	prompt := fmt.Sprintf(
-		"You are an evaluation judge. Compare the actual response to the expected response for the given question.\n\n"+
-			"Question: %s\n\nExpected Response: %s\n\nActual Response: %s\n\n"+
-			"Score the actual response from 0 to 100 based on how well it matches the expected response in meaning and correctness. "+
-			"Provide a brief reasoning.",
-		question, expectedResponse, actualResponse,
-	)
+func judgeResponse(ctx context.Context, client *api.Client, model string, promptTmpl *template.Template, question, expectedResponse, actualResponse string) (judgeResult, error) {
+	prompt, err := buildJudgePrompt(promptTmpl, question, expectedResponse, actualResponse)
+	if err != nil {
+		return judgeResult{}, err
+	}
 
+	raw, err := client.CortexComplete(ctx, buildJudgeStatement(model, prompt))
+	if err != nil {
+		return judgeResult{}, err
+	}
+
+	return parseJudgeResponse(raw)
+}
+
+// buildJudgeStatement returns the AI_COMPLETE statement for prompt. The
+// response_format is always attached, so custom prompts still yield the
+// structured {score, reasoning} output.
+func buildJudgeStatement(model, prompt string) string {
 	// Escape single quotes for SQL string literal
 	escapedPrompt := strings.ReplaceAll(prompt, "'", "''")
 
-	stmt := fmt.Sprintf(`SELECT SNOWFLAKE.CORTEX.AI_COMPLETE(
+	return fmt.Sprintf(`SELECT SNOWFLAKE.CORTEX.AI_COMPLETE(
     model => '%s',
     prompt => '%s',
     model_parameters => {
@@ -96,13 +251,6 @@ func judgeResponse(ctx context.Context, client *api.Client, model, question, exp
     },
     show_details => TRUE
 ) AS response;`, model, escapedPrompt)
-
-	raw, err := client.CortexComplete(ctx, stmt)
-	if err != nil {
-		return judgeResult{}, err
-	}
-
-	return parseJudgeResponse(raw)
 }
 
 // parseJudgeResponse extracts the judgeResult from either the structured-output
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// evalProgressInterval is the minimum gap between progress lines when
+// stderr is not a terminal.
+const evalProgressInterval = 30 * time.Second
+
+// evalProgress tracks elapsed time and per-test durations for an eval suite
+// and reports an ETA extrapolated from the average completed test. On a
+// terminal the status is a single line rewritten in place below the test
+// results; otherwise a plain line is printed at most every
+// evalProgressInterval. It is safe for concurrent use.
+type evalProgress struct {
+	mu        sync.Mutex
+	w         io.Writer
+	total     int
+	workers   int
+	live      bool
+	quiet     bool
+	start     time.Time
+	lastLine  time.Time
+	durations []time.Duration
+	shown     bool
+	now       func() time.Time
+}
+
+func newEvalProgress(w io.Writer, total int, live, quiet bool) *evalProgress {
+	now := time.Now()
+	return &evalProgress{w: w, total: total, workers: 1, live: live, quiet: quiet, start: now, lastLine: now, now: time.Now}
+}
+
+// record adds the duration of a completed test and refreshes the status.
+func (p *evalProgress) record(d time.Duration) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.durations = append(p.durations, d)
+	if p.quiet || len(p.durations) >= p.total {
+		return
+	}
+	now := p.now()
+	if p.live {
+		fmt.Fprintf(p.w, "\r\033[K%s", p.status(now))
+		p.shown = true
+		return
+	}
+	if now.Sub(p.lastLine) >= evalProgressInterval {
+		fmt.Fprintln(p.w, p.status(now))
+		p.lastLine = now
+	}
+}
+
+// clear removes the live status line so regular output can be printed.
+func (p *evalProgress) clear() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clearLocked()
+}
+
+// print clears the live status line and runs fn while holding the lock, so
+// result lines from concurrent tests do not interleave with the status.
+func (p *evalProgress) print(fn func()) {
+	if p == nil {
+		fn()
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.clearLocked()
+	fn()
+}
+
+func (p *evalProgress) clearLocked() {
+	if !p.shown {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K")
+	p.shown = false
+}
+
+// elapsed returns the time since the suite started.
+func (p *evalProgress) elapsed() time.Duration {
+	return p.now().Sub(p.start)
+}
+
+func (p *evalProgress) status(now time.Time) string {
+	return fmt.Sprintf("Progress: %d/%d done, elapsed %s, ETA %s",
+		len(p.durations), p.total,
+		formatEvalDuration(now.Sub(p.start)),
+		formatEvalDuration(estimateRemaining(p.durations, p.total)/time.Duration(max(p.workers, 1))))
+}
+
+// estimateRemaining extrapolates the time left for total tests from the
+// average duration of the completed ones. It returns 0 when nothing has
+// completed yet or all tests are done.
+func estimateRemaining(durations []time.Duration, total int) time.Duration {
+	done := len(durations)
+	if done == 0 || done >= total {
+		return 0
+	}
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	return sum / time.Duration(done) * time.Duration(total-done)
+}
+
+// formatEvalDuration rounds d to whole seconds for display, e.g. "1m12s".
+func formatEvalDuration(d time.Duration) string {
+	return d.Round(time.Second).String()
+}
@@ -1,14 +1,22 @@
 package cli
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
 	"coragent/internal/config"
+	"coragent/internal/regression"
 )
 
 func TestEvalOutputPaths(t *testing.T) {
@@ -373,6 +381,41 @@ func TestComputeOverallPass(t *testing.T) {
 			tc:   agent.EvalTestCase{ExpectedTools: []string{"tool_a"}, Command: "echo ok"},
 			want: false,
 		},
+		{
+			name: "substrings - all found",
+			result: EvalResult{
+				SubstringMatch: boolPtr(true),
+			},
+			tc:   agent.EvalTestCase{ExpectedSubstrings: []string{"revenue"}},
+			want: true,
+		},
+		{
+			name: "substrings - missing",
+			result: EvalResult{
+				ToolMatch:      true,
+				SubstringMatch: boolPtr(false),
+			},
+			tc:   agent.EvalTestCase{ExpectedTools: []string{"tool_a"}, ExpectedSubstrings: []string{"revenue"}},
+			want: false,
+		},
+		{
+			name: "json schema valid",
+			result: EvalResult{
+				ToolMatch:           true,
+				ResponseSchemaValid: boolPtr(true),
+			},
+			tc:   agent.EvalTestCase{ExpectedTools: []string{"tool_a"}},
+			want: true,
+		},
+		{
+			name: "json schema invalid",
+			result: EvalResult{
+				ToolMatch:           true,
+				ResponseSchemaValid: boolPtr(false),
+			},
+			tc:   agent.EvalTestCase{ExpectedTools: []string{"tool_a"}},
+			want: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -385,6 +428,106 @@ func TestComputeOverallPass(t *testing.T) {
 	}
 }
 
+func TestMissingSubstrings(t *testing.T) {
+	response := "Total Revenue was 42 USD"
+	tests := []struct {
+		name       string
+		want       []string
+		ignoreCase bool
+		missing    []string
+	}{
+		{name: "all present", want: []string{"Revenue", "42"}},
+		{name: "case-sensitive miss", want: []string{"revenue", "42", "EUR"}, missing: []string{"revenue", "EUR"}},
+		{name: "ignore case", want: []string{"revenue", "usd"}, ignoreCase: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := missingSubstrings(response, tt.want, tt.ignoreCase)
+			if strings.Join(got, "|") != strings.Join(tt.missing, "|") {
+				t.Errorf("missingSubstrings() = %v, want %v", got, tt.missing)
+			}
+		})
+	}
+}
+
+func TestRunEvalTest_ExpectedSubstrings(t *testing.T) {
+	client := newMockAgentClient(t, "sub-agent", regression.BuildSSEReply("Revenue grew in Q3"))
+	target := Target{Database: "DB", Schema: "SCH"}
+
+	tc := agent.EvalTestCase{Question: "q?", ExpectedSubstrings: []string{"revenue", "q3"}, ExpectedSubstringsIgnoreCase: true}
+	result := runEvalTest(client, target, "sub-agent", tc, 1, 1, ".", evalOptions{quiet: true})
+	if !result.Passed || result.SubstringMatch == nil || !*result.SubstringMatch {
+		t.Errorf("ignore case: passed=%v match=%v missing=%v, want pass", result.Passed, result.SubstringMatch, result.MissingSubstrings)
+	}
+
+	tc.ExpectedSubstringsIgnoreCase = false
+	result = runEvalTest(client, target, "sub-agent", tc, 1, 1, ".", evalOptions{quiet: true})
+	if result.Passed {
+		t.Error("case-sensitive: expected failure")
+	}
+	if strings.Join(result.MissingSubstrings, ",") != "revenue,q3" {
+		t.Errorf("MissingSubstrings = %v, want [revenue q3]", result.MissingSubstrings)
+	}
+
+	md := generateEvalMarkdown(EvalReport{AgentName: "sub-agent", Results: []EvalResult{result}})
+	if !strings.Contains(md, "**Expected Substrings:** `revenue`, `q3`") || !strings.Contains(md, "❌ missing `revenue`, `q3`") {
+		t.Errorf("markdown missing substring details:\n%s", md)
+	}
+}
+
+func TestRunEvalTest_ConversationRunsTurnsInOneThread(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	client := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	if err := client.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: "conv-agent"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunReply("conv-agent", regression.BuildSSEReply("EMEA revenue", "sales_view"))
+
+	tc := agent.EvalTestCase{
+		Conversation:  []string{"Show revenue by region"},
+		Question:      "Only EMEA",
+		ExpectedTools: []string{"sales_view"},
+	}
+	result := runEvalTest(client, Target{Database: "DB", Schema: "SCH"}, "conv-agent", tc, 1, 1, ".", evalOptions{quiet: true})
+	if !result.Passed || result.Error != "" {
+		t.Fatalf("expected pass, got %+v", result)
+	}
+	if len(result.ActualTools) != 1 {
+		t.Errorf("ActualTools = %v, want only the final turn's tool", result.ActualTools)
+	}
+
+	var last struct {
+		ThreadID        string `json:"thread_id"`
+		ParentMessageID *int64 `json:"parent_message_id"`
+		Messages        []struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(ms.LastRunRequest("conv-agent"), &last); err != nil {
+		t.Fatalf("parse last run request: %v", err)
+	}
+	if last.ThreadID != result.ThreadID {
+		t.Errorf("final turn thread_id = %q, want %q", last.ThreadID, result.ThreadID)
+	}
+	if last.ParentMessageID == nil || *last.ParentMessageID != 1 {
+		t.Errorf("final turn parent_message_id = %v, want the first turn's message ID 1", last.ParentMessageID)
+	}
+	if len(last.Messages) != 1 || len(last.Messages[0].Content) != 1 || last.Messages[0].Content[0].Text != "Only EMEA" {
+		t.Errorf("final turn messages = %+v", last.Messages)
+	}
+
+	md := generateEvalMarkdown(EvalReport{AgentName: "conv-agent", Results: []EvalResult{result}})
+	if !strings.Contains(md, "**Earlier Turns:**\n1. Show revenue by region\n") {
+		t.Errorf("markdown missing earlier turns:\n%s", md)
+	}
+}
+
 func TestRunEvalCommand(t *testing.T) {
 	dir := t.TempDir()
 
@@ -579,6 +722,77 @@ func TestComputeOverallPassWithResponseScoreThreshold(t *testing.T) {
 	}
 }
 
+func TestBuildJudgePrompt_DefaultTemplate(t *testing.T) {
+	got, err := buildJudgePrompt(nil, "Q?", "exp", "act")
+	if err != nil {
+		t.Fatalf("buildJudgePrompt: %v", err)
+	}
+	want := "You are an evaluation judge. Compare the actual response to the expected response for the given question.\n\n" +
+		"Question: Q?\n\nExpected Response: exp\n\nActual Response: act\n\n" +
+		"Score the actual response from 0 to 100 based on how well it matches the expected response in meaning and correctness. " +
+		"Provide a brief reasoning."
+	if got != want {
+		t.Errorf("prompt = %q, want %q", got, want)
+	}
+}
+
+func TestBuildJudgeStatement_CustomTemplate(t *testing.T) {
+	tmpl, err := parseJudgePromptTemplate("Rubric: finance.\nQ: {{.Question}}\nGold: {{.Expected}}\nAnswer: {{.Actual}}")
+	if err != nil {
+		t.Fatalf("parseJudgePromptTemplate: %v", err)
+	}
+	prompt, err := buildJudgePrompt(tmpl, "What's revenue?", "$120M", "It's $120M")
+	if err != nil {
+		t.Fatalf("buildJudgePrompt: %v", err)
+	}
+	if prompt != "Rubric: finance.\nQ: What's revenue?\nGold: $120M\nAnswer: It's $120M" {
+		t.Errorf("prompt = %q", prompt)
+	}
+
+	stmt := buildJudgeStatement("llama4-scout", prompt)
+	if !strings.Contains(stmt, "prompt => 'Rubric: finance.\nQ: What''s revenue?\nGold: $120M\nAnswer: It''s $120M'") {
+		t.Errorf("statement does not embed the escaped custom prompt:\n%s", stmt)
+	}
+	for _, want := range []string{"model => 'llama4-scout'", "'score': {'type': 'integer'}", "'required': ['score', 'reasoning']"} {
+		if !strings.Contains(stmt, want) {
+			t.Errorf("statement missing %q:\n%s", want, stmt)
+		}
+	}
+}
+
+func TestParseJudgePromptTemplate_Rejects(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"syntax error", "Q: {{.Question", "judge_prompt_template"},
+		{"unknown field", "{{.Answer}} {{.Actual}}", "Answer"},
+		{"missing actual", "Q: {{.Question}} E: {{.Expected}}", "must include {{.Actual}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseJudgePromptTemplate(tt.text)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveJudgePromptTemplate(t *testing.T) {
+	cfg := config.CoragentConfig{}
+	if got := resolveJudgePromptTemplate(agent.AgentSpec{}, cfg); got != defaultJudgePromptTemplate {
+		t.Errorf("default: got %q", got)
+	}
+	cfg.Eval.JudgePromptTemplate = "config {{.Actual}}"
+	if got := resolveJudgePromptTemplate(agent.AgentSpec{}, cfg); got != "config {{.Actual}}" {
+		t.Errorf("config.toml value: got %q", got)
+	}
+	spec := agent.AgentSpec{Eval: &agent.EvalConfig{JudgePromptTemplate: "spec {{.Actual}}"}}
+	if got := resolveJudgePromptTemplate(spec, cfg); got != "spec {{.Actual}}" {
+		t.Errorf("spec overrides config.toml: got %q", got)
+	}
+}
+
 func TestParseJudgeResponse(t *testing.T) {
 	t.Run("valid response", func(t *testing.T) {
 		raw := `{"structured_output":[{"raw_message":{"score":85,"reasoning":"Good match"},"type":"json"}]}`
@@ -964,3 +1178,553 @@ func TestEffectiveThreshold(t *testing.T) {
 		}
 	})
 }
+
+func TestRunEvalForAgent_SummaryOnlyWritesNoReports(t *testing.T) {
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	outDir := t.TempDir()
+	spec := agent.AgentSpec{
+		Name: "ci-agent",
+		Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+			{Question: "pass?", ExpectedTools: []string{"sales_view"}},
+			{Question: "fail?", ExpectedTools: []string{"other_tool"}},
+		}},
+	}
+
+	var stdout bytes.Buffer
+	eo := evalOptions{summaryOnly: true, summaryOut: &stdout}
+	target := Target{Database: "DB", Schema: "SCH"}
+	if _, err := runEvalForAgent(client, target, spec, outDir, outDir, false, eo); err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatalf("read output dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no report files with --summary-only, got %d", len(entries))
+	}
+
+	var summary EvalSummary
+	if err := json.Unmarshal(stdout.Bytes(), &summary); err != nil {
+		t.Fatalf("unmarshal summary %q: %v", stdout.String(), err)
+	}
+	if summary.AgentName != "ci-agent" || summary.Passed != 1 || summary.Total != 2 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	if len(summary.Failed) != 1 || summary.Failed[0] != "fail?" {
+		t.Errorf("Failed = %v, want [fail?]", summary.Failed)
+	}
+}
+
+func TestRunEvalForAgent_WritesReportsByDefault(t *testing.T) {
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	outDir := t.TempDir()
+	spec := agent.AgentSpec{
+		Name: "ci-agent",
+		Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+			{Question: "pass?", ExpectedTools: []string{"sales_view"}},
+		}},
+	}
+
+	target := Target{Database: "DB", Schema: "SCH"}
+	if _, err := runEvalForAgent(client, target, spec, outDir, outDir, false, evalOptions{}); err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+	for _, name := range []string{"ci-agent_eval.json", "ci-agent_eval.md"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Errorf("expected report %s: %v", name, err)
+		}
+	}
+}
+
+func TestRunEvalForAgent_FailuresOnlyReport(t *testing.T) {
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	outDir := t.TempDir()
+	spec := agent.AgentSpec{
+		Name: "ci-agent",
+		Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+			{Question: "pass one?", ExpectedTools: []string{"sales_view"}},
+			{Question: "wrong tool?", ExpectedTools: []string{"other_tool"}},
+			{Question: "pass two?", ExpectedTools: []string{"sales_view"}},
+		}},
+	}
+
+	eo := evalOptions{quiet: true, failuresOnly: true}
+	summary, err := runEvalForAgent(client, Target{Database: "DB", Schema: "SCH"}, spec, outDir, ".", false, eo)
+	if err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+	if summary.Total != 3 || summary.Passed != 2 {
+		t.Errorf("summary = %+v, want 2/3 passed", summary)
+	}
+
+	jsonPath, mdPath := evalOutputPaths(outDir, "ci-agent", false)
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var report EvalReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(report.Results) != 1 || report.Results[0].Question != "wrong tool?" {
+		t.Errorf("report results = %+v, want only the failed test", report.Results)
+	}
+	if report.OmittedPassed != 2 {
+		t.Errorf("OmittedPassed = %d, want 2", report.OmittedPassed)
+	}
+
+	md, err := os.ReadFile(mdPath)
+	if err != nil {
+		t.Fatalf("read markdown: %v", err)
+	}
+	for _, want := range []string{"2 passed test(s) omitted", "**Result: 2/3 passed**", "wrong tool?"} {
+		if !strings.Contains(string(md), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if strings.Contains(string(md), "pass one?") {
+		t.Errorf("markdown includes a passed test:\n%s", md)
+	}
+}
+
+func TestFailuresOnlyReport_KeepsErrored(t *testing.T) {
+	report := EvalReport{AgentName: "a", Results: []EvalResult{
+		{Question: "ok", Passed: true},
+		{Question: "boom", Error: "stream timed out"},
+	}}
+	got := failuresOnlyReport(report)
+	if len(got.Results) != 1 || got.Results[0].Question != "boom" || got.OmittedPassed != 1 {
+		t.Errorf("failuresOnlyReport() = %+v", got)
+	}
+	if len(report.Results) != 2 {
+		t.Error("failuresOnlyReport modified its input")
+	}
+}
+
+func TestSuiteVerdict(t *testing.T) {
+	tests := []struct {
+		name      string
+		summary   EvalSummary
+		threshold float64
+		wantPass  bool
+		want      string
+	}{
+		{"just above", EvalSummary{Passed: 23, Total: 25}, 0.9, true, "Suite: PASS (92% >= 90%)"},
+		{"exactly at", EvalSummary{Passed: 9, Total: 10}, 0.9, true, "Suite: PASS (90% >= 90%)"},
+		{"just below", EvalSummary{Passed: 8, Total: 9}, 0.9, false, "Suite: FAIL (88.9% < 90%)"},
+		{"rounds to threshold", EvalSummary{Passed: 1799, Total: 2000}, 0.9, false, "Suite: FAIL (89.95% < 90%)"},
+		{"all pass", EvalSummary{Passed: 3, Total: 3}, 1, true, "Suite: PASS (100% >= 100%)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suitePasses(tt.summary, tt.threshold); got != tt.wantPass {
+				t.Errorf("suitePasses() = %v, want %v", got, tt.wantPass)
+			}
+			if got := suiteVerdict(tt.summary, tt.threshold); got != tt.want {
+				t.Errorf("suiteVerdict() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunEvalForAgent_PassRateDeterminesExitCode(t *testing.T) {
+	// One of two tests passes (50%).
+	spec := agent.AgentSpec{
+		Name: "ci-agent",
+		Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+			{Question: "pass?", ExpectedTools: []string{"sales_view"}},
+			{Question: "fail?", ExpectedTools: []string{"other_tool"}},
+		}},
+	}
+	tests := []struct {
+		name      string
+		threshold float64
+		wantCode  int
+	}{
+		{"just above threshold", 0.49, ExitClean},
+		{"just below threshold", 0.51, ExitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+			eo := evalOptions{summaryOnly: true, summaryOut: &bytes.Buffer{}, passRateThreshold: tt.threshold}
+			summary, err := runEvalForAgent(client, Target{Database: "DB", Schema: "SCH"}, spec, t.TempDir(), ".", false, eo)
+			if err != nil {
+				t.Fatalf("runEvalForAgent: %v", err)
+			}
+			var failing []string
+			if !suitePasses(summary, tt.threshold) {
+				failing = append(failing, spec.Name)
+			}
+			err = passRateError(failing)
+			code := ExitClean
+			var exitErr ExitCodeError
+			if errors.As(err, &exitErr) {
+				code = exitErr.Code
+			} else if err != nil {
+				t.Fatalf("unexpected error type %T: %v", err, err)
+			}
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (err = %v)", code, tt.wantCode, err)
+			}
+		})
+	}
+}
+
+func TestRunEvalForAgent_FailFastStopsAfterFailure(t *testing.T) {
+	spec := agent.AgentSpec{
+		Name: "ci-agent",
+		Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+			{Question: "first?", ExpectedTools: []string{"sales_view"}},
+			{Question: "second?", ExpectedTools: []string{"other_tool"}},
+			{Question: "third?", ExpectedTools: []string{"sales_view"}},
+		}},
+	}
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	outDir := t.TempDir()
+	eo := evalOptions{quiet: true, failFast: true}
+	summary, err := runEvalForAgent(client, Target{Database: "DB", Schema: "SCH"}, spec, outDir, ".", false, eo)
+	if err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+	if summary.Total != 2 || summary.Passed != 1 {
+		t.Errorf("summary = %+v, want 1/2 passed", summary)
+	}
+
+	jsonPath, _ := evalOutputPaths(outDir, "ci-agent", false)
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("read partial report: %v", err)
+	}
+	var report EvalReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(report.Results) != 2 || report.Results[1].Question != "second?" {
+		t.Errorf("report results = %+v, want first two tests", report.Results)
+	}
+}
+
+func TestFailedTestsError(t *testing.T) {
+	if err := failedTestsError(0, 12, nil); err != nil {
+		t.Errorf("no failures: got %v", err)
+	}
+	var exitErr ExitCodeError
+	err := failedTestsError(3, 12, []string{"a", "b"})
+	if !errors.As(err, &exitErr) || exitErr.Code != ExitFailure {
+		t.Fatalf("expected ExitFailure, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "3 of 12 eval tests failed: a, b") {
+		t.Errorf("unexpected message: %v", err)
+	}
+}
+
+func TestRunEvalForAgent_WritesJSONLInOrder(t *testing.T) {
+	spec := agent.AgentSpec{
+		Name: "ci-agent",
+		Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+			{Question: "first?", ExpectedTools: []string{"sales_view"}},
+			{Question: "second?", ExpectedTools: []string{"other_tool"}},
+		}},
+	}
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	var jsonl bytes.Buffer
+	eo := evalOptions{summaryOnly: true, summaryOut: &bytes.Buffer{}, jsonlOut: &jsonl}
+	if _, err := runEvalForAgent(client, Target{Database: "DB", Schema: "SCH"}, spec, t.TempDir(), ".", false, eo); err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(jsonl.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), jsonl.String())
+	}
+	for i, want := range []string{"first?", "second?"} {
+		var rec evalJSONLResult
+		if err := json.Unmarshal([]byte(lines[i]), &rec); err != nil {
+			t.Fatalf("line %d: %v", i, err)
+		}
+		if rec.Type != "result" || rec.AgentName != "ci-agent" || rec.Question != want {
+			t.Errorf("line %d = %+v, want result for %q", i, rec, want)
+		}
+	}
+	var sum evalJSONLSummary
+	if err := json.Unmarshal([]byte(lines[2]), &sum); err != nil {
+		t.Fatalf("summary line: %v", err)
+	}
+	if sum.Type != "summary" || sum.Passed != 1 || sum.Total != 2 {
+		t.Errorf("summary = %+v, want 1/2 passed", sum)
+	}
+}
+
+func TestRunEvalForAgent_ConcurrencyPreservesOrder(t *testing.T) {
+	questions := []string{"q1?", "q2?", "q3?", "q4?", "q5?"}
+	var tests []agent.EvalTestCase
+	for _, q := range questions {
+		tests = append(tests, agent.EvalTestCase{Question: q, ExpectedTools: []string{"sales_view"}})
+	}
+	spec := agent.AgentSpec{Name: "ci-agent", Eval: &agent.EvalConfig{Tests: tests}}
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	outDir := t.TempDir()
+	eo := evalOptions{concurrency: 3, quiet: true}
+	summary, err := runEvalForAgent(client, Target{Database: "DB", Schema: "SCH"}, spec, outDir, ".", false, eo)
+	if err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+	if summary.Passed != len(questions) {
+		t.Errorf("passed = %d, want %d", summary.Passed, len(questions))
+	}
+
+	jsonPath, _ := evalOutputPaths(outDir, "ci-agent", false)
+	data, err := os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatalf("read report: %v", err)
+	}
+	var report EvalReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("parse report: %v", err)
+	}
+	if len(report.Results) != len(questions) {
+		t.Fatalf("got %d results, want %d", len(report.Results), len(questions))
+	}
+	for i, q := range questions {
+		if report.Results[i].Question != q {
+			t.Errorf("Results[%d].Question = %q, want %q", i, report.Results[i].Question, q)
+		}
+	}
+}
+
+func TestRunEvalForAgent_DelayPacesTests(t *testing.T) {
+	spec := agent.AgentSpec{Name: "ci-agent", Eval: &agent.EvalConfig{Tests: []agent.EvalTestCase{
+		{Question: "q1?", ExpectedTools: []string{"sales_view"}},
+		{Question: "q2?", ExpectedTools: []string{"sales_view"}},
+		{Question: "q3?", ExpectedTools: []string{"sales_view"}},
+	}}}
+	client := newMockAgentClient(t, "ci-agent", regression.BuildSSEReply("ok", "sales_view"))
+	eo := evalOptions{quiet: true, summaryOnly: true, summaryOut: &bytes.Buffer{}, delay: 40 * time.Millisecond}
+	start := time.Now()
+	summary, err := runEvalForAgent(client, Target{Database: "DB", Schema: "SCH"}, spec, t.TempDir(), ".", false, eo)
+	if err != nil {
+		t.Fatalf("runEvalForAgent: %v", err)
+	}
+	if summary.Passed != 3 {
+		t.Errorf("passed = %d, want 3", summary.Passed)
+	}
+	// Three sequential tests wait twice: before the second and third.
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Errorf("elapsed = %s, want at least 80ms with a 40ms delay", elapsed)
+	}
+}
+
+func TestResolvePassRateThreshold(t *testing.T) {
+	cfg := config.CoragentConfig{}
+	cfg.Eval.PassRateThreshold = 0.8
+	if got := resolvePassRateThreshold(agent.AgentSpec{}, cfg); got != 0.8 {
+		t.Errorf("config.toml value: got %g, want 0.8", got)
+	}
+
+	rate := 0.95
+	spec := agent.AgentSpec{Eval: &agent.EvalConfig{PassRateThreshold: &rate}}
+	if got := resolvePassRateThreshold(spec, cfg); got != 0.95 {
+		t.Errorf("spec overrides config.toml: got %g, want 0.95", got)
+	}
+}
+
+func TestRunEvalTest_AgentErrorMarksTestErrored(t *testing.T) {
+	client := newMockAgentClient(t, "err-agent", regression.BuildSSEErrorReply("partial", "399504", "warehouse suspended"))
+	tc := agent.EvalTestCase{Question: "q?", ExpectedResponse: "full answer"}
+
+	result := runEvalTest(client, Target{Database: "DB", Schema: "SCH"}, "err-agent", tc, 1, 1, ".", evalOptions{})
+	if result.Passed {
+		t.Error("expected test to fail on agent error")
+	}
+	if !strings.Contains(result.Error, "warehouse suspended") || !strings.Contains(result.Error, "399504") {
+		t.Errorf("Error = %q, want server code and message", result.Error)
+	}
+	if result.Response != "partial" {
+		t.Errorf("Response = %q, want partial text", result.Response)
+	}
+	if result.ResponseScore != nil || result.JudgeModel != "" {
+		t.Error("expected judge to be skipped for an errored run")
+	}
+}
+
+func TestRunEvalTest_TimeoutFailsTest(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	client := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	if err := client.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: "slow-agent"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunStall("slow-agent", "event: response.status\ndata: {\"status\":\"running\",\"message\":\"\",\"sequence_number\":1}\n\n")
+
+	tc := agent.EvalTestCase{Question: "slow?"}
+	start := time.Now()
+	result := runEvalTest(client, Target{Database: "DB", Schema: "SCH"}, "slow-agent", tc, 1, 1, ".", evalOptions{quiet: true, timeout: 200 * time.Millisecond})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("test took %s; timeout was not applied", elapsed)
+	}
+	if result.Passed || result.Error == "" {
+		t.Errorf("expected timed-out test to fail with an error, got %+v", result)
+	}
+}
+
+func TestEstimateRemaining(t *testing.T) {
+	durations := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second}
+	if got := estimateRemaining(durations, 10); got != 140*time.Second {
+		t.Errorf("estimateRemaining = %s, want 2m20s", got)
+	}
+	if got := estimateRemaining(nil, 10); got != 0 {
+		t.Errorf("estimateRemaining with no samples = %s, want 0", got)
+	}
+	if got := estimateRemaining(durations, 3); got != 0 {
+		t.Errorf("estimateRemaining when done = %s, want 0", got)
+	}
+}
+
+func TestEvalProgress_NonTTYPrintsPeriodically(t *testing.T) {
+	var buf bytes.Buffer
+	p := newEvalProgress(&buf, 4, false, false)
+	now := p.start
+	p.now = func() time.Time { return now }
+
+	now = now.Add(10 * time.Second)
+	p.record(10 * time.Second)
+	if buf.Len() != 0 {
+		t.Fatalf("expected no line before the interval, got %q", buf.String())
+	}
+
+	now = now.Add(30 * time.Second)
+	p.record(30 * time.Second)
+	if got := buf.String(); got != "Progress: 2/4 done, elapsed 40s, ETA 40s\n" {
+		t.Errorf("progress line = %q", got)
+	}
+
+	buf.Reset()
+	quiet := newEvalProgress(&buf, 4, true, true)
+	quiet.record(time.Minute)
+	quiet.clear()
+	if buf.Len() != 0 {
+		t.Errorf("quiet progress wrote %q", buf.String())
+	}
+}
+
+func TestEvalCmd_ExitCodeReflectsFailedTests(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"failures exit non-zero", nil, "1 of 2 eval tests failed: thread-agent"},
+		{"exit-zero keeps exit code 0", []string{"--exit-zero"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms, home := setupRunMock(t)
+			ms.SetRunReply("thread-agent", regression.BuildSSEReply("ok", "sales_view"))
+			specPath := filepath.Join(home, "agent.yaml")
+			if err := os.WriteFile(specPath, []byte(`
+name: thread-agent
+eval:
+  tests:
+    - question: pass?
+      expected_tools: [sales_view]
+    - question: fail?
+      expected_tools: [other_tool]
+`), 0o644); err != nil {
+				t.Fatalf("write spec: %v", err)
+			}
+
+			cmd := newEvalCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{specPath, "--summary-only", "-q"}, tt.args...))
+			err := cmd.Execute()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var exitErr ExitCodeError
+			if !errors.As(err, &exitErr) || exitErr.Code != ExitFailure {
+				t.Fatalf("expected ExitFailure, got %v", err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestComputeOverallPass_MaxResponseMs(t *testing.T) {
+	tests := []struct {
+		name   string
+		result EvalResult
+		want   bool
+	}{
+		{"under the limit", EvalResult{ResponseMs: 800, MaxResponseMs: 1000}, true},
+		{"at the limit", EvalResult{ResponseMs: 1000, MaxResponseMs: 1000}, true},
+		{"over the limit", EvalResult{ResponseMs: 1500, MaxResponseMs: 1000}, false},
+		{"no limit", EvalResult{ResponseMs: 60000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeOverallPass(tt.result, agent.EvalTestCase{}, 0); got != tt.want {
+				t.Errorf("computeOverallPass() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveMaxResponseMs(t *testing.T) {
+	if got := effectiveMaxResponseMs(agent.EvalTestCase{}, 5000); got != 5000 {
+		t.Errorf("agent default: got %d, want 5000", got)
+	}
+	limit := 2000
+	if got := effectiveMaxResponseMs(agent.EvalTestCase{MaxResponseMs: &limit}, 5000); got != 2000 {
+		t.Errorf("test override: got %d, want 2000", got)
+	}
+	zero := 0
+	if got := effectiveMaxResponseMs(agent.EvalTestCase{MaxResponseMs: &zero}, 5000); got != 0 {
+		t.Errorf("test zero disables the limit: got %d, want 0", got)
+	}
+}
+
+func TestResolveMaxResponseMs(t *testing.T) {
+	cfg := config.CoragentConfig{}
+	cfg.Eval.MaxResponseMs = 10000
+	if got := resolveMaxResponseMs(agent.AgentSpec{}, cfg); got != 10000 {
+		t.Errorf("config.toml value: got %d, want 10000", got)
+	}
+
+	limit := 3000
+	spec := agent.AgentSpec{Eval: &agent.EvalConfig{MaxResponseMs: &limit}}
+	if got := resolveMaxResponseMs(spec, cfg); got != 3000 {
+		t.Errorf("spec overrides config.toml: got %d, want 3000", got)
+	}
+}
+
+func TestRunEvalTest_RecordsResponseTime(t *testing.T) {
+	client := newMockAgentClient(t, "fast-agent", regression.BuildSSEReply("ok"))
+	limit := 60000
+	tc := agent.EvalTestCase{Question: "q?", MaxResponseMs: &limit}
+
+	result := runEvalTest(client, Target{Database: "DB", Schema: "SCH"}, "fast-agent", tc, 1, 1, ".", evalOptions{quiet: true, maxResponseMs: 1})
+	if !result.Passed {
+		t.Errorf("expected test under its own limit to pass, got %+v", result)
+	}
+	if result.MaxResponseMs != 60000 {
+		t.Errorf("MaxResponseMs = %d, want the test override 60000", result.MaxResponseMs)
+	}
+
+	md := generateEvalMarkdown(EvalReport{AgentName: "fast-agent", Results: []EvalResult{
+		{Question: "slow?", ResponseMs: 1500, MaxResponseMs: 1000},
+	}})
+	if !strings.Contains(md, "**Response Time:** 1500 ms (max 1000 ms) ❌") {
+		t.Errorf("markdown missing response time:\n%s", md)
+	}
+}
@@ -1,195 +1,329 @@
 package cli
 
 import (
-	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 
-	"gopkg.in/yaml.v3"
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/config"
 
 	"github.com/spf13/cobra"
 )
 
 func newExportCmd(opts *RootOptions) *cobra.Command {
-	var outPath string
+	var (
+		outPath    string
+		all        bool
+		outDir     string
+		allSchemas bool
+		yamlIndent int
+		quoteStr   bool
+	)
 	cmd := &cobra.Command{
-		Use:   "export <agent-name>",
-		Short: "Export existing agent to YAML",
+		Use:     "export [agent-name]",
+		Aliases: []string{"import"},
+		Short:   "Export existing agent to YAML",
+		Long: `Export a deployed agent (for example one created in Snowsight) as a YAML
+spec that plan/apply can manage. Also available as "import".
+
+Columns and agent_spec keys that coragent cannot represent are reported on
+stderr and listed in a comment at the top of the YAML; they are not part of
+the spec and will not be re-applied.`,
 		Example: `  # Print agent YAML to stdout
   coragent export MY_AGENT
 
+  # Bring an agent created in Snowsight under management
+  coragent import MY_AGENT -o agent.yaml
+
   # Save exported YAML to a file
-  coragent export MY_AGENT -o agent.yaml`,
-		Args: cobra.ExactArgs(1),
+  coragent export MY_AGENT -o agent.yaml
+
+  # Pick several agents interactively and write <name>.yaml files into ./agents
+  coragent export -o ./agents
+
+  # Export every agent in the schema to ./agents/<db>/<schema>/<name>.yaml
+  coragent export --all --out-dir ./agents
+
+  # Export every agent in every schema of the database
+  coragent export --all --all-schemas --out-dir ./agents
+
+  # Match a repository that uses 4-space indent and quoted strings
+  coragent export MY_AGENT --yaml-indent 4 --quote-strings`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			name := args[0]
+			format, err := resolveYAMLFormat(cmd, config.LoadCoragentConfig().Format, yamlIndent, quoteStr)
+			if err != nil {
+				return err
+			}
+			if all {
+				if len(args) == 1 {
+					return UserErr(fmt.Errorf("--all cannot be combined with an agent name"))
+				}
+				if outDir == "" {
+					return UserErr(fmt.Errorf("--all requires --out-dir"))
+				}
+				return exportAllAgents(cmd.OutOrStdout(), opts, outDir, allSchemas, format)
+			}
+			if outDir != "" || allSchemas {
+				return UserErr(fmt.Errorf("--out-dir and --all-schemas require --all"))
+			}
 
+			if len(args) == 1 {
+				client, cfg, err := buildClientAndCfg(opts)
+				if err != nil {
+					return err
+				}
+				target, err := ResolveTargetForExport(opts, cfg)
+				if err != nil {
+					return err
+				}
+				data, err := exportAgentYAML(opts, client, target, args[0], format)
+				if err != nil {
+					return err
+				}
+				if outPath == "" {
+					_, err = cmd.OutOrStdout().Write(data)
+					return err
+				}
+				if err := os.WriteFile(outPath, data, 0o644); err != nil {
+					return fmt.Errorf("write %q: %w", outPath, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "exported to %s\n", outPath)
+				return nil
+			}
+
+			if !canPrompt(opts) {
+				return UserErr(fmt.Errorf("agent name is required when not running interactively"))
+			}
 			client, cfg, err := buildClientAndCfg(opts)
 			if err != nil {
 				return err
 			}
-
 			target, err := ResolveTargetForExport(opts, cfg)
 			if err != nil {
 				return err
 			}
-
-			result, err := client.DescribeAgent(commandContext("export"), target.Database, target.Schema, name)
+			names, err := selectRemoteAgents(client, target, "export")
 			if err != nil {
 				return err
 			}
-			if !result.Exists {
-				return fmt.Errorf("agent %q not found", name)
-			}
-			spec := result.Spec
-			for _, col := range result.UnmappedColumns {
-				fmt.Fprintf(os.Stderr, "\033[33mWarning: DESCRIBE AGENT returned unmapped column %q (not exported)\033[0m\n", col)
-			}
-			for _, key := range result.UnmappedSpecKeys {
-				fmt.Fprintf(os.Stderr, "\033[33mWarning: agent_spec contains unmapped key %q (not exported)\033[0m\n", key)
+			if len(names) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No agents selected.")
+				return nil
 			}
 
-			var doc yaml.Node
-			if err := doc.Encode(spec); err != nil {
-				return fmt.Errorf("marshal YAML: %w", err)
+			outDir := outPath
+			if outDir == "" {
+				outDir = "."
 			}
-			setLiteralStyleForMultiline(&doc)
-			reorderExportKeys(&doc)
-
-			var buf bytes.Buffer
-			enc := yaml.NewEncoder(&buf)
-			enc.SetIndent(2)
-			if err := enc.Encode(&doc); err != nil {
-				return fmt.Errorf("marshal YAML: %w", err)
+			if err := os.MkdirAll(outDir, 0o755); err != nil {
+				return fmt.Errorf("create %q: %w", outDir, err)
 			}
-			if err := enc.Close(); err != nil {
-				return fmt.Errorf("flush YAML encoder: %w", err)
-			}
-			data := buf.Bytes()
-
-			if outPath == "" {
-				_, err = cmd.OutOrStdout().Write(data)
-				return err
-			}
-
-			if err := os.WriteFile(outPath, data, 0o644); err != nil {
-				return fmt.Errorf("write %q: %w", outPath, err)
+			for _, name := range names {
+				data, err := exportAgentYAML(opts, client, target, name, format)
+				if err != nil {
+					return err
+				}
+				path, err := exportFilePath(outDir, name)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(path, data, 0o644); err != nil {
+					return fmt.Errorf("write %q: %w", path, err)
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "exported to %s\n", path)
 			}
-			fmt.Fprintf(cmd.OutOrStdout(), "exported to %s\n", outPath)
 			return nil
 		},
 	}
-	cmd.Flags().StringVarP(&outPath, "out", "o", "", "Output file path (default: stdout)")
+	cmd.Flags().StringVarP(&outPath, "out", "o", "", "Output file path (default: stdout); output directory when agents are selected interactively")
+	cmd.Flags().BoolVar(&all, "all", false, "Export every agent in the target schema (requires --out-dir)")
+	cmd.Flags().StringVar(&outDir, "out-dir", "", "Directory to write <db>/<schema>/<name>.yaml files into with --all")
+	cmd.Flags().BoolVar(&allSchemas, "all-schemas", false, "With --all, export agents from every schema in the target database")
+	cmd.Flags().IntVar(&yamlIndent, "yaml-indent", 0, "Spaces per YAML indent level, 2-8 (default: format.yaml_indent or 2)")
+	cmd.Flags().BoolVar(&quoteStr, "quote-strings", false, "Double-quote single-line string values (default: format.quote_strings)")
 	return cmd
 }
 
-// setLiteralStyleForMultiline walks a yaml.Node tree and sets LiteralStyle
-// on scalar nodes whose value contains newlines, producing "|" block syntax.
-func setLiteralStyleForMultiline(node *yaml.Node) {
-	if node == nil {
-		return
+// resolveYAMLFormat combines the --yaml-indent and --quote-strings flags
+// with the [format] settings of .coragent.toml. Flags that were set win.
+func resolveYAMLFormat(cmd *cobra.Command, settings config.FormatSettings, indent int, quote bool) (agent.YAMLFormat, error) {
+	format := agent.YAMLFormat{Indent: settings.YAMLIndent, QuoteStrings: settings.QuoteStrings}
+	if cmd.Flags().Changed("yaml-indent") {
+		format.Indent = indent
 	}
-	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "\n") {
-		node.Style = yaml.LiteralStyle
+	if cmd.Flags().Changed("quote-strings") {
+		format.QuoteStrings = quote
 	}
-	for _, child := range node.Content {
-		setLiteralStyleForMultiline(child)
+	if format.Indent == 0 {
+		format.Indent = agent.DefaultYAMLIndent
 	}
+	if format.Indent < agent.MinYAMLIndent || format.Indent > agent.MaxYAMLIndent {
+		return agent.YAMLFormat{}, UserErr(fmt.Errorf("YAML indent must be between %d and %d, got %d", agent.MinYAMLIndent, agent.MaxYAMLIndent, format.Indent))
+	}
+	return format, nil
 }
 
-// reorderExportKeys reorders map keys in the YAML node tree so that
-// tool_spec keys appear as name, type, description first and
-// tool_resources entries have semantic_view / search_service first.
-func reorderExportKeys(node *yaml.Node) {
-	if node == nil {
-		return
-	}
-	// Unwrap document node.
-	if node.Kind == yaml.DocumentNode {
-		for _, child := range node.Content {
-			reorderExportKeys(child)
-		}
-		return
+// exportAllAgents lists the agents in the target schema, or in every schema
+// of the target database when allSchemas is set, and writes each one to
+// <outDir>/<db>/<schema>/<name>.yaml. It finishes with a summary that names
+// the agents whose export dropped unmapped columns or spec keys.
+func exportAllAgents(w io.Writer, opts *RootOptions, outDir string, allSchemas bool, format agent.YAMLFormat) error {
+	client, cfg, err := buildClientAndCfg(opts)
+	if err != nil {
+		return err
 	}
-	if node.Kind != yaml.MappingNode {
-		for _, child := range node.Content {
-			reorderExportKeys(child)
+	ctx := commandContext("export")
+
+	var items []api.AgentListItem
+	if allSchemas {
+		db := firstNonEmpty(opts.Database, cfg.Database)
+		if db == "" {
+			return UserErr(fmt.Errorf("database is required for export --all-schemas (use --database or env SNOWFLAKE_DATABASE)"))
+		}
+		if opts.QuoteIdentifiers {
+			db = quoteIdentifier(db)
+		}
+		err = runWithRetry(opts, func() error {
+			items, err = client.ListAgentsInDatabase(ctx, db)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("list agents: %w", err)
+		}
+		for i := range items {
+			if items[i].Database == "" {
+				items[i].Database = db
+			}
+			if items[i].Schema == "" {
+				return fmt.Errorf("list agents: schema of agent %q is unknown", items[i].Name)
+			}
+		}
+	} else {
+		target, err := ResolveTargetForExport(opts, cfg)
+		if err != nil {
+			return err
+		}
+		err = runWithRetry(opts, func() error {
+			items, err = client.ListAgents(ctx, target.Database, target.Schema)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("list agents: %w", err)
+		}
+		for i := range items {
+			items[i].Database, items[i].Schema = target.Database, target.Schema
 		}
-		return
+	}
+	if len(items) == 0 {
+		fmt.Fprintln(w, "No agents found.")
+		return nil
 	}
 
-	// Iterate key-value pairs to find tool_spec and tool_resources mappings.
-	for i := 0; i+1 < len(node.Content); i += 2 {
-		keyNode := node.Content[i]
-		valNode := node.Content[i+1]
-
-		if keyNode.Kind == yaml.ScalarNode && keyNode.Value == "tool_spec" && valNode.Kind == yaml.MappingNode {
-			reorderMappingKeys(valNode, []string{"name", "type", "description"})
+	var unmapped []string
+	for _, item := range items {
+		var data []byte
+		var result api.DescribeResult
+		err := runWithRetry(opts, func() error {
+			var err error
+			data, result, err = client.DescribeAgentYAMLFormat(ctx, item.Database, item.Schema, item.Name, format)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("describe %s: %w", item.Name, err)
 		}
-
-		if keyNode.Kind == yaml.ScalarNode && keyNode.Value == "tool_resources" && valNode.Kind == yaml.MappingNode {
-			// Each child of tool_resources is a tool name → resource config mapping.
-			for j := 0; j+1 < len(valNode.Content); j += 2 {
-				resVal := valNode.Content[j+1]
-				if resVal.Kind == yaml.MappingNode {
-					reorderMappingKeys(resVal, []string{"semantic_view", "search_service"})
-				}
-			}
+		if !result.Exists {
+			return fmt.Errorf("agent %q not found", item.Name)
 		}
+		path, err := exportFilePath(outDir, item.Database, item.Schema, item.Name)
+		if err != nil {
+			return err
+		}
+		dir := filepath.Dir(path)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create %q: %w", dir, err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("write %q: %w", path, err)
+		}
+		fmt.Fprintf(w, "exported to %s\n", path)
+		if len(result.UnmappedColumns) > 0 || len(result.UnmappedSpecKeys) > 0 {
+			unmapped = append(unmapped, path)
+		}
+	}
 
-		// Recurse into value nodes.
-		reorderExportKeys(valNode)
+	fmt.Fprintf(w, "\nExported %d agent(s) to %s\n", len(items), outDir)
+	if len(unmapped) > 0 {
+		fmt.Fprintf(w, "\033[33m%d with unmapped columns or spec keys (listed at the top of each file):\033[0m\n", len(unmapped))
+		for _, path := range unmapped {
+			fmt.Fprintf(w, "  %s\n", path)
+		}
 	}
+	return nil
 }
 
-// reorderMappingKeys moves the specified keys to the front of a mapping node,
-// preserving their relative order. Keys not in the priority list keep their
-// original order after the prioritized keys.
-func reorderMappingKeys(node *yaml.Node, priority []string) {
-	if node.Kind != yaml.MappingNode || len(node.Content) < 4 {
-		return
+// exportFilePath builds <outDir>/<dirs...>/<name>.yaml from identifiers
+// reported by the server. Surrounding quotes are stripped, and a segment
+// that is empty, "." or "..", or contains a path separator is rejected so
+// that a quoted identifier cannot write outside outDir.
+func exportFilePath(outDir string, segments ...string) (string, error) {
+	parts := []string{outDir}
+	for _, seg := range segments {
+		name := strings.Trim(seg, `"`)
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`+"\x00") {
+			return "", fmt.Errorf("cannot export %q: the identifier is not usable as a file name", seg)
+		}
+		parts = append(parts, name)
 	}
-
-	type pair struct {
-		key *yaml.Node
-		val *yaml.Node
+	parts[len(parts)-1] += ".yaml"
+	path := filepath.Join(parts...)
+	rel, err := filepath.Rel(outDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("cannot export %q: path %q is outside %q", segments[len(segments)-1], path, outDir)
 	}
+	return path, nil
+}
 
-	pairs := make([]pair, 0, len(node.Content)/2)
-	for i := 0; i+1 < len(node.Content); i += 2 {
-		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+// exportAgentYAML describes the named agent in target and renders it as
+// export YAML, warning on stderr about columns and spec keys that are not
+// exported. The client is shared across agents, so a multi-select export
+// authenticates once.
+func exportAgentYAML(opts *RootOptions, client *api.Client, target Target, name string, format agent.YAMLFormat) ([]byte, error) {
+	var data []byte
+	var result api.DescribeResult
+	err := runWithRetry(opts, func() error {
+		var err error
+		data, result, err = client.DescribeAgentYAMLFormat(commandContext("export"), target.Database, target.Schema, name, format)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
-
-	// Build index of priority keys.
-	priorityIndex := make(map[string]int, len(priority))
-	for i, k := range priority {
-		priorityIndex[k] = i
+	if !result.Exists {
+		return nil, fmt.Errorf("agent %q not found", name)
 	}
-
-	// Split into priority and rest.
-	priorityPairs := make([]pair, len(priority))
-	found := make([]bool, len(priority))
-	var rest []pair
-
-	for _, p := range pairs {
-		if idx, ok := priorityIndex[p.key.Value]; ok {
-			priorityPairs[idx] = p
-			found[idx] = true
-		} else {
-			rest = append(rest, p)
-		}
+	for _, col := range result.UnmappedColumns {
+		fmt.Fprintf(os.Stderr, "\033[33mWarning: DESCRIBE AGENT returned unmapped column %q (not exported)\033[0m\n", col)
+	}
+	for _, key := range result.UnmappedSpecKeys {
+		fmt.Fprintf(os.Stderr, "\033[33mWarning: agent_spec contains unmapped key %q (not exported)\033[0m\n", key)
 	}
+	return data, nil
+}
 
-	// Rebuild: priority keys first (only those that exist), then rest.
-	result := make([]*yaml.Node, 0, len(node.Content))
-	for i, p := range priorityPairs {
-		if found[i] {
-			result = append(result, p.key, p.val)
-		}
+// selectRemoteAgents lists agents in the target schema and lets the user
+// pick several of them with selectAgents.
+func selectRemoteAgents(client *api.Client, target Target, command string) ([]string, error) {
+	agents, err := client.ListAgents(commandContext(command), target.Database, target.Schema)
+	if err != nil {
+		return nil, fmt.Errorf("list agents: %w", err)
 	}
-	for _, p := range rest {
-		result = append(result, p.key, p.val)
+	if len(agents) == 0 {
+		return nil, fmt.Errorf("no agents found in %s.%s", target.Database, target.Schema)
 	}
-	node.Content = result
+	return selectAgents(agents)
 }
@@ -2,10 +2,18 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
+	"coragent/internal/config"
+	"coragent/internal/regression"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,21 +21,11 @@ import (
 // encodeSpec is a test helper that encodes an AgentSpec through the export pipeline.
 func encodeSpec(t *testing.T, spec agent.AgentSpec) string {
 	t.Helper()
-	var doc yaml.Node
-	if err := doc.Encode(spec); err != nil {
+	data, err := api.RenderAgentYAML(api.DescribeResult{Exists: true, Spec: spec}, agent.YAMLFormat{})
+	if err != nil {
 		t.Fatal(err)
 	}
-	setLiteralStyleForMultiline(&doc)
-	reorderExportKeys(&doc)
-
-	var buf bytes.Buffer
-	enc := yaml.NewEncoder(&buf)
-	enc.SetIndent(2)
-	if err := enc.Encode(&doc); err != nil {
-		t.Fatal(err)
-	}
-	enc.Close()
-	return buf.String()
+	return string(data)
 }
 
 func TestExport_MultilineComment(t *testing.T) {
@@ -84,11 +82,37 @@ func TestExport_ToolSpecKeyOrder(t *testing.T) {
 	}
 }
 
+func TestExport_ToolSpecTitleBeforeDescription(t *testing.T) {
+	spec := agent.AgentSpec{
+		Name: "test-agent",
+		Tools: []agent.Tool{
+			{ToolSpec: map[string]any{
+				"description": "Search docs",
+				"title":       "Docs",
+				"type":        "cortex_search",
+				"name":        "docs",
+			}},
+		},
+	}
+	output := encodeSpec(t, spec)
+
+	typeIdx := strings.Index(output, "type: cortex_search")
+	titleIdx := strings.Index(output, "title: Docs")
+	descIdx := strings.Index(output, "description: Search docs")
+
+	if typeIdx == -1 || titleIdx == -1 || descIdx == -1 {
+		t.Fatalf("missing expected keys in output:\n%s", output)
+	}
+	if typeIdx > titleIdx || titleIdx > descIdx {
+		t.Errorf("expected type, title, description order in tool_spec:\n%s", output)
+	}
+}
+
 func TestExport_ToolResourcesSemanticViewFirst(t *testing.T) {
 	spec := agent.AgentSpec{
 		Name: "test-agent",
 		ToolResources: agent.ToolResources{
-			"my_tool": {
+			"my_tool": map[string]any{
 				"execution_environment": map[string]any{"type": "warehouse"},
 				"semantic_view":         "DB.SCHEMA.VIEW",
 			},
@@ -111,7 +135,7 @@ func TestExport_ToolResourcesSearchServiceFirst(t *testing.T) {
 	spec := agent.AgentSpec{
 		Name: "test-agent",
 		ToolResources: agent.ToolResources{
-			"search_tool": {
+			"search_tool": map[string]any{
 				"max_results":    4,
 				"id_column":      "ID",
 				"search_service": "DB.SCHEMA.SVC",
@@ -131,3 +155,232 @@ func TestExport_ToolResourcesSearchServiceFirst(t *testing.T) {
 		t.Errorf("search_service should appear first:\n%s", output)
 	}
 }
+
+func TestExport_NoNameWithoutInputIsUserError(t *testing.T) {
+	cmd := newExportCmd(&RootOptions{NoInput: true})
+	cmd.SetArgs([]string{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected error when no agent name is given under --no-input")
+	}
+	if !IsUserError(err) {
+		t.Errorf("expected user error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "agent name is required") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestExportRenderAgentYAML_ListsUnmappedInComment(t *testing.T) {
+	data, err := api.RenderAgentYAML(api.DescribeResult{
+		Exists:           true,
+		Spec:             agent.AgentSpec{Name: "test-agent"},
+		UnmappedColumns:  []string{"new_column"},
+		UnmappedSpecKeys: []string{"experimental"},
+	}, agent.YAMLFormat{})
+	if err != nil {
+		t.Fatalf("RenderAgentYAML: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"# Not exported (not representable in the coragent spec):\n",
+		"#   DESCRIBE AGENT column: new_column\n",
+		"#   agent_spec key: experimental\n",
+		"name: test-agent\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	var spec agent.AgentSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil || spec.Name != "test-agent" {
+		t.Errorf("exported YAML does not round-trip: spec=%+v err=%v", spec, err)
+	}
+}
+
+// runExportAllAgainstMock seeds the mock with two agents in DB.SCH and runs
+// export with args, returning stdout.
+func runExportAllAgainstMock(t *testing.T, args ...string) string {
+	t.Helper()
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	seed := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	for _, name := range []string{"sales-agent", "support-agent"} {
+		if err := seed.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: name, Comment: name + " comment"}); err != nil {
+			t.Fatalf("CreateAgent %s: %v", name, err)
+		}
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SNOWFLAKE_HOME", home)
+	t.Setenv("CORAGENT_API_BASE_URL", ms.URL())
+	t.Setenv("SNOWFLAKE_ACCOUNT", "TEST")
+	t.Setenv("SNOWFLAKE_TOKEN", "tok")
+
+	var out bytes.Buffer
+	cmd := newExportCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetOut(&out)
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	return out.String()
+}
+
+func assertExportedTree(t *testing.T, outDir, out string) {
+	t.Helper()
+	for _, name := range []string{"sales-agent", "support-agent"} {
+		path := filepath.Join(outDir, "DB", "SCH", name+".yaml")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read %s: %v\noutput:\n%s", path, err, out)
+		}
+		var spec agent.AgentSpec
+		if err := yaml.Unmarshal(data, &spec); err != nil {
+			t.Fatalf("parse %s: %v", path, err)
+		}
+		if spec.Name != name || spec.Comment != name+" comment" {
+			t.Errorf("%s = %+v", path, spec)
+		}
+	}
+	if !strings.Contains(out, "Exported 2 agent(s) to "+outDir) {
+		t.Errorf("missing summary in output:\n%s", out)
+	}
+}
+
+func TestExportAll_WritesOneFilePerAgent(t *testing.T) {
+	outDir := t.TempDir()
+	out := runExportAllAgainstMock(t, "--all", "--out-dir", outDir)
+	assertExportedTree(t, outDir, out)
+}
+
+func TestExportAll_AllSchemas(t *testing.T) {
+	outDir := t.TempDir()
+	out := runExportAllAgainstMock(t, "--all", "--all-schemas", "--out-dir", outDir)
+	assertExportedTree(t, outDir, out)
+}
+
+func TestExportAll_FlagValidation(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--all"}, "--all requires --out-dir"},
+		{[]string{"--all", "--out-dir", "x", "my-agent"}, "cannot be combined"},
+		{[]string{"--out-dir", "x", "my-agent"}, "require --all"},
+		{[]string{"--all-schemas", "my-agent"}, "require --all"},
+	}
+	for _, tt := range tests {
+		cmd := newExportCmd(&RootOptions{})
+		cmd.SetArgs(tt.args)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		err := cmd.Execute()
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("args %v: err = %v, want containing %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestExportFilePath(t *testing.T) {
+	outDir := filepath.Join("out", "agents")
+	got, err := exportFilePath(outDir, "DB", `"My Schema"`, "sales-agent")
+	if err != nil {
+		t.Fatalf("exportFilePath: %v", err)
+	}
+	if want := filepath.Join(outDir, "DB", "My Schema", "sales-agent.yaml"); got != want {
+		t.Errorf("path = %q, want %q", got, want)
+	}
+
+	for _, name := range []string{`"../escape"`, "a/b", `a\b`, "..", `"."`, `""`} {
+		if _, err := exportFilePath(outDir, "DB", "SCH", name); err == nil {
+			t.Errorf("exportFilePath(%q) succeeded, want error", name)
+		}
+	}
+	if _, err := exportFilePath(outDir, "..", "SCH", "agent"); err == nil {
+		t.Error("exportFilePath with database \"..\" succeeded, want error")
+	}
+}
+
+func TestExportRenderAgentYAML_Format(t *testing.T) {
+	spec := agent.AgentSpec{
+		Name:    "test-agent",
+		Comment: "line1\nline2",
+		Profile: &agent.Profile{DisplayName: "Sales"},
+	}
+	tests := []struct {
+		name   string
+		format agent.YAMLFormat
+		want   []string
+		absent []string
+	}{
+		{"default", agent.YAMLFormat{}, []string{"name: test-agent\n", "profile:\n  display_name: Sales\n", "comment: |-\n  line1\n"}, []string{`"`}},
+		{"indent 4", agent.YAMLFormat{Indent: 4}, []string{"profile:\n    display_name: Sales\n", "comment: |-\n    line1\n"}, nil},
+		{"quote strings", agent.YAMLFormat{QuoteStrings: true}, []string{"name: \"test-agent\"\n", "display_name: \"Sales\"\n", "comment: |-\n"}, []string{`"name"`, `"line1`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := api.RenderAgentYAML(api.DescribeResult{Exists: true, Spec: spec}, tt.format)
+			if err != nil {
+				t.Fatalf("RenderAgentYAML: %v", err)
+			}
+			out := string(data)
+			for _, want := range tt.want {
+				if !strings.Contains(out, want) {
+					t.Errorf("output missing %q:\n%s", want, out)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(out, absent) {
+					t.Errorf("output should not contain %q:\n%s", absent, out)
+				}
+			}
+			var got agent.AgentSpec
+			if err := yaml.Unmarshal(data, &got); err != nil || got.Name != spec.Name || got.Comment != spec.Comment {
+				t.Errorf("exported YAML does not round-trip: spec=%+v err=%v", got, err)
+			}
+		})
+	}
+}
+
+func TestResolveYAMLFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings config.FormatSettings
+		args     []string
+		want     agent.YAMLFormat
+		wantErr  string
+	}{
+		{"default", config.FormatSettings{}, nil, agent.YAMLFormat{Indent: 2}, ""},
+		{"config", config.FormatSettings{YAMLIndent: 4, QuoteStrings: true}, nil, agent.YAMLFormat{Indent: 4, QuoteStrings: true}, ""},
+		{"flags win", config.FormatSettings{YAMLIndent: 4, QuoteStrings: true}, []string{"--yaml-indent", "3", "--quote-strings=false"}, agent.YAMLFormat{Indent: 3}, ""},
+		{"out of range", config.FormatSettings{}, []string{"--yaml-indent", "1"}, agent.YAMLFormat{}, "between 2 and 8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newExportCmd(&RootOptions{})
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("parse flags: %v", err)
+			}
+			indent, _ := cmd.Flags().GetInt("yaml-indent")
+			quote, _ := cmd.Flags().GetBool("quote-strings")
+			got, err := resolveYAMLFormat(cmd, tt.settings, indent, quote)
+			if tt.wantErr != "" {
+				if !IsUserError(err) || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected user error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("resolveYAMLFormat = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+}
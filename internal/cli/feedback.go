@@ -3,14 +3,18 @@ package cli
 import (
 	"bufio"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 
 	"coragent/internal/api"
 	"coragent/internal/auth"
@@ -60,6 +64,20 @@ func feedbackQueryOptions(since string, inferNegative bool, judgeModel string) a
 	return opts
 }
 
+// startSQLSpinner shows a spinner on stderr while feedback SQL statements
+// run. It returns the callback for FeedbackQueryOptions.OnSQLProgress and a
+// stop function. When disabled or stderr is not a terminal, the callback is
+// nil and stop does nothing.
+func startSQLSpinner(enabled bool) (func(string), func()) {
+	if !enabled || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil, func() {}
+	}
+	s := newSpinner()
+	s.SetMessage("Querying observability events...")
+	s.Start()
+	return s.SetMessage, s.Stop
+}
+
 func feedbackProgressf(cmd *cobra.Command, enabled bool, format string, args ...any) {
 	if !enabled {
 		return
@@ -71,6 +89,7 @@ func newFeedbackCmd(opts *RootOptions) *cobra.Command {
 	var showAll bool
 	var limit int
 	var jsonOut bool
+	var output string
 	var yes bool
 	var includeChecked bool
 	var noTools bool
@@ -78,6 +97,9 @@ func newFeedbackCmd(opts *RootOptions) *cobra.Command {
 	var clearCache bool
 	var initTable bool
 	var inferNegative bool
+	var exportEval string
+	var sentiment string
+	var since time.Duration
 
 	cmd := &cobra.Command{
 		Use:   "feedback [agent-name]",
@@ -89,13 +111,19 @@ Records are shown one at a time. After each record you are prompted to mark
 it as checked; checked records are hidden on subsequent runs, letting you
 work through feedback incrementally.
 
-By default, only negative feedback is shown. Use --all to show all feedback.`,
+By default, only negative feedback is shown. Use --all to show all feedback,
+or --sentiment to show one sentiment. --since keeps only recent records. In
+text output a summary line with counts by sentiment and the average response
+time of the records in scope precedes the records.`,
 		Example: `  # Show negative feedback (default)
   coragent feedback my-agent -d MY_DB -s MY_SCHEMA
 
   # Show all feedback
   coragent feedback my-agent --all
 
+  # Show positive feedback from the last 7 days
+  coragent feedback my-agent --sentiment positive --since 168h
+
   # Auto-confirm marking each record as checked
   coragent feedback my-agent -y
 
@@ -108,9 +136,15 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
   # JSON output
   coragent feedback my-agent --json | jq .
 
+  # CSV for a spreadsheet
+  coragent feedback my-agent --all --output csv > feedback.csv
+
   # Infer negative interactions without explicit feedback
   coragent feedback my-agent --infer-negative
 
+  # Turn negative feedback into eval test cases
+  coragent feedback my-agent --export-eval cases.yaml
+
   # Ensure remote feedback table exists (when feedback.remote.enabled in config)
   coragent feedback --init`,
 		Args: func(cmd *cobra.Command, args []string) error {
@@ -124,6 +158,29 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
 			return cobra.ExactArgs(1)(cmd, args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch output {
+			case "text", "json", "csv":
+			default:
+				return UserErr(fmt.Errorf("invalid --output %q (valid: text, json, csv)", output))
+			}
+			if jsonOut {
+				if output == "csv" {
+					return UserErr(fmt.Errorf("--json cannot be combined with --output csv"))
+				}
+				output = "json"
+			}
+			switch sentiment {
+			case "", "positive", "negative", "unknown":
+			default:
+				return UserErr(fmt.Errorf("invalid --sentiment %q (valid: positive, negative, unknown)", sentiment))
+			}
+			if sentiment != "" && showAll {
+				return UserErr(fmt.Errorf("--sentiment cannot be combined with --all"))
+			}
+			if since < 0 {
+				return UserErr(fmt.Errorf("--since must not be negative"))
+			}
+
 			appCfg := config.LoadCoragentConfig()
 			feedbackJudgeModel := resolveFeedbackJudgeModel(appCfg)
 			remoteDb, remoteSchema, remoteTable := resolveFeedbackRemote(appCfg)
@@ -183,7 +240,7 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
 			var remoteClient feedbackClient
 			var toShow []feedbackcache.Record
 			var localCache *feedbackcache.Cache
-			progressEnabled := !jsonOut
+			progressEnabled := output == "text"
 			if useRemote {
 				feedbackProgressf(cmd, progressEnabled, "Loading remote feedback state...")
 				client, cfg, err := buildFeedbackClientAndCfg(opts)
@@ -229,7 +286,12 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
 					} else {
 						feedbackProgressf(cmd, progressEnabled, "Syncing feedback updates since %s into %s.%s.%s...", since, remoteDb, remoteSchema, remoteTable)
 					}
-					if err := client.SyncFeedbackFromEventsToTable(ctx, target.Database, target.Schema, agentName, remoteDb, remoteSchema, remoteTable, feedbackQueryOptions(since, inferNegative, feedbackJudgeModel)); err != nil {
+					queryOpts := feedbackQueryOptions(since, inferNegative, feedbackJudgeModel)
+					onProgress, stopSpinner := startSQLSpinner(progressEnabled)
+					queryOpts.OnSQLProgress = onProgress
+					err = client.SyncFeedbackFromEventsToTable(ctx, target.Database, target.Schema, agentName, remoteDb, remoteSchema, remoteTable, queryOpts)
+					stopSpinner()
+					if err != nil {
 						return fmt.Errorf("sync feedback to remote table: %w", err)
 					}
 				}
@@ -264,7 +326,11 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
 					} else {
 						feedbackProgressf(cmd, progressEnabled, "Fetching feedback updates since %s...", since)
 					}
-					fresh, err := client.GetFeedback(ctx, target.Database, target.Schema, agentName, feedbackQueryOptions(since, inferNegative, feedbackJudgeModel))
+					queryOpts := feedbackQueryOptions(since, inferNegative, feedbackJudgeModel)
+					onProgress, stopSpinner := startSQLSpinner(progressEnabled)
+					queryOpts.OnSQLProgress = onProgress
+					fresh, err := client.GetFeedback(ctx, target.Database, target.Schema, agentName, queryOpts)
+					stopSpinner()
 					if err != nil {
 						return err
 					}
@@ -287,36 +353,56 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
 			}
 			feedbackProgressf(cmd, progressEnabled, "Preparing feedback records for display...")
 
-			// Apply sentiment filter (--all) and --limit.
-			if !showAll {
-				var filtered []feedbackcache.Record
-				for _, r := range toShow {
-					if r.Sentiment == "negative" {
-						filtered = append(filtered, r)
-					}
-				}
-				toShow = filtered
+			// Apply --since, then the sentiment filter (--all / --sentiment) and --limit.
+			if since > 0 {
+				toShow = filterFeedbackSince(toShow, time.Now().Add(-since))
+			}
+			summary := feedbackSummary(toShow)
+			if sentiment == "" && !showAll {
+				sentiment = "negative"
+			}
+			if sentiment != "" {
+				toShow = filterFeedbackSentiment(toShow, sentiment)
 			}
 			if limit > 0 && len(toShow) > limit {
 				toShow = toShow[:limit]
 			}
 
-			// 5. JSON output — no prompt.
-			if jsonOut {
+			// Eval export — no prompt.
+			if exportEval != "" {
+				n, err := writeFeedbackEvalFile(exportEval, toShow)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Wrote %d eval test case(s) to %s.\n", n, exportEval)
+				return nil
+			}
+
+			// 5. JSON or CSV output — no prompt.
+			switch output {
+			case "json":
 				data, err := marshalFeedbackJSON(toShow)
 				if err != nil {
 					return fmt.Errorf("marshal JSON: %w", err)
 				}
 				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
 				return err
+			case "csv":
+				return writeFeedbackCSV(cmd.OutOrStdout(), toShow)
 			}
 
 			// 6. Header.
-			filter := "negative only"
-			if showAll {
-				filter = "all"
+			filter := "all"
+			if sentiment != "" {
+				filter = sentiment + " only"
+			}
+			if since > 0 {
+				filter += ", last " + since.String()
 			}
 			fmt.Fprintf(cmd.OutOrStdout(), "Feedback for agent %q (%s):\n\n", agentName, filter)
+			if summary != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n", summary)
+			}
 
 			if len(toShow) == 0 {
 				if inferNegative {
@@ -394,7 +480,8 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
 
 	cmd.Flags().BoolVar(&showAll, "all", false, "Show all feedback (default: negative only)")
 	cmd.Flags().IntVar(&limit, "limit", 50, "Maximum number of records to show (0 = unlimited)")
-	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON array")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output as JSON array (same as --output json)")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format: text, json or csv (json and csv skip the checked prompt)")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Auto-confirm marking each record as checked")
 	cmd.Flags().BoolVar(&includeChecked, "include-checked", false, "Also show already-checked records")
 	cmd.Flags().BoolVar(&noTools, "no-tools", false, "Hide tool invocation details (Tools, Query, SQL)")
@@ -402,6 +489,9 @@ By default, only negative feedback is shown. Use --all to show all feedback.`,
 	cmd.Flags().BoolVar(&clearCache, "clear", false, "Clear feedback state for the agent and exit (local cache or remote table)")
 	cmd.Flags().BoolVar(&initTable, "init", false, "Ensure the remote feedback table exists (create if missing); requires feedback.remote in config")
 	cmd.Flags().BoolVar(&inferNegative, "infer-negative", false, "Infer negative interactions from request/response pairs when explicit feedback is absent")
+	cmd.Flags().StringVar(&sentiment, "sentiment", "", "Show only feedback with this sentiment: positive, negative or unknown (default: negative)")
+	cmd.Flags().DurationVar(&since, "since", 0, "Only include feedback recorded within this duration (e.g. 168h)")
+	cmd.Flags().StringVar(&exportEval, "export-eval", "", "Write negative feedback with a question as eval test cases to this YAML file")
 
 	return cmd
 }
@@ -412,6 +502,67 @@ func resolveFeedbackRemote(appCfg config.CoragentConfig) (db, schema, table stri
 	return strings.TrimSpace(r.Database), strings.TrimSpace(r.Schema), strings.TrimSpace(r.Table)
 }
 
+// filterFeedbackSentiment returns the records with the given sentiment.
+func filterFeedbackSentiment(records []feedbackcache.Record, sentiment string) []feedbackcache.Record {
+	var filtered []feedbackcache.Record
+	for _, r := range records {
+		if r.Sentiment == sentiment {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// filterFeedbackSince returns the records whose timestamp is at or after
+// cutoff. Records with a timestamp that cannot be parsed are dropped.
+func filterFeedbackSince(records []feedbackcache.Record, cutoff time.Time) []feedbackcache.Record {
+	var filtered []feedbackcache.Record
+	for _, r := range records {
+		ts, ok := parseFeedbackTimestamp(r.Timestamp)
+		if ok && !ts.Before(cutoff) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
+
+// parseFeedbackTimestamp parses the normalized record timestamp
+// ("2006-01-02 15:04:05.000 UTC"), falling back to RFC 3339.
+func parseFeedbackTimestamp(s string) (time.Time, bool) {
+	if ts, err := time.Parse("2006-01-02 15:04:05.000 MST", s); err == nil {
+		return ts, true
+	}
+	if ts, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return ts, true
+	}
+	return time.Time{}, false
+}
+
+// feedbackSummary returns a line with the record counts by sentiment and the
+// average response_time_ms of the records that have one, or "" when there
+// are no records.
+func feedbackSummary(records []feedbackcache.Record) string {
+	if len(records) == 0 {
+		return ""
+	}
+	counts := map[string]int{}
+	var totalMs int64
+	timed := 0
+	for _, r := range records {
+		counts[r.Sentiment]++
+		if r.ResponseTimeMs > 0 {
+			totalMs += r.ResponseTimeMs
+			timed++
+		}
+	}
+	line := fmt.Sprintf("Summary: %d record(s): %d negative, %d positive, %d unknown",
+		len(records), counts["negative"], counts["positive"], counts["unknown"])
+	if timed > 0 {
+		line += fmt.Sprintf("; avg response time %dms", totalMs/int64(timed))
+	}
+	return line
+}
+
 // runFeedbackInit ensures the remote feedback table exists; creates it if missing.
 func runFeedbackInit(cmd *cobra.Command, opts *RootOptions, appCfg config.CoragentConfig) error {
 	db, schema, table := resolveFeedbackRemote(appCfg)
@@ -543,6 +694,39 @@ func marshalFeedbackJSON(records []feedbackcache.Record) ([]byte, error) {
 	return json.MarshalIndent(records, "", "  ")
 }
 
+// feedbackCSVHeader lists the columns written by writeFeedbackCSV.
+var feedbackCSVHeader = []string{"record_id", "timestamp", "sentiment", "user_name", "question", "response", "response_time_ms", "categories"}
+
+// writeFeedbackCSV writes records as CSV with a header row. Categories are
+// joined with semicolons; a header alone is written when there are no records.
+func writeFeedbackCSV(w io.Writer, records []feedbackcache.Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(feedbackCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		responseTime := ""
+		if r.ResponseTimeMs > 0 {
+			responseTime = strconv.FormatInt(r.ResponseTimeMs, 10)
+		}
+		row := []string{
+			r.RecordID,
+			r.Timestamp,
+			r.Sentiment,
+			r.UserName,
+			r.Question,
+			r.Response,
+			responseTime,
+			strings.Join(r.Categories, ";"),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
 // printOneRecord prints a single feedback record with its index out of total.
 func printOneRecord(cmd *cobra.Command, idx, total int, r feedbackcache.Record, includeChecked bool, noTools bool) {
 	checkedMark := ""
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"coragent/internal/agent"
+	"coragent/internal/feedbackcache"
+)
+
+// feedbackEvalCase pairs a generated eval test case with the feedback text it
+// came from so the text can be emitted as a YAML comment.
+type feedbackEvalCase struct {
+	Test agent.EvalTestCase
+	Note string
+}
+
+// feedbackEvalCases converts negative feedback records into eval test cases.
+// Records without a question are skipped and duplicate questions are kept only
+// once. The tools the agent actually used become the starting expected_tools;
+// when it used none, an expected_response derived from the feedback gives the
+// case the assertion the spec loader requires.
+func feedbackEvalCases(records []feedbackcache.Record) []feedbackEvalCase {
+	var out []feedbackEvalCase
+	seen := make(map[string]bool)
+	for _, r := range records {
+		if r.Sentiment != "negative" {
+			continue
+		}
+		question := strings.TrimSpace(r.Question)
+		if question == "" || seen[question] {
+			continue
+		}
+		seen[question] = true
+
+		var tools []string
+		toolSeen := make(map[string]bool)
+		for _, tu := range r.ToolUses {
+			if tu.ToolName == "" || toolSeen[tu.ToolName] {
+				continue
+			}
+			toolSeen[tu.ToolName] = true
+			tools = append(tools, tu.ToolName)
+		}
+
+		note := strings.TrimSpace(r.FeedbackMessage)
+		if note == "" {
+			note = strings.TrimSpace(r.SentimentReason)
+		}
+		test := agent.EvalTestCase{Question: question, ExpectedTools: tools}
+		if len(tools) == 0 {
+			test.ExpectedResponse = feedbackExpectedResponse(note)
+		}
+		out = append(out, feedbackEvalCase{Test: test, Note: note})
+	}
+	return out
+}
+
+// feedbackExpectedResponse describes the answer a case without tool
+// expectations should be scored against, based on the feedback note.
+func feedbackExpectedResponse(note string) string {
+	if note == "" {
+		return "A correct and complete answer to the question."
+	}
+	return "A correct and complete answer to the question that avoids the reported problem: " + strings.Join(strings.Fields(note), " ")
+}
+
+// marshalFeedbackEvalCases renders cases as an `eval.tests` YAML fragment that
+// can be merged into an agent spec. Feedback text is attached as a comment
+// above each test so the fragment still loads with strict field checking.
+func marshalFeedbackEvalCases(cases []feedbackEvalCase) ([]byte, error) {
+	tests := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, c := range cases {
+		var item yaml.Node
+		if err := item.Encode(c.Test); err != nil {
+			return nil, fmt.Errorf("encode eval test: %w", err)
+		}
+		if c.Note != "" {
+			item.HeadComment = "feedback: " + strings.Join(strings.Fields(c.Note), " ")
+		}
+		tests.Content = append(tests.Content, &item)
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "eval"},
+		{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "tests"},
+			tests,
+		}},
+	}}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeFeedbackEvalFile writes eval test cases derived from records to path
+// and returns the number of cases written.
+func writeFeedbackEvalFile(path string, records []feedbackcache.Record) (int, error) {
+	cases := feedbackEvalCases(records)
+	data, err := marshalFeedbackEvalCases(cases)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return 0, fmt.Errorf("write eval cases: %w", err)
+	}
+	return len(cases), nil
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/feedbackcache"
+)
+
+func sampleFeedbackRecords() []feedbackcache.Record {
+	return []feedbackcache.Record{
+		{FeedbackRecord: api.FeedbackRecord{
+			RecordID:        "r1",
+			Sentiment:       "negative",
+			Question:        "What was Q4 revenue?",
+			FeedbackMessage: "Used the wrong\nfiscal calendar",
+			ToolUses: []api.ToolUseInfo{
+				{ToolName: "revenue_view"},
+				{ToolName: "data_to_chart"},
+				{ToolName: "revenue_view"},
+			},
+		}},
+		{FeedbackRecord: api.FeedbackRecord{
+			RecordID:  "r2",
+			Sentiment: "positive",
+			Question:  "Show me sales",
+		}},
+		{FeedbackRecord: api.FeedbackRecord{
+			RecordID:  "r3",
+			Sentiment: "negative",
+		}},
+		{FeedbackRecord: api.FeedbackRecord{
+			RecordID:        "r4",
+			Sentiment:       "negative",
+			Question:        "What was Q4 revenue?",
+			FeedbackMessage: "duplicate",
+		}},
+		{FeedbackRecord: api.FeedbackRecord{
+			RecordID:        "r5",
+			Sentiment:       "negative",
+			Question:        "  Search the docs  ",
+			SentimentReason: "Answer ignored the search results",
+		}},
+	}
+}
+
+func TestFeedbackEvalCases(t *testing.T) {
+	cases := feedbackEvalCases(sampleFeedbackRecords())
+	if len(cases) != 2 {
+		t.Fatalf("expected 2 cases, got %d: %+v", len(cases), cases)
+	}
+
+	if cases[0].Test.Question != "What was Q4 revenue?" {
+		t.Errorf("cases[0].Question = %q", cases[0].Test.Question)
+	}
+	if want := []string{"revenue_view", "data_to_chart"}; !reflect.DeepEqual(cases[0].Test.ExpectedTools, want) {
+		t.Errorf("cases[0].ExpectedTools = %v, want %v", cases[0].Test.ExpectedTools, want)
+	}
+	if cases[0].Note != "Used the wrong\nfiscal calendar" {
+		t.Errorf("cases[0].Note = %q", cases[0].Note)
+	}
+
+	if cases[1].Test.Question != "Search the docs" {
+		t.Errorf("cases[1].Question = %q", cases[1].Test.Question)
+	}
+	if cases[1].Test.ExpectedTools != nil {
+		t.Errorf("cases[1].ExpectedTools = %v, want nil", cases[1].Test.ExpectedTools)
+	}
+	if want := "A correct and complete answer to the question that avoids the reported problem: Answer ignored the search results"; cases[1].Test.ExpectedResponse != want {
+		t.Errorf("cases[1].ExpectedResponse = %q, want %q", cases[1].Test.ExpectedResponse, want)
+	}
+	if cases[0].Test.ExpectedResponse != "" {
+		t.Errorf("cases[0].ExpectedResponse = %q, want empty when tools are expected", cases[0].Test.ExpectedResponse)
+	}
+	if cases[1].Note != "Answer ignored the search results" {
+		t.Errorf("cases[1].Note should fall back to sentiment reason, got %q", cases[1].Note)
+	}
+}
+
+func TestWriteFeedbackEvalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cases.yaml")
+	n, err := writeFeedbackEvalFile(path, sampleFeedbackRecords())
+	if err != nil {
+		t.Fatalf("writeFeedbackEvalFile: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("n = %d, want 2", n)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read output: %v", err)
+	}
+	out := string(data)
+	if !strings.Contains(out, "# feedback: Used the wrong fiscal calendar") {
+		t.Errorf("expected feedback note comment, got:\n%s", out)
+	}
+
+	var parsed struct {
+		Eval agent.EvalConfig `yaml:"eval"`
+	}
+	dec := yaml.NewDecoder(strings.NewReader(out))
+	dec.KnownFields(true)
+	if err := dec.Decode(&parsed); err != nil {
+		t.Fatalf("decode fragment: %v\n%s", err, out)
+	}
+	if len(parsed.Eval.Tests) != 2 {
+		t.Fatalf("expected 2 tests in fragment, got %d", len(parsed.Eval.Tests))
+	}
+	if parsed.Eval.Tests[0].ExpectedTools[0] != "revenue_view" {
+		t.Errorf("unexpected expected_tools: %v", parsed.Eval.Tests[0].ExpectedTools)
+	}
+}
+
+func TestFeedbackEvalFile_LoadsIntoSpec(t *testing.T) {
+	dir := t.TempDir()
+	records := append(sampleFeedbackRecords(), feedbackcache.Record{FeedbackRecord: api.FeedbackRecord{
+		RecordID:  "r6",
+		Sentiment: "negative",
+		Question:  "Why is the answer empty?",
+	}})
+	if _, err := writeFeedbackEvalFile(filepath.Join(dir, "cases.yaml"), records); err != nil {
+		t.Fatalf("writeFeedbackEvalFile: %v", err)
+	}
+	spec := `name: feedback-agent
+include:
+  - ./cases.yaml
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: revenue_view
+`
+	if err := os.WriteFile(filepath.Join(dir, "agent.yaml"), []byte(spec), 0o644); err != nil {
+		t.Fatalf("write spec: %v", err)
+	}
+
+	agents, err := agent.LoadAgents(filepath.Join(dir, "agent.yaml"), false, "")
+	if err != nil {
+		t.Fatalf("LoadAgents with exported cases: %v", err)
+	}
+	if got := len(agents[0].Spec.Eval.Tests); got != 3 {
+		t.Errorf("loaded %d eval tests, want 3", got)
+	}
+}
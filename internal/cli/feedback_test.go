@@ -948,6 +948,80 @@ func TestMarshalFeedbackJSON_NonEmptyReturnsArray(t *testing.T) {
 	}
 }
 
+func TestWriteFeedbackCSV(t *testing.T) {
+	var out bytes.Buffer
+	err := writeFeedbackCSV(&out, []feedbackcache.Record{
+		{FeedbackRecord: api.FeedbackRecord{
+			RecordID:       "r1",
+			Timestamp:      "2026-03-08 00:00:00.000 UTC",
+			Sentiment:      "negative",
+			UserName:       "alice",
+			Question:       "Sales, by region?",
+			Response:       "Line one\nline \"two\"",
+			ResponseTimeMs: 1234,
+			Categories:     []string{"wrong_answer", "slow"},
+		}},
+		{FeedbackRecord: api.FeedbackRecord{RecordID: "r2", Sentiment: "positive"}},
+	})
+	if err != nil {
+		t.Fatalf("writeFeedbackCSV() error = %v", err)
+	}
+
+	want := "record_id,timestamp,sentiment,user_name,question,response,response_time_ms,categories\n" +
+		"r1,2026-03-08 00:00:00.000 UTC,negative,alice,\"Sales, by region?\",\"Line one\nline \"\"two\"\"\",1234,wrong_answer;slow\n" +
+		"r2,,positive,,,,,\n"
+	if out.String() != want {
+		t.Fatalf("writeFeedbackCSV() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestFeedbackOutputCSV(t *testing.T) {
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Setenv("HOME", dir)
+
+	client := &stubFeedbackClient{
+		getFeedbackFn: func(ctx context.Context, db, schema, agentName string, opts api.FeedbackQueryOptions) ([]api.FeedbackRecord, error) {
+			return []api.FeedbackRecord{{RecordID: "r1", Sentiment: "negative", Question: "q"}}, nil
+		},
+	}
+	origBuild := buildFeedbackClientAndCfg
+	t.Cleanup(func() { buildFeedbackClientAndCfg = origBuild })
+	buildFeedbackClientAndCfg = func(opts *RootOptions) (feedbackClient, auth.Config, error) {
+		return client, auth.Config{Database: "DB", Schema: "SC"}, nil
+	}
+
+	var out bytes.Buffer
+	cmd := newFeedbackCmd(&RootOptions{Database: "DB", Schema: "SC"})
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"my-agent", "--output", "csv"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "record_id,") || lines[1] != "r1,,negative,,q,,," {
+		t.Fatalf("unexpected CSV output:\n%s", out.String())
+	}
+}
+
+func TestFeedbackOutputRejectsInvalidFormat(t *testing.T) {
+	for _, args := range [][]string{
+		{"my-agent", "--output", "xml"},
+		{"my-agent", "--json", "--output", "csv"},
+	} {
+		cmd := newFeedbackCmd(&RootOptions{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil || !IsUserError(err) {
+			t.Errorf("%v: expected user error, got %v", args, err)
+		}
+	}
+}
+
 func TestFormatToolChain(t *testing.T) {
 	got := formatToolChain([]api.ToolUseInfo{
 		{ToolType: "cortex_analyst_text_to_sql", ToolName: "sample_semantic_view"},
@@ -1009,3 +1083,109 @@ func TestPrintOneRecord_ShowsInferenceMetadata(t *testing.T) {
 		t.Fatalf("expected inference reason in output, got:\n%s", got)
 	}
 }
+
+func seedSentimentFeedbackCache(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Setenv("HOME", dir)
+
+	recent := time.Now().UTC().Add(-time.Hour).Format("2006-01-02 15:04:05.000 UTC")
+	old := time.Now().UTC().Add(-72 * time.Hour).Format("2006-01-02 15:04:05.000 UTC")
+	if err := feedbackcache.Save("my-agent", &feedbackcache.Cache{
+		Records: []feedbackcache.Record{
+			{FeedbackRecord: api.FeedbackRecord{RecordID: "neg-new", Timestamp: recent, Sentiment: "negative", ResponseTimeMs: 1000}},
+			{FeedbackRecord: api.FeedbackRecord{RecordID: "pos-new", Timestamp: recent, Sentiment: "positive", ResponseTimeMs: 3000}},
+			{FeedbackRecord: api.FeedbackRecord{RecordID: "unk-new", Timestamp: recent, Sentiment: "unknown"}},
+			{FeedbackRecord: api.FeedbackRecord{RecordID: "pos-old", Timestamp: old, Sentiment: "positive", ResponseTimeMs: 9000}},
+		},
+	}); err != nil {
+		t.Fatalf("seed cache: %v", err)
+	}
+}
+
+func TestFeedbackSentimentAndSinceFilter(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"--sentiment", "positive"}, []string{"pos-new", "pos-old"}},
+		{[]string{"--sentiment", "positive", "--since", "24h"}, []string{"pos-new"}},
+		{[]string{"--since", "24h"}, []string{"neg-new"}},
+		{[]string{"--all", "--since", "24h"}, []string{"neg-new", "pos-new", "unk-new"}},
+		{[]string{"--sentiment", "unknown"}, []string{"unk-new"}},
+	}
+	for _, tt := range tests {
+		seedSentimentFeedbackCache(t)
+		var out bytes.Buffer
+		cmd := newFeedbackCmd(&RootOptions{})
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"my-agent", "--no-refresh", "--json"}, tt.args...))
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("%v: Execute() error = %v", tt.args, err)
+		}
+		var records []feedbackcache.Record
+		if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+			t.Fatalf("%v: parse JSON: %v\n%s", tt.args, err, out.String())
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, r.RecordID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%v: records = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestFeedbackTextPrintsSummary(t *testing.T) {
+	seedSentimentFeedbackCache(t)
+	var out bytes.Buffer
+	cmd := newFeedbackCmd(&RootOptions{})
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"my-agent", "--no-refresh", "--sentiment", "positive", "--since", "24h", "-y"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	for _, want := range []string{
+		"(positive only, last 24h0m0s):",
+		"Summary: 3 record(s): 1 negative, 1 positive, 1 unknown; avg response time 2000ms\n",
+		"1 record(s) shown",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestFeedbackSummary(t *testing.T) {
+	if got := feedbackSummary(nil); got != "" {
+		t.Errorf("feedbackSummary(nil) = %q, want empty", got)
+	}
+	got := feedbackSummary([]feedbackcache.Record{
+		{FeedbackRecord: api.FeedbackRecord{Sentiment: "negative"}},
+		{FeedbackRecord: api.FeedbackRecord{Sentiment: "negative"}},
+	})
+	if want := "Summary: 2 record(s): 2 negative, 0 positive, 0 unknown"; got != want {
+		t.Errorf("feedbackSummary = %q, want %q", got, want)
+	}
+}
+
+func TestFeedbackSentimentRejectsInvalidFlags(t *testing.T) {
+	for _, args := range [][]string{
+		{"my-agent", "--sentiment", "angry"},
+		{"my-agent", "--sentiment", "negative", "--all"},
+		{"my-agent", "--since", "-1h"},
+	} {
+		cmd := newFeedbackCmd(&RootOptions{})
+		cmd.SetArgs(args)
+		if err := cmd.Execute(); err == nil || !IsUserError(err) {
+			t.Errorf("%v: expected user error, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/grant"
+
+	"github.com/spf13/cobra"
+)
+
+func newGrantCmd(opts *RootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grant",
+		Short: "Review agent grants separately from spec changes",
+		Long: `Commands for reviewing the privileges declared in deploy.grant against
+the grants that exist in Snowflake, without looking at the agent spec.`,
+	}
+
+	cmd.AddCommand(newGrantDiffCmd(opts))
+
+	return cmd
+}
+
+func newGrantDiffCmd(opts *RootOptions) *cobra.Command {
+	var recursive bool
+	cmd := &cobra.Command{
+		Use:   "diff <agent-name> [path]",
+		Short: "Show which privileges apply would grant or revoke for an agent",
+		Long: `Compare the deploy.grant account_roles and database_roles of an agent's
+spec with SHOW GRANTS ON AGENT and print the GRANT and REVOKE statements
+apply would run. The agent spec itself is neither compared nor changed.`,
+		Example: `  # Review grants of my-agent defined under the current directory
+  coragent grant diff my-agent
+
+  # Look for the spec in a directory tree
+  coragent grant diff my-agent ./agents -R`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) == 2 {
+				path = args[1]
+			}
+			specs, err := agent.LoadAgents(path, recursive, opts.Env)
+			if err != nil {
+				return UserErr(err)
+			}
+			parsed, err := findParsedAgent(specs, args[0], path)
+			if err != nil {
+				return err
+			}
+
+			client, cfg, err := buildClientAndCfg(opts)
+			if err != nil {
+				return err
+			}
+			target, err := ResolveTarget(parsed.Spec, opts, cfg)
+			if err != nil {
+				return err
+			}
+			return runGrantDiff(commandContext("grant"), cmd.OutOrStdout(), parsed.Spec, target, client)
+		},
+	}
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Recursively load agents from subdirectories")
+	return cmd
+}
+
+// findParsedAgent returns the spec named name from specs loaded from path.
+func findParsedAgent(specs []agent.ParsedAgent, name, path string) (agent.ParsedAgent, error) {
+	for _, s := range specs {
+		if s.Spec.Name == name {
+			return s, nil
+		}
+	}
+	return agent.ParsedAgent{}, UserErr(fmt.Errorf("no spec for agent %q found in %s", name, path))
+}
+
+// runGrantDiff prints the grant changes apply would make for spec at target.
+// Like plan, extra grants on an existing agent are reported as revokes, and
+// an agent that does not exist yet is diffed against no grants.
+func runGrantDiff(ctx context.Context, w io.Writer, spec agent.AgentSpec, target Target, grantSvc api.GrantService) error {
+	var grantCfg *agent.GrantConfig
+	if spec.Deploy != nil {
+		grantCfg = spec.Deploy.Grant
+	}
+	if grantCfg == nil {
+		fmt.Fprintf(w, "%s: deploy.grant is not set; apply leaves its grants untouched.\n", spec.Name)
+		return nil
+	}
+
+	var gd grant.GrantDiff
+	rows, err := grantSvc.ShowGrants(ctx, target.Database, target.Schema, spec.Name)
+	switch {
+	case api.IsNotFoundError(err):
+		fmt.Fprintf(w, "%s does not exist in %s.%s yet; showing the grants apply would add after creating it.\n", spec.Name, target.Database, target.Schema)
+		gd = grant.DiffGrants(grantCfg, nil, grant.DiffOptions{})
+	case err != nil:
+		return fmt.Errorf("show grants: %w", err)
+	default:
+		gd = grant.DiffGrants(grantCfg, convertGrantRows(rows), grant.DiffOptions{RevokeExtra: true})
+	}
+
+	if !gd.HasChanges() {
+		fmt.Fprintf(w, "No grant changes for %s.\n", spec.Name)
+		return nil
+	}
+	fmt.Fprintf(w, "Grants for %s (%s.%s):\n", spec.Name, target.Database, target.Schema)
+	writeGrantActions(w, gd)
+	fmt.Fprintf(w, "\nGrants: %d to grant, %d to revoke.\n", len(gd.ToGrant), len(gd.ToRevoke))
+	return nil
+}
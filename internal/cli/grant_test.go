@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+)
+
+func grantSpec() agent.AgentSpec {
+	return agent.AgentSpec{
+		Name: "my-agent",
+		Deploy: &agent.DeployConfig{
+			Grant: &agent.GrantConfig{
+				AccountRoles: []agent.RoleGrant{{Role: "ANALYST", Privileges: []string{"USAGE"}}},
+			},
+		},
+	}
+}
+
+func TestRunGrantDiff_ReportsGrantsAndRevokes(t *testing.T) {
+	svc := &fakeAgentService{Grants: map[string][]api.ShowGrantsRow{
+		"TEST_DB.PUBLIC.my-agent": {{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "OLD_ROLE"}},
+	}}
+	var buf bytes.Buffer
+	target := Target{Database: "TEST_DB", Schema: "PUBLIC"}
+	if err := runGrantDiff(context.Background(), &buf, grantSpec(), target, svc); err != nil {
+		t.Fatalf("runGrantDiff: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Grants for my-agent (TEST_DB.PUBLIC):",
+		"would revoke USAGE from ROLE OLD_ROLE",
+		"would grant USAGE to ROLE ANALYST",
+		"Grants: 1 to grant, 1 to revoke.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunGrantDiff_NoChanges(t *testing.T) {
+	svc := &fakeAgentService{Grants: map[string][]api.ShowGrantsRow{
+		"TEST_DB.PUBLIC.my-agent": {{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "ANALYST"}},
+	}}
+	var buf bytes.Buffer
+	if err := runGrantDiff(context.Background(), &buf, grantSpec(), Target{Database: "TEST_DB", Schema: "PUBLIC"}, svc); err != nil {
+		t.Fatalf("runGrantDiff: %v", err)
+	}
+	if got := buf.String(); got != "No grant changes for my-agent.\n" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestRunGrantDiff_MissingAgentGrantsEverything(t *testing.T) {
+	svc := &fakeAgentService{ShowGrantsErr: api.APIError{StatusCode: 404}}
+	var buf bytes.Buffer
+	if err := runGrantDiff(context.Background(), &buf, grantSpec(), Target{Database: "TEST_DB", Schema: "PUBLIC"}, svc); err != nil {
+		t.Fatalf("runGrantDiff: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "does not exist in TEST_DB.PUBLIC yet") || !strings.Contains(out, "would grant USAGE to ROLE ANALYST") {
+		t.Errorf("output:\n%s", out)
+	}
+}
+
+func TestRunGrantDiff_WithoutDeployGrantSkipsShowGrants(t *testing.T) {
+	svc := &fakeAgentService{}
+	var buf bytes.Buffer
+	if err := runGrantDiff(context.Background(), &buf, agent.AgentSpec{Name: "my-agent"}, Target{Database: "TEST_DB", Schema: "PUBLIC"}, svc); err != nil {
+		t.Fatalf("runGrantDiff: %v", err)
+	}
+	if svc.ShowGrantsCallCount != 0 {
+		t.Errorf("ShowGrants called %d times, want 0", svc.ShowGrantsCallCount)
+	}
+	if !strings.Contains(buf.String(), "deploy.grant is not set") {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestFindParsedAgent(t *testing.T) {
+	specs := []agent.ParsedAgent{{Path: "a.yaml", Spec: agent.AgentSpec{Name: "a"}}, {Path: "b.yaml", Spec: agent.AgentSpec{Name: "b"}}}
+	got, err := findParsedAgent(specs, "b", ".")
+	if err != nil || got.Path != "b.yaml" {
+		t.Fatalf("findParsedAgent = %+v, %v", got, err)
+	}
+	if _, err := findParsedAgent(specs, "c", "./agents"); err == nil || !strings.Contains(err.Error(), `no spec for agent "c" found in ./agents`) {
+		t.Errorf("err = %v", err)
+	}
+}
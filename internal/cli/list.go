@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"coragent/internal/api"
+
+	"github.com/spf13/cobra"
+)
+
+func newListCmd(opts *RootOptions) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List deployed agents in the target schema",
+		Long: `List the agents deployed in the target database and schema with their
+owner, creation time and comment.`,
+		Example: `  # Table of agents in the configured schema
+  coragent agent list
+
+  # JSON for scripting
+  coragent agent list -d MY_DB -s MY_SCHEMA --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateListOutput(output); err != nil {
+				return err
+			}
+			client, cfg, err := buildClientAndCfg(opts)
+			if err != nil {
+				return err
+			}
+			target, err := ResolveTargetForExport(opts, cfg)
+			if err != nil {
+				return err
+			}
+			var agents []api.AgentListItem
+			err = runWithRetry(opts, func() error {
+				agents, err = client.ListAgents(commandContext("list"), target.Database, target.Schema)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("list agents: %w", err)
+			}
+			return writeAgentList(cmd.OutOrStdout(), agents, output)
+		},
+	}
+	cmd.Flags().StringVar(&output, "output", "table", "Output format: table or json")
+	return cmd
+}
+
+// validateListOutput accepts the list output formats.
+func validateListOutput(output string) error {
+	switch output {
+	case "table", "json":
+		return nil
+	}
+	return UserErr(fmt.Errorf("invalid --output %q (valid: table, json)", output))
+}
+
+// writeAgentList renders agents as a table or, with output "json", as a JSON
+// array (empty array when there are no agents).
+func writeAgentList(w io.Writer, agents []api.AgentListItem, output string) error {
+	if output == "json" {
+		if agents == nil {
+			agents = []api.AgentListItem{}
+		}
+		return writeJSONIndent(w, agents)
+	}
+	if len(agents) == 0 {
+		_, err := fmt.Fprintln(w, "No agents found.")
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tOWNER\tCREATED_ON\tCOMMENT")
+	for _, a := range agents {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", a.Name, a.Owner, a.CreatedOn, a.Comment)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
+	"coragent/internal/regression"
+)
+
+func runListAgainstMock(t *testing.T, args ...string) string {
+	t.Helper()
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	seed := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	if err := seed.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: "sales-agent", Comment: "Sales Q&A"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SNOWFLAKE_HOME", home)
+	t.Setenv("CORAGENT_API_BASE_URL", ms.URL())
+	t.Setenv("SNOWFLAKE_ACCOUNT", "TEST")
+	t.Setenv("SNOWFLAKE_TOKEN", "tok")
+
+	var out bytes.Buffer
+	cmd := newListCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetOut(&out)
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	return out.String()
+}
+
+func TestListCmd_JSONIncludesOwnerAndCreatedOn(t *testing.T) {
+	out := runListAgainstMock(t, "--output", "json")
+	var agents []api.AgentListItem
+	if err := json.Unmarshal([]byte(out), &agents); err != nil {
+		t.Fatalf("parse output: %v\n%s", err, out)
+	}
+	if len(agents) != 1 {
+		t.Fatalf("got %d agents, want 1", len(agents))
+	}
+	got := agents[0]
+	if got.Name != "sales-agent" || got.Comment != "Sales Q&A" || got.Owner != regression.MockOwner || got.CreatedOn != regression.MockCreatedOn {
+		t.Errorf("agent = %+v", got)
+	}
+}
+
+func TestListCmd_TextTable(t *testing.T) {
+	out := runListAgainstMock(t)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want header and one row:\n%s", len(lines), out)
+	}
+	if !strings.HasPrefix(lines[0], "NAME") || !strings.Contains(lines[0], "OWNER") || !strings.Contains(lines[0], "CREATED_ON") {
+		t.Errorf("header = %q", lines[0])
+	}
+	for _, want := range []string{"sales-agent", regression.MockOwner, regression.MockCreatedOn, "Sales Q&A"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q missing %q", lines[1], want)
+		}
+	}
+}
+
+func TestAgentListCmd_OutputTable(t *testing.T) {
+	setupRunMock(t)
+	root, _ := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"agent", "list", "--output", "table", "-d", "DB", "-s", "SCH"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("agent list: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "NAME") || !strings.Contains(out.String(), "thread-agent") {
+		t.Errorf("output = %q, want a table with thread-agent", out.String())
+	}
+}
+
+func TestListCmd_RejectsUnknownOutput(t *testing.T) {
+	cmd := newListCmd(&RootOptions{})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--output", "yaml"})
+	err := cmd.Execute()
+	if !IsUserError(err) || !strings.Contains(err.Error(), "valid: table, json") {
+		t.Fatalf("expected output user error, got %v", err)
+	}
+}
+
+func TestListCmd_OnlyUnderAgent(t *testing.T) {
+	root, _ := newRootCmd()
+	if cmd, _, err := root.Find([]string{"agent", "list"}); err != nil || cmd.Name() != "list" {
+		t.Fatalf("agent list not found: %v", err)
+	}
+	if cmd, _, _ := root.Find([]string{"list"}); cmd != nil && cmd.Name() == "list" {
+		t.Error("list is still registered at the top level")
+	}
+}
+
+func TestWriteAgentList_EmptyJSONIsArray(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeAgentList(&out, nil, "json"); err != nil {
+		t.Fatalf("writeAgentList: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("output = %q, want []", out.String())
+	}
+}
+
+func TestListCmd_CollectsEveryResultPartition(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	seed := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	want := map[string]bool{}
+	for _, name := range []string{"agent-a", "agent-b", "agent-c"} {
+		if err := seed.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: name}); err != nil {
+			t.Fatalf("CreateAgent %s: %v", name, err)
+		}
+		want[name] = true
+	}
+	ms.SetShowAgentsPageSize(2)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SNOWFLAKE_HOME", home)
+	t.Setenv("CORAGENT_API_BASE_URL", ms.URL())
+	t.Setenv("SNOWFLAKE_ACCOUNT", "TEST")
+	t.Setenv("SNOWFLAKE_TOKEN", "tok")
+
+	var out bytes.Buffer
+	cmd := newListCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--output", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	var agents []api.AgentListItem
+	if err := json.Unmarshal(out.Bytes(), &agents); err != nil {
+		t.Fatalf("parse output: %v\n%s", err, out.String())
+	}
+	if len(agents) != len(want) {
+		t.Fatalf("got %d agents across partitions, want %d: %+v", len(agents), len(want), agents)
+	}
+	for _, a := range agents {
+		if !want[a.Name] {
+			t.Errorf("unexpected agent %q", a.Name)
+		}
+	}
+}
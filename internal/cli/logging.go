@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// newLogger builds the API client logger from --log-format and --log-level.
+// Logging is off (nil logger) unless a level is given or debug is set;
+// --debug alone means level debug. Text output stays the default for
+// interactive use, while json emits one object per line for log collectors.
+func newLogger(w io.Writer, format, level string, debug bool) (*slog.Logger, error) {
+	if format != "text" && format != "json" {
+		return nil, UserErr(fmt.Errorf("invalid --log-format %q: must be text or json", format))
+	}
+	if level == "" {
+		if !debug {
+			return nil, nil
+		}
+		level = "debug"
+	}
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return nil, UserErr(fmt.Errorf("invalid --log-level %q: must be debug, info, warn or error", level))
+	}
+	handlerOpts := &slog.HandlerOptions{Level: lvl}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, handlerOpts)), nil
+	}
+	return slog.New(slog.NewTextHandler(w, handlerOpts)), nil
+}
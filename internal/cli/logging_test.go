@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger_JSON(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger(&out, "json", "info", false)
+	if err != nil {
+		t.Fatalf("newLogger: %v", err)
+	}
+	logger.Debug("hidden")
+	logger.Info("retrying request", "status", 429)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want only the info record:\n%s", len(lines), out.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("log line is not JSON: %v\n%s", err, lines[0])
+	}
+	if rec["msg"] != "retrying request" || rec["level"] != "INFO" || rec["status"] != float64(429) {
+		t.Errorf("record = %v", rec)
+	}
+}
+
+func TestNewLogger_DebugFlagDefaultsToTextDebug(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger(&out, "text", "", true)
+	if err != nil {
+		t.Fatalf("newLogger: %v", err)
+	}
+	logger.Debug("http", "method", "GET")
+	if !strings.Contains(out.String(), "level=DEBUG msg=http method=GET") {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestNewLogger_OffByDefault(t *testing.T) {
+	logger, err := newLogger(&bytes.Buffer{}, "text", "", false)
+	if err != nil || logger != nil {
+		t.Errorf("newLogger = %v, %v; want nil logger", logger, err)
+	}
+}
+
+func TestNewLogger_RejectsInvalidValues(t *testing.T) {
+	if _, err := newLogger(&bytes.Buffer{}, "yaml", "", false); err == nil || !IsUserError(err) {
+		t.Errorf("invalid format: got %v", err)
+	}
+	if _, err := newLogger(&bytes.Buffer{}, "json", "verbose", false); err == nil || !IsUserError(err) {
+		t.Errorf("invalid level: got %v", err)
+	}
+}
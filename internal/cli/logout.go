@@ -22,6 +22,7 @@ func newLogoutCmd(opts *RootOptions) *cobra.Command {
 		Use:   "logout",
 		Short: "Remove stored OAuth tokens",
 		Long: `Remove stored OAuth tokens for one or all Snowflake accounts.
+Also available as "coragent auth logout".
 
 Example:
   # Logout from specific account
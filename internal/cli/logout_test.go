@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"coragent/internal/auth"
+)
+
+func TestAuthLogout_ClearsStoredTokens(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	store, err := auth.LoadTokenStore()
+	if err != nil {
+		t.Fatalf("LoadTokenStore: %v", err)
+	}
+	store.SetTokens(auth.OAuthTokens{Account: "MYACCOUNT", AccessToken: "tok", ExpiresAt: time.Now().Add(time.Hour)})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	root := NewRootCmd()
+	root.SetArgs([]string{"auth", "logout", "--account", "myaccount"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("auth logout: %v", err)
+	}
+
+	store, err = auth.LoadTokenStore()
+	if err != nil {
+		t.Fatalf("LoadTokenStore: %v", err)
+	}
+	if store.GetTokens("MYACCOUNT") != nil {
+		t.Error("expected tokens to be removed by auth logout")
+	}
+}
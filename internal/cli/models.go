@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"coragent/internal/agent"
+
+	"github.com/spf13/cobra"
+)
+
+func newModelsCmd(opts *RootOptions) *cobra.Command {
+	var output string
+	var refresh bool
+	cmd := &cobra.Command{
+		Use:   "models",
+		Short: "List model names for models.orchestration",
+		Long: `List the model names accepted by models.orchestration in an agent spec.
+
+By default the curated list built into coragent is printed; validate and new
+warn about names that are not in it. With --refresh, the models the current
+role can use are read from SNOWFLAKE.MODELS instead. That schema lists every
+Cortex model of the account, including ones that cannot orchestrate an agent.`,
+		Example: `  # Built-in list of orchestration models
+  coragent models
+
+  # Models available in the account
+  coragent models --refresh --output json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+			models := agent.KnownOrchestrationModels
+			if refresh {
+				client, _, err := buildClientAndCfg(opts)
+				if err != nil {
+					return err
+				}
+				models, err = client.ListModels(commandContext("models"))
+				if err != nil {
+					return fmt.Errorf("list models: %w", err)
+				}
+			}
+			return writeModelList(cmd.OutOrStdout(), models, output)
+		},
+	}
+	cmd.Flags().StringVar(&output, "output", "text", "Output format: text or json")
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Query the account (SHOW MODELS IN SCHEMA SNOWFLAKE.MODELS) instead of the built-in list")
+	return cmd
+}
+
+// writeModelList renders models one per line or, with output "json", as a
+// JSON array.
+func writeModelList(w io.Writer, models []string, output string) error {
+	if output == "json" {
+		if models == nil {
+			models = []string{}
+		}
+		return writeJSONIndent(w, models)
+	}
+	if len(models) == 0 {
+		_, err := fmt.Fprintln(w, "No models found.")
+		return err
+	}
+	for _, m := range models {
+		if _, err := fmt.Fprintln(w, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+)
+
+func TestModelsCmd_PrintsBuiltInList(t *testing.T) {
+	var out bytes.Buffer
+	cmd := newModelsCmd(&RootOptions{})
+	cmd.SetOut(&out)
+	cmd.SetArgs(nil)
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("models: %v", err)
+	}
+	want := strings.Join(agent.KnownOrchestrationModels, "\n") + "\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestModelsCmd_RefreshQueriesAccount(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	ms.SetModels("CLAUDE-4-SONNET", "LLAMA3.1-8B")
+
+	var out bytes.Buffer
+	cmd := newModelsCmd(&RootOptions{})
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--refresh", "--output", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("models --refresh: %v", err)
+	}
+	var models []string
+	if err := json.Unmarshal(out.Bytes(), &models); err != nil {
+		t.Fatalf("parse output: %v\n%s", err, out.String())
+	}
+	if strings.Join(models, ",") != "claude-4-sonnet,llama3.1-8b" {
+		t.Errorf("models = %v", models)
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+)
+
+// caseSensitiveNote explains why names that differ only by case are
+// different agents.
+const caseSensitiveNote = "quoted names (and names with characters other than letters, digits, _ and $) are case-sensitive, while unquoted names are folded to upper case"
+
+// localNameCaseWarnings returns a warning for each group of local specs
+// whose names resolve to identifiers that differ only by case, such as
+// sales-agent and SALES-AGENT. Snowflake treats them as different agents.
+func localNameCaseWarnings(specs []agent.ParsedAgent) []string {
+	groups := map[string][]agent.ParsedAgent{}
+	var keys []string
+	for _, item := range specs {
+		key := strings.ToUpper(api.ResolveIdentifier(item.Spec.Name))
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], item)
+	}
+	sort.Strings(keys)
+
+	var warnings []string
+	for _, key := range keys {
+		group := groups[key]
+		resolved := map[string]bool{}
+		for _, item := range group {
+			resolved[api.ResolveIdentifier(item.Spec.Name)] = true
+		}
+		if len(resolved) < 2 {
+			continue
+		}
+		var parts []string
+		for _, item := range group {
+			parts = append(parts, fmt.Sprintf("%s (%s)", api.IdentifierSQL(api.ResolveIdentifier(item.Spec.Name)), item.Path))
+		}
+		warnings = append(warnings, fmt.Sprintf("agent names differ only by case: %s; %s, so these are different agents", strings.Join(parts, ", "), caseSensitiveNote))
+	}
+	return warnings
+}
+
+// remoteNameCaseWarning returns a warning when remote, the agents deployed
+// to target, has an agent whose name differs from the spec name only by
+// case or quoting, or "" when there is none.
+func remoteNameCaseWarning(name string, target Target, remote []api.AgentListItem) string {
+	resolved := api.ResolveIdentifier(name)
+	var others []string
+	for _, a := range remote {
+		if a.Name != resolved && strings.EqualFold(a.Name, resolved) {
+			others = append(others, api.IdentifierSQL(a.Name))
+		}
+	}
+	if len(others) == 0 {
+		return ""
+	}
+	sort.Strings(others)
+	return fmt.Sprintf("name %q refers to %s, but %s.%s also has %s, which differs only by case; %s, so these are different agents",
+		name, api.IdentifierSQL(resolved), target.Database, target.Schema, strings.Join(others, ", "), caseSensitiveNote)
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+)
+
+func TestLocalNameCaseWarnings(t *testing.T) {
+	specs := []agent.ParsedAgent{
+		{Path: "a.yaml", Spec: agent.AgentSpec{Name: "sales-agent"}},
+		{Path: "b.yaml", Spec: agent.AgentSpec{Name: "SALES-AGENT"}},
+		// Unquoted names fold to the same identifier, so they do not warn.
+		{Path: "c.yaml", Spec: agent.AgentSpec{Name: "support"}},
+		{Path: "d.yaml", Spec: agent.AgentSpec{Name: "SUPPORT"}},
+	}
+
+	got := localNameCaseWarnings(specs)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 warning, got %v", got)
+	}
+	for _, want := range []string{`"sales-agent" (a.yaml)`, `"SALES-AGENT" (b.yaml)`, "case-sensitive"} {
+		if !strings.Contains(got[0], want) {
+			t.Errorf("warning missing %q: %s", want, got[0])
+		}
+	}
+}
+
+func TestRemoteNameCaseWarning(t *testing.T) {
+	target := Target{Database: "DB", Schema: "PUBLIC"}
+	tests := []struct {
+		name   string
+		remote []api.AgentListItem
+		want   string
+	}{
+		{"quoted lower vs unquoted", []api.AgentListItem{{Name: "my_agent"}}, `name "MY_AGENT" refers to MY_AGENT, but DB.PUBLIC also has "my_agent"`},
+		{"exact match", []api.AgentListItem{{Name: "MY_AGENT"}}, ""},
+		{"unrelated", []api.AgentListItem{{Name: "OTHER"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := remoteNameCaseWarning("MY_AGENT", target, tt.remote)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("expected no warning, got %q", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("warning = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunDiff_WarnsAboutCaseOnlyRemoteName(t *testing.T) {
+	svc := &fakeAgentService{Remote: []api.AgentListItem{{Name: "MY-AGENT"}}}
+	specs := []agent.ParsedAgent{{Path: "agent.yaml", Spec: agent.AgentSpec{Name: "my-agent"}}}
+
+	var out, errOut bytes.Buffer
+	if _, err := runDiff(context.Background(), &out, &errOut, specs, testOpts(), testCfg(), svc, false); err != nil {
+		t.Fatalf("runDiff: %v", err)
+	}
+	if !strings.Contains(errOut.String(), `agent.yaml: name "my-agent" refers to "my-agent", but TEST_DB.PUBLIC also has "MY-AGENT"`) {
+		t.Errorf("expected case warning on stderr, got:\n%s", errOut.String())
+	}
+	if strings.Contains(out.String(), "Warning") {
+		t.Errorf("warnings must not be mixed into the diff output:\n%s", out.String())
+	}
+}
+
+func TestValidateCmd_WarnsAboutCaseOnlyNames(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("name: sales-agent\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.yaml"), []byte("name: Sales-Agent\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	var out, errOut bytes.Buffer
+	cmd := newValidateCmd(&RootOptions{})
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !strings.Contains(errOut.String(), "agent names differ only by case") {
+		t.Errorf("expected case warning, got:\n%s", errOut.String())
+	}
+}
@@ -1,7 +1,6 @@
 package cli
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -11,7 +10,6 @@ import (
 	"coragent/internal/agent"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var avatarOptions = []string{
@@ -217,6 +215,9 @@ func runNew() error {
 		}
 		if orchModel != "" && orchModel != "auto" {
 			spec.Models = &agent.Models{Orchestration: orchModel}
+			for _, msg := range agent.ModelWarnings(spec) {
+				fmt.Printf("\033[33mWarning: %s\033[0m\n", msg)
+			}
 		}
 
 		// --- Instructions ---
@@ -433,25 +434,13 @@ func runNew() error {
 	}
 
 	// --- Write ---
-	var doc yaml.Node
-	if err := doc.Encode(spec); err != nil {
-		return fmt.Errorf("marshal YAML: %w", err)
-	}
-	setLiteralStyleForMultiline(&doc)
-	reorderExportKeys(&doc)
-
-	var buf bytes.Buffer
-	enc := yaml.NewEncoder(&buf)
-	enc.SetIndent(2)
-	if err := enc.Encode(&doc); err != nil {
-		return fmt.Errorf("marshal YAML: %w", err)
-	}
-	if err := enc.Close(); err != nil {
-		return fmt.Errorf("flush YAML encoder: %w", err)
+	data, err := agent.MarshalYAML(spec)
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("\nWriting %s...\n", outFile)
-	if err := os.WriteFile(outFile, buf.Bytes(), 0o644); err != nil {
+	if err := os.WriteFile(outFile, data, 0o644); err != nil {
 		return fmt.Errorf("write %q: %w", outFile, err)
 	}
 	fmt.Printf("Done! Run 'coragent validate %s' to verify.\n", outFile)
@@ -1,7 +1,9 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"slices"
 	"strings"
@@ -16,6 +18,9 @@ import (
 
 func newPlanCmd(opts *RootOptions) *cobra.Command {
 	var recursive bool
+	var exitCode bool
+	var output string
+	var onlyChanged bool
 	cmd := &cobra.Command{
 		Use:   "plan [path]",
 		Short: "Show execution plan without applying changes",
@@ -26,37 +31,111 @@ func newPlanCmd(opts *RootOptions) *cobra.Command {
   coragent plan agent.yaml
 
   # Plan all agents in a directory tree
-  coragent plan -R ./agents/`,
-		Args: cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			path := "."
-			if len(args) == 1 {
-				path = args[0]
-			}
+  coragent plan -R ./agents/
 
-			specs, err := agent.LoadAgents(path, recursive, opts.Env)
-			if err != nil {
-				return UserErr(err)
-			}
+  # Script-friendly: exit 0 when clean, 2 when changes exist, 1 on error
+  coragent plan --exit-code
 
-			client, cfg, err := buildClientAndCfg(opts)
-			if err != nil {
-				return err
-			}
+  # Machine-readable change set for CI
+  coragent plan --output json
 
-			planItems, err := buildPlanItems(commandContext("plan"), specs, opts, cfg, client, client)
-			if err != nil {
-				return err
+  # JSON entries only for agents with changes
+  coragent plan --output json --only-changed`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := runPlan(opts, args, recursive, exitCode, output, onlyChanged)
+			var exitErr ExitCodeError
+			if exitCode && err != nil && !errors.As(err, &exitErr) {
+				return ExitCodeError{Code: ExitFailure, Err: err}
 			}
-
-			_, err = writePlanPreview(os.Stdout, planItems)
 			return err
 		},
 	}
 	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Recursively load agents from subdirectories")
+	cmd.Flags().BoolVar(&exitCode, "exit-code", false, "Print change counts and exit 0 when clean, 2 when changes exist, 1 on error")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format: text or json")
+	cmd.Flags().BoolVar(&onlyChanged, "only-changed", false, "Omit create_only skip notices from text output, and unchanged agents from JSON output with an 'N agents unchanged' summary")
 	return cmd
 }
 
+// validateOutputFormat checks the value of an --output flag.
+func validateOutputFormat(output string) error {
+	switch output {
+	case "text", "json":
+		return nil
+	}
+	return UserErr(fmt.Errorf("invalid --output %q (valid: text, json)", output))
+}
+
+// writePlan renders plan items in the requested output format. The text
+// format always omits unchanged agents from the body; with onlyChanged, it
+// also drops create_only skip notices and the JSON format omits unchanged
+// agents too. The summary counts every item either way.
+func writePlan(w io.Writer, items []applyItem, output string, onlyChanged bool) (planPreviewSummary, error) {
+	if output == "json" {
+		if !onlyChanged {
+			return writePlanJSON(w, items)
+		}
+		if _, err := writePlanJSON(w, changedPlanItems(items)); err != nil {
+			return planPreviewSummary{}, err
+		}
+		return summarizePlanPreview(items), nil
+	}
+	return writePlanPreview(w, items, onlyChanged)
+}
+
+func runPlan(opts *RootOptions, args []string, recursive, exitCode bool, output string, onlyChanged bool) error {
+	if err := validateOutputFormat(output); err != nil {
+		return err
+	}
+
+	path := "."
+	if len(args) == 1 {
+		path = args[0]
+	}
+
+	specs, err := agent.LoadAgents(path, recursive, opts.Env)
+	if err != nil {
+		return UserErr(err)
+	}
+
+	var planItems []applyItem
+	err = runWithRetry(opts, func() error {
+		client, cfg, err := buildClientAndCfg(opts)
+		if err != nil {
+			return err
+		}
+		planItems, err = buildPlanItems(commandContext("plan"), specs, opts, cfg, client, client)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	summary, err := writePlan(os.Stdout, planItems, output, onlyChanged)
+	if err != nil {
+		return err
+	}
+	if onlyChanged && output == "json" {
+		writeUnchangedCount(os.Stderr, summary.noChangeCount)
+	}
+	if !exitCode {
+		return nil
+	}
+
+	if output != "json" {
+		stats, err := planChangeStats(planItems)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stdout, "Changes: %d added, %d removed, %d modified\n", stats.added, stats.removed, stats.modified)
+	}
+	if summary.createCount+summary.updateCount > 0 {
+		return ExitCodeError{Code: ExitChanges}
+	}
+	return nil
+}
+
 func changeSymbol(t diff.ChangeType) string {
 	switch t {
 	case diff.Added:
@@ -39,10 +39,18 @@ func buildPlanItems(
 		if item.Spec.Deploy != nil {
 			grantCfg = item.Spec.Deploy.Grant
 		}
-		desiredGrants := grant.FromGrantConfig(grantCfg)
-
+		strategy := item.Spec.DeployStrategy()
+		if exists && strategy == agent.StrategyCreateOnly {
+			items = append(items, applyItem{
+				Parsed:  item,
+				Target:  target,
+				Exists:  true,
+				Skipped: true,
+			})
+			continue
+		}
 		if !exists {
-			grantDiff := grant.ComputeDiff(desiredGrants, grant.GrantState{})
+			grantDiff := grant.DiffGrants(grantCfg, nil, grant.DiffOptions{})
 			items = append(items, applyItem{
 				Parsed:    item,
 				Target:    target,
@@ -52,11 +60,24 @@ func buildPlanItems(
 			continue
 		}
 
-		changes, err := diff.Diff(item.Spec, remote)
+		changes, err := diff.DiffWithOptions(item.Spec, remote, diff.Options{MatchArraysByKey: true})
 		if err != nil {
 			return nil, fmt.Errorf("%s: %w", item.Path, err)
 		}
 
+		if strategy == agent.StrategyReplace && diff.HasChanges(changes) {
+			// The re-created agent has no grants, so grant everything desired.
+			items = append(items, applyItem{
+				Parsed:    item,
+				Target:    target,
+				Exists:    true,
+				Changes:   changes,
+				GrantDiff: grant.DiffGrants(grantCfg, nil, grant.DiffOptions{}),
+				Replace:   true,
+			})
+			continue
+		}
+
 		var grantDiff grant.GrantDiff
 		if grantCfg == nil {
 			// Skip grant logic when deploy.grant is not specified; leave existing grants untouched.
@@ -66,8 +87,7 @@ func buildPlanItems(
 			if err != nil {
 				return nil, fmt.Errorf("show grants: %w", err)
 			}
-			currentGrants := grant.FromShowGrantsRows(convertGrantRows(grantRows))
-			grantDiff = grant.ComputeDiff(desiredGrants, currentGrants)
+			grantDiff = grant.DiffGrants(grantCfg, convertGrantRows(grantRows), grant.DiffOptions{RevokeExtra: true})
 		}
 
 		items = append(items, applyItem{
@@ -17,8 +17,11 @@ import (
 type fakeAgentService struct {
 	Agents map[string]agent.AgentSpec // key: "db.schema.name"
 	Grants map[string][]api.ShowGrantsRow
+	Remote []api.AgentListItem // returned by ListAgents
 	// GetAgentErr, if non-nil, is returned by every GetAgent call.
 	GetAgentErr error
+	// GetAgentFailures makes the first n GetAgent calls fail with a 503.
+	GetAgentFailures int
 	// ShowGrantsErr, if non-nil, is returned by every ShowGrants call.
 	ShowGrantsErr error
 	// ShowGrantsCallCount records how many times ShowGrants was invoked.
@@ -35,6 +38,10 @@ func (f *fakeAgentService) GetAgent(_ context.Context, db, schema, name string)
 	if f.GetAgentErr != nil {
 		return agent.AgentSpec{}, false, f.GetAgentErr
 	}
+	if f.GetAgentFailures > 0 {
+		f.GetAgentFailures--
+		return agent.AgentSpec{}, false, api.APIError{StatusCode: 503}
+	}
 	spec, ok := f.Agents[f.agentKey(db, schema, name)]
 	return spec, ok, nil
 }
@@ -51,8 +58,16 @@ func (f *fakeAgentService) DeleteAgent(_ context.Context, _, _, _ string) error
 	return nil
 }
 
+func (f *fakeAgentService) DeleteAgentIfExists(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+func (f *fakeAgentService) RenameAgent(_ context.Context, _, _, _, _ string) error {
+	return nil
+}
+
 func (f *fakeAgentService) ListAgents(_ context.Context, _, _ string) ([]api.AgentListItem, error) {
-	return nil, nil
+	return f.Remote, nil
 }
 
 func (f *fakeAgentService) DescribeAgent(_ context.Context, _, _, _ string) (api.DescribeResult, error) {
@@ -78,6 +93,14 @@ func (f *fakeAgentService) ExecuteRevoke(_ context.Context, _, _, _, _, _, _ str
 	return nil
 }
 
+func (f *fakeAgentService) ExecuteGrantWithGrantOption(_ context.Context, _, _, _, _, _, _ string) error {
+	return nil
+}
+
+func (f *fakeAgentService) RevokeGrantOption(_ context.Context, _, _, _, _, _, _ string) error {
+	return nil
+}
+
 // testOpts returns minimal RootOptions for tests (database + schema required
 // since SNOWFLAKE_DATABASE / SNOWFLAKE_SCHEMA env vars are not set in CI).
 func testOpts() *RootOptions {
@@ -292,3 +315,83 @@ func TestBuildPlanItems_GrantUnspecifiedWithDeployBlock(t *testing.T) {
 		t.Errorf("expected ShowGrants not to be called, got %d calls", svc.ShowGrantsCallCount)
 	}
 }
+
+// TestBuildPlanItems_CreateOnly verifies that deploy.strategy create_only
+// creates a missing agent but skips an existing one without diffing it.
+func TestBuildPlanItems_CreateOnly(t *testing.T) {
+	remote := agent.AgentSpec{Name: "existing", Comment: "managed elsewhere"}
+	deploy := &agent.DeployConfig{
+		Strategy: agent.StrategyCreateOnly,
+		Grant:    &agent.GrantConfig{AccountRoles: []agent.RoleGrant{{Role: "ANALYST", Privileges: []string{"USAGE"}}}},
+	}
+	svc := &fakeAgentService{
+		Agents: map[string]agent.AgentSpec{"TEST_DB.PUBLIC.existing": remote},
+	}
+	specs := []agent.ParsedAgent{
+		{Path: "a.yaml", Spec: agent.AgentSpec{Name: "existing", Comment: "local", Deploy: deploy}},
+		{Path: "b.yaml", Spec: agent.AgentSpec{Name: "missing", Deploy: deploy}},
+	}
+
+	items, err := buildPlanItems(context.Background(), specs, testOpts(), testCfg(), svc, svc)
+	if err != nil {
+		t.Fatalf("buildPlanItems: %v", err)
+	}
+	if !items[0].Exists || !items[0].Skipped {
+		t.Errorf("existing: Exists=%v Skipped=%v, want both true", items[0].Exists, items[0].Skipped)
+	}
+	if diff.HasChanges(items[0].Changes) || items[0].GrantDiff.HasChanges() {
+		t.Errorf("existing: expected no changes, got %v / %+v", items[0].Changes, items[0].GrantDiff)
+	}
+	if svc.ShowGrantsCallCount != 0 {
+		t.Errorf("expected ShowGrants not to be called, got %d calls", svc.ShowGrantsCallCount)
+	}
+	if items[1].Exists || items[1].Skipped {
+		t.Errorf("missing: Exists=%v Skipped=%v, want a create", items[1].Exists, items[1].Skipped)
+	}
+	if !items[1].GrantDiff.HasChanges() {
+		t.Error("missing: expected grants for the created agent")
+	}
+}
+
+// TestBuildPlanItems_Replace verifies that deploy.strategy replace turns a
+// changed agent into a delete and create that grants everything again, and
+// leaves an unchanged agent alone.
+func TestBuildPlanItems_Replace(t *testing.T) {
+	deploy := &agent.DeployConfig{
+		Strategy: agent.StrategyReplace,
+		Grant:    &agent.GrantConfig{AccountRoles: []agent.RoleGrant{{Role: "ANALYST", Privileges: []string{"USAGE"}}}},
+	}
+	svc := &fakeAgentService{
+		Agents: map[string]agent.AgentSpec{
+			"TEST_DB.PUBLIC.changed":   {Name: "changed", Comment: "old"},
+			"TEST_DB.PUBLIC.unchanged": {Name: "unchanged", Comment: "same"},
+		},
+		Grants: map[string][]api.ShowGrantsRow{
+			"TEST_DB.PUBLIC.unchanged": {{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "ANALYST"}},
+		},
+	}
+	specs := []agent.ParsedAgent{
+		{Path: "a.yaml", Spec: agent.AgentSpec{Name: "changed", Comment: "new", Deploy: deploy}},
+		{Path: "b.yaml", Spec: agent.AgentSpec{Name: "unchanged", Comment: "same", Deploy: deploy}},
+	}
+
+	items, err := buildPlanItems(context.Background(), specs, testOpts(), testCfg(), svc, svc)
+	if err != nil {
+		t.Fatalf("buildPlanItems: %v", err)
+	}
+	if !items[0].Replace || !diff.HasChanges(items[0].Changes) {
+		t.Errorf("changed: Replace=%v changes=%v, want a replace", items[0].Replace, items[0].Changes)
+	}
+	if len(items[0].GrantDiff.ToGrant) != 1 {
+		t.Errorf("changed: expected the desired grant to be re-applied, got %+v", items[0].GrantDiff)
+	}
+	if items[1].Replace {
+		t.Error("unchanged: expected no replace without changes")
+	}
+	if items[1].GrantDiff.HasChanges() {
+		t.Errorf("unchanged: expected no grant changes, got %+v", items[1].GrantDiff)
+	}
+	if svc.ShowGrantsCallCount != 1 {
+		t.Errorf("expected ShowGrants only for the unchanged agent, got %d calls", svc.ShowGrantsCallCount)
+	}
+}
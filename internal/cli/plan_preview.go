@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 
@@ -16,10 +17,20 @@ type planPreviewSummary struct {
 	noChangeCount int
 }
 
-func writePlanPreview(w io.Writer, items []applyItem) (planPreviewSummary, error) {
+// writePlanPreview writes the text plan. Unchanged agents never get a body;
+// with onlyChanged, create_only agents that are skipped print no notice
+// either and are only counted in the trailing summary line.
+func writePlanPreview(w io.Writer, items []applyItem, onlyChanged bool) (planPreviewSummary, error) {
 	summary := summarizePlanPreview(items)
 
 	for _, item := range items {
+		if item.Skipped {
+			if onlyChanged {
+				continue
+			}
+			color.New(color.FgCyan).Fprintf(w, "%s: already exists, skipped (deploy.strategy: create_only)\n", item.Parsed.Spec.Name)
+			continue
+		}
 		if isUnchangedPlanItem(item) {
 			continue
 		}
@@ -45,6 +56,9 @@ func writePlanPreview(w io.Writer, items []applyItem) (planPreviewSummary, error
 			continue
 		}
 
+		if item.Replace {
+			color.New(color.FgYellow).Fprintln(w, "  -/+ replace (deploy.strategy: replace)")
+		}
 		for _, c := range item.Changes {
 			writePlanChange(w, c)
 		}
@@ -60,6 +74,84 @@ func writePlanPreview(w io.Writer, items []applyItem) (planPreviewSummary, error
 	return summary, nil
 }
 
+// planJSONItem is one agent entry in `--output json` plan output.
+type planJSONItem struct {
+	Agent    string          `json:"agent"`
+	Database string          `json:"database"`
+	Schema   string          `json:"schema"`
+	Action   string          `json:"action"` // "create", "update", "replace", "skip" or "none"
+	Changes  json.RawMessage `json:"changes"`
+}
+
+// writePlanJSON writes the plan as a JSON array with one entry per agent.
+// Each entry's changes are encoded with diff.MarshalChanges; agents to be
+// created list every field as an ADDED change.
+func writePlanJSON(w io.Writer, items []applyItem) (planPreviewSummary, error) {
+	out := make([]planJSONItem, 0, len(items))
+	for _, item := range items {
+		changes := item.Changes
+		action := "update"
+		switch {
+		case !item.Exists:
+			action = "create"
+			var err error
+			changes, err = diff.DiffForCreate(item.Parsed.Spec)
+			if err != nil {
+				return planPreviewSummary{}, fmt.Errorf("%s: %w", item.Parsed.Path, err)
+			}
+		case item.Replace:
+			action = "replace"
+		case item.Skipped:
+			action = "skip"
+		case isUnchangedPlanItem(item):
+			action = "none"
+		}
+		data, err := diff.MarshalChanges(changes)
+		if err != nil {
+			return planPreviewSummary{}, fmt.Errorf("%s: %w", item.Parsed.Path, err)
+		}
+		out = append(out, planJSONItem{
+			Agent:    item.Parsed.Spec.Name,
+			Database: item.Target.Database,
+			Schema:   item.Target.Schema,
+			Action:   action,
+			Changes:  data,
+		})
+	}
+	if err := writeJSONIndent(w, out); err != nil {
+		return planPreviewSummary{}, err
+	}
+	return summarizePlanPreview(items), nil
+}
+
+type planChangeCounts struct {
+	added    int
+	removed  int
+	modified int
+}
+
+// planChangeStats counts field and grant changes across all plan items.
+// Agents to be created count every field as added; grants to add and revoke
+// count as added and removed.
+func planChangeStats(items []applyItem) (planChangeCounts, error) {
+	var counts planChangeCounts
+	for _, item := range items {
+		changes := item.Changes
+		if !item.Exists {
+			var err error
+			changes, err = diff.DiffForCreate(item.Parsed.Spec)
+			if err != nil {
+				return planChangeCounts{}, fmt.Errorf("%s: %w", item.Parsed.Path, err)
+			}
+		}
+		added, removed, modified := diff.Stats(changes)
+		counts.added += added + len(item.GrantDiff.ToGrant)
+		counts.removed += removed + len(item.GrantDiff.ToRevoke)
+		counts.modified += modified
+	}
+	return counts, nil
+}
+
 func summarizePlanPreview(items []applyItem) planPreviewSummary {
 	var summary planPreviewSummary
 
@@ -77,6 +169,105 @@ func summarizePlanPreview(items []applyItem) planPreviewSummary {
 	return summary
 }
 
+// changedPlanItems returns the items that create or update an agent.
+func changedPlanItems(items []applyItem) []applyItem {
+	var out []applyItem
+	for _, item := range items {
+		if !isUnchangedPlanItem(item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// writeApplyProgress prints a line per agent about to be applied. With
+// onlyChanged, unchanged agents produce no line and are only counted.
+func writeApplyProgress(w io.Writer, items []applyItem, onlyChanged bool) (unchanged int) {
+	for _, item := range items {
+		switch {
+		case !item.Exists:
+			color.New(color.FgGreen).Fprintf(w, "Creating %s...\n", item.Parsed.Spec.Name)
+		case item.Replace:
+			color.New(color.FgYellow).Fprintf(w, "Replacing %s...\n", item.Parsed.Spec.Name)
+		case item.Skipped:
+			unchanged++
+			if !onlyChanged {
+				color.New(color.FgCyan).Fprintf(w, "Skipping %s (deploy.strategy: create_only)\n", item.Parsed.Spec.Name)
+			}
+		case !isUnchangedPlanItem(item):
+			color.New(color.FgYellow).Fprintf(w, "Updating %s...\n", item.Parsed.Spec.Name)
+		default:
+			unchanged++
+			if !onlyChanged {
+				color.New(color.FgCyan).Fprintf(w, "No changes for %s\n", item.Parsed.Spec.Name)
+			}
+		}
+	}
+	return unchanged
+}
+
+// writeDryRunActions prints, for apply --dry-run, what executeApply would do
+// for each agent: create or update it, then each GRANT and REVOKE. It is the
+// dry-run counterpart of writeApplyProgress and returns the unchanged count.
+func writeDryRunActions(w io.Writer, items []applyItem, onlyChanged bool) (unchanged int) {
+	for _, item := range items {
+		name := item.Parsed.Spec.Name
+		switch {
+		case !item.Exists:
+			color.New(color.FgGreen).Fprintf(w, "Would create %s\n", name)
+		case item.Replace:
+			color.New(color.FgYellow).Fprintf(w, "Would replace %s (delete and create)\n", name)
+		case item.Skipped:
+			unchanged++
+			if !onlyChanged {
+				color.New(color.FgCyan).Fprintf(w, "Would skip %s (deploy.strategy: create_only)\n", name)
+			}
+			continue
+		case diff.HasChanges(item.Changes):
+			color.New(color.FgYellow).Fprintf(w, "Would update %s\n", name)
+		case item.GrantDiff.HasChanges():
+			color.New(color.FgYellow).Fprintf(w, "Would update grants of %s\n", name)
+		default:
+			unchanged++
+			if !onlyChanged {
+				color.New(color.FgCyan).Fprintf(w, "No changes for %s\n", name)
+			}
+			continue
+		}
+		writeGrantActions(w, item.GrantDiff)
+	}
+	return unchanged
+}
+
+// writeGrantActions prints one "would grant"/"would revoke" line per entry
+// of gd, in the order applyGrantDiff executes them.
+func writeGrantActions(w io.Writer, gd grant.GrantDiff) {
+	for _, e := range gd.ToRevoke {
+		if e.GrantOptionOnly {
+			fmt.Fprintf(w, "  would revoke GRANT OPTION FOR %s from %s %s\n", e.Privilege, e.RoleType, e.RoleName)
+			continue
+		}
+		fmt.Fprintf(w, "  would revoke %s from %s %s\n", e.Privilege, e.RoleType, e.RoleName)
+	}
+	for _, e := range gd.ToGrant {
+		suffix := ""
+		if e.GrantOption {
+			suffix = " WITH GRANT OPTION"
+		}
+		fmt.Fprintf(w, "  would grant %s to %s %s%s\n", e.Privilege, e.RoleType, e.RoleName, suffix)
+	}
+}
+
+// writeUnchangedCount prints the trailing "N agents unchanged" line used by
+// --only-changed.
+func writeUnchangedCount(w io.Writer, n int) {
+	noun := "agents"
+	if n == 1 {
+		noun = "agent"
+	}
+	fmt.Fprintf(w, "%d %s unchanged\n", n, noun)
+}
+
 func isUnchangedPlanItem(item applyItem) bool {
 	return item.Exists && !diff.HasChanges(item.Changes) && !item.GrantDiff.HasChanges()
 }
@@ -112,18 +303,27 @@ func writeGrantPlan(w io.Writer, diff grant.GrantDiff) {
 	fmt.Fprintf(w, "  grants:\n")
 
 	for _, e := range diff.ToRevoke {
+		privilege := e.Privilege
+		if e.GrantOptionOnly {
+			privilege = "GRANT OPTION FOR " + privilege
+		}
 		fmt.Fprintf(w, "    %s %s TO %s %s\n",
 			color.New(color.FgRed).Sprint("-"),
-			e.Privilege,
+			privilege,
 			e.RoleType,
 			e.RoleName)
 	}
 
 	for _, e := range diff.ToGrant {
-		fmt.Fprintf(w, "    %s %s TO %s %s\n",
+		suffix := ""
+		if e.GrantOption {
+			suffix = " WITH GRANT OPTION"
+		}
+		fmt.Fprintf(w, "    %s %s TO %s %s%s\n",
 			color.New(color.FgGreen).Sprint("+"),
 			e.Privilege,
 			e.RoleType,
-			e.RoleName)
+			e.RoleName,
+			suffix)
 	}
 }
@@ -2,6 +2,9 @@ package cli
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strings"
 	"testing"
 
@@ -248,7 +251,7 @@ func TestWritePlanPreview_HidesUnchangedItems(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	summary, err := writePlanPreview(&buf, items)
+	summary, err := writePlanPreview(&buf, items, false)
 	if err != nil {
 		t.Fatalf("writePlanPreview: %v", err)
 	}
@@ -283,6 +286,76 @@ func TestWritePlanPreview_HidesUnchangedItems(t *testing.T) {
 	}
 }
 
+func TestWritePlanJSON(t *testing.T) {
+	items := []applyItem{
+		{
+			Parsed: agent.ParsedAgent{Path: "unchanged.yaml", Spec: agent.AgentSpec{Name: "UNCHANGED"}},
+			Target: Target{Database: "TEST_DB", Schema: "PUBLIC"},
+			Exists: true,
+		},
+		{
+			Parsed: agent.ParsedAgent{Path: "updated.yaml", Spec: agent.AgentSpec{Name: "UPDATED"}},
+			Target: Target{Database: "TEST_DB", Schema: "PUBLIC"},
+			Exists: true,
+			Changes: []diff.Change{
+				{Path: "instructions.response", Type: diff.Modified, Before: "old", After: "new"},
+			},
+		},
+		{
+			Parsed: agent.ParsedAgent{Path: "created.yaml", Spec: agent.AgentSpec{Name: "CREATED", Comment: "brand new"}},
+			Target: Target{Database: "TEST_DB", Schema: "PUBLIC"},
+		},
+	}
+
+	var buf bytes.Buffer
+	summary, err := writePlanJSON(&buf, items)
+	if err != nil {
+		t.Fatalf("writePlanJSON: %v", err)
+	}
+	if summary.createCount != 1 || summary.updateCount != 1 || summary.noChangeCount != 1 {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+
+	var got []struct {
+		Agent   string `json:"agent"`
+		Action  string `json:"action"`
+		Changes []struct {
+			Path   string `json:"path"`
+			Type   string `json:"type"`
+			Before any    `json:"before"`
+			After  any    `json:"after"`
+		} `json:"changes"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(got))
+	}
+	if got[0].Action != "none" || len(got[0].Changes) != 0 {
+		t.Errorf("unchanged entry = %+v", got[0])
+	}
+	if got[1].Action != "update" || len(got[1].Changes) != 1 ||
+		got[1].Changes[0].Path != "instructions.response" || got[1].Changes[0].Type != "MODIFIED" ||
+		got[1].Changes[0].Before != "old" || got[1].Changes[0].After != "new" {
+		t.Errorf("updated entry = %+v", got[1])
+	}
+	if got[2].Action != "create" || len(got[2].Changes) != 2 || got[2].Changes[0].Path != "name" {
+		t.Errorf("created entry = %+v", got[2])
+	}
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+	for _, v := range []string{"text", "json"} {
+		if err := validateOutputFormat(v); err != nil {
+			t.Errorf("validateOutputFormat(%q) = %v", v, err)
+		}
+	}
+	if err := validateOutputFormat("yaml"); err == nil || !IsUserError(err) {
+		t.Errorf("validateOutputFormat(yaml) = %v, want user error", err)
+	}
+}
+
 func TestWritePlanPreview_ShowsMultilineStringDiff(t *testing.T) {
 	items := []applyItem{
 		{
@@ -324,7 +397,7 @@ func TestWritePlanPreview_ShowsMultilineStringDiff(t *testing.T) {
 	}
 
 	var buf bytes.Buffer
-	_, err := writePlanPreview(&buf, items)
+	_, err := writePlanPreview(&buf, items, false)
 	if err != nil {
 		t.Fatalf("writePlanPreview: %v", err)
 	}
@@ -344,3 +417,144 @@ func TestWritePlanPreview_ShowsMultilineStringDiff(t *testing.T) {
 		}
 	}
 }
+
+func TestPlanChangeStats(t *testing.T) {
+	items := []applyItem{
+		{
+			Parsed: agent.ParsedAgent{Path: "updated.yaml", Spec: agent.AgentSpec{Name: "UPDATED"}},
+			Exists: true,
+			Changes: []diff.Change{
+				{Path: "comment", Type: diff.Modified, Before: "old", After: "new"},
+				{Path: "instructions.system", Type: diff.Removed, Before: "x"},
+			},
+			GrantDiff: grant.GrantDiff{
+				ToGrant:  []grant.GrantEntry{{Privilege: "USAGE", RoleType: "ROLE", RoleName: "ANALYST"}},
+				ToRevoke: []grant.GrantEntry{{Privilege: "USAGE", RoleType: "ROLE", RoleName: "OLD"}},
+			},
+		},
+		{
+			Parsed: agent.ParsedAgent{Path: "created.yaml", Spec: agent.AgentSpec{Name: "CREATED", Comment: "new"}},
+			Exists: false,
+		},
+		{
+			Parsed: agent.ParsedAgent{Path: "same.yaml", Spec: agent.AgentSpec{Name: "SAME"}},
+			Exists: true,
+		},
+	}
+
+	stats, err := planChangeStats(items)
+	if err != nil {
+		t.Fatalf("planChangeStats: %v", err)
+	}
+	// CREATED adds name and comment; UPDATED adds one grant, removes a field
+	// and a grant, and modifies one field.
+	if stats.added != 3 || stats.removed != 2 || stats.modified != 1 {
+		t.Errorf("stats = %+v, want added=3 removed=2 modified=1", stats)
+	}
+}
+
+func TestExitCodeError(t *testing.T) {
+	silent := ExitCodeError{Code: ExitChanges}
+	if silent.Unwrap() != nil {
+		t.Error("expected no wrapped error for changes exit")
+	}
+	cause := errors.New("boom")
+	wrapped := fmt.Errorf("plan: %w", ExitCodeError{Code: ExitFailure, Err: cause})
+	var exitErr ExitCodeError
+	if !errors.As(wrapped, &exitErr) || exitErr.Code != ExitFailure {
+		t.Fatalf("expected ExitCodeError with code %d, got %v", ExitFailure, wrapped)
+	}
+	if !errors.Is(wrapped, cause) {
+		t.Error("expected ExitCodeError to unwrap to its cause")
+	}
+}
+
+func TestOnlyChanged_SkipsUnchangedAgentsButCountsThem(t *testing.T) {
+	items := []applyItem{
+		{
+			Parsed: agent.ParsedAgent{Path: "a.yaml", Spec: agent.AgentSpec{Name: "QUIET_A"}},
+			Target: Target{Database: "TEST_DB", Schema: "PUBLIC"},
+			Exists: true,
+		},
+		{
+			Parsed: agent.ParsedAgent{Path: "updated.yaml", Spec: agent.AgentSpec{Name: "UPDATED"}},
+			Target: Target{Database: "TEST_DB", Schema: "PUBLIC"},
+			Exists: true,
+			Changes: []diff.Change{
+				{Path: "comment", Type: diff.Modified, Before: "old", After: "new"},
+			},
+		},
+		{
+			Parsed: agent.ParsedAgent{Path: "b.yaml", Spec: agent.AgentSpec{Name: "QUIET_B"}},
+			Target: Target{Database: "TEST_DB", Schema: "PUBLIC"},
+			Exists: true,
+		},
+	}
+
+	var progress bytes.Buffer
+	unchanged := writeApplyProgress(&progress, items, true)
+	writeUnchangedCount(&progress, unchanged)
+	out := progress.String()
+	if strings.Contains(out, "QUIET_A") || strings.Contains(out, "QUIET_B") {
+		t.Errorf("unchanged agents should produce no output, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Updating UPDATED...") {
+		t.Errorf("missing line for changed agent, got:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "2 agents unchanged\n") {
+		t.Errorf("missing trailing unchanged count, got:\n%s", out)
+	}
+
+	var buf bytes.Buffer
+	summary, err := writePlan(&buf, items, "json", true)
+	if err != nil {
+		t.Fatalf("writePlan: %v", err)
+	}
+	if summary.noChangeCount != 2 || summary.updateCount != 1 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+	var got []struct {
+		Agent string `json:"agent"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, buf.String())
+	}
+	if len(got) != 1 || got[0].Agent != "UPDATED" {
+		t.Errorf("JSON entries = %+v, want only UPDATED", got)
+	}
+}
+
+func TestOnlyChanged_TextPlanOmitsSkipNotices(t *testing.T) {
+	items := []applyItem{
+		{
+			Parsed:  agent.ParsedAgent{Path: "kept.yaml", Spec: agent.AgentSpec{Name: "KEPT"}},
+			Target:  Target{Database: "TEST_DB", Schema: "PUBLIC"},
+			Exists:  true,
+			Skipped: true,
+		},
+		{
+			Parsed: agent.ParsedAgent{Path: "new.yaml", Spec: agent.AgentSpec{Name: "NEW"}},
+			Target: Target{Database: "TEST_DB", Schema: "PUBLIC"},
+		},
+	}
+
+	var full bytes.Buffer
+	if _, err := writePlan(&full, items, "text", false); err != nil {
+		t.Fatalf("writePlan: %v", err)
+	}
+	if !strings.Contains(full.String(), "KEPT: already exists, skipped") {
+		t.Errorf("skip notice missing without onlyChanged:\n%s", full.String())
+	}
+
+	var buf bytes.Buffer
+	if _, err := writePlan(&buf, items, "text", true); err != nil {
+		t.Fatalf("writePlan: %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "KEPT") {
+		t.Errorf("skip notice should be omitted with onlyChanged:\n%s", out)
+	}
+	if !strings.Contains(out, "NEW:") || !strings.HasSuffix(out, "Plan: 1 to create, 0 to update, 1 unchanged\n") {
+		t.Errorf("unexpected plan output:\n%s", out)
+	}
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"coragent/internal/api"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func newAgentCmd(opts *RootOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Manage deployed agents by name",
+		Long: `Commands that operate on deployed agents by name rather than on
+YAML specs.`,
+	}
+
+	cmd.AddCommand(
+		newListCmd(opts),
+		newRenameCmd(opts),
+	)
+
+	return cmd
+}
+
+func newRenameCmd(opts *RootOptions) *cobra.Command {
+	var autoApprove bool
+	cmd := &cobra.Command{
+		Use:   "rename <old-name> <new-name>",
+		Short: "Rename an existing agent in place",
+		Long: `Rename an existing agent with ALTER AGENT ... RENAME TO.
+
+Unlike deleting and re-creating the agent, renaming keeps its grants and
+history. When the account does not support ALTER AGENT ... RENAME, the agent
+is copied instead: the old agent is described, created under the new name and
+then deleted. The copy does not carry over grants or threads.
+
+Remember to update the name field in the agent's YAML spec.`,
+		Example: `  # Rename an agent in the configured database/schema
+  coragent agent rename MY_AGENT MY_AGENT_V2
+
+  # Rename an agent in a specific database/schema, skip confirmation
+  coragent agent rename MY_AGENT MY_AGENT_V2 -d MY_DB -s MY_SCHEMA -y`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldName, newName := args[0], args[1]
+			if oldName == newName {
+				return UserErr(fmt.Errorf("new name must differ from the current name %q", oldName))
+			}
+
+			client, cfg, err := buildClientAndCfg(opts)
+			if err != nil {
+				return err
+			}
+			target, err := ResolveTargetForExport(opts, cfg)
+			if err != nil {
+				return err
+			}
+
+			if !autoApprove {
+				prompt := fmt.Sprintf("Rename %s.%s.%s to %s?", target.Database, target.Schema, oldName, newName)
+				if !confirm(prompt, cmd.InOrStdin()) {
+					fmt.Fprintln(cmd.OutOrStdout(), "Rename aborted.")
+					return nil
+				}
+			}
+			return renameAgent(commandContext("rename"), cmd.OutOrStdout(), client, target, oldName, newName)
+		},
+	}
+	cmd.Flags().BoolVarP(&autoApprove, "yes", "y", false, "Skip confirmation prompt")
+	return cmd
+}
+
+// renameAgent renames oldName to newName with ALTER AGENT ... RENAME TO and
+// falls back to copyAgent when the account does not support it.
+func renameAgent(ctx context.Context, w io.Writer, agentSvc api.AgentService, target Target, oldName, newName string) error {
+	fmt.Fprintf(w, "Renaming %s to %s... ", oldName, newName)
+	err := agentSvc.RenameAgent(ctx, target.Database, target.Schema, oldName, newName)
+	if errors.Is(err, api.ErrRenameUnsupported) {
+		fmt.Fprintln(w, "not supported")
+		color.New(color.FgYellow).Fprintf(w, "Warning: ALTER AGENT ... RENAME is not supported; copying %s to %s instead. Grants and threads will not be migrated.\n", oldName, newName)
+		return copyAgent(ctx, w, agentSvc, target, oldName, newName)
+	}
+	if err != nil {
+		fmt.Fprintln(w, "failed")
+		return renameError(err, target, oldName, newName)
+	}
+	color.New(color.FgGreen).Fprintln(w, "done")
+	return nil
+}
+
+// copyAgent re-creates oldName's spec as newName and deletes oldName. The
+// old agent is only deleted once the new one has been created.
+func copyAgent(ctx context.Context, w io.Writer, agentSvc api.AgentService, target Target, oldName, newName string) error {
+	spec, exists, err := agentSvc.GetAgent(ctx, target.Database, target.Schema, oldName)
+	if err != nil {
+		return fmt.Errorf("describe %s: %w", oldName, err)
+	}
+	if !exists {
+		return renameError(api.ErrAgentNotFound, target, oldName, newName)
+	}
+	if _, exists, err := agentSvc.GetAgent(ctx, target.Database, target.Schema, newName); err != nil {
+		return fmt.Errorf("describe %s: %w", newName, err)
+	} else if exists {
+		return renameError(api.ErrAgentAlreadyExists, target, oldName, newName)
+	}
+
+	spec.Name = newName
+	fmt.Fprintf(w, "Creating %s... ", newName)
+	if err := agentSvc.CreateAgent(ctx, target.Database, target.Schema, spec); err != nil {
+		fmt.Fprintln(w, "failed")
+		return renameError(err, target, oldName, newName)
+	}
+	color.New(color.FgGreen).Fprintln(w, "done")
+
+	fmt.Fprintf(w, "Deleting %s... ", oldName)
+	if err := agentSvc.DeleteAgent(ctx, target.Database, target.Schema, oldName); err != nil {
+		fmt.Fprintln(w, "failed")
+		return fmt.Errorf("created %s but could not delete %s; delete it manually: %w", newName, oldName, err)
+	}
+	color.New(color.FgGreen).Fprintln(w, "done")
+	return nil
+}
+
+// renameError maps the sentinel errors of a rename to user errors.
+func renameError(err error, target Target, oldName, newName string) error {
+	switch {
+	case errors.Is(err, api.ErrAgentNotFound):
+		return UserErr(fmt.Errorf("agent %q not found in %s.%s", oldName, target.Database, target.Schema))
+	case errors.Is(err, api.ErrAgentAlreadyExists):
+		return UserErr(fmt.Errorf("agent %q already exists in %s.%s", newName, target.Database, target.Schema))
+	}
+	return fmt.Errorf("snowflake API error: %w", err)
+}
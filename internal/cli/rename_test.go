@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+)
+
+func TestRenameAgent_ServerSide(t *testing.T) {
+	svc := &applyFakeService{}
+	var out bytes.Buffer
+	target := Target{Database: "DB", Schema: "PUBLIC"}
+
+	if err := renameAgent(context.Background(), &out, svc, target, "OLD", "NEW"); err != nil {
+		t.Fatalf("renameAgent: %v", err)
+	}
+	if strings.Join(svc.RenameCalls, ",") != "OLD->NEW" {
+		t.Errorf("rename calls = %v", svc.RenameCalls)
+	}
+	if len(svc.CreateCalls) != 0 || len(svc.ReplaceDeletes) != 0 {
+		t.Errorf("unexpected fallback: created %v, deleted %v", svc.CreateCalls, svc.ReplaceDeletes)
+	}
+}
+
+func TestRenameAgent_FallsBackToCopy(t *testing.T) {
+	svc := &applyFakeService{
+		Agents:    map[string]agent.AgentSpec{"DB.PUBLIC.OLD": {Name: "OLD", Comment: "keep me"}},
+		RenameErr: fmt.Errorf("%w: unsupported feature", api.ErrRenameUnsupported),
+	}
+	var out bytes.Buffer
+	target := Target{Database: "DB", Schema: "PUBLIC"}
+
+	if err := renameAgent(context.Background(), &out, svc, target, "OLD", "NEW"); err != nil {
+		t.Fatalf("renameAgent: %v", err)
+	}
+	if strings.Join(svc.CreateCalls, ",") != "NEW" {
+		t.Errorf("created = %v, want [NEW]", svc.CreateCalls)
+	}
+	if strings.Join(svc.ReplaceDeletes, ",") != "OLD" {
+		t.Errorf("deleted = %v, want [OLD]", svc.ReplaceDeletes)
+	}
+	if !strings.Contains(out.String(), "Grants and threads will not be migrated") {
+		t.Errorf("expected migration warning, got:\n%s", out.String())
+	}
+}
+
+func TestRenameAgent_FallbackTargetExists(t *testing.T) {
+	svc := &applyFakeService{
+		Agents: map[string]agent.AgentSpec{
+			"DB.PUBLIC.OLD": {Name: "OLD"},
+			"DB.PUBLIC.NEW": {Name: "NEW"},
+		},
+		RenameErr: api.ErrRenameUnsupported,
+	}
+	var out bytes.Buffer
+	target := Target{Database: "DB", Schema: "PUBLIC"}
+
+	err := renameAgent(context.Background(), &out, svc, target, "OLD", "NEW")
+	if !IsUserError(err) || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected already-exists user error, got %v", err)
+	}
+	if len(svc.CreateCalls) != 0 || len(svc.ReplaceDeletes) != 0 {
+		t.Errorf("expected no changes, created %v, deleted %v", svc.CreateCalls, svc.ReplaceDeletes)
+	}
+}
+
+func TestRenameAgent_FallbackCreateFails(t *testing.T) {
+	svc := &applyFakeService{
+		Agents:    map[string]agent.AgentSpec{"DB.PUBLIC.OLD": {Name: "OLD"}},
+		RenameErr: api.ErrRenameUnsupported,
+		CreateErr: errors.New("boom"),
+	}
+	var out bytes.Buffer
+	target := Target{Database: "DB", Schema: "PUBLIC"}
+
+	if err := renameAgent(context.Background(), &out, svc, target, "OLD", "NEW"); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(svc.ReplaceDeletes) != 0 {
+		t.Errorf("old agent must survive a failed create, deleted %v", svc.ReplaceDeletes)
+	}
+}
+
+func TestRenameAgent_NotFound(t *testing.T) {
+	svc := &applyFakeService{RenameErr: fmt.Errorf("%w: OLD", api.ErrAgentNotFound)}
+	var out bytes.Buffer
+	target := Target{Database: "DB", Schema: "PUBLIC"}
+
+	err := renameAgent(context.Background(), &out, svc, target, "OLD", "NEW")
+	if !IsUserError(err) || !strings.Contains(err.Error(), `"OLD" not found`) {
+		t.Fatalf("expected not-found user error, got %v", err)
+	}
+}
+
+func TestRenameCmd_ReadOnlyBlocksRename(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	before := ms.Requests()
+
+	root, _ := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"agent", "rename", "thread-agent", "renamed-agent", "-d", "DB", "-s", "SCH", "-y", "--read-only"})
+	err := root.Execute()
+	if !api.IsReadOnlyError(err) || !IsUserError(err) {
+		t.Fatalf("expected read-only user error, got %v", err)
+	}
+	if n := ms.Requests() - before; n != 0 {
+		t.Errorf("read-only rename sent %d request(s), want 0", n)
+	}
+}
+
+func TestRenameCmd_OnlyUnderAgent(t *testing.T) {
+	root, _ := newRootCmd()
+	if cmd, _, err := root.Find([]string{"agent", "rename"}); err != nil || cmd.Name() != "rename" {
+		t.Fatalf("agent rename not found: %v", err)
+	}
+	if cmd, _, _ := root.Find([]string{"rename"}); cmd != nil && cmd.Name() == "rename" {
+		t.Error("rename is still registered at the top level")
+	}
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"coragent/internal/api"
+)
+
+// retrySleep is overridden in tests to avoid real delays.
+var retrySleep = time.Sleep
+
+// runWithRetry runs fn and, when --retry is set, re-runs it after
+// --retry-delay as long as it fails with a retryable error. It is meant for
+// read-only command cores where repeating the work has no side effects.
+func runWithRetry(opts *RootOptions, fn func() error) error {
+	attempts := 1
+	if opts.Retry > 0 {
+		attempts += opts.Retry
+	}
+	var err error
+	for i := 1; i <= attempts; i++ {
+		err = fn()
+		if err == nil || i == attempts || !isRetryableCommandError(err) {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Attempt %d/%d failed: %v; retrying in %s\n", i, attempts, err, opts.RetryDelay)
+		retrySleep(opts.RetryDelay)
+	}
+	return err
+}
+
+// isRetryableCommandError reports whether err looks transient: network
+// failures, throttling, server errors, or an expired session.
+func isRetryableCommandError(err error) bool {
+	if err == nil || IsUserError(err) {
+		return false
+	}
+	var apiErr api.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == 429 || apiErr.StatusCode == 401
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"coragent/internal/api"
+)
+
+func stubRetrySleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var slept []time.Duration
+	orig := retrySleep
+	retrySleep = func(d time.Duration) { slept = append(slept, d) }
+	t.Cleanup(func() { retrySleep = orig })
+	return &slept
+}
+
+func TestRunWithRetry_SucceedsAfterTransientFailures(t *testing.T) {
+	slept := stubRetrySleep(t)
+	calls := 0
+	err := runWithRetry(&RootOptions{Retry: 3, RetryDelay: time.Second}, func() error {
+		calls++
+		if calls <= 2 {
+			return fmt.Errorf("describe agent: %w", api.APIError{StatusCode: 503, Body: "unavailable"})
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runWithRetry() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if len(*slept) != 2 || (*slept)[0] != time.Second {
+		t.Errorf("slept = %v, want two 1s delays", *slept)
+	}
+}
+
+func TestRunWithRetry_OffByDefault(t *testing.T) {
+	stubRetrySleep(t)
+	calls := 0
+	err := runWithRetry(&RootOptions{}, func() error {
+		calls++
+		return api.APIError{StatusCode: 503}
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRunWithRetry_GivesUpAfterLimit(t *testing.T) {
+	stubRetrySleep(t)
+	calls := 0
+	err := runWithRetry(&RootOptions{Retry: 2}, func() error {
+		calls++
+		return api.APIError{StatusCode: 502}
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRunWithRetry_NonRetryableStopsImmediately(t *testing.T) {
+	stubRetrySleep(t)
+	calls := 0
+	err := runWithRetry(&RootOptions{Retry: 5}, func() error {
+		calls++
+		return UserErr(errors.New("agent not found"))
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestIsRetryableCommandError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"server error", api.APIError{StatusCode: 500}, true},
+		{"throttled", api.APIError{StatusCode: 429}, true},
+		{"auth expired", api.APIError{StatusCode: 401}, true},
+		{"bad request", api.APIError{StatusCode: 400}, false},
+		{"wrapped server error", fmt.Errorf("list: %w", api.APIError{StatusCode: 503}), true},
+		{"user error", UserErr(api.APIError{StatusCode: 503}), false},
+		{"plain error", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryableCommandError(tt.err); got != tt.want {
+				t.Errorf("isRetryableCommandError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
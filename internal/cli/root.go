@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"runtime/debug"
+	"time"
+
+	"coragent/internal/agent"
+	"coragent/internal/config"
 
 	"github.com/spf13/cobra"
 )
@@ -15,13 +21,29 @@ type RootOptions struct {
 	Role             string
 	Connection       string
 	Env              string
+	EnvFile          string
 	QuoteIdentifiers bool
 	Debug            bool
+	Retry            int
+	RetryDelay       time.Duration
+	NoInput          bool
+	Trace            string
+	LogFormat        string
+	LogLevel         string
+	ReadOnly         bool
+
+	trace  *traceRecorder // requests recorded for --trace
+	logger *slog.Logger   // API client logger from --log-format/--log-level; nil keeps the default
 }
 
 var DebugEnabled bool
 
 func NewRootCmd() *cobra.Command {
+	cmd, _ := newRootCmd()
+	return cmd
+}
+
+func newRootCmd() (*cobra.Command, *RootOptions) {
 	opts := &RootOptions{}
 	cmd := &cobra.Command{
 		Use:           "coragent",
@@ -29,8 +51,15 @@ func NewRootCmd() *cobra.Command {
 		Version:       Version,
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			DebugEnabled = opts.Debug
+			logger, err := newLogger(os.Stderr, opts.LogFormat, opts.LogLevel, opts.Debug)
+			if err != nil {
+				return err
+			}
+			opts.logger = logger
+			applyValidateSettings(config.LoadCoragentConfig().Validate)
+			return applyEnvFile(opts.EnvFile)
 		},
 	}
 
@@ -40,31 +69,58 @@ func NewRootCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&opts.Role, "role", "r", "", "Snowflake role to use (e.g., CORTEX_USER)")
 	cmd.PersistentFlags().StringVarP(&opts.Connection, "connection", "c", "", "Snowflake CLI connection name (from ~/.snowflake/config.toml)")
 	cmd.PersistentFlags().StringVarP(&opts.Env, "env", "e", "", "Variable environment name (selects vars group in spec file)")
+	cmd.PersistentFlags().StringVar(&opts.EnvFile, "env-file", "", "Load KEY=VALUE pairs from a dotenv file for ${ env.X } substitution (existing environment variables win)")
 	cmd.PersistentFlags().BoolVar(&opts.QuoteIdentifiers, "quote-identifiers", false, "Double-quote database/schema names for case-sensitive identifiers")
 	cmd.PersistentFlags().BoolVar(&opts.Debug, "debug", false, "Enable debug logging with trace output")
+	cmd.PersistentFlags().IntVar(&opts.Retry, "retry", 0, "Retry read-only commands (plan, diff, describe, agent list, export, validate --remote) this many times on transient errors")
+	cmd.PersistentFlags().DurationVar(&opts.RetryDelay, "retry-delay", 5*time.Second, "Delay between command retries")
+	cmd.PersistentFlags().BoolVar(&opts.NoInput, "no-input", false, "Disable interactive prompts; commands that need a selection fail instead")
+	cmd.PersistentFlags().StringVar(&opts.Trace, "trace", "", "Record every HTTP request of the command to this JSON file")
+	cmd.PersistentFlags().StringVar(&opts.LogFormat, "log-format", "text", "Log output format on stderr: text or json")
+	cmd.PersistentFlags().StringVar(&opts.LogLevel, "log-level", "", "Log level: debug, info, warn or error (logging is off unless set or --debug is given)")
+	cmd.PersistentFlags().BoolVar(&opts.ReadOnly, "read-only", false, "Block agent create/update/delete/rename and GRANT/REVOKE; reads, runs and feedback still work")
 
 	cmd.AddCommand(
 		newPlanCmd(opts),
 		newApplyCmd(opts),
+		newDiffCmd(opts),
+		newGrantCmd(opts),
 		newDeleteCmd(opts),
+		newAgentCmd(opts),
 		newValidateCmd(opts),
 		newExportCmd(opts),
+		newDescribeCmd(opts),
+		newModelsCmd(opts),
 		newNewCmd(opts),
 		newRunCmd(opts),
+		newTestToolCmd(opts),
 		newThreadsCmd(opts),
 		newEvalCmd(opts),
 		newFeedbackCmd(opts),
 		newLoginCmd(opts),
 		newLogoutCmd(opts),
 		newAuthCmd(opts),
+		newConfigCmd(opts),
 	)
 
-	return cmd
+	return cmd, opts
 }
 
 func Execute() {
-	root := NewRootCmd()
-	if err := root.Execute(); err != nil {
+	root, opts := newRootCmd()
+	err := root.Execute()
+	// The trace is most useful when the command failed, so write it first.
+	if traceErr := writeTrace(opts); traceErr != nil {
+		fmt.Fprintln(os.Stderr, "Warning:", traceErr)
+	}
+	if err != nil {
+		var exitErr ExitCodeError
+		if errors.As(err, &exitErr) {
+			if exitErr.Err != nil {
+				fmt.Fprintln(os.Stderr, "Error:", exitErr.Err)
+			}
+			os.Exit(exitErr.Code)
+		}
 		if DebugEnabled {
 			fmt.Fprintln(os.Stderr, "DEBUG STACK TRACE:")
 			fmt.Fprintln(os.Stderr, string(debug.Stack()))
@@ -81,3 +137,36 @@ func Execute() {
 		os.Exit(2)
 	}
 }
+
+// applyValidateSettings overrides the agent spec text limits with the
+// non-zero values from .coragent.toml.
+func applyValidateSettings(v config.ValidateSettings) {
+	if v.MaxCommentLength > 0 {
+		agent.MaxCommentLength = v.MaxCommentLength
+	}
+	if v.MaxDisplayNameLength > 0 {
+		agent.MaxDisplayNameLength = v.MaxDisplayNameLength
+	}
+}
+
+// applyEnvFile exports the variables of the --env-file dotenv file so that
+// ${ env.X } references resolve to them. Variables already set in the
+// environment are left untouched.
+func applyEnvFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	values, err := agent.LoadEnvFile(path)
+	if err != nil {
+		return UserErr(err)
+	}
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("set %s from env file: %w", key, err)
+		}
+	}
+	return nil
+}
@@ -17,12 +17,36 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// defaultRunTimeout bounds a run, and each eval test case, unless --timeout
+// is set.
+const defaultRunTimeout = 15 * time.Minute
+
+const (
+	// autoContinueMessage is sent by --auto-continue after a response was
+	// truncated by the agent's budget.
+	autoContinueMessage = "continue"
+	// maxAutoContinues bounds the follow-ups sent for a single run.
+	maxAutoContinues = 3
+)
+
 func newRunCmd(opts *RootOptions) *cobra.Command {
 	var message string
 	var showThinking bool
 	var newThread bool
+	var threadName string
 	var threadID string
 	var withoutThread bool
+	var noThreadSave bool
+	var streamIdleTimeout time.Duration
+	var timeout time.Duration
+	var jsonSchemaPath string
+	var quietTools bool
+	var showToolResults bool
+	var autoContinue bool
+	var conflictRetries int
+	var inputJSONPath string
+	var messageFile string
+	var output string
 
 	cmd := &cobra.Command{
 		Use:   "run [agent-name]",
@@ -31,14 +55,42 @@ func newRunCmd(opts *RootOptions) *cobra.Command {
 
 If agent-name is omitted, you'll be prompted to select from available agents.
 If -m is omitted, you'll be prompted to enter a message interactively.
+Use "-m -" to read the whole message from stdin, or --message-file to read
+it from a file; the message must not be empty.
 
 The agent's response is streamed to stdout as it is generated.
-Tool usage is displayed on stderr automatically.
+Tool usage is displayed on stderr automatically; use --quiet-tools to hide it
+or --show-tool-results to also print truncated tool results.
 Use --show-thinking to display reasoning tokens on stderr.
 
 By default, you'll be prompted to select from existing conversation threads
 or create a new one. Use --new to skip selection and start fresh, --thread
-to continue a specific thread, or --without-thread for single-turn mode.`,
+to continue a specific thread, or --without-thread for single-turn mode.
+Label a new thread with --new --thread-name; the name is sent to the server
+and shown in the thread selection list.
+Use --no-thread-save to keep using server threads without recording them
+in the local thread state (~/.coragent/threads.json).
+
+When the agent stops because it reached its orchestration budget, a
+"response truncated" note is printed on stderr. With --auto-continue, the run
+then sends "continue" in the same thread (up to 3 times) to get the rest.
+
+--input-json sends a JSON array of messages ({"role": ..., "content":
+[{"type": "text", "text": ...}]}) as the request's messages instead of a
+single -m text message. Unknown fields are rejected and the last message
+must come from the user.
+
+--output json suppresses the streaming output, spinner and tool markers and
+prints one JSON object when the run finishes: {"response", "tools_used",
+"thread_id", "message_id"}. tools_used lists every tool call in order.
+
+When Snowflake rejects the run because another run is still in progress on
+the thread, the command fails with a conflict error. --conflict-retries sends
+the run again that many times with exponential backoff.
+
+The run is cancelled after --timeout (default 15m). A run that times out or
+is interrupted still records its thread in the local thread state, so the
+conversation can be continued with --thread.`,
 		Example: `  # Fully interactive (select agent, then enter message)
   coragent run
 
@@ -51,22 +103,86 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
   # Specify both agent and message
   coragent run my-agent -m "What are the top sales by region?"
 
+  # Pipe a long prompt from stdin, or read it from a file
+  cat prompt.txt | coragent run my-agent -m -
+  coragent run my-agent --message-file prompt.txt
+
   # Start a new conversation thread
   coragent run my-agent --new -m "Starting fresh topic"
 
+  # Start a named thread for easier selection later
+  coragent run my-agent --new --thread-name "Q4 analysis" -m "Summarize Q4"
+
   # Continue a specific thread
   coragent run my-agent --thread 12345 -m "Follow-up question"
 
   # Single-turn mode (no thread tracking)
   coragent run my-agent --without-thread -m "One-off question"
 
+  # Continue a thread without saving it to local thread state
+  coragent run my-agent --thread 12345 --no-thread-save -m "Throwaway follow-up"
+
   # With database/schema
   coragent run my-agent -d MY_DB -s MY_SCHEMA -m "Summarize Q4 results"
 
   # Show thinking/reasoning
-  coragent run my-agent -m "Complex query" --show-thinking`,
+  coragent run my-agent -m "Complex query" --show-thinking
+
+  # Request JSON output constrained by a schema
+  coragent run my-agent -m "List top regions" --json-schema regions.schema.json
+
+  # Send a prepared messages array instead of -m
+  coragent run my-agent --without-thread --input-json messages.json
+
+  # Print the response, tools used and thread ID as one JSON object
+  coragent run my-agent --new -m "Top regions?" --output json
+
+  # Allow a long-running analysis up to 45 minutes
+  coragent run my-agent -m "Build the yearly report" --timeout 45m`,
 		Args: cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if threadName != "" && !newThread {
+				return UserErr(fmt.Errorf("--thread-name requires --new"))
+			}
+			if timeout <= 0 {
+				return UserErr(fmt.Errorf("--timeout must be positive, got %s", timeout))
+			}
+			if err := validateConflictRetries(conflictRetries); err != nil {
+				return err
+			}
+			if err := validateOutputFormat(output); err != nil {
+				return err
+			}
+			jsonOutput := output == "json"
+
+			var inputMessages []api.Message
+			if inputJSONPath != "" {
+				if message != "" || messageFile != "" {
+					return UserErr(fmt.Errorf("--input-json cannot be combined with --message or --message-file"))
+				}
+				var err error
+				inputMessages, err = loadInputMessages(inputJSONPath)
+				if err != nil {
+					return UserErr(err)
+				}
+				message = lastUserText(inputMessages)
+			} else if message != "" || messageFile != "" {
+				var err error
+				message, err = readRunMessage(message, messageFile, cmd.InOrStdin())
+				if err != nil {
+					return UserErr(err)
+				}
+			}
+
+			var schema map[string]any
+			if jsonSchemaPath != "" {
+				var err error
+				schema, err = loadJSONSchema(jsonSchemaPath)
+				if err != nil {
+					return UserErr(err)
+				}
+			}
+
 			client, cfg, err := buildClientAndCfg(opts)
 			if err != nil {
 				return err
@@ -77,7 +193,7 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 				return err
 			}
 
-			ctx, cancel := context.WithTimeout(commandContext("run"), 15*time.Minute)
+			ctx, cancel := context.WithTimeout(commandContext("run"), timeout)
 			defer cancel()
 
 			// Determine agent name
@@ -96,7 +212,7 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 			}
 
 			// Prompt for message if not provided via flag
-			if message == "" {
+			if message == "" && inputMessages == nil {
 				line, err := readLine("Enter message: ")
 				if err != nil {
 					if errors.Is(err, errInterrupted) {
@@ -110,7 +226,7 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 				}
 			}
 
-			ctx, cancel = context.WithTimeout(commandContext("run"), 15*time.Minute)
+			ctx, cancel = context.WithTimeout(commandContext("run"), timeout)
 			defer cancel()
 
 			// Determine thread settings
@@ -122,7 +238,7 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 			} else if newThread {
 				// Create new thread via Threads API
 				fmt.Fprintf(os.Stderr, "Creating new thread...\n")
-				tid, err := client.CreateThread(ctx)
+				tid, err := client.CreateNamedThread(ctx, threadName)
 				if err != nil {
 					return fmt.Errorf("create thread: %w", err)
 				}
@@ -168,24 +284,42 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 				ThreadID:        reqThreadID,
 				ParentMessageID: reqParentMsgID,
 			}
+			if inputMessages != nil {
+				req.Messages = inputMessages
+			}
+			if schema != nil {
+				req.ResponseFormat = jsonResponseFormat(schema)
+			}
 
 			// Setup spinner for status updates
 			spinner := newSpinner()
-			spinner.Start()
+			if !jsonOutput {
+				spinner.Start()
+			}
 
 			// Track if we've received any content
 			var contentStarted bool
 			var contentMu sync.Mutex
 
-			// Capture thread/message IDs from response
+			// Capture thread/message IDs and text from response
 			var respThreadID string
 			var respMessageID int64
+			var respText strings.Builder
+			toolsUsed := []string{}
 
 			// Setup streaming callbacks
 			dimColor := color.New(color.FgHiBlack)
-			cyanColor := color.New(color.FgCyan)
+			tools := toolPrinter{
+				w:           os.Stderr,
+				quiet:       quietTools || jsonOutput,
+				showResults: showToolResults && !jsonOutput,
+				debug:       opts.Debug,
+				color:       color.New(color.FgCyan),
+			}
 
 			runOpts := api.RunAgentOptions{
+				StreamIdleTimeout: streamIdleTimeout,
+				ConflictRetries:   conflictRetries,
 				OnProgress: func(phase string) {
 					spinner.SetMessage(phase)
 				},
@@ -204,7 +338,10 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 						spinner.Stop()
 					}
 					contentMu.Unlock()
-					fmt.Fprint(os.Stdout, delta)
+					respText.WriteString(delta)
+					if !jsonOutput {
+						fmt.Fprint(os.Stdout, delta)
+					}
 				},
 				OnThinkingDelta: func(delta string) {
 					contentMu.Lock()
@@ -213,22 +350,19 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 						spinner.Stop()
 					}
 					contentMu.Unlock()
-					if showThinking {
+					if showThinking && !jsonOutput {
 						dimColor.Fprint(os.Stderr, delta)
 					}
 				},
 				OnToolUse: func(name string, input json.RawMessage) {
+					toolsUsed = append(toolsUsed, name)
 					contentMu.Lock()
 					started := contentStarted
 					contentMu.Unlock()
 					if !started {
 						spinner.SetMessage(fmt.Sprintf("Using %s...", name))
-					} else {
-						cyanColor.Fprintf(os.Stderr, "\n[Tool: %s]\n", name)
-					}
-					if opts.Debug && len(input) > 0 {
-						fmt.Fprintf(os.Stderr, "  Input: %s\n", string(input))
 					}
+					tools.ToolUse(name, input, started)
 				},
 				OnToolResult: func(name string, result json.RawMessage) {
 					contentMu.Lock()
@@ -237,9 +371,7 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 					if !started {
 						spinner.SetMessage("Processing results...")
 					}
-					if opts.Debug {
-						fmt.Fprintf(os.Stderr, "  Result (%s): %s\n", name, truncateResult(result))
-					}
+					tools.ToolResult(name, result)
 				},
 				OnMetadata: func(tid string, mid int64) {
 					respThreadID = tid
@@ -247,36 +379,117 @@ to continue a specific thread, or --without-thread for single-turn mode.`,
 				},
 			}
 
-			_, err = client.RunAgent(ctx, target.Database, target.Schema, agentName, req, runOpts)
+			resp, err := client.RunAgent(ctx, target.Database, target.Schema, agentName, req, runOpts)
+			for continued := 0; err == nil && resp.BudgetExhausted(); continued++ {
+				if !autoContinue || continued == maxAutoContinues || reqThreadID == "" || respMessageID == 0 {
+					spinner.Stop()
+					color.New(color.FgYellow).Fprintln(os.Stderr, "\nResponse truncated (budget reached); the text above is incomplete.")
+					break
+				}
+				spinner.SetMessage("Response truncated (budget reached); continuing...")
+				parentID := respMessageID
+				req.Messages = []api.Message{api.NewTextMessage("user", autoContinueMessage)}
+				req.ThreadID = firstNonEmpty(respThreadID, reqThreadID)
+				req.ParentMessageID = &parentID
+				resp, err = client.RunAgent(ctx, target.Database, target.Schema, agentName, req, runOpts)
+			}
 			spinner.Stop()
-			fmt.Fprintln(os.Stdout) // newline after streaming
+			if !jsonOutput {
+				fmt.Fprintln(os.Stdout) // newline after streaming
+			}
+
+			if api.IsConflictError(err) {
+				return UserErr(fmt.Errorf("%w; wait for the other run to finish, use --new for a separate thread, or retry with --conflict-retries", err))
+			}
 
-			// Save thread state (unless --without-thread)
-			if err == nil && !withoutThread && reqThreadID != "" {
+			var runErr *api.AgentRunError
+			if errors.As(err, &runErr) && runErr.PartialText != "" {
+				color.New(color.FgYellow).Fprintln(os.Stderr, "Response interrupted by an agent error; the text above is incomplete.")
+			}
+
+			// A run cut short by --timeout or Ctrl+C still saves the thread so
+			// it can be continued; without a response message ID the thread
+			// stays at the message it was continued from.
+			interrupted := err != nil && ctx.Err() != nil
+			if interrupted {
+				color.New(color.FgYellow).Fprintf(os.Stderr, "Run stopped before the response completed (%v); the text above is incomplete.\n", ctx.Err())
+			}
+
+			// Save thread state (unless --without-thread or --no-thread-save)
+			if (err == nil || interrupted) && !withoutThread && !noThreadSave && reqThreadID != "" {
 				// Use request thread ID if response didn't provide one
 				finalThreadID := respThreadID
 				if finalThreadID == "" {
 					finalThreadID = reqThreadID
 				}
+				lastMessageID := respMessageID
+				if lastMessageID == 0 && interrupted {
+					lastMessageID = *reqParentMsgID
+				}
 				state, _ := thread.LoadState()
 				state.AddOrUpdateThread(cfg.Account, target.Database, target.Schema, agentName, thread.ThreadState{
 					ThreadID:      finalThreadID,
-					LastMessageID: respMessageID,
+					LastMessageID: lastMessageID,
 					LastUsed:      time.Now(),
 					Summary:       truncateSummary(message),
+					Name:          threadName,
 				})
 				_ = state.Save()
 			}
 
+			if err == nil && schema != nil {
+				if verr := validateResponseJSON(respText.String(), schema); verr != nil {
+					return fmt.Errorf("response does not match JSON schema: %w", verr)
+				}
+			}
+
+			if err == nil && jsonOutput {
+				return writeJSONIndent(cmd.OutOrStdout(), runJSONOutput{
+					Response:  respText.String(),
+					ToolsUsed: toolsUsed,
+					ThreadID:  firstNonEmpty(respThreadID, reqThreadID),
+					MessageID: respMessageID,
+				})
+			}
+
 			return err
 		},
 	}
 
-	cmd.Flags().StringVarP(&message, "message", "m", "", "Message to send to the agent (omit for interactive input)")
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Message to send to the agent (\"-\" reads it from stdin; omit for interactive input)")
+	cmd.Flags().StringVar(&messageFile, "message-file", "", "Read the message to send to the agent from this file")
+	cmd.Flags().StringVar(&inputJSONPath, "input-json", "", "Send the JSON array of messages in this file instead of a -m text message")
 	cmd.Flags().BoolVar(&showThinking, "show-thinking", false, "Display reasoning tokens on stderr")
 	cmd.Flags().BoolVar(&newThread, "new", false, "Start a new conversation thread")
+	cmd.Flags().StringVar(&threadName, "thread-name", "", "Name for the thread created with --new (saved on the server and in local thread state)")
 	cmd.Flags().StringVar(&threadID, "thread", "", "Continue a specific thread by ID")
 	cmd.Flags().BoolVar(&withoutThread, "without-thread", false, "Run without thread support (single-turn)")
+	cmd.Flags().BoolVar(&noThreadSave, "no-thread-save", false, "Use threads as usual but do not save them to local thread state")
+	cmd.Flags().BoolVar(&quietTools, "quiet-tools", false, "Do not print [Tool: name] markers on stderr")
+	cmd.Flags().BoolVar(&showToolResults, "show-tool-results", false, "Print truncated tool results on stderr (shown with --debug as well)")
+	cmd.Flags().StringVar(&jsonSchemaPath, "json-schema", "", "Request JSON output matching this JSON Schema file and validate the response")
+	cmd.Flags().DurationVar(&streamIdleTimeout, "stream-idle-timeout", api.DefaultStreamIdleTimeout, "Abort the response stream when no event arrives within this duration")
+	cmd.Flags().BoolVar(&autoContinue, "auto-continue", false, "Send \"continue\" in the same thread when a response is truncated by the agent's budget (up to 3 times)")
+	cmd.Flags().DurationVar(&timeout, "timeout", defaultRunTimeout, "Cancel the run after this duration (e.g. 30s, 45m)")
+	cmd.Flags().IntVar(&conflictRetries, "conflict-retries", 0, "Retry a run rejected because another run is in progress on the thread this many times (0-10), with backoff capped at 30s")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format: text (stream the response) or json (print one JSON object at the end)")
 
 	return cmd
 }
+
+// runJSONOutput is the object printed by run --output json.
+type runJSONOutput struct {
+	Response  string   `json:"response"`
+	ToolsUsed []string `json:"tools_used"` // every tool call, in order
+	ThreadID  string   `json:"thread_id"`  // pass to --thread to continue
+	MessageID int64    `json:"message_id"`
+}
+
+// validateConflictRetries checks --conflict-retries against
+// api.MaxConflictRetries.
+func validateConflictRetries(n int) error {
+	if n < 0 || n > api.MaxConflictRetries {
+		return UserErr(fmt.Errorf("--conflict-retries must be between 0 and %d, got %d", api.MaxConflictRetries, n))
+	}
+	return nil
+}
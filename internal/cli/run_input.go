@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"coragent/internal/api"
+)
+
+// loadInputMessages reads a JSON array of api.Message objects for
+// run --input-json. Unknown fields are rejected so that typos do not
+// silently drop content, and every message must have a known role and at
+// least one typed content block. The last message must come from the user.
+func loadInputMessages(path string) ([]api.Message, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read input JSON: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var messages []api.Message
+	if err := dec.Decode(&messages); err != nil {
+		return nil, fmt.Errorf("parse input JSON %q: %w", path, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("parse input JSON %q: unexpected data after the messages array", path)
+	}
+	if err := validateInputMessages(messages); err != nil {
+		return nil, fmt.Errorf("input JSON %q: %w", path, err)
+	}
+	return messages, nil
+}
+
+// validateInputMessages checks messages against the shape the agent:run
+// endpoint accepts.
+func validateInputMessages(messages []api.Message) error {
+	if len(messages) == 0 {
+		return fmt.Errorf("messages array is empty")
+	}
+	for i, m := range messages {
+		if m.Role != "user" && m.Role != "assistant" {
+			return fmt.Errorf("messages[%d].role must be \"user\" or \"assistant\", got %q", i, m.Role)
+		}
+		if len(m.Content) == 0 {
+			return fmt.Errorf("messages[%d].content is empty", i)
+		}
+		for j, c := range m.Content {
+			if c.Type == "" {
+				return fmt.Errorf("messages[%d].content[%d].type is required", i, j)
+			}
+			if c.Type == "text" && c.Text == "" {
+				return fmt.Errorf("messages[%d].content[%d].text is required for type \"text\"", i, j)
+			}
+		}
+	}
+	if last := messages[len(messages)-1]; last.Role != "user" {
+		return fmt.Errorf("the last message must have role \"user\", got %q", last.Role)
+	}
+	return nil
+}
+
+// lastUserText returns the text of the last user message, used as the
+// thread summary for runs started from --input-json.
+func lastUserText(messages []api.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "user" {
+			continue
+		}
+		for _, c := range messages[i].Content {
+			if c.Text != "" {
+				return c.Text
+			}
+		}
+	}
+	return ""
+}
+
+// readRunMessage resolves the message for run from -m and --message-file.
+// "-m -" reads the whole message from in (stdin). At most one of the two may
+// be set, and the message, with surrounding whitespace trimmed, must not be
+// empty.
+func readRunMessage(message, messageFile string, in io.Reader) (string, error) {
+	if message != "" && messageFile != "" {
+		return "", fmt.Errorf("--message and --message-file cannot be combined")
+	}
+	source := "--message"
+	switch {
+	case messageFile != "":
+		data, err := os.ReadFile(messageFile)
+		if err != nil {
+			return "", fmt.Errorf("read message file: %w", err)
+		}
+		message, source = string(data), fmt.Sprintf("message file %q", messageFile)
+	case message == "-":
+		data, err := io.ReadAll(in)
+		if err != nil {
+			return "", fmt.Errorf("read message from stdin: %w", err)
+		}
+		message, source = string(data), "stdin"
+	}
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return "", fmt.Errorf("message from %s is empty", source)
+	}
+	return message, nil
+}
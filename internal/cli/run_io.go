@@ -2,7 +2,9 @@ package cli
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strconv"
@@ -27,6 +29,33 @@ func truncateResult(data json.RawMessage) string {
 	return s[:maxLen] + "..."
 }
 
+// toolPrinter writes tool invocation markers and results for the run command.
+type toolPrinter struct {
+	w           io.Writer
+	quiet       bool // --quiet-tools: suppress [Tool: name] markers
+	showResults bool // --show-tool-results: print truncated results
+	debug       bool // --debug: print inputs and results
+	color       *color.Color
+}
+
+// ToolUse prints the marker for a tool call once streamed content has
+// started (before that the spinner shows the tool name instead).
+func (p toolPrinter) ToolUse(name string, input json.RawMessage, contentStarted bool) {
+	if contentStarted && !p.quiet {
+		p.color.Fprintf(p.w, "\n[Tool: %s]\n", name)
+	}
+	if p.debug && len(input) > 0 {
+		fmt.Fprintf(p.w, "  Input: %s\n", string(input))
+	}
+}
+
+// ToolResult prints a truncated tool result when requested.
+func (p toolPrinter) ToolResult(name string, result json.RawMessage) {
+	if p.debug || p.showResults {
+		fmt.Fprintf(p.w, "  Result (%s): %s\n", name, truncateResult(result))
+	}
+}
+
 // spinner provides a simple terminal spinner with status message.
 type spinner struct {
 	frames    []string
@@ -143,9 +172,7 @@ func selectThread(threads []thread.ThreadState, agentName string) *thread.Thread
 	// Show thread list
 	fmt.Fprintf(os.Stderr, "Available threads for %s:\n", agentName)
 	for i, t := range threads {
-		age := formatAge(t.LastUsed)
-		summary := truncateDisplay(t.Summary, 40)
-		fmt.Fprintf(os.Stderr, "  [%d] Thread %s (%s) - \"%s\"\n", i+1, t.ThreadID, age, summary)
+		fmt.Fprintf(os.Stderr, "  [%d] %s\n", i+1, formatThreadChoice(t))
 	}
 	fmt.Fprintf(os.Stderr, "  [%d] Create new thread\n", len(threads)+1)
 
@@ -162,6 +189,17 @@ func selectThread(threads []thread.ThreadState, agentName string) *thread.Thread
 	return &threads[selection-1]
 }
 
+// formatThreadChoice renders one entry of the thread selection list. Named
+// threads lead with their name; the summary is kept for context.
+func formatThreadChoice(t thread.ThreadState) string {
+	age := formatAge(t.LastUsed)
+	summary := truncateDisplay(t.Summary, 40)
+	if t.Name != "" {
+		return fmt.Sprintf("%s - Thread %s (%s) - \"%s\"", t.Name, t.ThreadID, age, summary)
+	}
+	return fmt.Sprintf("Thread %s (%s) - \"%s\"", t.ThreadID, age, summary)
+}
+
 // selectAgent shows interactive agent selection UI.
 func selectAgent(agents []api.AgentListItem) string {
 	fmt.Fprintf(os.Stderr, "Available agents:\n")
@@ -186,6 +224,96 @@ func selectAgent(agents []api.AgentListItem) string {
 	return agents[selection-1].Name
 }
 
+// selectAgents shows a checkbox-style agent list and returns the names the
+// user toggled on. Each input line toggles entries; an empty line confirms.
+func selectAgents(agents []api.AgentListItem) ([]string, error) {
+	selected := make([]bool, len(agents))
+	for {
+		fmt.Fprintf(os.Stderr, "Available agents:\n")
+		for i, a := range agents {
+			mark := " "
+			if selected[i] {
+				mark = "x"
+			}
+			if a.Comment != "" {
+				fmt.Fprintf(os.Stderr, "  [%s] %d. %s - \"%s\"\n", mark, i+1, a.Name, truncateDisplay(a.Comment, 50))
+			} else {
+				fmt.Fprintf(os.Stderr, "  [%s] %d. %s\n", mark, i+1, a.Name)
+			}
+		}
+
+		fmt.Fprintf(os.Stderr, "Toggle agents [1-%d, ranges like 1-3, a=all, n=none; Enter to confirm]: ", len(agents))
+
+		line, err := readLine("")
+		if err != nil {
+			if errors.Is(err, errInterrupted) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if err := toggleSelection(selected, line); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	}
+
+	var names []string
+	for i, on := range selected {
+		if on {
+			names = append(names, agents[i].Name)
+		}
+	}
+	return names, nil
+}
+
+// toggleSelection applies one line of multi-select input to selected.
+// "a" selects every entry and "n" clears the selection; otherwise the input
+// is a comma- or space-separated list of 1-based indexes and ranges whose
+// entries are flipped. selected is left untouched when the input is invalid.
+func toggleSelection(selected []bool, input string) error {
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "a", "all":
+		for i := range selected {
+			selected[i] = true
+		}
+		return nil
+	case "n", "none":
+		for i := range selected {
+			selected[i] = false
+		}
+		return nil
+	}
+
+	var indexes []int
+	fields := strings.FieldsFunc(input, func(r rune) bool { return r == ',' || r == ' ' })
+	for _, field := range fields {
+		lo, hi, isRange := strings.Cut(field, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return fmt.Errorf("invalid selection %q", field)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				return fmt.Errorf("invalid selection %q", field)
+			}
+		}
+		if start < 1 || end > len(selected) || start > end {
+			return fmt.Errorf("selection %q out of range [1-%d]", field, len(selected))
+		}
+		for i := start; i <= end; i++ {
+			indexes = append(indexes, i-1)
+		}
+	}
+	for _, i := range indexes {
+		selected[i] = !selected[i]
+	}
+	return nil
+}
+
 // formatAge formats a time as a human-readable relative duration.
 func formatAge(t time.Time) string {
 	d := time.Since(t)
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// loadJSONSchema reads a JSON Schema document from path.
+func loadJSONSchema(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read JSON schema: %w", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("parse JSON schema %q: %w", path, err)
+	}
+	return schema, nil
+}
+
+// jsonResponseFormat builds the response_format payload requesting JSON
+// output that conforms to schema.
+func jsonResponseFormat(schema map[string]any) map[string]any {
+	return map[string]any{
+		"type":   "json",
+		"schema": schema,
+	}
+}
+
+// validateResponseJSON parses an agent response as JSON and checks it against
+// schema. A surrounding ```json fence is tolerated.
+func validateResponseJSON(text string, schema map[string]any) error {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+	}
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return fmt.Errorf("response is not valid JSON: %w", err)
+	}
+	return validateJSONValue("$", value, schema)
+}
+
+// validateJSONValue checks value against the subset of JSON Schema that
+// structured-output schemas typically use: type, enum, properties, required,
+// additionalProperties (false only) and items.
+func validateJSONValue(path string, value any, schema map[string]any) error {
+	if t, ok := schema["type"]; ok {
+		if !matchesJSONType(value, t) {
+			return fmt.Errorf("%s: expected type %v, got %s", path, t, jsonTypeName(value))
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value %v is not one of %v", path, value, enum)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, ok := v[name]; !ok {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, ok := props[k].(map[string]any)
+			if !ok {
+				if ap, ok := schema["additionalProperties"].(bool); ok && !ap {
+					return fmt.Errorf("%s: unexpected property %q", path, k)
+				}
+				continue
+			}
+			if err := validateJSONValue(path+"."+k, v[k], sub); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateJSONValue(fmt.Sprintf("%s[%d]", path, i), item, items); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesJSONType reports whether value satisfies a schema "type", which may
+// be a single type name or a list of names.
+func matchesJSONType(value any, t any) bool {
+	switch tt := t.(type) {
+	case string:
+		name := jsonTypeName(value)
+		if tt == "number" && name == "integer" {
+			return true
+		}
+		return name == tt
+	case []any:
+		for _, item := range tt {
+			if matchesJSONType(value, item) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func jsonEqual(a, b any) bool {
+	ad, err1 := json.Marshal(a)
+	bd, err2 := json.Marshal(b)
+	return err1 == nil && err2 == nil && string(ad) == string(bd)
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testAnswerSchema() map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []any{"region", "total"},
+		"properties": map[string]any{
+			"region": map[string]any{"type": "string", "enum": []any{"EMEA", "APAC"}},
+			"total":  map[string]any{"type": "number"},
+			"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"additionalProperties": false,
+	}
+}
+
+func TestValidateResponseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		wantErr string
+	}{
+		{name: "valid", text: `{"region":"EMEA","total":12.5,"tags":["a"]}`},
+		{name: "integer as number", text: `{"region":"APAC","total":3}`},
+		{name: "fenced", text: "```json\n{\"region\":\"EMEA\",\"total\":1}\n```"},
+		{name: "not JSON", text: "The total is 3", wantErr: "not valid JSON"},
+		{name: "missing required", text: `{"region":"EMEA"}`, wantErr: `missing required property "total"`},
+		{name: "wrong type", text: `{"region":"EMEA","total":"3"}`, wantErr: "$.total: expected type number"},
+		{name: "enum", text: `{"region":"US","total":1}`, wantErr: "$.region: value US is not one of"},
+		{name: "item type", text: `{"region":"EMEA","total":1,"tags":[1]}`, wantErr: "$.tags[0]"},
+		{name: "additional property", text: `{"region":"EMEA","total":1,"extra":true}`, wantErr: `unexpected property "extra"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateResponseJSON(tt.text, testAnswerSchema())
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadJSONSchema(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(path, []byte(`{"type":"object"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := loadJSONSchema(path)
+	if err != nil {
+		t.Fatalf("loadJSONSchema: %v", err)
+	}
+	format := jsonResponseFormat(schema)
+	if format["type"] != "json" || format["schema"].(map[string]any)["type"] != "object" {
+		t.Errorf("unexpected response format: %v", format)
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadJSONSchema(bad); err == nil {
+		t.Error("expected parse error for invalid schema")
+	}
+}
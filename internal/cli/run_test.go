@@ -1,10 +1,24 @@
 package cli
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
+	"coragent/internal/regression"
+	"coragent/internal/thread"
+
+	"github.com/fatih/color"
 )
 
 func TestFormatAge(t *testing.T) {
@@ -57,6 +71,41 @@ func TestTruncateDisplay(t *testing.T) {
 	}
 }
 
+func TestToggleSelection(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   []bool
+		input   string
+		want    []bool
+		wantErr bool
+	}{
+		{"single index", []bool{false, false, false}, "2", []bool{false, true, false}, false},
+		{"comma list", []bool{false, false, false}, "1,3", []bool{true, false, true}, false},
+		{"space list", []bool{false, false, false}, "1 3", []bool{true, false, true}, false},
+		{"range", []bool{false, false, false, false}, "2-4", []bool{false, true, true, true}, false},
+		{"toggle off", []bool{true, true, false}, "1", []bool{false, true, false}, false},
+		{"all", []bool{false, true, false}, "a", []bool{true, true, true}, false},
+		{"none", []bool{true, true, false}, "n", []bool{false, false, false}, false},
+		{"out of range", []bool{false, false}, "3", []bool{false, false}, true},
+		{"reversed range", []bool{false, false, false}, "3-1", []bool{false, false, false}, true},
+		{"not a number", []bool{false, false}, "1,x", []bool{false, false}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := append([]bool(nil), tt.start...)
+			err := toggleSelection(got, tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("toggleSelection(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("toggleSelection(%q) = %v, want %v", tt.input, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
 func TestTruncateSummary(t *testing.T) {
 	tests := []struct {
 		name string
@@ -126,3 +175,478 @@ func TestTruncateResult(t *testing.T) {
 		})
 	}
 }
+
+// newMockAgentClient starts a mock server with agentName created and
+// replying with reply, and returns a client pointed at it.
+func newMockAgentClient(t *testing.T, agentName, reply string) *api.Client {
+	t.Helper()
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	client := api.NewClientForTest(base, auth.Config{
+		Account:    "TEST",
+		User:       "TESTUSER",
+		PrivateKey: regression.TestRSAPEM(t),
+	})
+	if err := client.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: agentName}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunReply(agentName, reply)
+	return client
+}
+
+// runWithToolPrinter streams a mock reply that calls one tool and returns
+// what the toolPrinter wrote to stderr.
+func runWithToolPrinter(t *testing.T, p toolPrinter) string {
+	t.Helper()
+	client := newMockAgentClient(t, "tool-agent", regression.BuildSSEReply("done", "sales_view"))
+	ctx := context.Background()
+
+	var stderr bytes.Buffer
+	p.w = &stderr
+	p.color = color.New(color.FgCyan)
+	_, err := client.RunAgent(ctx, "DB", "SCH", "tool-agent", api.RunAgentRequest{
+		Messages: []api.Message{api.NewTextMessage("user", "hi")},
+	}, api.RunAgentOptions{
+		OnToolUse:    func(name string, input json.RawMessage) { p.ToolUse(name, input, true) },
+		OnToolResult: func(name string, result json.RawMessage) { p.ToolResult(name, result) },
+	})
+	if err != nil {
+		t.Fatalf("RunAgent: %v", err)
+	}
+	return stderr.String()
+}
+
+func TestToolPrinter_Default(t *testing.T) {
+	out := runWithToolPrinter(t, toolPrinter{})
+	if !strings.Contains(out, "[Tool: sales_view]") {
+		t.Errorf("expected tool marker, got %q", out)
+	}
+	if strings.Contains(out, "Result (sales_view)") {
+		t.Errorf("did not expect tool result by default, got %q", out)
+	}
+}
+
+func TestToolPrinter_QuietTools(t *testing.T) {
+	out := runWithToolPrinter(t, toolPrinter{quiet: true})
+	if out != "" {
+		t.Errorf("expected no stderr output with --quiet-tools, got %q", out)
+	}
+}
+
+func TestToolPrinter_ShowToolResults(t *testing.T) {
+	out := runWithToolPrinter(t, toolPrinter{showResults: true})
+	if !strings.Contains(out, "[Tool: sales_view]") {
+		t.Errorf("expected tool marker, got %q", out)
+	}
+	if !strings.Contains(out, "Result (sales_view): {}") {
+		t.Errorf("expected tool result, got %q", out)
+	}
+	if strings.Contains(out, "Input:") {
+		t.Errorf("did not expect tool input without --debug, got %q", out)
+	}
+}
+
+func TestToolPrinter_QuietWithResults(t *testing.T) {
+	out := runWithToolPrinter(t, toolPrinter{quiet: true, showResults: true})
+	if strings.Contains(out, "[Tool:") {
+		t.Errorf("expected no tool marker with --quiet-tools, got %q", out)
+	}
+	if !strings.Contains(out, "Result (sales_view)") {
+		t.Errorf("expected tool result, got %q", out)
+	}
+}
+
+// runCmdAgainstMock executes the run command against a mock server with HOME
+// pointed at a temp dir and returns that dir.
+func runCmdAgainstMock(t *testing.T, extraArgs ...string) string {
+	t.Helper()
+	ms, home := setupRunMock(t)
+	ms.SetRunReply("thread-agent", regression.BuildSSEReply("done"))
+
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetArgs(append([]string{"thread-agent", "-m", "hi"}, extraArgs...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	return home
+}
+
+// setupRunMock seeds thread-agent in DB.SCH of a mock server, points the CLI
+// environment at it with HOME in a temp dir, and returns the server and dir.
+func setupRunMock(t *testing.T) (*regression.MockServer, string) {
+	t.Helper()
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	seed := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	if err := seed.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: "thread-agent"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SNOWFLAKE_HOME", home)
+	t.Setenv("CORAGENT_API_BASE_URL", ms.URL())
+	t.Setenv("SNOWFLAKE_ACCOUNT", "TEST")
+	t.Setenv("SNOWFLAKE_TOKEN", "tok")
+	return ms, home
+}
+
+func TestRunCmd_SavesThreadState(t *testing.T) {
+	home := runCmdAgainstMock(t, "--thread", "42")
+	if _, err := os.Stat(filepath.Join(home, ".coragent", "threads.json")); err != nil {
+		t.Fatalf("expected thread state to be saved: %v", err)
+	}
+	state, err := thread.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if len(state.GetThreads("TEST", "DB", "SCH", "thread-agent")) != 1 {
+		t.Errorf("expected one saved thread, got %+v", state.GetAllThreads())
+	}
+}
+
+func TestRunCmd_NoThreadSaveSkipsState(t *testing.T) {
+	home := runCmdAgainstMock(t, "--thread", "42", "--no-thread-save")
+	if _, err := os.Stat(filepath.Join(home, ".coragent", "threads.json")); !os.IsNotExist(err) {
+		t.Fatalf("expected no thread state file under --no-thread-save, stat err = %v", err)
+	}
+}
+
+func TestRunCmd_ThreadNameSavedInState(t *testing.T) {
+	runCmdAgainstMock(t, "--new", "--thread-name", "Q4 analysis")
+	state, err := thread.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	threads := state.GetThreads("TEST", "DB", "SCH", "thread-agent")
+	if len(threads) != 1 || threads[0].Name != "Q4 analysis" {
+		t.Errorf("expected one thread named %q, got %+v", "Q4 analysis", threads)
+	}
+}
+
+func TestRunCmd_ThreadNameRequiresNew(t *testing.T) {
+	cmd := newRunCmd(&RootOptions{})
+	cmd.SetArgs([]string{"agent", "-m", "hi", "--thread-name", "Q4 analysis"})
+	err := cmd.Execute()
+	if err == nil || !IsUserError(err) || !strings.Contains(err.Error(), "--new") {
+		t.Fatalf("expected user error mentioning --new, got %v", err)
+	}
+}
+
+func TestRunCmd_TimeoutSavesPartialThread(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	ms.SetRunStall("thread-agent", "event: response.text.delta\ndata: {\"text\":\"partial\",\"content_index\":0,\"sequence_number\":1}\n\n")
+
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetArgs([]string{"thread-agent", "-m", "long question", "--new", "--timeout", "200ms"})
+	start := time.Now()
+	if err := cmd.Execute(); err == nil {
+		t.Fatal("expected the stalled run to fail after --timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("run took %s; --timeout was not applied", elapsed)
+	}
+
+	state, err := thread.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	threads := state.GetThreads("TEST", "DB", "SCH", "thread-agent")
+	if len(threads) != 1 {
+		t.Fatalf("expected the timed-out thread to be saved, got %+v", state.GetAllThreads())
+	}
+	if threads[0].LastMessageID != 0 || threads[0].Summary != "long question" {
+		t.Errorf("unexpected saved thread: %+v", threads[0])
+	}
+}
+
+func TestRunCmd_RejectsNonPositiveTimeout(t *testing.T) {
+	cmd := newRunCmd(&RootOptions{})
+	cmd.SetArgs([]string{"agent", "-m", "hi", "--timeout", "0s"})
+	err := cmd.Execute()
+	if err == nil || !IsUserError(err) || !strings.Contains(err.Error(), "--timeout") {
+		t.Fatalf("expected user error mentioning --timeout, got %v", err)
+	}
+}
+
+func TestRunCmd_AutoContinueAfterBudgetStop(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		args        []string
+		wantMessage string
+	}{
+		{"without flag", nil, "hi"},
+		{"auto-continue", []string{"--auto-continue"}, autoContinueMessage},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ms, _ := setupRunMock(t)
+			ms.SetRunReply("thread-agent", regression.BuildSSEBudgetStopReply("partial"))
+
+			cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+			cmd.SetArgs(append([]string{"thread-agent", "-m", "hi", "--thread", "42"}, tc.args...))
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("run: %v", err)
+			}
+
+			var req api.RunAgentRequest
+			if err := json.Unmarshal(ms.LastRunRequest("thread-agent"), &req); err != nil {
+				t.Fatalf("decode run request: %v", err)
+			}
+			if got := req.Messages[0].Content[0].Text; got != tc.wantMessage {
+				t.Errorf("last message = %q, want %q", got, tc.wantMessage)
+			}
+			if tc.wantMessage == autoContinueMessage && (req.ThreadID != "mock-thread" || req.ParentMessageID == nil || *req.ParentMessageID != 1) {
+				t.Errorf("continuation must follow the truncated message, got thread %q parent %v", req.ThreadID, req.ParentMessageID)
+			}
+		})
+	}
+}
+
+func TestFormatThreadChoice(t *testing.T) {
+	ts := thread.ThreadState{ThreadID: "42", LastUsed: time.Now(), Summary: "What were Q4 sales?"}
+	if got := formatThreadChoice(ts); got != `Thread 42 (just now) - "What were Q4 sales?"` {
+		t.Errorf("unnamed: got %q", got)
+	}
+	ts.Name = "Q4 analysis"
+	if got := formatThreadChoice(ts); got != `Q4 analysis - Thread 42 (just now) - "What were Q4 sales?"` {
+		t.Errorf("named: got %q", got)
+	}
+}
+
+func TestRunCmd_InputJSONSendsMessages(t *testing.T) {
+	ms, home := setupRunMock(t)
+	ms.SetRunReply("thread-agent", regression.BuildSSEReply("ok"))
+	input := filepath.Join(home, "messages.json")
+	if err := os.WriteFile(input, []byte(`[
+  {"role": "user", "content": [{"type": "text", "text": "Use fiscal quarters."}]},
+  {"role": "user", "content": [{"type": "text", "text": "What were Q4 sales?"}, {"type": "text", "text": "Group by region."}]}
+]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetArgs([]string{"thread-agent", "--without-thread", "--input-json", input})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var req api.RunAgentRequest
+	if err := json.Unmarshal(ms.LastRunRequest("thread-agent"), &req); err != nil {
+		t.Fatalf("decode run request: %v", err)
+	}
+	want := []api.Message{
+		api.NewTextMessage("user", "Use fiscal quarters."),
+		{Role: "user", Content: []api.ContentBlock{{Type: "text", Text: "What were Q4 sales?"}, {Type: "text", Text: "Group by region."}}},
+	}
+	if !reflect.DeepEqual(req.Messages, want) {
+		t.Errorf("messages = %+v, want %+v", req.Messages, want)
+	}
+}
+
+func TestLoadInputMessages_Rejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"not an array", `{"role": "user"}`, "parse input JSON"},
+		{"unknown field", `[{"role": "user", "contents": []}]`, "unknown field"},
+		{"empty", `[]`, "messages array is empty"},
+		{"bad role", `[{"role": "system", "content": [{"type": "text", "text": "x"}]}]`, "messages[0].role"},
+		{"no content", `[{"role": "user", "content": []}]`, "messages[0].content is empty"},
+		{"missing type", `[{"role": "user", "content": [{"text": "x"}]}]`, "messages[0].content[0].type"},
+		{"empty text", `[{"role": "user", "content": [{"type": "text"}]}]`, "messages[0].content[0].text"},
+		{"ends with assistant", `[{"role": "user", "content": [{"type": "text", "text": "a"}]}, {"role": "assistant", "content": [{"type": "text", "text": "b"}]}]`, "last message"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "messages.json")
+			if err := os.WriteFile(path, []byte(tt.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadInputMessages(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadInputMessages() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunCmd_InputJSONConflictsWithMessage(t *testing.T) {
+	cmd := newRunCmd(&RootOptions{})
+	cmd.SetArgs([]string{"agent", "-m", "hi", "--input-json", "messages.json"})
+	if err := cmd.Execute(); err == nil || !IsUserError(err) {
+		t.Fatalf("expected user error, got %v", err)
+	}
+}
+
+func TestReadRunMessage(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "prompt.txt")
+	if err := os.WriteFile(file, []byte("Summarize Q4\nby region\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	blank := filepath.Join(dir, "blank.txt")
+	if err := os.WriteFile(blank, []byte(" \n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		file    string
+		stdin   string
+		want    string
+		wantErr string
+	}{
+		{name: "flag", message: "hi", want: "hi"},
+		{name: "stdin", message: "-", stdin: "long prompt\nline two\n", want: "long prompt\nline two"},
+		{name: "file", file: file, want: "Summarize Q4\nby region"},
+		{name: "both", message: "hi", file: file, wantErr: "cannot be combined"},
+		{name: "empty stdin", message: "-", stdin: "\n", wantErr: "message from stdin is empty"},
+		{name: "blank file", file: blank, wantErr: "is empty"},
+		{name: "missing file", file: filepath.Join(dir, "missing.txt"), wantErr: "read message file"},
+		{name: "blank flag", message: "  ", wantErr: "message from --message is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readRunMessage(tt.message, tt.file, strings.NewReader(tt.stdin))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("readRunMessage() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readRunMessage: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("readRunMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunCmd_MessageFromStdin(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	ms.SetRunReply("thread-agent", regression.BuildSSEReply("ok"))
+
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetIn(strings.NewReader("What were Q4 sales?\nGroup by region.\n"))
+	cmd.SetArgs([]string{"thread-agent", "--without-thread", "-m", "-"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var req api.RunAgentRequest
+	if err := json.Unmarshal(ms.LastRunRequest("thread-agent"), &req); err != nil {
+		t.Fatalf("decode run request: %v", err)
+	}
+	want := []api.Message{api.NewTextMessage("user", "What were Q4 sales?\nGroup by region.")}
+	if !reflect.DeepEqual(req.Messages, want) {
+		t.Errorf("messages = %+v, want %+v", req.Messages, want)
+	}
+}
+
+func TestRunCmd_MessageFileConflictsWithInputJSON(t *testing.T) {
+	cmd := newRunCmd(&RootOptions{})
+	cmd.SetArgs([]string{"agent", "--message-file", "prompt.txt", "--input-json", "messages.json"})
+	if err := cmd.Execute(); err == nil || !IsUserError(err) {
+		t.Fatalf("expected user error, got %v", err)
+	}
+}
+
+func TestRunCmd_OutputJSON(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	ms.SetRunReply("thread-agent", regression.BuildSSEReply("Q4 sales were up.", "sales_analyst", "sales_search"))
+
+	var out bytes.Buffer
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"thread-agent", "--thread", "42", "-m", "Q4 sales?", "--output", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+
+	var got runJSONOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("stdout is not one JSON object: %v\n%s", err, out.String())
+	}
+	want := runJSONOutput{
+		Response:  "Q4 sales were up.",
+		ToolsUsed: []string{"sales_analyst", "sales_search"},
+		ThreadID:  "mock-thread",
+		MessageID: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("output = %+v, want %+v", got, want)
+	}
+}
+
+func TestRunCmd_OutputJSONWithoutToolsOrThread(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	ms.SetRunReply("thread-agent", regression.BuildSSEReply("hello"))
+
+	var out bytes.Buffer
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"thread-agent", "--without-thread", "-m", "hi", "--output", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !strings.Contains(out.String(), `"tools_used": []`) {
+		t.Errorf("expected an empty tools_used array:\n%s", out.String())
+	}
+}
+
+func TestRunCmd_RejectsUnknownOutput(t *testing.T) {
+	cmd := newRunCmd(&RootOptions{})
+	cmd.SetArgs([]string{"agent", "-m", "hi", "--output", "yaml"})
+	if err := cmd.Execute(); err == nil || !IsUserError(err) {
+		t.Fatalf("expected user error, got %v", err)
+	}
+}
+
+func TestRunCmd_ConflictIsUserErrorWithHint(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	ms.SetRunReply("thread-agent", regression.BuildSSEReply("done"))
+	ms.SetRunConflicts("thread-agent", 1)
+
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetArgs([]string{"thread-agent", "-m", "hi", "--thread", "42"})
+	err := cmd.Execute()
+	if !api.IsConflictError(err) || !IsUserError(err) || !strings.Contains(err.Error(), "--conflict-retries") {
+		t.Fatalf("expected conflict user error with hint, got %v", err)
+	}
+}
+
+func TestRunCmd_ConflictRetriesSucceeds(t *testing.T) {
+	ms, _ := setupRunMock(t)
+	ms.SetRunReply("thread-agent", regression.BuildSSEReply("done"))
+	ms.SetRunConflicts("thread-agent", 1)
+
+	cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+	cmd.SetArgs([]string{"thread-agent", "-m", "hi", "--thread", "42", "--conflict-retries", "1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run with --conflict-retries: %v", err)
+	}
+}
+
+func TestRunCmd_ConflictRetriesOutOfRange(t *testing.T) {
+	for _, n := range []string{"-1", "11"} {
+		cmd := newRunCmd(&RootOptions{Database: "DB", Schema: "SCH"})
+		cmd.SetArgs([]string{"thread-agent", "-m", "hi", "--conflict-retries", n})
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		err := cmd.Execute()
+		if !IsUserError(err) || !strings.Contains(err.Error(), "--conflict-retries must be between 0 and 10") {
+			t.Errorf("--conflict-retries %s: expected range user error, got %v", n, err)
+		}
+	}
+}
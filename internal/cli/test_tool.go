@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func newTestToolCmd(opts *RootOptions) *cobra.Command {
+	var message string
+	cmd := &cobra.Command{
+		Use:   "test-tool <agent-name> <tool-name>",
+		Short: "Invoke a single agent tool in isolation",
+		Long: `Run an agent with its tool choice forced to one tool and print the tool
+input and result as JSON.
+
+Use this to check whether a misbehaving tool (for example a Cortex Analyst
+semantic view) fails on its own, independent of the agent's orchestration.
+The command fails if the agent finishes without calling the tool.`,
+		Example: `  # Ask the analyst tool a question directly
+  coragent test-tool MY_AGENT analyst -m "Total sales last month"`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			agentName, toolName := args[0], args[1]
+			if strings.TrimSpace(message) == "" {
+				return UserErr(fmt.Errorf("--message is required"))
+			}
+
+			client, cfg, err := buildClientAndCfg(opts)
+			if err != nil {
+				return err
+			}
+			target, err := ResolveTargetForExport(opts, cfg)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(commandContext("test-tool"), 15*time.Minute)
+			defer cancel()
+
+			result, err := client.TestTool(ctx, target.Database, target.Schema, agentName, toolName, message)
+			if err != nil {
+				return fmt.Errorf("test tool %q: %w", toolName, err)
+			}
+			return writeJSONIndent(cmd.OutOrStdout(), result)
+		},
+	}
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Input message passed to the agent")
+	return cmd
+}
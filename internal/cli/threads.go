@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"sort"
 	"strconv"
@@ -27,23 +28,31 @@ func newThreadsCmd(opts *RootOptions) *cobra.Command {
 	var deleteID string
 
 	cmd := &cobra.Command{
-		Use:   "threads",
-		Short: "Manage conversation threads",
+		Use:     "threads",
+		Aliases: []string{"thread"},
+		Short:   "Manage conversation threads",
 		Long: `List and delete conversation threads.
 
 By default, runs in interactive mode where you can view all threads
 and select which ones to delete.
 
-Use --list to display threads and exit without interaction.
-Use --delete to delete a specific thread by ID.`,
+Use --list (or the list subcommand) to display threads and exit without
+interaction. Use --delete (or the delete subcommand) to delete a specific
+thread by ID.`,
 		Example: `  # Interactive mode
   coragent threads
 
   # List all threads (non-interactive)
   coragent threads --list
 
+  # List the threads of one agent
+  coragent thread list my-agent -d MY_DB -s MY_SCHEMA
+
   # Delete a specific thread
-  coragent threads --delete 29864464`,
+  coragent thread delete 29864464
+
+  # Delete every thread not used in the last 30 days
+  coragent thread delete --all --older-than 720h`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			state, err := thread.LoadState()
 			if err != nil {
@@ -72,35 +81,234 @@ Use --delete to delete a specific thread by ID.`,
 	cmd.Flags().BoolVar(&listOnly, "list", false, "List threads and exit")
 	cmd.Flags().StringVar(&deleteID, "delete", "", "Delete specific thread by ID")
 
+	cmd.AddCommand(
+		newThreadsListCmd(opts),
+		newThreadsDeleteCmd(opts),
+	)
+
+	return cmd
+}
+
+func newThreadsListCmd(opts *RootOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list [agent-name]",
+		Short: "List locally tracked threads",
+		Long: `List the threads saved in ~/.coragent/threads.json, newest first.
+
+The --account, --database and --schema flags and the optional agent name
+narrow the list to matching agents. No API calls are made.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			state, err := thread.LoadState()
+			if err != nil {
+				return fmt.Errorf("load thread state: %w", err)
+			}
+			filter := newThreadFilter(opts, args)
+			threads := filterThreads(flattenThreads(state.GetAllThreads()), filter, 0, time.Now())
+			writeThreadList(cmd.OutOrStdout(), threads)
+			return nil
+		},
+	}
+}
+
+func newThreadsDeleteCmd(opts *RootOptions) *cobra.Command {
+	var all bool
+	var olderThan time.Duration
+	var agentName string
+	var autoApprove bool
+
+	cmd := &cobra.Command{
+		Use:   "delete [thread-id]",
+		Short: "Delete threads on the server and from local state",
+		Long: `Delete a thread by ID, or with --all every locally tracked thread of the
+connected account that matches the --database and --schema flags and --agent.
+
+--older-than limits --all to threads last used longer ago than the given
+duration. Threads that no longer exist on the server are removed from
+local state.`,
+		Example: `  # Delete a specific thread
+  coragent thread delete 29864464
+
+  # Delete the threads of one agent not used in the last 7 days
+  coragent thread delete --all --agent my-agent --older-than 168h -y`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch {
+			case all && len(args) == 1:
+				return UserErr(fmt.Errorf("specify a thread ID or --all, not both"))
+			case !all && len(args) == 0:
+				return UserErr(fmt.Errorf("specify a thread ID or --all"))
+			case !all && (olderThan != 0 || agentName != ""):
+				return UserErr(fmt.Errorf("--older-than and --agent require --all"))
+			case olderThan < 0:
+				return UserErr(fmt.Errorf("--older-than must not be negative"))
+			}
+
+			state, err := thread.LoadState()
+			if err != nil {
+				return fmt.Errorf("load thread state: %w", err)
+			}
+
+			if !all {
+				client, err := buildClient(opts)
+				if err != nil {
+					return err
+				}
+				return deleteThreadByID(client, state, args[0])
+			}
+
+			// Deletions go through a client for one account, so only that
+			// account's threads are candidates; others would 404 and be
+			// dropped from local state while still existing on the server.
+			client, cfg, err := buildClientAndCfg(opts)
+			if err != nil {
+				return err
+			}
+			var filterArgs []string
+			if agentName != "" {
+				filterArgs = []string{agentName}
+			}
+			filter := newThreadFilter(opts, filterArgs)
+			if cfg.Account != "" {
+				filter.Account = cfg.Account
+			}
+			threads := filterThreads(flattenThreads(state.GetAllThreads()), filter, olderThan, time.Now())
+			out := cmd.OutOrStdout()
+			if len(threads) == 0 {
+				fmt.Fprintln(out, "No threads to delete.")
+				return nil
+			}
+			writeThreadList(out, threads)
+			if !autoApprove {
+				if !confirm(fmt.Sprintf("Delete %d thread(s)?", len(threads)), cmd.InOrStdin()) {
+					fmt.Fprintln(out, "Aborted.")
+					return nil
+				}
+			}
+
+			return deleteThreads(out, client, state, threads)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Delete every matching thread instead of a single ID")
+	cmd.Flags().DurationVar(&olderThan, "older-than", 0, "With --all, only delete threads last used longer ago than this (e.g. 720h)")
+	cmd.Flags().StringVar(&agentName, "agent", "", "With --all, only delete threads of this agent")
+	cmd.Flags().BoolVarP(&autoApprove, "yes", "y", false, "Skip confirmation prompt")
+
 	return cmd
 }
 
+// threadFilter selects threads by the parts of their
+// "ACCOUNT/DATABASE/SCHEMA/AGENT" state key. Empty fields match anything.
+type threadFilter struct {
+	Account  string
+	Database string
+	Schema   string
+	Agent    string
+}
+
+// newThreadFilter builds a filter from the root flags and an optional agent
+// name argument.
+func newThreadFilter(opts *RootOptions, args []string) threadFilter {
+	f := threadFilter{
+		Account:  opts.Account,
+		Database: opts.Database,
+		Schema:   opts.Schema,
+	}
+	if len(args) > 0 {
+		f.Agent = args[0]
+	}
+	return f
+}
+
+// matches reports whether agentKey satisfies every non-empty filter field,
+// ignoring case like the state keys themselves.
+func (f threadFilter) matches(agentKey string) bool {
+	parts := strings.Split(agentKey, "/")
+	if len(parts) != 4 {
+		return false
+	}
+	for i, want := range []string{f.Account, f.Database, f.Schema, f.Agent} {
+		if want != "" && !strings.EqualFold(parts[i], want) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterThreads keeps the threads matching f. A positive olderThan also
+// drops threads used within that duration before now.
+func filterThreads(threads []threadInfo, f threadFilter, olderThan time.Duration, now time.Time) []threadInfo {
+	var result []threadInfo
+	for _, t := range threads {
+		if !f.matches(t.AgentKey) {
+			continue
+		}
+		if olderThan > 0 && now.Sub(t.State.LastUsed) < olderThan {
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
 // displayThreads shows all threads grouped by agent.
 func displayThreads(state *thread.StateStore) error {
-	allThreads := state.GetAllThreads()
-	if len(allThreads) == 0 {
-		fmt.Println("No threads found.")
-		return nil
-	}
+	writeThreadList(os.Stdout, flattenThreads(state.GetAllThreads()))
+	return nil
+}
 
-	// Flatten and sort threads
-	threads := flattenThreads(allThreads)
+// writeThreadList prints threads with their ID, age, summary and agent key.
+func writeThreadList(w io.Writer, threads []threadInfo) {
 	if len(threads) == 0 {
-		fmt.Println("No threads found.")
-		return nil
+		fmt.Fprintln(w, "No threads found.")
+		return
 	}
 
-	fmt.Println("Threads:")
+	fmt.Fprintln(w, "Threads:")
 	for i, t := range threads {
 		age := formatAge(t.State.LastUsed)
 		summary := truncateDisplay(t.State.Summary, 40)
-		fmt.Printf("  [%d] Thread %s (%s) - \"%s\"\n", i+1, t.State.ThreadID, age, summary)
-		fmt.Printf("      Agent: %s\n", t.AgentKey)
+		fmt.Fprintf(w, "  [%d] Thread %s (%s) - \"%s\"\n", i+1, t.State.ThreadID, age, summary)
+		fmt.Fprintf(w, "      Agent: %s\n", t.AgentKey)
 	}
+}
 
+// deleteThreads deletes threads through the API and from local state, then
+// saves the state. A thread that is already gone on the server is still
+// removed locally; other API failures are reported and the thread is kept.
+func deleteThreads(w io.Writer, client api.ThreadService, state *thread.StateStore, threads []threadInfo) error {
+	failed := 0
+	for _, t := range threads {
+		if err := deleteRemoteThread(client, t.State.ThreadID); err != nil && !api.IsNotFoundError(err) {
+			fmt.Fprintf(w, "Failed to delete thread %s: %v\n", t.State.ThreadID, err)
+			failed++
+			continue
+		}
+		parts := strings.Split(t.AgentKey, "/")
+		if len(parts) == 4 {
+			state.DeleteThread(parts[0], parts[1], parts[2], parts[3], t.State.ThreadID)
+		}
+		fmt.Fprintf(w, "Deleted thread %s\n", t.State.ThreadID)
+	}
+
+	if err := state.Save(); err != nil {
+		return fmt.Errorf("save state: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to delete %d of %d thread(s)", failed, len(threads))
+	}
 	return nil
 }
 
+// deleteRemoteThread deletes a single thread with its own timeout, so a
+// long batch does not run out of time partway through.
+func deleteRemoteThread(client api.ThreadService, threadID string) error {
+	ctx, cancel := context.WithTimeout(commandContext("threads"), 30*time.Second)
+	defer cancel()
+	return client.DeleteThread(ctx, threadID)
+}
+
 // interactiveThreadManager provides an interactive UI for managing threads.
 func interactiveThreadManager(client *api.Client, state *thread.StateStore) error {
 	reader := bufio.NewReader(os.Stdin)
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"coragent/internal/api"
+	"coragent/internal/thread"
+)
+
+func TestFilterThreads(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	threads := []threadInfo{
+		{AgentKey: "ACCT/DB/SCH/SALES", State: thread.ThreadState{ThreadID: "1", LastUsed: now.Add(-time.Hour)}},
+		{AgentKey: "ACCT/DB/SCH/SALES", State: thread.ThreadState{ThreadID: "2", LastUsed: now.Add(-48 * time.Hour)}},
+		{AgentKey: "ACCT/OTHER/SCH/SUPPORT", State: thread.ThreadState{ThreadID: "3", LastUsed: now.Add(-72 * time.Hour)}},
+	}
+
+	tests := []struct {
+		name      string
+		filter    threadFilter
+		olderThan time.Duration
+		want      []string
+	}{
+		{"no filter", threadFilter{}, 0, []string{"1", "2", "3"}},
+		{"agent ignores case", threadFilter{Agent: "sales"}, 0, []string{"1", "2"}},
+		{"database", threadFilter{Database: "other"}, 0, []string{"3"}},
+		{"older than", threadFilter{}, 24 * time.Hour, []string{"2", "3"}},
+		{"agent and older than", threadFilter{Agent: "SALES"}, 24 * time.Hour, []string{"2"}},
+		{"no match", threadFilter{Account: "ELSEWHERE"}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, ti := range filterThreads(threads, tt.filter, tt.olderThan, now) {
+				got = append(got, ti.State.ThreadID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("filterThreads() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// saveTestThreads writes thread state with one recent and one stale thread
+// for thread-agent and one recent thread for other-agent.
+func saveTestThreads(t *testing.T) {
+	t.Helper()
+	now := time.Now()
+	state := &thread.StateStore{Threads: map[string][]thread.ThreadState{}}
+	state.AddOrUpdateThread("TEST", "DB", "SCH", "thread-agent", thread.ThreadState{ThreadID: "101", LastUsed: now, Summary: "recent question"})
+	state.AddOrUpdateThread("TEST", "DB", "SCH", "thread-agent", thread.ThreadState{ThreadID: "102", LastUsed: now.Add(-10 * 24 * time.Hour), Summary: "stale question"})
+	state.AddOrUpdateThread("TEST", "DB", "SCH", "other-agent", thread.ThreadState{ThreadID: "201", LastUsed: now, Summary: "other question"})
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+}
+
+func TestThreadListCmd_FiltersByAgent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	saveTestThreads(t)
+
+	root, _ := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"thread", "list", "thread-agent", "-d", "db", "-s", "sch"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("thread list: %v", err)
+	}
+
+	got := out.String()
+	for _, want := range []string{"Thread 101 (just now)", `"recent question"`, "Thread 102 (10 days ago)", "Agent: TEST/DB/SCH/THREAD-AGENT"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "201") {
+		t.Errorf("output includes another agent's thread:\n%s", got)
+	}
+}
+
+func TestThreadDeleteCmd_AllOlderThan(t *testing.T) {
+	setupRunMock(t)
+	saveTestThreads(t)
+
+	root, _ := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"thread", "delete", "--all", "--older-than", "168h", "-y"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("thread delete: %v", err)
+	}
+	if !strings.Contains(out.String(), "Deleted thread 102") {
+		t.Errorf("output missing deletion:\n%s", out.String())
+	}
+
+	state, err := thread.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if state.FindThread("TEST", "DB", "SCH", "thread-agent", "102") != nil {
+		t.Error("stale thread is still in local state")
+	}
+	if state.FindThread("TEST", "DB", "SCH", "thread-agent", "101") == nil || state.FindThread("TEST", "DB", "SCH", "other-agent", "201") == nil {
+		t.Errorf("recent threads were deleted: %+v", state.GetAllThreads())
+	}
+}
+
+func TestThreadDeleteCmd_AllSkipsOtherAccounts(t *testing.T) {
+	setupRunMock(t)
+	saveTestThreads(t)
+	state, err := thread.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	state.AddOrUpdateThread("OTHER", "DB", "SCH", "thread-agent", thread.ThreadState{ThreadID: "301", LastUsed: time.Now()})
+	if err := state.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	root, _ := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"thread", "delete", "--all", "-y"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("thread delete: %v", err)
+	}
+	if strings.Contains(out.String(), "301") {
+		t.Errorf("deleted a thread of another account:\n%s", out.String())
+	}
+
+	state, err = thread.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+	if state.FindThread("OTHER", "DB", "SCH", "thread-agent", "301") == nil {
+		t.Error("another account's thread was removed from local state")
+	}
+	if state.FindThread("TEST", "DB", "SCH", "thread-agent", "101") != nil {
+		t.Error("current account's thread is still in local state")
+	}
+}
+
+func TestThreadDeleteCmd_RequiresIDOrAll(t *testing.T) {
+	for _, args := range [][]string{
+		{"thread", "delete"},
+		{"thread", "delete", "101", "--all"},
+		{"thread", "delete", "101", "--older-than", "1h"},
+	} {
+		root, _ := newRootCmd()
+		root.SetArgs(args)
+		if err := root.Execute(); err == nil || !IsUserError(err) {
+			t.Errorf("%v: expected user error, got %v", args, err)
+		}
+	}
+}
+
+// deadlineThreadService records whether each DeleteThread call received a
+// context that was still live.
+type deadlineThreadService struct {
+	api.ThreadService
+	ctxs []context.Context
+}
+
+func (s *deadlineThreadService) DeleteThread(ctx context.Context, threadID string) error {
+	s.ctxs = append(s.ctxs, ctx)
+	return ctx.Err()
+}
+
+func TestDeleteThreads_TimeoutPerThread(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	saveTestThreads(t)
+	state, err := thread.LoadState()
+	if err != nil {
+		t.Fatalf("LoadState: %v", err)
+	}
+
+	svc := &deadlineThreadService{}
+	var out bytes.Buffer
+	if err := deleteThreads(&out, svc, state, flattenThreads(state.GetAllThreads())); err != nil {
+		t.Fatalf("deleteThreads: %v\n%s", err, out.String())
+	}
+	if len(svc.ctxs) != 3 {
+		t.Fatalf("DeleteThread called %d times, want 3", len(svc.ctxs))
+	}
+	if svc.ctxs[0] == svc.ctxs[1] || svc.ctxs[1] == svc.ctxs[2] {
+		t.Error("deletions share one context; want a timeout per thread")
+	}
+	for i, ctx := range svc.ctxs {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Errorf("deletion %d has no deadline", i)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"coragent/internal/api"
+)
+
+// traceRecorder collects the HTTP requests of one command run for --trace.
+// Every client built for the run shares the recorder.
+type traceRecorder struct {
+	mu            sync.Mutex
+	CorrelationID string              `json:"correlation_id"`
+	Command       string              `json:"command"`
+	StartedAt     time.Time           `json:"started_at"`
+	Requests      []traceRequestEntry `json:"requests"`
+}
+
+// traceRequestEntry is one request in the trace file, tagged with the run's
+// correlation ID so entries from several files can be merged.
+type traceRequestEntry struct {
+	CorrelationID string `json:"correlation_id"`
+	api.RequestRecord
+}
+
+func newTraceRecorder() *traceRecorder {
+	return &traceRecorder{
+		CorrelationID: newCorrelationID(),
+		Command:       strings.Join(os.Args, " "),
+		StartedAt:     time.Now(),
+		Requests:      []traceRequestEntry{},
+	}
+}
+
+// newCorrelationID returns a random 16-byte hex identifier.
+func newCorrelationID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
+}
+
+func (r *traceRecorder) observe(rec api.RequestRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.Requests = append(r.Requests, traceRequestEntry{CorrelationID: r.CorrelationID, RequestRecord: rec})
+}
+
+// attachTrace registers the run's trace recorder on client when --trace is
+// set, creating the recorder on first use.
+func attachTrace(client *api.Client, opts *RootOptions) {
+	if opts.Trace == "" {
+		return
+	}
+	if opts.trace == nil {
+		opts.trace = newTraceRecorder()
+	}
+	client.SetResponseObserver(opts.trace.observe)
+}
+
+// writeTrace writes the recorded requests to the --trace file. Commands that
+// made no requests still produce a file with an empty request list.
+func writeTrace(opts *RootOptions) error {
+	if opts.Trace == "" {
+		return nil
+	}
+	if opts.trace == nil {
+		opts.trace = newTraceRecorder()
+	}
+	opts.trace.mu.Lock()
+	defer opts.trace.mu.Unlock()
+
+	f, err := os.Create(opts.Trace)
+	if err != nil {
+		return fmt.Errorf("create trace file: %w", err)
+	}
+	defer f.Close()
+	if err := writeJSONIndent(f, opts.trace); err != nil {
+		return fmt.Errorf("write trace file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
+	"coragent/internal/regression"
+)
+
+func TestTrace_RecordsEveryRequestAgainstMock(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	seed := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	if err := seed.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: "trace-agent"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunReply("trace-agent", regression.BuildSSEReply("done"))
+	before := ms.Requests()
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SNOWFLAKE_HOME", home)
+	t.Setenv("CORAGENT_API_BASE_URL", ms.URL())
+	t.Setenv("SNOWFLAKE_ACCOUNT", "TEST")
+	t.Setenv("SNOWFLAKE_TOKEN", "tok")
+
+	tracePath := filepath.Join(t.TempDir(), "trace.json")
+	opts := &RootOptions{Database: "DB", Schema: "SCH", Trace: tracePath}
+	cmd := newRunCmd(opts)
+	cmd.SetArgs([]string{"trace-agent", "-m", "hi"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if err := writeTrace(opts); err != nil {
+		t.Fatalf("writeTrace: %v", err)
+	}
+
+	data, err := os.ReadFile(tracePath)
+	if err != nil {
+		t.Fatalf("read trace: %v", err)
+	}
+	var trace struct {
+		CorrelationID string `json:"correlation_id"`
+		Requests      []struct {
+			CorrelationID  string            `json:"correlation_id"`
+			Method         string            `json:"method"`
+			URL            string            `json:"url"`
+			Status         int               `json:"status"`
+			RequestHeaders map[string]string `json:"request_headers"`
+		} `json:"requests"`
+	}
+	if err := json.Unmarshal(data, &trace); err != nil {
+		t.Fatalf("parse trace: %v\n%s", err, data)
+	}
+
+	want := ms.Requests() - before
+	if want == 0 {
+		t.Fatal("run made no requests against the mock")
+	}
+	if len(trace.Requests) != want {
+		t.Fatalf("trace has %d requests, mock received %d", len(trace.Requests), want)
+	}
+	if trace.CorrelationID == "" {
+		t.Error("trace has no correlation_id")
+	}
+	for i, r := range trace.Requests {
+		if r.CorrelationID != trace.CorrelationID {
+			t.Errorf("requests[%d].correlation_id = %q, want %q", i, r.CorrelationID, trace.CorrelationID)
+		}
+		if r.Method == "" || r.URL == "" || r.Status == 0 {
+			t.Errorf("requests[%d] incomplete: %+v", i, r)
+		}
+		if got := r.RequestHeaders["Authorization"]; got != "REDACTED" {
+			t.Errorf("requests[%d] Authorization = %q, want REDACTED", i, got)
+		}
+	}
+}
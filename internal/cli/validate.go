@@ -2,17 +2,31 @@ package cli
 
 import (
 	"fmt"
+	"io"
 
 	"coragent/internal/agent"
+	"coragent/internal/api"
 
 	"github.com/spf13/cobra"
 )
 
 func newValidateCmd(opts *RootOptions) *cobra.Command {
 	var recursive bool
+	var remote string
+	var strict bool
 	cmd := &cobra.Command{
 		Use:   "validate [path]",
-		Short: "Validate YAML files without applying",
+		Short: "Validate YAML files, or a deployed agent, without applying",
+		Long: `Validate agent YAML files. Local validation does not contact Snowflake.
+
+Every file is checked even when some fail, and each failure is reported
+with its path. The command exits with an error when any file is invalid.
+
+With --remote NAME, no files are read: the deployed agent is described
+(honoring --retry) and any DESCRIBE AGENT columns or agent_spec keys that
+coragent cannot represent are listed as warnings. Add --strict to fail when
+there are any, which signals that Snowflake has added features this version
+of coragent cannot manage.`,
 		Example: `  # Validate current directory
   coragent validate
 
@@ -20,25 +34,120 @@ func newValidateCmd(opts *RootOptions) *cobra.Command {
   coragent validate agent.yaml
 
   # Validate all agents in a directory tree
-  coragent validate -R ./agents/`,
+  coragent validate -R ./agents/
+
+  # Fail if a deployed agent uses fields coragent does not know yet
+  coragent validate --remote MY_AGENT --strict`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if strict && remote == "" {
+				return UserErr(fmt.Errorf("--strict requires --remote"))
+			}
+			if remote != "" {
+				if len(args) > 0 || recursive {
+					return UserErr(fmt.Errorf("--remote cannot be combined with a path or --recursive"))
+				}
+				return validateRemote(cmd, opts, remote, strict)
+			}
+
 			path := "."
 			if len(args) == 1 {
 				path = args[0]
 			}
 
-			specs, err := agent.LoadAgents(path, recursive, opts.Env)
+			result, err := agent.LoadAgentsResult(path, recursive, opts.Env)
 			if err != nil {
 				return UserErr(err)
 			}
 
-			for _, item := range specs {
+			writeSpecWarnings(cmd.ErrOrStderr(), result.Agents)
+			for _, item := range result.Agents {
 				fmt.Fprintf(cmd.OutOrStdout(), "ok: %s\n", item.Path)
 			}
-			return nil
+			if len(result.Failures) == 0 {
+				return nil
+			}
+			if len(result.Agents) == 0 && len(result.Failures) == 1 {
+				return UserErr(result.Failures[0].Err)
+			}
+			for _, f := range result.Failures {
+				fmt.Fprintf(cmd.ErrOrStderr(), "error: %v\n", f.Err)
+			}
+			total := len(result.Agents) + len(result.Failures)
+			return UserErr(fmt.Errorf("%d of %d files failed validation", len(result.Failures), total))
 		},
 	}
 	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "Recursively load agents from subdirectories")
+	cmd.Flags().StringVar(&remote, "remote", "", "Describe this deployed agent and check it for fields coragent cannot represent")
+	cmd.Flags().BoolVar(&strict, "strict", false, "With --remote, fail when the agent has unmapped columns or spec keys")
 	return cmd
 }
+
+// writeSpecWarnings prints the non-fatal findings for each loaded spec,
+// followed by names that collide with another spec's name by case.
+func writeSpecWarnings(w io.Writer, specs []agent.ParsedAgent) {
+	for _, item := range specs {
+		warnings := append(agent.ToolResourceWarnings(item.Spec), agent.ModelWarnings(item.Spec)...)
+		for _, msg := range warnings {
+			fmt.Fprintf(w, "\033[33mWarning: %s: %s\033[0m\n", item.Path, msg)
+		}
+	}
+	for _, msg := range localNameCaseWarnings(specs) {
+		fmt.Fprintf(w, "\033[33mWarning: %s\033[0m\n", msg)
+	}
+}
+
+// validateRemote describes the named agent and lists the DESCRIBE AGENT
+// columns and agent_spec keys that are not mapped to AgentSpec. They are
+// warnings, or an error when strict is set.
+func validateRemote(cmd *cobra.Command, opts *RootOptions, name string, strict bool) error {
+	client, cfg, err := buildClientAndCfg(opts)
+	if err != nil {
+		return err
+	}
+	target, err := ResolveTargetForExport(opts, cfg)
+	if err != nil {
+		return err
+	}
+	var result api.DescribeResult
+	err = runWithRetry(opts, func() error {
+		result, err = client.DescribeAgent(commandContext("validate"), target.Database, target.Schema, name)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("snowflake API error: %w", err)
+	}
+	if !result.Exists {
+		return UserErr(fmt.Errorf("agent %q not found in %s.%s", name, target.Database, target.Schema))
+	}
+
+	fqName := fmt.Sprintf("%s.%s.%s", target.Database, target.Schema, name)
+	findings := unmappedFindings(result)
+	if len(findings) == 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "ok: %s\n", fqName)
+		return nil
+	}
+	if !strict {
+		for _, msg := range findings {
+			fmt.Fprintf(cmd.ErrOrStderr(), "\033[33mWarning: %s: %s\033[0m\n", fqName, msg)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "ok: %s\n", fqName)
+		return nil
+	}
+	for _, msg := range findings {
+		fmt.Fprintf(cmd.ErrOrStderr(), "error: %s: %s\n", fqName, msg)
+	}
+	return UserErr(fmt.Errorf("%s has %d field(s) this version of coragent cannot manage; upgrade coragent", fqName, len(findings)))
+}
+
+// unmappedFindings describes each unmapped column and spec key of result.
+func unmappedFindings(result api.DescribeResult) []string {
+	var findings []string
+	for _, col := range result.UnmappedColumns {
+		findings = append(findings, fmt.Sprintf("unmapped DESCRIBE AGENT column %q", col))
+	}
+	for _, key := range result.UnmappedSpecKeys {
+		findings = append(findings, fmt.Sprintf("unmapped agent_spec key %q", key))
+	}
+	return findings
+}
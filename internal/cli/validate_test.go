@@ -2,10 +2,16 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/auth"
 )
 
 func runValidateCmd(opts *RootOptions, args []string) (string, error) {
@@ -70,6 +76,40 @@ func TestValidateCmdInvalidYAML(t *testing.T) {
 	}
 }
 
+func TestValidateCmdReportsAllInvalidFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.yaml":    "name: agent-a\n",
+		"b.yaml":    "name: agent-b\n",
+		"bad1.yaml": "key: [unclosed\n",
+		"bad2.yaml": "name: bad2\nunknown_field: true\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	var out, errOut bytes.Buffer
+	cmd := newValidateCmd(&RootOptions{})
+	cmd.SetOut(&out)
+	cmd.SetErr(&errOut)
+	cmd.SetArgs([]string{dir})
+	err := cmd.Execute()
+	if err == nil || !IsUserError(err) || !strings.Contains(err.Error(), "2 of 4 files failed validation") {
+		t.Fatalf("expected user error counting failures, got %v", err)
+	}
+	for _, want := range []string{"ok: " + filepath.Join(dir, "a.yaml"), "ok: " + filepath.Join(dir, "b.yaml")} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("stdout missing %q:\n%s", want, out.String())
+		}
+	}
+	for _, want := range []string{filepath.Join(dir, "bad1.yaml"), filepath.Join(dir, "bad2.yaml")} {
+		if !strings.Contains(errOut.String(), want) {
+			t.Errorf("stderr missing failure for %q:\n%s", want, errOut.String())
+		}
+	}
+}
+
 func TestValidateCmdNonExistentPath(t *testing.T) {
 	_, err := runValidateCmd(&RootOptions{}, []string{"/nonexistent/path/agent.yaml"})
 	if err == nil {
@@ -226,3 +266,206 @@ deploy:
 		t.Errorf("expected error about 'invalid privilege', got: %v", err)
 	}
 }
+
+func TestValidateCmdOrphanedToolResources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	if err := os.WriteFile(path, []byte(`
+name: test-agent
+tools:
+  - tool_spec:
+      type: cortex_analyst_text_to_sql
+      name: analyst
+tool_resources:
+  analyst:
+    semantic_view: DB.SCH.SALES
+  analyts:
+    semantic_view: DB.SCH.SALES
+`), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	_, err := runValidateCmd(&RootOptions{}, []string{path})
+	if err == nil {
+		t.Fatal("expected error for orphaned tool_resources key, got nil")
+	}
+	if !strings.Contains(err.Error(), `tool_resources key "analyts" does not match any tool name`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateCmdWarnsOnUnusedToolResources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	if err := os.WriteFile(path, []byte(`
+name: test-agent
+tools:
+  - tool_spec:
+      type: data_to_chart
+      name: chart
+tool_resources:
+  chart:
+    semantic_view: DB.SCH.SALES
+`), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := newValidateCmd(&RootOptions{})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stdout.String(), "ok: "+path) {
+		t.Errorf("stdout %q does not contain %q", stdout.String(), "ok: "+path)
+	}
+	if !strings.Contains(stderr.String(), "tool_resources.chart is ignored") {
+		t.Errorf("stderr %q does not contain the tool_resources warning", stderr.String())
+	}
+}
+
+func TestValidateCmdWarnsOnUnknownOrchestrationModel(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	if err := os.WriteFile(path, []byte("name: test-agent\nmodels:\n  orchestration: claude-4-sonet\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := newValidateCmd(&RootOptions{})
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{path})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("unknown model must only warn, got: %v", err)
+	}
+	if !strings.Contains(stderr.String(), `models.orchestration "claude-4-sonet" is not a known orchestration model`) {
+		t.Errorf("stderr %q does not contain the model warning", stderr.String())
+	}
+}
+
+func TestValidateCmdEnvFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "agent.yaml")
+	if err := os.WriteFile(path, []byte(`
+name: test-agent
+deploy:
+  database: ${ env.CORAGENT_TEST_ENVFILE_DB }
+  schema: ${ env.CORAGENT_TEST_ENVFILE_SCHEMA }
+`), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("# test values\nCORAGENT_TEST_ENVFILE_DB=\"FILE_DB\"\nCORAGENT_TEST_ENVFILE_SCHEMA=FILE_SCHEMA\n"), 0o644); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+	// The real environment wins over the env file.
+	t.Setenv("CORAGENT_TEST_ENVFILE_SCHEMA", "ENV_SCHEMA")
+	t.Setenv("CORAGENT_TEST_ENVFILE_DB", "")
+	os.Unsetenv("CORAGENT_TEST_ENVFILE_DB")
+
+	root, _ := newRootCmd()
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"validate", path, "--env-file", envPath})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := os.Getenv("CORAGENT_TEST_ENVFILE_DB"); got != "FILE_DB" {
+		t.Errorf("CORAGENT_TEST_ENVFILE_DB = %q, want FILE_DB", got)
+	}
+	if got := os.Getenv("CORAGENT_TEST_ENVFILE_SCHEMA"); got != "ENV_SCHEMA" {
+		t.Errorf("CORAGENT_TEST_ENVFILE_SCHEMA = %q, want ENV_SCHEMA", got)
+	}
+}
+
+func TestValidateCmdEnvFileInvalid(t *testing.T) {
+	envPath := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envPath, []byte("NOT_A_PAIR\n"), 0o644); err != nil {
+		t.Fatalf("write env file: %v", err)
+	}
+
+	root, _ := newRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetArgs([]string{"validate", t.TempDir(), "--env-file", envPath})
+	err := root.Execute()
+	if !IsUserError(err) || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected env file user error, got %v", err)
+	}
+}
+
+// seedFutureAgent deploys future-agent to DB.SCH with an agent_spec key
+// coragent does not know.
+func seedFutureAgent(t *testing.T) {
+	t.Helper()
+	ms, _ := setupRunMock(t)
+	ms.SetStoreHook(func(payload map[string]any) {
+		if payload["name"] == "future-agent" {
+			payload["future_feature"] = map[string]any{"enabled": true}
+		}
+	})
+	base, err := url.Parse(ms.URL())
+	if err != nil {
+		t.Fatalf("parse mock URL: %v", err)
+	}
+	seed := api.NewClientForTest(base, auth.Config{Account: "TEST", SessionToken: "tok"})
+	if err := seed.CreateAgent(context.Background(), "DB", "SCH", agent.AgentSpec{Name: "future-agent"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+}
+
+func runValidateRemote(t *testing.T, args ...string) (string, string, error) {
+	t.Helper()
+	root, _ := newRootCmd()
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetArgs(append([]string{"validate", "-d", "DB", "-s", "SCH"}, args...))
+	err := root.Execute()
+	return out.String(), errOut.String(), err
+}
+
+func TestValidateCmdRemoteStrictClean(t *testing.T) {
+	setupRunMock(t)
+	out, _, err := runValidateRemote(t, "--remote", "thread-agent", "--strict")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "ok: DB.SCH.thread-agent") {
+		t.Errorf("output %q does not contain ok line", out)
+	}
+}
+
+func TestValidateCmdRemoteStrictUnmapped(t *testing.T) {
+	seedFutureAgent(t)
+	_, errOut, err := runValidateRemote(t, "--remote", "future-agent", "--strict")
+	if !IsUserError(err) || !strings.Contains(err.Error(), "upgrade coragent") {
+		t.Fatalf("expected upgrade user error, got %v", err)
+	}
+	if !strings.Contains(errOut, `unmapped agent_spec key "future_feature"`) {
+		t.Errorf("stderr %q does not list the unmapped key", errOut)
+	}
+}
+
+func TestValidateCmdRemoteWarnsWithoutStrict(t *testing.T) {
+	seedFutureAgent(t)
+	out, errOut, err := runValidateRemote(t, "--remote", "future-agent")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(errOut, "Warning: DB.SCH.future-agent: unmapped agent_spec key \"future_feature\"") {
+		t.Errorf("stderr %q does not warn about the unmapped key", errOut)
+	}
+	if !strings.Contains(out, "ok: DB.SCH.future-agent") {
+		t.Errorf("output %q does not contain ok line", out)
+	}
+}
+
+func TestValidateCmdStrictRequiresRemote(t *testing.T) {
+	_, err := runValidateCmd(&RootOptions{}, []string{"--strict", t.TempDir()})
+	if !IsUserError(err) || !strings.Contains(err.Error(), "--strict requires --remote") {
+		t.Fatalf("expected --strict user error, got %v", err)
+	}
+}
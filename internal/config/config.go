@@ -4,15 +4,24 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/BurntSushi/toml"
 )
 
 // CoragentConfig represents the top-level structure of .coragent.toml.
 type CoragentConfig struct {
+	API      APISettings      `toml:"api"`
 	Eval     EvalSettings     `toml:"eval"`
 	Feedback FeedbackSettings `toml:"feedback"`
+	Format   FormatSettings   `toml:"format"`
 	QueryTag QueryTagSettings `toml:"query_tag"`
+	Validate ValidateSettings `toml:"validate"`
+}
+
+// APISettings tunes the Snowflake API client; 0 keeps the default.
+type APISettings struct {
+	MaxResponseBytes int `toml:"max_response_bytes"` // cap on a decompressed response body
 }
 
 // FeedbackSettings holds feedback-related configuration.
@@ -32,11 +41,22 @@ type FeedbackRemoteSettings struct {
 
 // EvalSettings holds eval-specific configuration.
 type EvalSettings struct {
-	OutputDir              string   `toml:"output_dir"`
-	TimestampSuffix        bool     `toml:"timestamp_suffix"`
-	JudgeModel             string   `toml:"judge_model"`
-	ResponseScoreThreshold int      `toml:"response_score_threshold"`
-	IgnoreTools            []string `toml:"ignore_tools"`
+	OutputDir              string        `toml:"output_dir"`
+	TimestampSuffix        bool          `toml:"timestamp_suffix"`
+	JudgeModel             string        `toml:"judge_model"`
+	JudgePromptTemplate    string        `toml:"judge_prompt_template"`
+	ResponseScoreThreshold int           `toml:"response_score_threshold"`
+	PassRateThreshold      float64       `toml:"pass_rate_threshold"`
+	MaxResponseMs          int           `toml:"max_response_ms"` // latency limit per test reply; 0 disables it
+	IgnoreTools            []string      `toml:"ignore_tools"`
+	RequestDelay           time.Duration `toml:"request_delay"` // pause between test cases, e.g. "2s"
+}
+
+// FormatSettings controls the YAML written by export; 0 and false keep the
+// canonical two-space, unquoted style.
+type FormatSettings struct {
+	YAMLIndent   int  `toml:"yaml_indent"`
+	QuoteStrings bool `toml:"quote_strings"`
 }
 
 // QueryTagSettings configures the base query tag value used for Snowflake requests.
@@ -44,6 +64,12 @@ type QueryTagSettings struct {
 	Base string `toml:"base"`
 }
 
+// ValidateSettings overrides the spec validation limits; 0 keeps the default.
+type ValidateSettings struct {
+	MaxCommentLength     int `toml:"max_comment_length"`
+	MaxDisplayNameLength int `toml:"max_display_name_length"`
+}
+
 // LoadCoragentConfig loads configuration from .coragent.toml.
 // Search order:
 //  1. Current directory: .coragent.toml
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadCoragentConfig_ProjectRoot(t *testing.T) {
@@ -250,3 +251,20 @@ base = "global-base"
 		t.Errorf("expected global query tag base, got %q", cfg.QueryTag.Base)
 	}
 }
+
+func TestLoadCoragentConfig_RequestDelay(t *testing.T) {
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	t.Cleanup(func() { os.Chdir(origDir) })
+	os.Chdir(dir)
+
+	content := `[eval]
+request_delay = "1500ms"
+`
+	os.WriteFile(filepath.Join(dir, ".coragent.toml"), []byte(content), 0o644)
+
+	cfg := LoadCoragentConfig()
+	if cfg.Eval.RequestDelay != 1500*time.Millisecond {
+		t.Errorf("expected 1.5s, got %s", cfg.Eval.RequestDelay)
+	}
+}
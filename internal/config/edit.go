@@ -0,0 +1,214 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// GlobalConfigPath returns the path to ~/.coragent/config.toml.
+func GlobalConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("resolve home directory: %w", err)
+	}
+	return filepath.Join(home, ".coragent", "config.toml"), nil
+}
+
+// Keys returns every dotted key accepted by GetValue and SetValue, sorted.
+func Keys() []string {
+	var keys []string
+	collectKeys("", reflect.TypeOf(CoragentConfig{}), &keys)
+	sort.Strings(keys)
+	return keys
+}
+
+func collectKeys(prefix string, t reflect.Type, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := prefix + f.Tag.Get("toml")
+		if f.Type.Kind() == reflect.Struct {
+			collectKeys(key+".", f.Type, keys)
+			continue
+		}
+		*keys = append(*keys, key)
+	}
+}
+
+// lookupField resolves a dotted key such as "eval.judge_model" to the
+// matching leaf field type of CoragentConfig.
+func lookupField(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(CoragentConfig{})
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		f, ok := fieldByTag(t, part)
+		if !ok {
+			return nil, fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(Keys(), ", "))
+		}
+		last := i == len(parts)-1
+		if f.Type.Kind() == reflect.Struct {
+			if last {
+				return nil, fmt.Errorf("config key %q is a section; use one of its fields", key)
+			}
+			t = f.Type
+			continue
+		}
+		if !last {
+			return nil, fmt.Errorf("unknown config key %q (valid keys: %s)", key, strings.Join(Keys(), ", "))
+		}
+		return f.Type, nil
+	}
+	return nil, fmt.Errorf("config key is empty")
+}
+
+func fieldByTag(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get("toml") == tag {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// parseValue converts a command-line string into the Go value stored for a
+// field of type t. Lists are given as comma-separated values, and durations
+// such as "2s" are stored as strings.
+func parseValue(t reflect.Type, key, raw string) (any, error) {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected a duration such as 2s or 500ms, got %q", key, raw)
+		}
+		return d.String(), nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected true or false, got %q", key, raw)
+		}
+		return v, nil
+	case reflect.Int:
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected an integer, got %q", key, raw)
+		}
+		return int64(v), nil
+	case reflect.Float64:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: expected a number, got %q", key, raw)
+		}
+		return v, nil
+	case reflect.Slice:
+		var out []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported value type %s", key, t)
+}
+
+// readTable decodes path into a generic table so that only keys present in
+// the file are written back. A missing file yields an empty table.
+func readTable(path string) (map[string]any, error) {
+	table := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return table, nil
+		}
+		return nil, err
+	}
+	var cfg CoragentConfig
+	if err := toml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := toml.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// GetValue returns the value of a dotted key from the config file at path,
+// formatted for display. found is false when the key is valid but unset.
+func GetValue(path, key string) (value string, found bool, err error) {
+	if _, err := lookupField(key); err != nil {
+		return "", false, err
+	}
+	table, err := readTable(path)
+	if err != nil {
+		return "", false, err
+	}
+	parts := strings.Split(key, ".")
+	var cur any = table
+	for _, part := range parts {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return "", false, nil
+		}
+		if cur, ok = m[part]; !ok {
+			return "", false, nil
+		}
+	}
+	if list, ok := cur.([]any); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ","), true, nil
+	}
+	return fmt.Sprint(cur), true, nil
+}
+
+// SetValue validates key against the CoragentConfig schema, converts raw to
+// the field's type and writes it into the config file at path, creating the
+// file and its directory when absent. Other keys in the file are preserved.
+func SetValue(path, key, raw string) error {
+	t, err := lookupField(key)
+	if err != nil {
+		return err
+	}
+	value, err := parseValue(t, key, raw)
+	if err != nil {
+		return err
+	}
+	table, err := readTable(path)
+	if err != nil {
+		return err
+	}
+
+	parts := strings.Split(key, ".")
+	cur := table
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := cur[part].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			cur[part] = next
+		}
+		cur = next
+	}
+	cur[parts[len(parts)-1]] = value
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(table); err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestSetValue_CreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".coragent", "config.toml")
+
+	if err := SetValue(path, "eval.judge_model", "claude-3-5-sonnet"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+	if err := SetValue(path, "eval.response_score_threshold", "70"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+
+	var cfg CoragentConfig
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		t.Fatalf("decode written file: %v", err)
+	}
+	if cfg.Eval.JudgeModel != "claude-3-5-sonnet" {
+		t.Errorf("JudgeModel = %q, want claude-3-5-sonnet", cfg.Eval.JudgeModel)
+	}
+	if cfg.Eval.ResponseScoreThreshold != 70 {
+		t.Errorf("ResponseScoreThreshold = %d, want 70", cfg.Eval.ResponseScoreThreshold)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "output_dir") {
+		t.Errorf("unset keys should not be written:\n%s", data)
+	}
+}
+
+func TestSetValue_PreservesOtherKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte("[query_tag]\nbase = \"team-a\"\n"), 0o644)
+
+	if err := SetValue(path, "eval.ignore_tools", "tool_a, tool_b"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+
+	var cfg CoragentConfig
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		t.Fatalf("decode written file: %v", err)
+	}
+	if cfg.QueryTag.Base != "team-a" {
+		t.Errorf("QueryTag.Base = %q, want team-a", cfg.QueryTag.Base)
+	}
+	if len(cfg.Eval.IgnoreTools) != 2 || cfg.Eval.IgnoreTools[1] != "tool_b" {
+		t.Errorf("IgnoreTools = %v, want [tool_a tool_b]", cfg.Eval.IgnoreTools)
+	}
+}
+
+func TestSetValue_RejectsInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	tests := []struct {
+		key, value, wantErr string
+	}{
+		{"eval.judge", "x", "unknown config key"},
+		{"eval", "x", "is a section"},
+		{"eval.timestamp_suffix", "maybe", "expected true or false"},
+		{"eval.response_score_threshold", "high", "expected an integer"},
+		{"eval.request_delay", "soon", "expected a duration"},
+	}
+	for _, tt := range tests {
+		err := SetValue(path, tt.key, tt.value)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("SetValue(%q, %q) error = %v, want %q", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("invalid set should not create the file")
+	}
+}
+
+func TestSetValue_Duration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+
+	if err := SetValue(path, "eval.request_delay", "2s"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+
+	var cfg CoragentConfig
+	if _, err := toml.DecodeFile(path, &cfg); err != nil {
+		t.Fatalf("decode written file: %v", err)
+	}
+	if cfg.Eval.RequestDelay != 2*time.Second {
+		t.Errorf("RequestDelay = %v, want 2s", cfg.Eval.RequestDelay)
+	}
+	value, found, err := GetValue(path, "eval.request_delay")
+	if err != nil || !found || value != "2s" {
+		t.Errorf("GetValue = %q, %v, %v; want 2s", value, found, err)
+	}
+}
+
+func TestGetValue_NestedValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	os.WriteFile(path, []byte("[eval]\noutput_dir = \"./eval-results\"\n\n[feedback.remote]\nenabled = true\n"), 0o644)
+
+	got, found, err := GetValue(path, "eval.output_dir")
+	if err != nil || !found || got != "./eval-results" {
+		t.Errorf("GetValue(eval.output_dir) = %q, %v, %v; want ./eval-results", got, found, err)
+	}
+	got, found, err = GetValue(path, "feedback.remote.enabled")
+	if err != nil || !found || got != "true" {
+		t.Errorf("GetValue(feedback.remote.enabled) = %q, %v, %v; want true", got, found, err)
+	}
+	_, found, err = GetValue(path, "eval.judge_model")
+	if err != nil || found {
+		t.Errorf("GetValue(unset key) found = %v, err = %v; want not found", found, err)
+	}
+	if _, _, err := GetValue(path, "eval.nope"); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
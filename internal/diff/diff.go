@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"coragent/internal/agent"
 )
@@ -27,6 +28,19 @@ type Change struct {
 
 type Options struct {
 	IgnoreMissingRemote bool
+	// MatchArraysByKey pairs array elements by the key registered for the
+	// array's path in arrayElementKeys instead of by index, so reordering
+	// elements produces no changes. Arrays whose elements lack a unique key,
+	// or whose path is not registered, are compared positionally.
+	MatchArraysByKey bool
+}
+
+// arrayElementKeys maps an array path to the dotted field that identifies
+// its elements when Options.MatchArraysByKey is set. An empty key keeps
+// positional comparison for arrays where order is meaningful.
+var arrayElementKeys = map[string]string{
+	"tools":                         "tool_spec.name",
+	"instructions.sample_questions": "",
 }
 
 func Diff(local, remote agent.AgentSpec) ([]Change, error) {
@@ -101,6 +115,62 @@ func HasChanges(changes []Change) bool {
 	return len(changes) > 0
 }
 
+// Stats counts changes by type for summary output and scripting.
+func Stats(changes []Change) (added, removed, modified int) {
+	for _, c := range changes {
+		switch c.Type {
+		case Added:
+			added++
+		case Removed:
+			removed++
+		case Modified:
+			modified++
+		}
+	}
+	return added, removed, modified
+}
+
+// jsonChange is the serialized form of a Change used by MarshalChanges.
+type jsonChange struct {
+	Path   string     `json:"path"`
+	Type   ChangeType `json:"type"`
+	Before any        `json:"before"`
+	After  any        `json:"after"`
+}
+
+// MarshalChanges serializes changes as a JSON array of
+// {"path","type","before","after"} objects. Changes are ordered by their
+// top-level field using the API field order; the relative order of changes
+// under the same field is preserved. An empty slice encodes as [].
+func MarshalChanges(changes []Change) ([]byte, error) {
+	ordered := make([]Change, len(changes))
+	copy(ordered, changes)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return agentKeyLess(topLevelKey(ordered[i].Path), topLevelKey(ordered[j].Path))
+	})
+
+	out := make([]jsonChange, len(ordered))
+	for i, c := range ordered {
+		out[i] = jsonChange{Path: c.Path, Type: c.Type, Before: c.Before, After: c.After}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return nil, fmt.Errorf("marshal changes: %w", err)
+	}
+	return data, nil
+}
+
+// topLevelKey returns the first field name of a change path such as
+// "tools[0].tool_spec.name".
+func topLevelKey(path string) string {
+	for i, r := range path {
+		if r == '.' || r == '[' {
+			return path[:i]
+		}
+	}
+	return path
+}
+
 // ToMap converts an AgentSpec to a map for comparison.
 func ToMap(spec agent.AgentSpec) (map[string]any, error) {
 	data, err := json.Marshal(spec)
@@ -159,6 +229,11 @@ func diffAny(path string, local, remote any, changes *[]Change, opts Options) {
 			*changes = append(*changes, Change{Path: path, Type: Modified, Before: local, After: remote})
 			return
 		}
+		if opts.MatchArraysByKey {
+			if key := arrayElementKeys[path]; key != "" && diffArrayByKey(path, key, l, r, changes, opts) {
+				return
+			}
+		}
 		maxLen := len(l)
 		if len(r) > maxLen {
 			maxLen = len(r)
@@ -182,6 +257,62 @@ func diffAny(path string, local, remote any, changes *[]Change, opts Options) {
 	}
 }
 
+// diffArrayByKey compares two arrays by pairing elements with the same value
+// at key. Element paths use the key value, e.g. "tools[sales_view]". Local
+// elements come first in local order, followed by remote-only elements. It
+// returns false without recording changes when any element lacks a
+// non-empty key or a key repeats, so the caller can fall back to positional
+// comparison.
+func diffArrayByKey(path, key string, local, remote []any, changes *[]Change, opts Options) bool {
+	localKeys, ok := elementKeys(local, key)
+	if !ok {
+		return false
+	}
+	remoteKeys, ok := elementKeys(remote, key)
+	if !ok {
+		return false
+	}
+	remoteByKey := make(map[string]any, len(remote))
+	for i, k := range remoteKeys {
+		remoteByKey[k] = remote[i]
+	}
+	inLocal := make(map[string]bool, len(local))
+	for i, k := range localKeys {
+		inLocal[k] = true
+		diffAny(fmt.Sprintf("%s[%s]", path, k), local[i], remoteByKey[k], changes, opts)
+	}
+	for i, k := range remoteKeys {
+		if !inLocal[k] {
+			diffAny(fmt.Sprintf("%s[%s]", path, k), nil, remote[i], changes, opts)
+		}
+	}
+	return true
+}
+
+// elementKeys returns the value at the dotted key for each element, or false
+// when an element has no non-empty string key or keys are not unique.
+func elementKeys(items []any, key string) ([]string, bool) {
+	keys := make([]string, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		value := item
+		for _, part := range strings.Split(key, ".") {
+			m, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			value = m[part]
+		}
+		k, ok := value.(string)
+		if !ok || k == "" || seen[k] {
+			return nil, false
+		}
+		seen[k] = true
+		keys[i] = k
+	}
+	return keys, true
+}
+
 func uniqueKeys(a, b map[string]any) []string {
 	keys := make(map[string]struct{})
 	for k := range a {
@@ -228,17 +359,22 @@ var agentFieldOrder = map[string]int{
 // Keys not in the predefined order are sorted alphabetically and placed at the end.
 func sortAgentKeys(keys []string) {
 	sort.Slice(keys, func(i, j int) bool {
-		oi, oki := agentFieldOrder[keys[i]]
-		oj, okj := agentFieldOrder[keys[j]]
-		if oki && okj {
-			return oi < oj
-		}
-		if oki {
-			return true
-		}
-		if okj {
-			return false
-		}
-		return keys[i] < keys[j]
+		return agentKeyLess(keys[i], keys[j])
 	})
 }
+
+// agentKeyLess reports whether key a sorts before key b in API field order.
+func agentKeyLess(a, b string) bool {
+	oa, oka := agentFieldOrder[a]
+	ob, okb := agentFieldOrder[b]
+	if oka && okb {
+		return oa < ob
+	}
+	if oka {
+		return true
+	}
+	if okb {
+		return false
+	}
+	return a < b
+}
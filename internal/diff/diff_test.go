@@ -1,6 +1,9 @@
 package diff
 
 import (
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
 
 	"coragent/internal/agent"
@@ -39,6 +42,40 @@ func TestDiffDetectsChanges(t *testing.T) {
 	}
 }
 
+func TestDiff_InstructionFieldPaths(t *testing.T) {
+	local := agent.AgentSpec{
+		Name: "agent",
+		Instructions: &agent.Instructions{
+			System:        "new system",
+			Orchestration: "use tools",
+			Examples:      "Q: hi A: hello",
+		},
+	}
+	remote := agent.AgentSpec{
+		Name: "agent",
+		Instructions: &agent.Instructions{
+			System:        "old system",
+			Orchestration: "use tools",
+		},
+	}
+
+	changes, err := Diff(local, remote)
+	if err != nil {
+		t.Fatalf("Diff error: %v", err)
+	}
+	got := map[string]ChangeType{}
+	for _, c := range changes {
+		got[c.Path] = c.Type
+	}
+	want := map[string]ChangeType{
+		"instructions.system":   Modified,
+		"instructions.examples": Added,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
+
 func TestDiffNoChanges(t *testing.T) {
 	spec := agent.AgentSpec{
 		Name: "agent",
@@ -209,6 +246,79 @@ func TestDiffWithOptions_IgnoreMissingRemote(t *testing.T) {
 	}
 }
 
+// TestDiffWithOptions_MatchArraysByKey tests that tools are paired by
+// tool_spec.name so a reorder produces no changes and only the modified tool
+// is reported.
+func TestDiffWithOptions_MatchArraysByKey(t *testing.T) {
+	remote := agent.AgentSpec{
+		Name: "agent",
+		Tools: []agent.Tool{
+			{ToolSpec: map[string]any{"name": "alpha", "description": "a"}},
+			{ToolSpec: map[string]any{"name": "beta", "description": "b"}},
+			{ToolSpec: map[string]any{"name": "gamma", "description": "c"}},
+		},
+	}
+	local := agent.AgentSpec{
+		Name: "agent",
+		Tools: []agent.Tool{
+			{ToolSpec: map[string]any{"name": "delta", "description": "d"}},
+			{ToolSpec: map[string]any{"name": "gamma", "description": "c"}},
+			{ToolSpec: map[string]any{"name": "alpha", "description": "changed"}},
+		},
+	}
+
+	changes, err := DiffWithOptions(local, remote, Options{MatchArraysByKey: true})
+	if err != nil {
+		t.Fatalf("DiffWithOptions error: %v", err)
+	}
+	got := make(map[string]ChangeType)
+	for _, c := range changes {
+		got[c.Path] = c.Type
+	}
+	want := map[string]ChangeType{
+		"tools[delta]":                       Added,
+		"tools[alpha].tool_spec.description": Modified,
+		"tools[beta]":                        Removed,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+
+	reordered := agent.AgentSpec{Name: "agent", Tools: []agent.Tool{remote.Tools[2], remote.Tools[0], remote.Tools[1]}}
+	changes, err = DiffWithOptions(reordered, remote, Options{MatchArraysByKey: true})
+	if err != nil {
+		t.Fatalf("DiffWithOptions error: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("expected no changes for a reorder, got %v", changes)
+	}
+}
+
+// TestDiffWithOptions_MatchArraysByKeyFallsBack tests positional comparison
+// when tool names repeat.
+func TestDiffWithOptions_MatchArraysByKeyFallsBack(t *testing.T) {
+	remote := agent.AgentSpec{Name: "agent", Tools: []agent.Tool{
+		{ToolSpec: map[string]any{"name": "dup", "description": "a"}},
+		{ToolSpec: map[string]any{"name": "dup", "description": "b"}},
+	}}
+	local := agent.AgentSpec{Name: "agent", Tools: []agent.Tool{
+		{ToolSpec: map[string]any{"name": "dup", "description": "b"}},
+		{ToolSpec: map[string]any{"name": "dup", "description": "a"}},
+	}}
+	changes, err := DiffWithOptions(local, remote, Options{MatchArraysByKey: true})
+	if err != nil {
+		t.Fatalf("DiffWithOptions error: %v", err)
+	}
+	for _, c := range changes {
+		if !strings.HasPrefix(c.Path, "tools[0]") && !strings.HasPrefix(c.Path, "tools[1]") {
+			t.Errorf("expected positional path, got %s", c.Path)
+		}
+	}
+	if len(changes) != 2 {
+		t.Errorf("expected 2 positional changes, got %d", len(changes))
+	}
+}
+
 // TestDiff_ArrayLengthDifference tests array comparison with different lengths.
 func TestDiff_ArrayLengthDifference(t *testing.T) {
 	local := agent.AgentSpec{
@@ -748,6 +858,34 @@ func TestSortAgentKeys(t *testing.T) {
 	}
 }
 
+func TestDiff_PerPhaseModels(t *testing.T) {
+	local := agent.AgentSpec{
+		Name:   "agent",
+		Models: &agent.Models{Orchestration: "auto", Response: "claude-4-sonnet", ToolUse: "llama3.1-70b"},
+	}
+	remote := agent.AgentSpec{
+		Name:   "agent",
+		Models: &agent.Models{Orchestration: "auto", Response: "claude-3-5-sonnet"},
+	}
+
+	changes, err := Diff(local, remote)
+	if err != nil {
+		t.Fatalf("Diff error: %v", err)
+	}
+	want := []Change{
+		{Path: "models.response", Type: Modified, Before: "claude-3-5-sonnet", After: "claude-4-sonnet"},
+		{Path: "models.tool_use", Type: Added, After: "llama3.1-70b"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i].Path != want[i].Path || changes[i].Type != want[i].Type || changes[i].Before != want[i].Before || changes[i].After != want[i].After {
+			t.Errorf("change[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
+
 // TestDiff_UnknownFieldsSorted tests that unknown fields are sorted alphabetically.
 func TestDiff_UnknownFieldsSorted(t *testing.T) {
 	// Test with ToolResources which can have arbitrary keys
@@ -783,6 +921,65 @@ func TestDiff_UnknownFieldsSorted(t *testing.T) {
 	}
 }
 
+// TestDiff_ToolSpecTitleDescriptionOrder tests that tool_spec display metadata
+// written in a different key order does not produce changes.
+func TestDiff_ToolSpecTitleDescriptionOrder(t *testing.T) {
+	var local agent.AgentSpec
+	if err := json.Unmarshal([]byte(`{"name":"agent","tools":[{"tool_spec":{"type":"cortex_search","name":"docs","title":"Docs","description":"Search docs"}}]}`), &local); err != nil {
+		t.Fatalf("unmarshal local: %v", err)
+	}
+	var remote agent.AgentSpec
+	if err := json.Unmarshal([]byte(`{"name":"agent","tools":[{"tool_spec":{"description":"Search docs","title":"Docs","name":"docs","type":"cortex_search"}}]}`), &remote); err != nil {
+		t.Fatalf("unmarshal remote: %v", err)
+	}
+
+	changes, err := Diff(local, remote)
+	if err != nil {
+		t.Fatalf("Diff error: %v", err)
+	}
+	if HasChanges(changes) {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
+
+// TestDiff_ToolSpecTitleChanged tests that title and description changes are
+// reported with stable, sorted paths.
+func TestDiff_ToolSpecTitleChanged(t *testing.T) {
+	local := agent.AgentSpec{
+		Name: "agent",
+		Tools: []agent.Tool{{ToolSpec: map[string]any{
+			"name":        "docs",
+			"title":       "Documentation",
+			"description": "Search all docs",
+		}}},
+	}
+	remote := agent.AgentSpec{
+		Name: "agent",
+		Tools: []agent.Tool{{ToolSpec: map[string]any{
+			"description": "Search docs",
+			"title":       "Docs",
+			"name":        "docs",
+		}}},
+	}
+
+	changes, err := Diff(local, remote)
+	if err != nil {
+		t.Fatalf("Diff error: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %d: %+v", len(changes), changes)
+	}
+	if changes[0].Path != "tools[0].tool_spec.description" {
+		t.Errorf("expected first change 'tools[0].tool_spec.description', got '%s'", changes[0].Path)
+	}
+	if changes[1].Path != "tools[0].tool_spec.title" {
+		t.Errorf("expected second change 'tools[0].tool_spec.title', got '%s'", changes[1].Path)
+	}
+	if changes[1].Before != "Docs" || changes[1].After != "Documentation" {
+		t.Errorf("unexpected title change: %+v", changes[1])
+	}
+}
+
 // TestDiff_MapVsArrayTypeMismatch tests when map is compared with array.
 func TestDiff_MapVsArrayTypeMismatch(t *testing.T) {
 	// Create specs with different types at the same path using ToolResources
@@ -914,3 +1111,80 @@ func TestDiffForCreate_EmptyMap(t *testing.T) {
 		t.Fatalf("expected 1 change, got %d", len(changes))
 	}
 }
+
+func TestStats_MixedChanges(t *testing.T) {
+	changes := []Change{
+		{Path: "comment", Type: Modified, Before: "old", After: "new"},
+		{Path: "profile.display_name", Type: Added, After: "Agent"},
+		{Path: "tools[1]", Type: Added, After: map[string]any{"tool_spec": nil}},
+		{Path: "instructions.system", Type: Removed, Before: "be nice"},
+		{Path: "models.orchestration", Type: Modified, Before: "a", After: "b"},
+		{Path: "orchestration.budget.seconds", Type: Modified, Before: 10, After: 20},
+	}
+
+	added, removed, modified := Stats(changes)
+	if added != 2 || removed != 1 || modified != 3 {
+		t.Errorf("Stats = (%d, %d, %d), want (2, 1, 3)", added, removed, modified)
+	}
+}
+
+func TestStats_Empty(t *testing.T) {
+	added, removed, modified := Stats(nil)
+	if added != 0 || removed != 0 || modified != 0 {
+		t.Errorf("Stats(nil) = (%d, %d, %d), want zeros", added, removed, modified)
+	}
+}
+
+func TestDiff_ToolResourcesMultipleSemanticViews(t *testing.T) {
+	remote := agent.AgentSpec{
+		Name: "agent",
+		ToolResources: agent.ToolResources{
+			"analyst": map[string]any{"semantic_view": []any{"DB.SCH.SALES"}},
+		},
+	}
+	local := agent.AgentSpec{
+		Name: "agent",
+		ToolResources: agent.ToolResources{
+			"analyst": map[string]any{"semantic_view": []any{"DB.SCH.SALES", "DB.SCH.ORDERS"}},
+		},
+	}
+
+	changes, err := Diff(local, remote)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 change, got %+v", changes)
+	}
+	c := changes[0]
+	if c.Path != "tool_resources.analyst.semantic_view[1]" || c.Type != Added || c.After != "DB.SCH.ORDERS" {
+		t.Errorf("unexpected change: %+v", c)
+	}
+}
+
+func TestMarshalChanges(t *testing.T) {
+	changes := []Change{
+		{Path: "tools[0].tool_spec.name", Type: Modified, Before: "old", After: "new"},
+		{Path: "instructions.response", Type: Modified, Before: "a", After: "b"},
+		{Path: "comment", Type: Added, Before: nil, After: "hello"},
+	}
+
+	data, err := MarshalChanges(changes)
+	if err != nil {
+		t.Fatalf("MarshalChanges: %v", err)
+	}
+	want := `[{"path":"comment","type":"ADDED","before":null,"after":"hello"},` +
+		`{"path":"instructions.response","type":"MODIFIED","before":"a","after":"b"},` +
+		`{"path":"tools[0].tool_spec.name","type":"MODIFIED","before":"old","after":"new"}]`
+	if string(data) != want {
+		t.Errorf("MarshalChanges =\n%s\nwant\n%s", data, want)
+	}
+
+	empty, err := MarshalChanges(nil)
+	if err != nil {
+		t.Fatalf("MarshalChanges(nil): %v", err)
+	}
+	if string(empty) != "[]" {
+		t.Errorf("MarshalChanges(nil) = %s, want []", empty)
+	}
+}
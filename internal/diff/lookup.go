@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lookup returns the value at path in a spec map from ToMap. Paths use the
+// syntax of Change.Path: dotted field names, "[N]" for an array index, and
+// "[key]" for an element of an array registered in arrayElementKeys (e.g.
+// "tools[sales_view].tool_spec.description"). It returns an error naming the
+// first segment that does not exist.
+func Lookup(spec map[string]any, path string) (any, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, fmt.Errorf("path is empty")
+	}
+	var current any = spec
+	walked := ""
+	for _, field := range strings.Split(path, ".") {
+		name, indexes, err := splitIndexes(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		if name != "" {
+			m, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("unknown path %q: %s is not an object", path, describeWalked(walked))
+			}
+			value, ok := m[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown path %q: %q not found in %s", path, name, describeWalked(walked))
+			}
+			current = value
+			walked = joinPath(walked, name)
+		}
+		for _, index := range indexes {
+			items, ok := current.([]any)
+			if !ok {
+				return nil, fmt.Errorf("unknown path %q: %s is not an array", path, describeWalked(walked))
+			}
+			element, err := arrayElement(walked, items, index)
+			if err != nil {
+				return nil, fmt.Errorf("unknown path %q: %w", path, err)
+			}
+			current = element
+			walked = fmt.Sprintf("%s[%s]", walked, index)
+		}
+	}
+	return current, nil
+}
+
+// splitIndexes splits a path field such as "tools[0]" into its name and
+// bracketed indexes.
+func splitIndexes(field string) (string, []string, error) {
+	open := strings.IndexByte(field, '[')
+	if open < 0 {
+		if field == "" {
+			return "", nil, fmt.Errorf("empty field name")
+		}
+		return field, nil, nil
+	}
+	name := field[:open]
+	var indexes []string
+	rest := field[open:]
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end < 0 {
+			return "", nil, fmt.Errorf("malformed index in %q", field)
+		}
+		index := rest[1:end]
+		if index == "" {
+			return "", nil, fmt.Errorf("empty index in %q", field)
+		}
+		indexes = append(indexes, index)
+		rest = rest[end+1:]
+	}
+	if name == "" && len(indexes) == 0 {
+		return "", nil, fmt.Errorf("empty field name")
+	}
+	return name, indexes, nil
+}
+
+// arrayElement returns items[index] for a numeric index, or the element
+// whose key (per arrayElementKeys[path]) equals index.
+func arrayElement(path string, items []any, index string) (any, error) {
+	if n, err := strconv.Atoi(index); err == nil {
+		if n < 0 || n >= len(items) {
+			return nil, fmt.Errorf("index %d out of range for %s (length %d)", n, describeWalked(path), len(items))
+		}
+		return items[n], nil
+	}
+	key := arrayElementKeys[path]
+	if key == "" {
+		return nil, fmt.Errorf("%s must be indexed by number, got %q", describeWalked(path), index)
+	}
+	for _, item := range items {
+		value := item
+		for _, part := range strings.Split(key, ".") {
+			m, ok := value.(map[string]any)
+			if !ok {
+				value = nil
+				break
+			}
+			value = m[part]
+		}
+		if value == index {
+			return item, nil
+		}
+	}
+	return nil, fmt.Errorf("no element of %s has %s %q", describeWalked(path), key, index)
+}
+
+func describeWalked(path string) string {
+	if path == "" {
+		return "the spec"
+	}
+	return path
+}
@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+)
+
+func lookupTestSpec(t *testing.T) map[string]any {
+	t.Helper()
+	spec := agent.AgentSpec{
+		Name:   "sales",
+		Models: &agent.Models{Orchestration: "claude-4-sonnet"},
+		Orchestration: &agent.Orchestration{
+			Budget: &agent.BudgetConfig{Seconds: 30, Tokens: 4096},
+		},
+		Tools: []agent.Tool{
+			{ToolSpec: map[string]any{"type": "cortex_analyst_text_to_sql", "name": "sales_view", "description": "Sales data"}},
+			{ToolSpec: map[string]any{"type": "cortex_search", "name": "docs"}},
+		},
+		ToolResources: agent.ToolResources{
+			"sales_view": map[string]any{"semantic_view": "DB.SCH.SALES_SV"},
+		},
+	}
+	m, err := ToMap(spec)
+	if err != nil {
+		t.Fatalf("ToMap: %v", err)
+	}
+	return m
+}
+
+func TestLookup(t *testing.T) {
+	spec := lookupTestSpec(t)
+	tests := []struct {
+		path string
+		want any
+	}{
+		{"name", "sales"},
+		{"models.orchestration", "claude-4-sonnet"},
+		{"orchestration.budget.tokens", float64(4096)},
+		{"orchestration.budget", map[string]any{"seconds": float64(30), "tokens": float64(4096)}},
+		{"tools[1].tool_spec.name", "docs"},
+		{"tools[sales_view].tool_spec.description", "Sales data"},
+		{"tool_resources.sales_view", map[string]any{"semantic_view": "DB.SCH.SALES_SV"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := Lookup(spec, tt.path)
+			if err != nil {
+				t.Fatalf("Lookup(%q): %v", tt.path, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup(%q) = %#v, want %#v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLookup_Errors(t *testing.T) {
+	spec := lookupTestSpec(t)
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{"models.response", `"response" not found in models`},
+		{"profile", `"profile" not found in the spec`},
+		{"name.first", "name is not an object"},
+		{"models[0]", "models is not an array"},
+		{"tools[5]", "index 5 out of range for tools (length 2)"},
+		{"tools[missing]", `no element of tools has tool_spec.name "missing"`},
+		{"tools[0", "malformed index"},
+		{"tools..name", "empty field name"},
+		{"", "path is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := Lookup(spec, tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Lookup(%q) error = %v, want it to contain %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
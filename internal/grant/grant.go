@@ -10,9 +10,13 @@ import (
 
 // GrantEntry represents a single grant on an agent.
 type GrantEntry struct {
-	Privilege string // USAGE, MODIFY, MONITOR, ALL
-	RoleType  string // "ROLE" or "DATABASE ROLE"
-	RoleName  string // role name (fully qualified for database roles)
+	Privilege   string // USAGE, MODIFY, MONITOR, ALL
+	RoleType    string // "ROLE" or "DATABASE ROLE"
+	RoleName    string // role name (fully qualified for database roles)
+	GrantOption bool   // granted WITH GRANT OPTION
+	// GrantOptionOnly marks a ToRevoke entry that removes only the grant
+	// option (REVOKE GRANT OPTION FOR) and keeps the privilege itself.
+	GrantOptionOnly bool
 }
 
 // GrantState represents the current grant state of an agent.
@@ -41,30 +45,38 @@ func entryKey(e GrantEntry) string {
 }
 
 // ComputeDiff compares desired state (from YAML) with current state (from SHOW GRANTS).
+// A privilege held on both sides with a different grant option is granted
+// again WITH GRANT OPTION, or has only its grant option revoked.
 func ComputeDiff(desired, current GrantState) GrantDiff {
 	// Build sets for comparison
-	desiredSet := make(map[string]bool)
+	desiredSet := make(map[string]GrantEntry)
 	for _, e := range desired.Entries {
-		desiredSet[entryKey(e)] = true
+		desiredSet[entryKey(e)] = e
 	}
 
-	currentSet := make(map[string]bool)
+	currentSet := make(map[string]GrantEntry)
 	for _, e := range current.Entries {
-		currentSet[entryKey(e)] = true
+		currentSet[entryKey(e)] = e
 	}
 
 	var diff GrantDiff
 
-	// Find entries to grant (in desired but not in current)
+	// Find entries to grant (in desired but not in current, or missing the grant option)
 	for _, e := range desired.Entries {
-		if !currentSet[entryKey(e)] {
+		cur, ok := currentSet[entryKey(e)]
+		if !ok || (e.GrantOption && !cur.GrantOption) {
 			diff.ToGrant = append(diff.ToGrant, e)
 		}
 	}
 
-	// Find entries to revoke (in current but not in desired)
+	// Find entries to revoke (in current but not in desired, or holding an unwanted grant option)
 	for _, e := range current.Entries {
-		if !desiredSet[entryKey(e)] {
+		want, ok := desiredSet[entryKey(e)]
+		switch {
+		case !ok:
+			diff.ToRevoke = append(diff.ToRevoke, e)
+		case e.GrantOption && !want.GrantOption:
+			e.GrantOptionOnly = true
 			diff.ToRevoke = append(diff.ToRevoke, e)
 		}
 	}
@@ -72,6 +84,32 @@ func ComputeDiff(desired, current GrantState) GrantDiff {
 	return diff
 }
 
+// DiffOptions controls DiffGrants.
+type DiffOptions struct {
+	// RevokeExtra revokes privileges that are granted on the agent but not
+	// listed in the desired config. Grant option downgrades of listed
+	// privileges are always included.
+	RevokeExtra bool
+}
+
+// DiffGrants compares the desired deploy.grant config with the rows returned
+// by SHOW GRANTS ON AGENT. It performs no I/O; plan and apply use the result
+// to show and execute the GRANT/REVOKE statements.
+func DiffGrants(desired *agent.GrantConfig, remote []ShowGrantsRow, opts DiffOptions) GrantDiff {
+	diff := ComputeDiff(FromGrantConfig(desired), FromShowGrantsRows(remote))
+	if opts.RevokeExtra {
+		return diff
+	}
+	var kept []GrantEntry
+	for _, e := range diff.ToRevoke {
+		if e.GrantOptionOnly {
+			kept = append(kept, e)
+		}
+	}
+	diff.ToRevoke = kept
+	return diff
+}
+
 // allPrivileges is the list of individual privileges that ALL expands to.
 var allPrivileges = []string{"USAGE", "MODIFY", "MONITOR"}
 
@@ -96,9 +134,10 @@ func FromGrantConfig(cfg *agent.GrantConfig) GrantState {
 		for _, priv := range rg.Privileges {
 			for _, expandedPriv := range expandPrivilege(priv) {
 				entries = append(entries, GrantEntry{
-					Privilege: expandedPriv,
-					RoleType:  "ROLE",
-					RoleName:  rg.Role,
+					Privilege:   expandedPriv,
+					RoleType:    "ROLE",
+					RoleName:    rg.Role,
+					GrantOption: rg.WithGrantOption,
 				})
 			}
 		}
@@ -108,9 +147,10 @@ func FromGrantConfig(cfg *agent.GrantConfig) GrantState {
 		for _, priv := range rg.Privileges {
 			for _, expandedPriv := range expandPrivilege(priv) {
 				entries = append(entries, GrantEntry{
-					Privilege: expandedPriv,
-					RoleType:  "DATABASE ROLE",
-					RoleName:  rg.Role,
+					Privilege:   expandedPriv,
+					RoleType:    "DATABASE ROLE",
+					RoleName:    rg.Role,
+					GrantOption: rg.WithGrantOption,
 				})
 			}
 		}
@@ -124,6 +164,7 @@ type ShowGrantsRow struct {
 	Privilege   string
 	GrantedTo   string // "ROLE" or "DATABASE_ROLE"
 	GranteeName string
+	GrantOption bool
 }
 
 // FromShowGrantsRows converts API response rows to GrantState.
@@ -143,9 +184,10 @@ func FromShowGrantsRows(rows []ShowGrantsRow) GrantState {
 		}
 
 		entries = append(entries, GrantEntry{
-			Privilege: row.Privilege,
-			RoleType:  roleType,
-			RoleName:  row.GranteeName,
+			Privilege:   row.Privilege,
+			RoleType:    roleType,
+			RoleName:    row.GranteeName,
+			GrantOption: row.GrantOption,
 		})
 	}
 
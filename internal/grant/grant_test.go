@@ -377,3 +377,83 @@ func TestGrantDiff_HasChanges(t *testing.T) {
 		})
 	}
 }
+
+func TestDiffGrants_NothingToDo(t *testing.T) {
+	desired := &agent.GrantConfig{
+		AccountRoles: []agent.RoleGrant{{Role: "ANALYST", Privileges: []string{"USAGE"}}},
+	}
+	remote := []ShowGrantsRow{
+		{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "ANALYST"},
+		{Privilege: "OWNERSHIP", GrantedTo: "ROLE", GranteeName: "SYSADMIN"},
+	}
+
+	diff := DiffGrants(desired, remote, DiffOptions{RevokeExtra: true})
+	if diff.HasChanges() {
+		t.Errorf("expected no changes, got %+v", diff)
+	}
+}
+
+func TestDiffGrants_MissingGrant(t *testing.T) {
+	desired := &agent.GrantConfig{
+		AccountRoles:  []agent.RoleGrant{{Role: "ANALYST", Privileges: []string{"USAGE"}}},
+		DatabaseRoles: []agent.RoleGrant{{Role: "DB.READER", Privileges: []string{"MONITOR"}}},
+	}
+	remote := []ShowGrantsRow{
+		{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "ANALYST"},
+	}
+
+	diff := DiffGrants(desired, remote, DiffOptions{})
+	want := GrantEntry{Privilege: "MONITOR", RoleType: "DATABASE ROLE", RoleName: "DB.READER"}
+	if len(diff.ToGrant) != 1 || diff.ToGrant[0] != want {
+		t.Errorf("ToGrant = %+v, want [%+v]", diff.ToGrant, want)
+	}
+	if len(diff.ToRevoke) != 0 {
+		t.Errorf("expected no revokes, got %+v", diff.ToRevoke)
+	}
+}
+
+func TestDiffGrants_ExtraGrantRevokedOnlyWithRevokeExtra(t *testing.T) {
+	desired := &agent.GrantConfig{
+		AccountRoles: []agent.RoleGrant{{Role: "ANALYST", Privileges: []string{"USAGE"}}},
+	}
+	remote := []ShowGrantsRow{
+		{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "ANALYST"},
+		{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "OLD_ROLE"},
+	}
+
+	if diff := DiffGrants(desired, remote, DiffOptions{}); diff.HasChanges() {
+		t.Errorf("expected extra grant to be kept without RevokeExtra, got %+v", diff)
+	}
+
+	diff := DiffGrants(desired, remote, DiffOptions{RevokeExtra: true})
+	want := GrantEntry{Privilege: "USAGE", RoleType: "ROLE", RoleName: "OLD_ROLE"}
+	if len(diff.ToRevoke) != 1 || diff.ToRevoke[0] != want {
+		t.Errorf("ToRevoke = %+v, want [%+v]", diff.ToRevoke, want)
+	}
+	if len(diff.ToGrant) != 0 {
+		t.Errorf("expected no grants, got %+v", diff.ToGrant)
+	}
+}
+
+func TestDiffGrants_GrantOptionChange(t *testing.T) {
+	desired := &agent.GrantConfig{
+		AccountRoles: []agent.RoleGrant{
+			{Role: "ADMIN", Privileges: []string{"USAGE"}, WithGrantOption: true},
+			{Role: "ANALYST", Privileges: []string{"USAGE"}},
+		},
+	}
+	remote := []ShowGrantsRow{
+		{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "ADMIN"},
+		{Privilege: "USAGE", GrantedTo: "ROLE", GranteeName: "ANALYST", GrantOption: true},
+	}
+
+	diff := DiffGrants(desired, remote, DiffOptions{})
+	wantGrant := GrantEntry{Privilege: "USAGE", RoleType: "ROLE", RoleName: "ADMIN", GrantOption: true}
+	if len(diff.ToGrant) != 1 || diff.ToGrant[0] != wantGrant {
+		t.Errorf("ToGrant = %+v, want [%+v]", diff.ToGrant, wantGrant)
+	}
+	wantRevoke := GrantEntry{Privilege: "USAGE", RoleType: "ROLE", RoleName: "ANALYST", GrantOption: true, GrantOptionOnly: true}
+	if len(diff.ToRevoke) != 1 || diff.ToRevoke[0] != wantRevoke {
+		t.Errorf("ToRevoke = %+v, want [%+v]", diff.ToRevoke, wantRevoke)
+	}
+}
@@ -141,3 +141,79 @@ func TestLifecycle_MultipleAgents(t *testing.T) {
 		t.Error("expected agent-a to still exist")
 	}
 }
+
+// TestLifecycle_ListAgentsMemoized verifies that repeated ListAgents calls on
+// one client reuse the first SHOW AGENTS result until an agent is created or
+// deleted through that client.
+func TestLifecycle_ListAgentsMemoized(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: "agent-a"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		listed, err := client.ListAgents(ctx, testDB, testSchema)
+		if err != nil {
+			t.Fatalf("ListAgents: %v", err)
+		}
+		if len(listed) != 1 {
+			t.Fatalf("ListAgents = %d agents, want 1", len(listed))
+		}
+	}
+	if got := ms.ShowAgentsCalls(); got != 1 {
+		t.Fatalf("SHOW AGENTS calls = %d, want 1 (second ListAgents should be memoized)", got)
+	}
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: "agent-b"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	listed, err := client.ListAgents(ctx, testDB, testSchema)
+	if err != nil {
+		t.Fatalf("ListAgents after create: %v", err)
+	}
+	if len(listed) != 2 {
+		t.Errorf("ListAgents after create = %d agents, want 2", len(listed))
+	}
+	if got := ms.ShowAgentsCalls(); got != 2 {
+		t.Errorf("SHOW AGENTS calls = %d, want 2 after create invalidated the cache", got)
+	}
+
+	if err := client.DeleteAgent(ctx, testDB, testSchema, "agent-a"); err != nil {
+		t.Fatalf("DeleteAgent: %v", err)
+	}
+	listed, err = client.ListAgents(ctx, testDB, testSchema)
+	if err != nil {
+		t.Fatalf("ListAgents after delete: %v", err)
+	}
+	if len(listed) != 1 || listed[0].Name != "agent-b" {
+		t.Errorf("ListAgents after delete = %+v, want only agent-b", listed)
+	}
+}
+
+// TestLifecycle_DeleteAgentIfExists verifies that deleting a missing agent
+// fails with DeleteAgent but succeeds with DeleteAgentIfExists.
+func TestLifecycle_DeleteAgentIfExists(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	if err := client.DeleteAgent(ctx, testDB, testSchema, "missing-agent"); err == nil {
+		t.Fatal("DeleteAgent on a missing agent: expected error, got nil")
+	}
+	if err := client.DeleteAgentIfExists(ctx, testDB, testSchema, "missing-agent"); err != nil {
+		t.Fatalf("DeleteAgentIfExists on a missing agent: %v", err)
+	}
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: "present-agent"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	if err := client.DeleteAgentIfExists(ctx, testDB, testSchema, "present-agent"); err != nil {
+		t.Fatalf("DeleteAgentIfExists: %v", err)
+	}
+	if _, exists, err := client.GetAgent(ctx, testDB, testSchema, "present-agent"); err != nil || exists {
+		t.Fatalf("GetAgent after delete: exists=%v err=%v, want deleted", exists, err)
+	}
+}
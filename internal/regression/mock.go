@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"encoding/pem"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -39,10 +40,12 @@ func (s *AgentStore) get(name string) (map[string]any, bool) {
 	return v, ok
 }
 
-func (s *AgentStore) del(name string) {
+func (s *AgentStore) del(name string) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	_, ok := s.agents[name]
 	delete(s.agents, name)
+	return ok
 }
 
 func (s *AgentStore) list() []map[string]any {
@@ -58,40 +61,81 @@ func (s *AgentStore) list() []map[string]any {
 // sqlStatementResponse mirrors the Snowflake SQL Statement API response.
 type sqlStatementResponse struct {
 	Data              [][]any `json:"data"`
+	StatementHandle   string  `json:"statementHandle,omitempty"`
 	ResultSetMetaData struct {
 		RowType []struct {
 			Name string `json:"name"`
 		} `json:"rowType"`
+		PartitionInfo []map[string]int `json:"partitionInfo,omitempty"`
 	} `json:"resultSetMetaData"`
 }
 
 // MockServer is a test HTTP server that simulates the Snowflake Cortex Agent API.
 type MockServer struct {
-	srv      *httptest.Server
-	store    *AgentStore
-	grants   map[string][]string // agentKey → []"PRIVILEGE:GRANTED_TO:GRANTEE_NAME"
-	runReply map[string]string   // agentKey → raw SSE body to stream on :run
-	threads  map[string]map[string]any
-	nextTID  int64
-	mu       sync.Mutex
+	srv             *httptest.Server
+	store           *AgentStore
+	grants          map[string][]string // agentKey → []"PRIVILEGE:GRANTED_TO:GRANTEE_NAME"
+	runReply        map[string]string   // agentKey → raw SSE body to stream on :run
+	runStall        map[string]bool     // agentKey → hold the :run connection open after the body
+	runRequests     map[string][]byte   // agentKey → body of the most recent :run request
+	runConflicts    map[string]int      // agentKey → :run calls still to reject with 409 Conflict
+	schemas         map[string]string   // agentKey → schema the agent was created in
+	threads         map[string]map[string]any
+	nextTID         int64
+	async           map[string]*asyncStatement // statementHandle → submitted async statement
+	nextSID         int64
+	partitions      map[string][][][]any // statementHandle → rows of each result partition
+	pageSize        int                  // rows per SHOW AGENTS partition; 0 returns one partition
+	models          []string             // names returned by SHOW MODELS IN SCHEMA SNOWFLAKE.MODELS
+	showAgentsCalls int
+	requests        int
+	storeHook       func(payload map[string]any) // applied to created/updated payloads before storing
+	noRename        bool                         // reject ALTER AGENT ... RENAME as an unsupported feature
+	mu              sync.Mutex
+}
+
+// MockOwner and MockCreatedOn are the owner and created_on values the mock
+// reports for every agent in SHOW AGENTS.
+const (
+	MockOwner     = "SYSADMIN"
+	MockCreatedOn = "2025-01-15 09:30:00.000 -0800"
+)
+
+// asyncStatement is a statement submitted with async=true. The first status
+// poll reports it as still running; later polls return the stored result.
+type asyncStatement struct {
+	polls  int
+	result []byte
 }
 
 // NewMockServer creates and starts a MockServer. The caller must call Close() when done.
 func NewMockServer(t *testing.T) *MockServer {
 	t.Helper()
 	ms := &MockServer{
-		store:    newAgentStore(),
-		grants:   make(map[string][]string),
-		runReply: make(map[string]string),
-		threads:  make(map[string]map[string]any),
-		nextTID:  1,
+		store:       newAgentStore(),
+		grants:      make(map[string][]string),
+		runReply:    make(map[string]string),
+		runStall:    make(map[string]bool),
+		runRequests: make(map[string][]byte),
+		schemas:     make(map[string]string),
+		threads:     make(map[string]map[string]any),
+		nextTID:     1,
+		async:       make(map[string]*asyncStatement),
+		partitions:  make(map[string][][][]any),
+		nextSID:     1,
 	}
 	mux := http.NewServeMux()
 	mux.HandleFunc("/api/v2/statements", ms.handleSQL)
+	mux.HandleFunc("/api/v2/statements/", ms.handleStatementStatus)
 	mux.HandleFunc("/api/v2/databases/", ms.handleAgents)
 	mux.HandleFunc("/api/v2/cortex/threads", ms.handleThreads)
 	mux.HandleFunc("/api/v2/cortex/threads/", ms.handleThread)
-	ms.srv = httptest.NewServer(mux)
+	ms.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms.mu.Lock()
+		ms.requests++
+		ms.mu.Unlock()
+		mux.ServeHTTP(w, r)
+	}))
 	t.Cleanup(ms.srv.Close)
 	return ms
 }
@@ -101,6 +145,36 @@ func (ms *MockServer) URL() string {
 	return ms.srv.URL
 }
 
+// SetShowAgentsPageSize splits SHOW AGENTS results into partitions of n rows,
+// the way Snowflake pages large results. Only the first partition is returned
+// inline; the rest are served by GET /api/v2/statements/{handle}?partition=N.
+func (ms *MockServer) SetShowAgentsPageSize(n int) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.pageSize = n
+}
+
+// ShowAgentsCalls returns how many SHOW AGENTS statements the server has handled.
+func (ms *MockServer) ShowAgentsCalls() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.showAgentsCalls
+}
+
+// Requests returns how many HTTP requests the server has received.
+func (ms *MockServer) Requests() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.requests
+}
+
+// SetModels sets the model names returned by SHOW MODELS.
+func (ms *MockServer) SetModels(names ...string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.models = names
+}
+
 // SetGrants sets the grants for an agent (used to prime the store for test scenarios).
 // Each entry is "PRIVILEGE:GRANTED_TO:GRANTEE_NAME" (e.g., "USAGE:ROLE:MY_ROLE").
 func (ms *MockServer) SetGrants(agentKey string, grants []string) {
@@ -117,6 +191,61 @@ func (ms *MockServer) SetRunReply(agentName, sseBody string) {
 	ms.runReply[agentName] = sseBody
 }
 
+// SetRunStall registers an SSE body that is flushed to the client after which
+// the :run connection is held open without further data until the client
+// disconnects. It simulates a half-open stream.
+func (ms *MockServer) SetRunStall(agentName, sseBody string) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.runReply[agentName] = sseBody
+	ms.runStall[agentName] = true
+}
+
+// SetRunConflicts makes the next n :run calls for the given agent name fail
+// with 409 Conflict, as Snowflake does while another run is in progress on
+// the thread. Later calls get the configured reply.
+func (ms *MockServer) SetRunConflicts(agentName string, n int) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.runConflicts == nil {
+		ms.runConflicts = make(map[string]int)
+	}
+	ms.runConflicts[agentName] = n
+}
+
+// SetStoreHook registers fn to modify every created or updated agent payload
+// before it is stored, simulating a server that normalizes or ignores fields.
+func (ms *MockServer) SetStoreHook(fn func(payload map[string]any)) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.storeHook = fn
+}
+
+// SetRenameUnsupported makes ALTER AGENT ... RENAME TO fail with Snowflake's
+// unsupported-feature error, simulating an account without server-side rename.
+func (ms *MockServer) SetRenameUnsupported(unsupported bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.noRename = unsupported
+}
+
+func (ms *MockServer) applyStoreHook(payload map[string]any) {
+	ms.mu.Lock()
+	hook := ms.storeHook
+	ms.mu.Unlock()
+	if hook != nil {
+		hook(payload)
+	}
+}
+
+// LastRunRequest returns the JSON body of the most recent :run request for
+// the given agent name, or nil if it has not been run.
+func (ms *MockServer) LastRunRequest(agentName string) []byte {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.runRequests[agentName]
+}
+
 // BuildSSEReply constructs a minimal SSE stream that delivers textReply as a
 // text response with an optional list of tool names called before the final text.
 func BuildSSEReply(textReply string, toolNames ...string) string {
@@ -140,6 +269,30 @@ func BuildSSEReply(textReply string, toolNames ...string) string {
 	return b.String()
 }
 
+// BuildSSEErrorReply constructs an SSE stream that delivers partialText as a
+// text delta and then fails with a response.error event carrying code and message.
+func BuildSSEErrorReply(partialText, code, message string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: response.status\ndata: {\"status\":\"running\",\"message\":\"\",\"sequence_number\":1}\n\n")
+	if partialText != "" {
+		fmt.Fprintf(&b, "event: response.text.delta\ndata: {\"text\":%q,\"content_index\":0,\"sequence_number\":2}\n\n", partialText)
+	}
+	fmt.Fprintf(&b, "event: response.error\ndata: {\"code\":%q,\"message\":%q,\"request_id\":\"mock-request\"}\n\n", code, message)
+	return b.String()
+}
+
+// BuildSSEBudgetStopReply constructs an SSE stream that delivers partialText
+// and then completes with a final response event whose stop_reason reports
+// that the orchestration budget was exhausted.
+func BuildSSEBudgetStopReply(partialText string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "event: response.status\ndata: {\"status\":\"running\",\"message\":\"\",\"sequence_number\":1}\n\n")
+	fmt.Fprintf(&b, "event: response.text.delta\ndata: {\"text\":%q,\"content_index\":0,\"sequence_number\":2}\n\n", partialText)
+	fmt.Fprintf(&b, "event: metadata\ndata: {\"metadata\":{\"thread_id\":\"mock-thread\",\"message_id\":1,\"role\":\"assistant\"}}\n\n")
+	fmt.Fprintf(&b, "event: response\ndata: {\"content\":[{\"type\":\"text\",\"text\":%q}],\"stop_reason\":\"budget_exhausted\"}\n\n", partialText)
+	return b.String()
+}
+
 func (ms *MockServer) handleSQL(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -155,15 +308,83 @@ func (ms *MockServer) handleSQL(w http.ResponseWriter, r *http.Request) {
 	}
 
 	stmt := strings.TrimSpace(req.Statement)
+
+	if r.URL.Query().Get("async") == "true" {
+		rec := httptest.NewRecorder()
+		ms.dispatchSQL(rec, stmt)
+		ms.mu.Lock()
+		handle := fmt.Sprintf("mock-stmt-%d", ms.nextSID)
+		ms.nextSID++
+		ms.async[handle] = &asyncStatement{result: rec.Body.Bytes()}
+		ms.mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"code":               "333334",
+			"statementHandle":    handle,
+			"statementStatusUrl": "/api/v2/statements/" + handle,
+		})
+		return
+	}
+	ms.dispatchSQL(w, stmt)
+}
+
+// handleStatementStatus serves GET /api/v2/statements/{handle} for statements
+// submitted asynchronously.
+func (ms *MockServer) handleStatementStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	handle := strings.TrimPrefix(r.URL.Path, "/api/v2/statements/")
+	if partition := r.URL.Query().Get("partition"); partition != "" {
+		ms.handlePartition(w, handle, partition)
+		return
+	}
+
+	ms.mu.Lock()
+	st, ok := ms.async[handle]
+	var polls int
+	if ok {
+		st.polls++
+		polls = st.polls
+	}
+	ms.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":"000709","message":"Statement not found"}`))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if polls == 1 {
+		w.WriteHeader(http.StatusAccepted)
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"code":               "333334",
+			"statementHandle":    handle,
+			"statementStatusUrl": "/api/v2/statements/" + handle,
+		})
+		return
+	}
+	_, _ = w.Write(st.result)
+}
+
+func (ms *MockServer) dispatchSQL(w http.ResponseWriter, stmt string) {
 	upper := strings.ToUpper(stmt)
 
 	switch {
 	case strings.HasPrefix(upper, "DESCRIBE AGENT "):
 		ms.handleDescribeAgent(w, stmt)
+	case strings.HasPrefix(upper, "ALTER AGENT ") && strings.Contains(upper, " RENAME TO "):
+		ms.handleRenameAgent(w, stmt)
 	case strings.HasPrefix(upper, "SHOW AGENTS IN SCHEMA "):
-		ms.handleShowAgents(w)
+		ms.handleShowAgents(w, "")
+	case strings.HasPrefix(upper, "SHOW AGENTS IN DATABASE "):
+		ms.handleShowAgents(w, stripQuotes(strings.Fields(stmt)[4]))
 	case strings.HasPrefix(upper, "SHOW GRANTS ON AGENT "):
 		ms.handleShowGrants(w, stmt)
+	case strings.HasPrefix(upper, "SHOW MODELS "):
+		ms.handleShowModels(w)
 	case strings.HasPrefix(upper, "GRANT "):
 		ms.handleGrant(w, stmt, true)
 	case strings.HasPrefix(upper, "REVOKE "):
@@ -207,7 +428,85 @@ func (ms *MockServer) handleDescribeAgent(w http.ResponseWriter, stmt string) {
 	writeJSON(w, resp)
 }
 
-func (ms *MockServer) handleShowAgents(w http.ResponseWriter) {
+func (ms *MockServer) handleRenameAgent(w http.ResponseWriter, stmt string) {
+	// Parse: ALTER AGENT <fq-old> RENAME TO <fq-new>
+	parts := strings.Fields(stmt)
+	if len(parts) < 6 {
+		writeJSON(w, sqlStatementResponse{})
+		return
+	}
+	oldSegs := strings.Split(parts[2], ".")
+	newSegs := strings.Split(parts[5], ".")
+	oldName := stripQuotes(oldSegs[len(oldSegs)-1])
+	newName := stripQuotes(newSegs[len(newSegs)-1])
+
+	ms.mu.Lock()
+	noRename := ms.noRename
+	ms.mu.Unlock()
+	if noRename {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":"000002","message":"SQL compilation error: Unsupported feature 'ALTER AGENT RENAME'."}`)) //nolint:errcheck
+		return
+	}
+
+	payload, ok := ms.store.get(oldName)
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if _, exists := ms.store.get(newName); exists {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"code":"002002","message":"SQL compilation error: Object '` + newName + `' already exists."}`)) //nolint:errcheck
+		return
+	}
+
+	payload["name"] = newName
+	ms.store.del(oldName)
+	ms.store.set(newName, payload)
+
+	ms.mu.Lock()
+	if schema, ok := ms.schemas[oldName]; ok {
+		ms.schemas[newName] = schema
+		delete(ms.schemas, oldName)
+	}
+	if grants, ok := ms.grants[oldName]; ok {
+		ms.grants[newName] = grants
+		delete(ms.grants, oldName)
+	}
+	ms.mu.Unlock()
+
+	writeJSON(w, sqlStatementResponse{})
+}
+
+// handleShowModels answers SHOW MODELS with the names set by SetModels.
+func (ms *MockServer) handleShowModels(w http.ResponseWriter) {
+	ms.mu.Lock()
+	names := ms.models
+	ms.mu.Unlock()
+	var resp sqlStatementResponse
+	resp.ResultSetMetaData.RowType = []struct {
+		Name string `json:"name"`
+	}{
+		{Name: "created_on"},
+		{Name: "name"},
+		{Name: "model_type"},
+	}
+	resp.Data = make([][]any, 0, len(names))
+	for _, name := range names {
+		resp.Data = append(resp.Data, []any{MockCreatedOn, name, "CORTEX_BASE"})
+	}
+	writeJSON(w, resp)
+}
+
+// handleShowAgents answers SHOW AGENTS. A non-empty db marks the
+// SHOW AGENTS IN DATABASE form, whose rows also carry database_name and
+// schema_name.
+func (ms *MockServer) handleShowAgents(w http.ResponseWriter, db string) {
+	ms.mu.Lock()
+	ms.showAgentsCalls++
+	ms.mu.Unlock()
 	list := ms.store.list()
 	var resp sqlStatementResponse
 	resp.ResultSetMetaData.RowType = []struct {
@@ -215,16 +514,68 @@ func (ms *MockServer) handleShowAgents(w http.ResponseWriter) {
 	}{
 		{Name: "name"},
 		{Name: "comment"},
+		{Name: "owner"},
+		{Name: "created_on"},
+	}
+	if db != "" {
+		resp.ResultSetMetaData.RowType = append(resp.ResultSetMetaData.RowType,
+			struct {
+				Name string `json:"name"`
+			}{Name: "database_name"},
+			struct {
+				Name string `json:"name"`
+			}{Name: "schema_name"})
 	}
 	resp.Data = make([][]any, 0, len(list))
 	for _, payload := range list {
 		name, _ := payload["name"].(string)
 		comment, _ := payload["comment"].(string)
-		resp.Data = append(resp.Data, []any{name, comment})
+		row := []any{name, comment, MockOwner, MockCreatedOn}
+		if db != "" {
+			ms.mu.Lock()
+			schema := ms.schemas[name]
+			ms.mu.Unlock()
+			row = append(row, db, schema)
+		}
+		resp.Data = append(resp.Data, row)
+	}
+
+	ms.mu.Lock()
+	size := ms.pageSize
+	if size > 0 && len(resp.Data) > size {
+		var pages [][][]any
+		for start := 0; start < len(resp.Data); start += size {
+			pages = append(pages, resp.Data[start:min(start+size, len(resp.Data))])
+			resp.ResultSetMetaData.PartitionInfo = append(resp.ResultSetMetaData.PartitionInfo,
+				map[string]int{"rowCount": len(pages[len(pages)-1])})
+		}
+		resp.StatementHandle = fmt.Sprintf("mock-stmt-%d", ms.nextSID)
+		ms.nextSID++
+		ms.partitions[resp.StatementHandle] = pages
+		resp.Data = pages[0]
 	}
+	ms.mu.Unlock()
 	writeJSON(w, resp)
 }
 
+// handlePartition serves one result partition of a paged statement.
+func (ms *MockServer) handlePartition(w http.ResponseWriter, handle, partition string) {
+	var idx int
+	if _, err := fmt.Sscanf(partition, "%d", &idx); err != nil {
+		http.Error(w, "bad partition", http.StatusBadRequest)
+		return
+	}
+	ms.mu.Lock()
+	pages, ok := ms.partitions[handle]
+	ms.mu.Unlock()
+	if !ok || idx < 0 || idx >= len(pages) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code":"000709","message":"Statement not found"}`))
+		return
+	}
+	writeJSON(w, sqlStatementResponse{Data: pages[idx]})
+}
+
 func (ms *MockServer) handleShowGrants(w http.ResponseWriter, stmt string) {
 	parts := strings.Fields(stmt)
 	if len(parts) < 5 {
@@ -383,7 +734,13 @@ func (ms *MockServer) handleAgents(w http.ResponseWriter, r *http.Request) {
 		name, _ := payload["name"].(string)
 		name = stripQuotes(name)   // client may send SQL-quoted names
 		payload["name"] = name     // normalize name in stored payload
+		ms.applyStoreHook(payload)
 		ms.store.set(name, payload)
+		if len(parts) >= 3 {
+			ms.mu.Lock()
+			ms.schemas[name] = stripQuotes(parts[2])
+			ms.mu.Unlock()
+		}
 		w.WriteHeader(http.StatusOK)
 		writeJSON(w, payload)
 	case http.MethodPut:
@@ -397,6 +754,7 @@ func (ms *MockServer) handleAgents(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "missing name", http.StatusBadRequest)
 			return
 		}
+		ms.applyStoreHook(payload)
 		ms.store.set(agentName, payload)
 		w.WriteHeader(http.StatusOK)
 	case http.MethodDelete:
@@ -404,7 +762,10 @@ func (ms *MockServer) handleAgents(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "missing name", http.StatusBadRequest)
 			return
 		}
-		ms.store.del(agentName)
+		if !ms.store.del(agentName) {
+			writeNotFound(w)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	default:
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
@@ -415,13 +776,17 @@ func (ms *MockServer) handleAgents(w http.ResponseWriter, r *http.Request) {
 func (ms *MockServer) handleThreads(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
+		var body struct {
+			ThreadName string `json:"thread_name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
 		ms.mu.Lock()
 		id := fmt.Sprintf("%d", ms.nextTID)
 		ms.nextTID++
 		now := int64(1000000) // fake epoch ms
 		t := map[string]any{
 			"thread_id":          id,
-			"thread_name":        "",
+			"thread_name":        body.ThreadName,
 			"origin_application": "coragent",
 			"created_on":         now,
 			"updated_on":         now,
@@ -467,11 +832,25 @@ func (ms *MockServer) handleThread(w http.ResponseWriter, r *http.Request) {
 
 // handleRun serves the agent :run streaming endpoint.
 // It returns the pre-registered SSE body for the agent, or an empty response.
-func (ms *MockServer) handleRun(w http.ResponseWriter, _ *http.Request, agentName string) {
+func (ms *MockServer) handleRun(w http.ResponseWriter, r *http.Request, agentName string) {
+	reqBody, _ := io.ReadAll(r.Body)
 	ms.mu.Lock()
+	ms.runRequests[agentName] = reqBody
 	body, ok := ms.runReply[agentName]
+	stall := ms.runStall[agentName]
+	conflict := ms.runConflicts[agentName] > 0
+	if conflict {
+		ms.runConflicts[agentName]--
+	}
 	ms.mu.Unlock()
 
+	if conflict {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"message":"A request is already running on this thread"}`))
+		return
+	}
 	if !ok {
 		http.Error(w, "no run reply configured", http.StatusServiceUnavailable)
 		return
@@ -480,6 +859,12 @@ func (ms *MockServer) handleRun(w http.ResponseWriter, _ *http.Request, agentNam
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.WriteHeader(http.StatusOK)
 	_, _ = fmt.Fprint(w, body)
+	if stall {
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		<-r.Context().Done()
+	}
 }
 
 // TestRSAPEM generates a PKCS8 RSA private key PEM for use in tests.
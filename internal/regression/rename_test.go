@@ -0,0 +1,108 @@
+package regression_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/regression"
+)
+
+// TestRename_MovesAgent verifies that after RenameAgent the new name can be
+// described and the old name no longer exists.
+func TestRename_MovesAgent(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	spec := agent.AgentSpec{Name: "old-agent", Comment: "rename me"}
+	if err := client.CreateAgent(ctx, testDB, testSchema, spec); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetGrants("old-agent", []string{"USAGE:ROLE:ANALYST"})
+
+	if err := client.RenameAgent(ctx, testDB, testSchema, "old-agent", "new-agent"); err != nil {
+		t.Fatalf("RenameAgent: %v", err)
+	}
+
+	got, exists, err := client.GetAgent(ctx, testDB, testSchema, "new-agent")
+	if err != nil {
+		t.Fatalf("GetAgent (new): %v", err)
+	}
+	if !exists {
+		t.Fatal("expected renamed agent to exist")
+	}
+	if got.Name != "new-agent" {
+		t.Errorf("GetAgent.Name = %q, want %q", got.Name, "new-agent")
+	}
+	if got.Comment != spec.Comment {
+		t.Errorf("GetAgent.Comment = %q, want %q", got.Comment, spec.Comment)
+	}
+
+	_, exists, err = client.GetAgent(ctx, testDB, testSchema, "old-agent")
+	if err != nil {
+		t.Fatalf("GetAgent (old): %v", err)
+	}
+	if exists {
+		t.Error("expected old agent name to no longer exist")
+	}
+
+	grants, err := client.ShowGrants(ctx, testDB, testSchema, "new-agent")
+	if err != nil {
+		t.Fatalf("ShowGrants: %v", err)
+	}
+	if len(grants) != 1 {
+		t.Errorf("expected grants to follow the rename, got %v", grants)
+	}
+}
+
+func TestRename_NotFound(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+
+	err := client.RenameAgent(context.Background(), testDB, testSchema, "missing", "new-agent")
+	if !errors.Is(err, api.ErrAgentNotFound) {
+		t.Fatalf("expected ErrAgentNotFound, got %v", err)
+	}
+}
+
+func TestRename_TargetAlreadyExists(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	for _, name := range []string{"agent-a", "agent-b"} {
+		if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: name}); err != nil {
+			t.Fatalf("CreateAgent %s: %v", name, err)
+		}
+	}
+
+	err := client.RenameAgent(ctx, testDB, testSchema, "agent-a", "agent-b")
+	if !errors.Is(err, api.ErrAgentAlreadyExists) {
+		t.Fatalf("expected ErrAgentAlreadyExists, got %v", err)
+	}
+	if _, exists, _ := client.GetAgent(ctx, testDB, testSchema, "agent-a"); !exists {
+		t.Error("expected source agent to remain after failed rename")
+	}
+}
+
+func TestRename_Unsupported(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	ms.SetRenameUnsupported(true)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: "old-agent"}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+
+	err := client.RenameAgent(ctx, testDB, testSchema, "old-agent", "new-agent")
+	if !errors.Is(err, api.ErrRenameUnsupported) {
+		t.Fatalf("expected ErrRenameUnsupported, got %v", err)
+	}
+	if _, exists, _ := client.GetAgent(ctx, testDB, testSchema, "old-agent"); !exists {
+		t.Error("expected agent to keep its name after an unsupported rename")
+	}
+}
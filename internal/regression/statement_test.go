@@ -0,0 +1,98 @@
+package regression_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/regression"
+)
+
+// TestStatement_SubmitAndFetch verifies that a statement submitted
+// asynchronously returns a handle immediately and that its rows can be
+// fetched later with that handle once execution completes.
+func TestStatement_SubmitAndFetch(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	for _, name := range []string{"agent-a", "agent-b"} {
+		if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: name}); err != nil {
+			t.Fatalf("CreateAgent %s: %v", name, err)
+		}
+	}
+
+	handle, err := client.SubmitSQL(ctx, testDB, testSchema, "SHOW AGENTS IN SCHEMA "+testDB+"."+testSchema)
+	if err != nil {
+		t.Fatalf("SubmitSQL: %v", err)
+	}
+	if handle == "" {
+		t.Fatal("expected non-empty statement handle")
+	}
+
+	// First poll: the mock still reports the statement as running.
+	if _, err := client.FetchResult(ctx, handle); !errors.Is(err, api.ErrStatementInProgress) {
+		t.Fatalf("FetchResult (first) error = %v, want ErrStatementInProgress", err)
+	}
+
+	// Second poll: rows are available.
+	res, err := client.FetchResult(ctx, handle)
+	if err != nil {
+		t.Fatalf("FetchResult (second): %v", err)
+	}
+	if res.StatementHandle != handle {
+		t.Errorf("StatementHandle = %q, want %q", res.StatementHandle, handle)
+	}
+	if strings.Join(res.Columns, ",") != "name,comment,owner,created_on" {
+		t.Errorf("Columns = %v, want [name comment owner created_on]", res.Columns)
+	}
+	if len(res.Rows) != 2 {
+		t.Errorf("Rows = %d, want 2", len(res.Rows))
+	}
+}
+
+// TestStatement_FetchUnknownHandle verifies that fetching an unknown handle
+// surfaces the API error.
+func TestStatement_FetchUnknownHandle(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+
+	_, err := client.FetchResult(context.Background(), "does-not-exist")
+	var apiErr api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 {
+		t.Fatalf("FetchResult error = %v, want 404 APIError", err)
+	}
+}
+
+// TestStatement_FetchPagedResult verifies that FetchResult returns the rows
+// of every result partition, not just the first.
+func TestStatement_FetchPagedResult(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	ms.SetShowAgentsPageSize(2)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	for _, name := range []string{"agent-a", "agent-b", "agent-c", "agent-d", "agent-e"} {
+		if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: name}); err != nil {
+			t.Fatalf("CreateAgent %s: %v", name, err)
+		}
+	}
+
+	handle, err := client.SubmitSQL(ctx, testDB, testSchema, "SHOW AGENTS IN SCHEMA "+testDB+"."+testSchema)
+	if err != nil {
+		t.Fatalf("SubmitSQL: %v", err)
+	}
+	if _, err := client.FetchResult(ctx, handle); !errors.Is(err, api.ErrStatementInProgress) {
+		t.Fatalf("FetchResult (first) error = %v, want ErrStatementInProgress", err)
+	}
+	res, err := client.FetchResult(ctx, handle)
+	if err != nil {
+		t.Fatalf("FetchResult (second): %v", err)
+	}
+	if len(res.Rows) != 5 {
+		t.Errorf("Rows = %d, want 5 across all partitions", len(res.Rows))
+	}
+}
@@ -0,0 +1,221 @@
+package regression_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"coragent/internal/agent"
+	"coragent/internal/api"
+	"coragent/internal/regression"
+)
+
+// TestRun_StalledStreamTimesOut verifies that RunAgent gives up on a stream
+// that delivers one event and then goes silent, returning an
+// IncompleteStreamError instead of hanging.
+func TestRun_StalledStreamTimesOut(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	const agentName = "stall-agent"
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: agentName}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunStall(agentName, "event: response.text.delta\ndata: {\"text\":\"partial\",\"content_index\":0,\"sequence_number\":1}\n\n")
+
+	var got string
+	start := time.Now()
+	_, err := client.RunAgent(ctx, testDB, testSchema, agentName, api.RunAgentRequest{
+		Messages: []api.Message{api.NewTextMessage("user", "hello")},
+	}, api.RunAgentOptions{
+		StreamIdleTimeout: 200 * time.Millisecond,
+		OnTextDelta:       func(d string) { got += d },
+	})
+
+	var incomplete *api.IncompleteStreamError
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("RunAgent error = %v, want IncompleteStreamError", err)
+	}
+	if incomplete.IdleTimeout != 200*time.Millisecond {
+		t.Errorf("IdleTimeout = %s, want 200ms", incomplete.IdleTimeout)
+	}
+	if got != "partial" {
+		t.Errorf("text before stall = %q, want %q", got, "partial")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RunAgent took %s, expected to abort shortly after the idle timeout", elapsed)
+	}
+}
+
+// TestRun_ResponseErrorEvent verifies that a response.error event mid-stream
+// is returned as an AgentRunError carrying the server code, message and the
+// text streamed before the failure.
+func TestRun_ResponseErrorEvent(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	const agentName = "error-agent"
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: agentName}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunReply(agentName, regression.BuildSSEErrorReply("Half an ans", "399504", "warehouse suspended"))
+
+	var got string
+	_, err := client.RunAgent(ctx, testDB, testSchema, agentName, api.RunAgentRequest{
+		Messages: []api.Message{api.NewTextMessage("user", "hello")},
+	}, api.RunAgentOptions{
+		OnTextDelta: func(d string) { got += d },
+	})
+
+	var runErr *api.AgentRunError
+	if !errors.As(err, &runErr) {
+		t.Fatalf("RunAgent error = %v, want AgentRunError", err)
+	}
+	if runErr.Code != "399504" || runErr.Message != "warehouse suspended" {
+		t.Errorf("AgentRunError = %+v, want code 399504 and server message", runErr)
+	}
+	if runErr.RequestID != "mock-request" {
+		t.Errorf("RequestID = %q, want mock-request", runErr.RequestID)
+	}
+	if runErr.PartialText != "Half an ans" {
+		t.Errorf("PartialText = %q, want %q", runErr.PartialText, "Half an ans")
+	}
+	if got != "Half an ans" {
+		t.Errorf("streamed text = %q, want partial text delivered before the error", got)
+	}
+}
+
+// TestRun_BudgetStopSetsStopReason verifies that a final response event with
+// a budget stop reason is reported through ResponseEvent.BudgetExhausted.
+func TestRun_BudgetStopSetsStopReason(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	const agentName = "budget-agent"
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: agentName}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunReply(agentName, regression.BuildSSEBudgetStopReply("The first half"))
+
+	resp, err := client.RunAgent(ctx, testDB, testSchema, agentName, api.RunAgentRequest{
+		Messages: []api.Message{api.NewTextMessage("user", "hello")},
+	}, api.RunAgentOptions{})
+	if err != nil {
+		t.Fatalf("RunAgent: %v", err)
+	}
+	if resp.StopReason != api.StopReasonBudgetExhausted || !resp.BudgetExhausted() {
+		t.Errorf("StopReason = %q, want %q", resp.StopReason, api.StopReasonBudgetExhausted)
+	}
+
+	ms.SetRunReply(agentName, "event: response\ndata: {\"content\":[{\"type\":\"text\",\"text\":\"done\"}]}\n\n")
+	resp, err = client.RunAgent(ctx, testDB, testSchema, agentName, api.RunAgentRequest{
+		Messages: []api.Message{api.NewTextMessage("user", "hello")},
+	}, api.RunAgentOptions{})
+	if err != nil {
+		t.Fatalf("RunAgent: %v", err)
+	}
+	if resp.BudgetExhausted() {
+		t.Error("a natural completion must not report an exhausted budget")
+	}
+}
+
+// TestRun_TestToolForcesToolChoice verifies that TestTool runs the agent with
+// a tool_choice naming the tool and returns that tool's input and result.
+func TestRun_TestToolForcesToolChoice(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	const agentName = "tool-agent"
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: agentName}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunReply(agentName, regression.BuildSSEReply("42 orders", "analyst"))
+
+	result, err := client.TestTool(ctx, testDB, testSchema, agentName, "analyst", "how many orders?")
+	if err != nil {
+		t.Fatalf("TestTool: %v", err)
+	}
+	if result.ToolName != "analyst" || string(result.Result) != "{}" {
+		t.Errorf("result = %+v, want analyst tool result", result)
+	}
+	if result.Response != "42 orders" {
+		t.Errorf("Response = %q, want %q", result.Response, "42 orders")
+	}
+
+	var req struct {
+		ToolChoice struct {
+			Type string   `json:"type"`
+			Name []string `json:"name"`
+		} `json:"tool_choice"`
+	}
+	if err := json.Unmarshal(ms.LastRunRequest(agentName), &req); err != nil {
+		t.Fatalf("decode run request: %v", err)
+	}
+	if req.ToolChoice.Type != "tool" || len(req.ToolChoice.Name) != 1 || req.ToolChoice.Name[0] != "analyst" {
+		t.Errorf("tool_choice = %+v, want type tool naming analyst", req.ToolChoice)
+	}
+
+	ms.SetRunReply(agentName, regression.BuildSSEReply("no tools used"))
+	if _, err := client.TestTool(ctx, testDB, testSchema, agentName, "analyst", "hi"); err == nil || !strings.Contains(err.Error(), "did not invoke tool") {
+		t.Errorf("TestTool without tool call error = %v, want did not invoke tool", err)
+	}
+}
+
+// TestRun_ConflictThenSuccess verifies that a :run rejected with 409 because
+// another run is in progress is returned as a ConflictError, and that
+// RunAgent sends the run again when ConflictRetries allows it.
+func TestRun_ConflictThenSuccess(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	const agentName = "busy-agent"
+
+	if err := client.CreateAgent(ctx, testDB, testSchema, agent.AgentSpec{Name: agentName}); err != nil {
+		t.Fatalf("CreateAgent: %v", err)
+	}
+	ms.SetRunReply(agentName, regression.BuildSSEReply("finally"))
+	req := api.RunAgentRequest{
+		ThreadID: "7",
+		Messages: []api.Message{api.NewTextMessage("user", "hello")},
+	}
+
+	ms.SetRunConflicts(agentName, 1)
+	_, err := client.RunAgent(ctx, testDB, testSchema, agentName, req, api.RunAgentOptions{})
+	var conflict api.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("RunAgent error = %v, want ConflictError", err)
+	}
+	if conflict.StatusCode != 409 {
+		t.Errorf("StatusCode = %d, want 409", conflict.StatusCode)
+	}
+
+	ms.SetRunConflicts(agentName, 1)
+	var got string
+	var progress []string
+	_, err = client.RunAgent(ctx, testDB, testSchema, agentName, req, api.RunAgentOptions{
+		ConflictRetries: 2,
+		OnTextDelta:     func(d string) { got += d },
+		OnProgress:      func(phase string) { progress = append(progress, phase) },
+	})
+	if err != nil {
+		t.Fatalf("RunAgent with ConflictRetries: %v", err)
+	}
+	if got != "finally" {
+		t.Errorf("text = %q, want %q", got, "finally")
+	}
+	if !strings.Contains(strings.Join(progress, "\n"), "Another run is in progress; retrying") {
+		t.Errorf("expected a retry progress message, got %v", progress)
+	}
+}
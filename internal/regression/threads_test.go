@@ -98,3 +98,23 @@ func TestThreads_Continuity(t *testing.T) {
 		t.Errorf("expected 0 threads after delete, got %d", len(threads))
 	}
 }
+
+// TestThreads_CreateNamedThread verifies that the thread name is sent on
+// creation and returned by GetThread.
+func TestThreads_CreateNamedThread(t *testing.T) {
+	ms := regression.NewMockServer(t)
+	client := newTestClient(t, ms)
+	ctx := context.Background()
+
+	threadID, err := client.CreateNamedThread(ctx, "Q4 analysis")
+	if err != nil {
+		t.Fatalf("CreateNamedThread: %v", err)
+	}
+	got, err := client.GetThread(ctx, threadID)
+	if err != nil {
+		t.Fatalf("GetThread: %v", err)
+	}
+	if got.ThreadName != "Q4 analysis" {
+		t.Errorf("ThreadName = %q, want %q", got.ThreadName, "Q4 analysis")
+	}
+}
@@ -13,7 +13,8 @@ type ThreadState struct {
 	ThreadID      string    `json:"thread_id"`
 	LastMessageID int64     `json:"last_message_id"`
 	LastUsed      time.Time `json:"last_used"`
-	Summary       string    `json:"summary"` // First message or auto-generated summary
+	Summary       string    `json:"summary"`        // First message or auto-generated summary
+	Name          string    `json:"name,omitempty"` // Label set with run --thread-name
 }
 
 // StateStore holds thread state for all agents.
@@ -86,10 +87,13 @@ func (s *StateStore) AddOrUpdateThread(account, db, schema, agent string, state
 		if threads[i].ThreadID == state.ThreadID {
 			threads[i].LastMessageID = state.LastMessageID
 			threads[i].LastUsed = state.LastUsed
-			// Keep original summary unless new one is provided
+			// Keep original summary and name unless new ones are provided
 			if state.Summary != "" {
 				threads[i].Summary = state.Summary
 			}
+			if state.Name != "" {
+				threads[i].Name = state.Name
+			}
 			s.Threads[key] = threads
 			return
 		}
@@ -259,3 +259,30 @@ func TestGetAllThreads(t *testing.T) {
 		t.Errorf("expected 2 threads for AGENT2")
 	}
 }
+
+func TestSaveAndLoadState_Name(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	store := &StateStore{Threads: make(map[string][]ThreadState)}
+	store.AddOrUpdateThread("ACCT", "DB", "SCH", "AGENT", ThreadState{ThreadID: "t1", Summary: "hello", Name: "Q4 analysis"})
+	// A later update without a name keeps the stored one.
+	store.AddOrUpdateThread("ACCT", "DB", "SCH", "AGENT", ThreadState{ThreadID: "t1", LastMessageID: 7})
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save error: %v", err)
+	}
+
+	loaded, err := LoadState()
+	if err != nil {
+		t.Fatalf("LoadState error: %v", err)
+	}
+	ts := loaded.FindThread("ACCT", "DB", "SCH", "AGENT", "t1")
+	if ts == nil {
+		t.Fatal("thread t1 not found after reload")
+	}
+	if ts.Name != "Q4 analysis" {
+		t.Errorf("Name = %q, want %q", ts.Name, "Q4 analysis")
+	}
+	if ts.LastMessageID != 7 {
+		t.Errorf("LastMessageID = %d, want 7", ts.LastMessageID)
+	}
+}